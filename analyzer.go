@@ -0,0 +1,349 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// defaultAnalyzerLanguage is used when a document or query does not name its language,
+// matching the language writes default to
+const defaultAnalyzerLanguage = "en"
+
+// Analyzer turns text into the terms that are indexed and matched. Documents and queries
+// must go through the same analyzer for their terms to meet.
+type Analyzer interface {
+	Analyze(text string) []string
+}
+
+// AnalyzerConfig describes a text analysis chain: tokenization, lowercasing, accent folding,
+// stop-word removal and stemming, in that order
+type AnalyzerConfig struct {
+	Language       string   `json:"language"`
+	StopWords      []string `json:"stop_words"`
+	Stem           bool     `json:"stem"`             // Porter stemming; only applies to English
+	FoldAccents    bool     `json:"fold_accents"`     // Map "café" to "cafe"
+	CJKBigrams     bool     `json:"cjk_bigrams"`      // Index runs of CJK characters as overlapping bigrams
+	MinTokenLength int      `json:"min_token_length"` // Shorter non-CJK tokens are dropped
+}
+
+// TextAnalyzer is the standard Analyzer built from an AnalyzerConfig
+type TextAnalyzer struct {
+	language    string
+	stopWords   map[string]bool
+	stemmer     func(string) string
+	foldAccents bool
+	cjkBigrams  bool
+	minLength   int
+}
+
+// NewTextAnalyzer builds an analyzer; stop words are folded like the text they are checked against
+func NewTextAnalyzer(config AnalyzerConfig) *TextAnalyzer {
+	analyzer := &TextAnalyzer{
+		language:    normalizeLanguage(config.Language),
+		stopWords:   make(map[string]bool, len(config.StopWords)),
+		foldAccents: config.FoldAccents,
+		cjkBigrams:  config.CJKBigrams,
+		minLength:   config.MinTokenLength,
+	}
+
+	for _, word := range config.StopWords {
+		word = strings.ToLower(word)
+		if analyzer.foldAccents {
+			word = foldAccents(word)
+		}
+		analyzer.stopWords[word] = true
+	}
+
+	if config.Stem && analyzer.language == "en" {
+		analyzer.stemmer = porterStem
+	}
+
+	return analyzer
+}
+
+// Language returns the language code the analyzer was built for
+func (a *TextAnalyzer) Language() string {
+	return a.language
+}
+
+// IsStopWord reports whether a lowercased token is one of the analyzer's stop words
+func (a *TextAnalyzer) IsStopWord(token string) bool {
+	return a.stopWords[token]
+}
+
+// Analyze splits text into terms. Letters and digits form tokens, everything else separates
+// them, and runs of CJK characters become bigrams since they are written without spaces.
+func (a *TextAnalyzer) Analyze(text string) []string {
+	var terms []string
+	var token strings.Builder
+	var cjk []rune
+
+	flushToken := func() {
+		if token.Len() == 0 {
+			return
+		}
+		if term, ok := a.normalizeToken(token.String()); ok {
+			terms = append(terms, term)
+		}
+		token.Reset()
+	}
+	flushCJK := func() {
+		terms = append(terms, a.cjkTerms(cjk)...)
+		cjk = cjk[:0]
+	}
+
+	for _, r := range text {
+		r = unicode.ToLower(r)
+		switch {
+		case isCJKRune(r):
+			flushToken()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			token.WriteRune(r)
+		case unicode.Is(unicode.Mn, r):
+			// Combining marks belong to the letter before them; folding drops them
+			if !a.foldAccents && token.Len() > 0 {
+				token.WriteRune(r)
+			}
+		default:
+			flushToken()
+			flushCJK()
+		}
+	}
+	flushToken()
+	flushCJK()
+
+	return terms
+}
+
+// normalizeToken folds, filters and stems one lowercased token
+func (a *TextAnalyzer) normalizeToken(token string) (string, bool) {
+	if a.foldAccents {
+		token = foldAccents(token)
+	}
+	if len([]rune(token)) < a.minLength || a.stopWords[token] {
+		return "", false
+	}
+	if a.stemmer != nil {
+		token = a.stemmer(token)
+	}
+	return token, true
+}
+
+// cjkTerms turns a run of CJK characters into overlapping bigrams, or single characters when
+// bigrams are disabled or the run is one character long
+func (a *TextAnalyzer) cjkTerms(run []rune) []string {
+	if len(run) == 0 {
+		return nil
+	}
+	if !a.cjkBigrams || len(run) == 1 {
+		terms := make([]string, len(run))
+		for i, r := range run {
+			terms[i] = string(r)
+		}
+		return terms
+	}
+	terms := make([]string, 0, len(run)-1)
+	for i := 0; i+1 < len(run); i++ {
+		terms = append(terms, string(run[i:i+2]))
+	}
+	return terms
+}
+
+// isCJKRune reports whether r is a Chinese, Japanese or Korean character
+func isCJKRune(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)
+}
+
+// accentFolds maps accented Latin letters to their unaccented forms
+var accentFolds = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'ç': "c", 'ć': "c", 'ĉ': "c", 'ċ': "c", 'č': "c",
+	'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ĕ': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'ĝ': "g", 'ğ': "g", 'ġ': "g", 'ģ': "g",
+	'ĥ': "h", 'ħ': "h",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ĩ': "i", 'ī': "i", 'ĭ': "i", 'į': "i", 'ı': "i",
+	'ĵ': "j", 'ķ': "k",
+	'ĺ': "l", 'ļ': "l", 'ľ': "l", 'ŀ': "l", 'ł': "l",
+	'ñ': "n", 'ń': "n", 'ņ': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ŏ': "o", 'ő': "o",
+	'ŕ': "r", 'ŗ': "r", 'ř': "r",
+	'ś': "s", 'ŝ': "s", 'ş': "s", 'š': "s", 'ș': "s",
+	'ţ': "t", 'ť': "t", 'ŧ': "t", 'ț': "t",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ũ': "u", 'ū': "u", 'ŭ': "u", 'ů': "u", 'ű': "u", 'ų': "u",
+	'ŵ': "w", 'ý': "y", 'ÿ': "y", 'ŷ': "y",
+	'ź': "z", 'ż': "z", 'ž': "z",
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'þ': "th",
+}
+
+// foldAccents replaces accented Latin letters in lowercased text with plain ones
+func foldAccents(text string) string {
+	ascii := true
+	for i := 0; i < len(text); i++ {
+		if text[i] >= 0x80 {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return text
+	}
+
+	var folded strings.Builder
+	for _, r := range text {
+		if plain, ok := accentFolds[r]; ok {
+			folded.WriteString(plain)
+		} else if !unicode.Is(unicode.Mn, r) {
+			folded.WriteRune(r)
+		}
+	}
+	return folded.String()
+}
+
+// normalizeLanguage reduces a language tag such as "en-US" to its lowercase primary subtag
+func normalizeLanguage(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if i := strings.IndexAny(language, "-_"); i >= 0 {
+		language = language[:i]
+	}
+	return language
+}
+
+// AnalyzerConfigForLanguage returns the built-in analysis chain for a language code. Languages
+// without their own stop words get the standard chain: folding and CJK bigrams only.
+func AnalyzerConfigForLanguage(language string) AnalyzerConfig {
+	language = normalizeLanguage(language)
+	if language == "" {
+		language = defaultAnalyzerLanguage
+	}
+
+	return AnalyzerConfig{
+		Language:       language,
+		StopWords:      stopWordLists[language],
+		Stem:           language == "en",
+		FoldAccents:    true,
+		CJKBigrams:     true,
+		MinTokenLength: 1,
+	}
+}
+
+// AnalyzerRegistry selects the analyzer for a language code, building the built-in chain for
+// languages that have not been registered explicitly
+type AnalyzerRegistry struct {
+	mu        sync.RWMutex
+	analyzers map[string]Analyzer
+}
+
+// NewAnalyzerRegistry creates a registry serving the built-in analyzers
+func NewAnalyzerRegistry() *AnalyzerRegistry {
+	return &AnalyzerRegistry{
+		analyzers: make(map[string]Analyzer),
+	}
+}
+
+// Register installs an analyzer for a language code, replacing the built-in one
+func (r *AnalyzerRegistry) Register(language string, analyzer Analyzer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.analyzers[normalizeLanguage(language)] = analyzer
+}
+
+// ForLanguage returns the analyzer for a language code; an empty code means English
+func (r *AnalyzerRegistry) ForLanguage(language string) Analyzer {
+	language = normalizeLanguage(language)
+	if language == "" {
+		language = defaultAnalyzerLanguage
+	}
+
+	r.mu.RLock()
+	analyzer, exists := r.analyzers[language]
+	r.mu.RUnlock()
+	if exists {
+		return analyzer
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if analyzer, exists := r.analyzers[language]; exists {
+		return analyzer
+	}
+	analyzer = NewTextAnalyzer(AnalyzerConfigForLanguage(language))
+	r.analyzers[language] = analyzer
+	return analyzer
+}
+
+// surfaceAnalyzer splits text into lowercased, folded words without dropping or stemming any,
+// for suggestions that show words as they were written
+var surfaceAnalyzer = NewTextAnalyzer(AnalyzerConfig{FoldAccents: true, MinTokenLength: 1})
+
+// defaultAnalyzers is shared by stores and searchers that are not given their own registry
+var defaultAnalyzers = NewAnalyzerRegistry()
+
+// AnalyzerForLanguage returns the analyzer the default registry holds for a language code
+func AnalyzerForLanguage(language string) Analyzer {
+	return defaultAnalyzers.ForLanguage(language)
+}
+
+// documentLanguage reads the language a document was written in from its metadata
+func documentLanguage(metadata map[string]interface{}) string {
+	language, _ := metadata["language"].(string)
+	return language
+}
+
+// documentText returns the text a document is indexed under: its content, preceded by the
+// heading breadcrumb of a Markdown chunk so queries naming a section find the chunks under it
+func documentText(content string, metadata map[string]interface{}) string {
+	if path := headingPath(metadata); path != "" {
+		return path + "\n" + content
+	}
+	return content
+}
+
+// stopWordLists holds the built-in stop words per language code
+var stopWordLists = map[string][]string{
+	"en": {
+		"a", "an", "and", "are", "as", "at", "be", "been", "but", "by", "can", "could", "did",
+		"do", "does", "for", "from", "had", "has", "have", "he", "her", "him", "his", "i", "if",
+		"in", "into", "is", "it", "its", "may", "me", "might", "must", "my", "no", "not", "of",
+		"on", "or", "our", "she", "should", "so", "such", "than", "that", "the", "their", "them",
+		"then", "there", "these", "they", "this", "those", "to", "us", "was", "we", "were",
+		"will", "with", "would", "you", "your",
+	},
+	"de": {
+		"aber", "als", "am", "an", "auch", "auf", "aus", "bei", "bin", "bis", "da", "das", "dass",
+		"dem", "den", "der", "des", "die", "doch", "du", "durch", "ein", "eine", "einem", "einen",
+		"einer", "eines", "er", "es", "für", "hat", "ich", "ihr", "im", "in", "ist", "ja", "kein",
+		"mit", "nach", "nicht", "noch", "nur", "oder", "sich", "sie", "sind", "so", "um", "und",
+		"uns", "von", "vor", "war", "was", "wie", "wir", "wird", "zu", "zum", "zur",
+	},
+	"fr": {
+		"au", "aux", "avec", "ce", "ces", "cette", "dans", "de", "des", "du", "elle", "en", "est",
+		"et", "été", "il", "ils", "je", "la", "le", "les", "leur", "lui", "mais", "me", "même",
+		"mes", "ne", "nous", "on", "ou", "par", "pas", "pour", "qu", "que", "qui", "sa", "se",
+		"ses", "son", "sur", "ta", "te", "tu", "un", "une", "vous", "y",
+	},
+	"es": {
+		"al", "como", "con", "de", "del", "el", "ella", "en", "entre", "es", "esta", "este", "fue",
+		"ha", "la", "las", "le", "lo", "los", "más", "me", "mi", "no", "o", "para", "pero", "por",
+		"que", "se", "si", "sin", "sobre", "su", "sus", "también", "te", "tu", "un", "una", "y", "ya",
+	},
+	"it": {
+		"al", "alla", "anche", "che", "chi", "con", "da", "dal", "dei", "del", "della", "di", "e",
+		"è", "gli", "ha", "il", "in", "la", "le", "lo", "ma", "mi", "nel", "nella", "non", "per",
+		"più", "se", "si", "su", "sua", "suo", "ti", "tra", "un", "una", "uno",
+	},
+	"pt": {
+		"a", "ao", "aos", "as", "com", "como", "da", "das", "de", "do", "dos", "e", "é", "ela",
+		"ele", "em", "entre", "foi", "mais", "mas", "na", "nas", "no", "nos", "não", "o", "os",
+		"ou", "para", "pela", "pelo", "por", "que", "se", "sem", "seu", "sua", "um", "uma",
+	},
+	"nl": {
+		"aan", "al", "bij", "dat", "de", "den", "der", "die", "dit", "een", "en", "er", "het",
+		"hij", "ik", "in", "is", "je", "maar", "met", "na", "niet", "nog", "of", "om", "ook", "op",
+		"over", "te", "tot", "uit", "van", "voor", "was", "wat", "we", "wel", "zij", "zijn", "zo",
+	},
+}
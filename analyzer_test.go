@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTextAnalyzer(t *testing.T) {
+	Convey("Given the built-in analyzers", t, func() {
+		Convey("When analyzing English text", func() {
+			terms := AnalyzerForLanguage("en").Analyze("The engineers are indexing Café documents")
+
+			Convey("Then stop words should be dropped and terms folded and stemmed", func() {
+				So(terms, ShouldResemble, []string{"engin", "index", "cafe", "document"})
+			})
+		})
+
+		Convey("When analyzing German text", func() {
+			terms := AnalyzerForLanguage("de-DE").Analyze("Die Größe und das Gewicht")
+
+			Convey("Then German stop words should be dropped without English stemming", func() {
+				So(terms, ShouldResemble, []string{"grosse", "gewicht"})
+			})
+		})
+
+		Convey("When analyzing CJK text", func() {
+			terms := AnalyzerForLanguage("zh").Analyze("向量数据库 v2")
+
+			Convey("Then runs of CJK characters should become bigrams", func() {
+				So(terms, ShouldResemble, []string{"向量", "量数", "数据", "据库", "v2"})
+			})
+		})
+
+		Convey("When analyzing a decomposed accent", func() {
+			terms := AnalyzerForLanguage("fr").Analyze("café été")
+
+			Convey("Then the combining mark should be folded away", func() {
+				So(terms, ShouldResemble, []string{"cafe"})
+			})
+		})
+
+		Convey("When building an analyzer without folding or bigrams", func() {
+			analyzer := NewTextAnalyzer(AnalyzerConfig{Language: "xx", StopWords: []string{"und"}, MinTokenLength: 2})
+			terms := analyzer.Analyze("Ärger und a 数据库")
+
+			Convey("Then accents and single CJK characters should be kept", func() {
+				So(terms, ShouldResemble, []string{"ärger", "数", "据", "库"})
+			})
+		})
+	})
+}
+
+func TestAnalyzerRegistry(t *testing.T) {
+	Convey("Given an analyzer registry", t, func() {
+		registry := NewAnalyzerRegistry()
+
+		Convey("When no language is given", func() {
+			Convey("Then English should be used", func() {
+				So(registry.ForLanguage(""), ShouldEqual, registry.ForLanguage("EN"))
+			})
+		})
+
+		Convey("When an analyzer is registered", func() {
+			custom := NewTextAnalyzer(AnalyzerConfig{Language: "en"})
+			registry.Register("en-GB", custom)
+
+			Convey("Then it should replace the built-in chain for that language", func() {
+				So(registry.ForLanguage("en"), ShouldEqual, custom)
+			})
+		})
+	})
+}
+
+func TestFileSearchIndexLanguages(t *testing.T) {
+	Convey("Given a FileSearchIndex with documents in several languages", t, func() {
+		ctx := context.Background()
+		index := NewFileSearchIndex(filepath.Join(t.TempDir(), "search_index.json"))
+
+		So(index.Index(ctx, IndexDocument{ID: "en", Content: "Indexing documents quickly", Metadata: map[string]interface{}{"language": "en"}}), ShouldBeNil)
+		So(index.Index(ctx, IndexDocument{ID: "de", Content: "Die Größe der Datenbank", Metadata: map[string]interface{}{"language": "de"}}), ShouldBeNil)
+		So(index.Index(ctx, IndexDocument{ID: "zh", Content: "向量数据库的设计", Metadata: map[string]interface{}{"language": "zh"}}), ShouldBeNil)
+
+		Convey("Then an English query should match inflected forms", func() {
+			results, err := index.Search(ctx, "indexed document", SearchIndexOptions{})
+			So(err, ShouldBeNil)
+			So(len(results), ShouldEqual, 1)
+			So(results[0].ID, ShouldEqual, "en")
+		})
+
+		Convey("Then a German query should match folded words", func() {
+			results, err := index.Search(ctx, "grosse", SearchIndexOptions{Language: "de"})
+			So(err, ShouldBeNil)
+			So(len(results), ShouldEqual, 1)
+			So(results[0].ID, ShouldEqual, "de")
+		})
+
+		Convey("Then a Chinese query should match through bigrams", func() {
+			results, err := index.Search(ctx, `"数据库"`, SearchIndexOptions{Language: "zh"})
+			So(err, ShouldBeNil)
+			So(len(results), ShouldEqual, 1)
+			So(results[0].ID, ShouldEqual, "zh")
+		})
+
+		Convey("Then suggestions should be unstemmed words", func() {
+			suggestions, err := index.Suggest(ctx, "ind", "", 5)
+			So(err, ShouldBeNil)
+			So(suggestions, ShouldResemble, []string{"indexing"})
+		})
+	})
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// persistChunkGraph writes a chunk's graph view: a node for its source and for the chunk,
+// its entity and claim nodes, PART_OF edges up to the chunk and source, RELATED_TO edges
+// between co-occurring entities, edges from claims to their subject and object entities,
+// SUPPORTS and REFUTES edges from claims to the stored claims they agree with or contradict,
+// and a RELATED_TO edge to the chunk it nearly duplicates when it was stored under the link policy
+func persistChunkGraph(ctx context.Context, graphStore GraphStore, chunk *Chunk) error {
+	now := time.Now()
+
+	chunkNode := &Node{
+		ID:   chunk.ID,
+		Type: ChunkNode,
+		Properties: map[string]interface{}{
+			"chunk_id": chunk.ID,
+			"source":   chunk.Source,
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := upsertNode(ctx, graphStore, chunkNode); err != nil {
+		return fmt.Errorf("failed to create chunk node: %w", err)
+	}
+
+	if chunk.Source != "" {
+		sourceNode := &Node{
+			ID:   sourceNodeID(chunk.Source),
+			Type: SourceNode,
+			Properties: map[string]interface{}{
+				"name": chunk.Source,
+			},
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := upsertNode(ctx, graphStore, sourceNode); err != nil {
+			return fmt.Errorf("failed to create source node: %w", err)
+		}
+		if err := linkOnce(ctx, graphStore, chunk.ID, sourceNode.ID, PartOf, nil); err != nil {
+			return fmt.Errorf("failed to link chunk to source: %w", err)
+		}
+	}
+
+	// A chunk stored despite nearly duplicating another is linked to the one it duplicates
+	if duplicateID, ok := chunk.Metadata["duplicate_of"].(string); ok && duplicateID != "" {
+		if _, err := graphStore.GetNode(ctx, duplicateID); err == nil {
+			properties := map[string]interface{}{"relation": "near_duplicate"}
+			if err := linkOnce(ctx, graphStore, chunk.ID, duplicateID, RelatedTo, properties); err != nil {
+				return fmt.Errorf("failed to link chunk to its duplicate: %w", err)
+			}
+		}
+	}
+
+	var entityIDs []string
+	for _, entity := range chunk.Entities {
+		node := &Node{
+			ID:   entity.ID,
+			Type: EntityNode,
+			Properties: map[string]interface{}{
+				"name":       entity.Name,
+				"type":       entity.Type,
+				"confidence": entity.Confidence,
+			},
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		// Entity resolution may already have created the node; update it instead. Entities
+		// are shared between chunks, so the chunks they belong to are only their PART_OF edges
+		if err := upsertNode(ctx, graphStore, node); err != nil {
+			return fmt.Errorf("failed to create entity node: %w", err)
+		}
+		if err := linkOnce(ctx, graphStore, entity.ID, chunk.ID, PartOf, nil); err != nil {
+			return fmt.Errorf("failed to link entity to chunk: %w", err)
+		}
+		entityIDs = append(entityIDs, entity.ID)
+	}
+
+	// Each chunk two entities share adds one to the weight of the edges between them
+	entityIDs = uniqueSorted(entityIDs)
+	for i := 0; i < len(entityIDs); i++ {
+		for j := i + 1; j < len(entityIDs); j++ {
+			if err := addCooccurrence(ctx, graphStore, entityIDs[i], entityIDs[j]); err != nil {
+				return fmt.Errorf("failed to link co-occurring entities: %w", err)
+			}
+			if err := addCooccurrence(ctx, graphStore, entityIDs[j], entityIDs[i]); err != nil {
+				return fmt.Errorf("failed to link co-occurring entities: %w", err)
+			}
+		}
+	}
+
+	for _, claim := range chunk.Claims {
+		// Compare the claim with stored claims before it joins them; agreement raises its confidence
+		statement := parseClaimStatement(claim.Subject, claim.Predicate, claim.Object)
+		related, err := findRelatedClaims(ctx, graphStore, chunk.ID, statement)
+		if err != nil {
+			return err
+		}
+		confidence := claim.Confidence
+		for _, stored := range related {
+			if stored.relation == claimAgrees {
+				storedConfidence, _ := filterNumber(stored.node.Properties["confidence"])
+				confidence = corroborate(confidence, storedConfidence)
+			}
+		}
+
+		node := &Node{
+			ID:   claim.ID,
+			Type: ClaimNode,
+			Properties: map[string]interface{}{
+				"subject":    claim.Subject,
+				"predicate":  claim.Predicate,
+				"object":     claim.Object,
+				"confidence": confidence,
+				"chunk_id":   chunk.ID,
+				"claim_key":  statement.key(),
+			},
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := upsertNode(ctx, graphStore, node); err != nil {
+			return fmt.Errorf("failed to create claim node: %w", err)
+		}
+		if err := linkOnce(ctx, graphStore, claim.ID, chunk.ID, PartOf, nil); err != nil {
+			return fmt.Errorf("failed to link claim to chunk: %w", err)
+		}
+		if err := linkRelatedClaims(ctx, graphStore, claim.ID, chunk.ID, claim.Confidence, related); err != nil {
+			return err
+		}
+
+		for _, role := range []struct{ name, mention string }{
+			{"subject", claim.Subject},
+			{"object", claim.Object},
+		} {
+			entityID := resolveClaimEntity(ctx, graphStore, chunk.Entities, role.mention)
+			if entityID == "" {
+				continue
+			}
+			properties := map[string]interface{}{"role": role.name}
+			if err := linkOnce(ctx, graphStore, claim.ID, entityID, RelatedTo, properties); err != nil {
+				return fmt.Errorf("failed to link claim %s to entity: %w", role.name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveClaimEntity finds the entity a claim's subject or object refers to, preferring
+// entities from the same chunk and falling back to the graph's entities of the same name
+func resolveClaimEntity(ctx context.Context, graphStore GraphStore, entities []Entity, mention string) string {
+	if strings.TrimSpace(mention) == "" {
+		return ""
+	}
+
+	bestID := ""
+	bestScore := 0.0
+	for _, entity := range entities {
+		if score := entityLinkScore(mention, entity.Name); score > bestScore {
+			bestID = entity.ID
+			bestScore = score
+		}
+	}
+	if bestID != "" {
+		return bestID
+	}
+
+	nodes, err := graphStore.FindEntitiesByName(ctx, mention)
+	if err != nil || len(nodes) == 0 {
+		return ""
+	}
+	return nodes[0].ID
+}
+
+// nodeChunkIDs returns the chunks a node belongs to: a chunk node is its own, a claim
+// records its chunk, and an entity is PART_OF every chunk that mentions it
+func nodeChunkIDs(ctx context.Context, graphStore GraphStore, node *Node) []string {
+	if node.Type == EntityNode {
+		return entityChunks(ctx, graphStore, node.ID, "")
+	}
+	if chunkID, ok := node.Properties["chunk_id"].(string); ok && chunkID != "" {
+		return []string{chunkID}
+	}
+	return nil
+}
+
+// upsertNode creates a node, updating it if it already exists
+func upsertNode(ctx context.Context, graphStore GraphStore, node *Node) error {
+	err := graphStore.CreateNode(ctx, node)
+	if err == nil {
+		return nil
+	}
+	if existing, getErr := graphStore.GetNode(ctx, node.ID); getErr == nil {
+		node.CreatedAt = existing.CreatedAt
+	}
+	if updateErr := graphStore.UpdateNode(ctx, node); updateErr != nil {
+		return err
+	}
+	return nil
+}
+
+// linkOnce creates an edge unless an edge with the same type and endpoints already exists
+func linkOnce(ctx context.Context, graphStore GraphStore, from, to string, edgeType EdgeType, properties map[string]interface{}) error {
+	id := graphEdgeID(edgeType, from, to)
+	if _, err := graphStore.GetEdge(ctx, id); err == nil {
+		return nil
+	}
+
+	edge := NewEdge(id, from, to, edgeType, 1.0)
+	for key, value := range properties {
+		edge.Properties[key] = value
+	}
+	return graphStore.CreateEdge(ctx, edge)
+}
+
+// addCooccurrence adds one to the weight of the RELATED_TO edge from one entity to another
+func addCooccurrence(ctx context.Context, graphStore GraphStore, from, to string) error {
+	id := graphEdgeID(RelatedTo, from, to)
+	existing, err := graphStore.GetEdge(ctx, id)
+	if err != nil {
+		edge := NewEdge(id, from, to, RelatedTo, 1.0)
+		edge.Properties["cooccurrences"] = 1
+		return graphStore.CreateEdge(ctx, edge)
+	}
+
+	updated := *existing
+	updated.Properties = make(map[string]interface{}, len(existing.Properties)+1)
+	for key, value := range existing.Properties {
+		updated.Properties[key] = value
+	}
+	updated.Weight = existing.Weight + 1
+	updated.Properties["cooccurrences"] = int(updated.Weight)
+	return graphStore.UpdateEdge(ctx, &updated)
+}
+
+// removeCooccurrence takes one from the weight of the RELATED_TO edge from one entity to
+// another, deleting the edge once no chunk mentions both
+func removeCooccurrence(ctx context.Context, graphStore GraphStore, from, to string) error {
+	id := graphEdgeID(RelatedTo, from, to)
+	existing, err := graphStore.GetEdge(ctx, id)
+	if err != nil {
+		return nil
+	}
+	if existing.Weight <= 1 {
+		return graphStore.DeleteEdge(ctx, id)
+	}
+
+	updated := *existing
+	updated.Properties = make(map[string]interface{}, len(existing.Properties)+1)
+	for key, value := range existing.Properties {
+		updated.Properties[key] = value
+	}
+	updated.Weight = existing.Weight - 1
+	updated.Properties["cooccurrences"] = int(updated.Weight)
+	return graphStore.UpdateEdge(ctx, &updated)
+}
+
+// repointChunkEdges re-creates PART_OF edges from a chunk's entities and claims onto another chunk
+func repointChunkEdges(ctx context.Context, graphStore GraphStore, nodes []*Node, toID string) error {
+	if _, err := graphStore.GetNode(ctx, toID); err != nil {
+		return nil
+	}
+	for _, node := range nodes {
+		if err := linkOnce(ctx, graphStore, node.ID, toID, PartOf, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// graphEdgeID derives a stable edge ID from its type and endpoints
+func graphEdgeID(edgeType EdgeType, from, to string) string {
+	return fmt.Sprintf("%s_%s_%s", strings.ToLower(string(edgeType)), from, to)
+}
+
+// sourceNodeID derives the node ID for a source
+func sourceNodeID(source string) string {
+	return "source_" + source
+}
+
+// uniqueSorted returns the distinct non-empty values in sorted order
+func uniqueSorted(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var unique []string
+	for _, value := range values {
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		unique = append(unique, value)
+	}
+	sort.Strings(unique)
+	return unique
+}
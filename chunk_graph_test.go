@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// newTestGraphChunk creates a chunk from source mentioning the named entities
+func newTestGraphChunk(id, source string, entityNames ...string) *Chunk {
+	chunk := NewChunk(id, "content", source)
+	for _, name := range entityNames {
+		chunk.AddEntity(*NewEntity("entity_"+name, name, "Concept", source))
+	}
+	return chunk
+}
+
+func TestPersistChunkGraph(t *testing.T) {
+	Convey("Given an empty graph store", t, func() {
+		ctx := context.Background()
+		graphStore := NewMockGraphStore()
+
+		Convey("When persisting a chunk with entities and a claim", func() {
+			chunk := newTestGraphChunk("notes_chunk_0", "notes", "Go", "Rust")
+			chunk.AddClaim(*NewClaim("claim_0", "Go", "compiles faster than", "Rust", "notes"))
+			So(persistChunkGraph(ctx, graphStore, chunk), ShouldBeNil)
+
+			edges := make(map[string]*Edge)
+			for _, edge := range graphStore.GetEdges() {
+				edges[edge.ID] = edge
+			}
+
+			Convey("Then source, chunk, entity and claim nodes should exist", func() {
+				nodes := graphStore.GetNodes()
+				So(nodes["source_notes"].Type, ShouldEqual, SourceNode)
+				So(nodes["notes_chunk_0"].Type, ShouldEqual, ChunkNode)
+				So(nodes["entity_Go"].Type, ShouldEqual, EntityNode)
+				So(nodes["claim_0"].Type, ShouldEqual, ClaimNode)
+			})
+
+			Convey("Then PART_OF edges should lead from entities and claims to the chunk and on to its source", func() {
+				So(edges["part_of_notes_chunk_0_source_notes"].Type, ShouldEqual, PartOf)
+				So(edges["part_of_entity_Go_notes_chunk_0"].Type, ShouldEqual, PartOf)
+				So(edges["part_of_entity_Rust_notes_chunk_0"].Type, ShouldEqual, PartOf)
+				So(edges["part_of_claim_0_notes_chunk_0"].Type, ShouldEqual, PartOf)
+			})
+
+			Convey("Then co-occurring entities should be related in both directions", func() {
+				So(edges["related_to_entity_Go_entity_Rust"].Weight, ShouldEqual, 1.0)
+				So(edges["related_to_entity_Rust_entity_Go"].Weight, ShouldEqual, 1.0)
+			})
+
+			Convey("Then the claim should link to its subject and object entities", func() {
+				So(edges["related_to_claim_0_entity_Go"].Properties["role"], ShouldEqual, "subject")
+				So(edges["related_to_claim_0_entity_Rust"].Properties["role"], ShouldEqual, "object")
+			})
+		})
+
+		Convey("When the same entities co-occur in several chunks", func() {
+			So(persistChunkGraph(ctx, graphStore, newTestGraphChunk("a_chunk_0", "a", "Go", "Rust")), ShouldBeNil)
+			So(persistChunkGraph(ctx, graphStore, newTestGraphChunk("b_chunk_0", "b", "Go", "Rust", "Zig")), ShouldBeNil)
+
+			Convey("Then the RELATED_TO weight should count the chunks they share", func() {
+				goRust, err := graphStore.GetEdge(ctx, "related_to_entity_Go_entity_Rust")
+				So(err, ShouldBeNil)
+				So(goRust.Weight, ShouldEqual, 2.0)
+				So(goRust.Properties["cooccurrences"], ShouldEqual, 2)
+
+				goZig, err := graphStore.GetEdge(ctx, "related_to_entity_Go_entity_Zig")
+				So(err, ShouldBeNil)
+				So(goZig.Weight, ShouldEqual, 1.0)
+			})
+
+			Convey("Then each entity should be part of both chunks", func() {
+				neighbors, err := graphStore.GetNeighbors(ctx, "entity_Go", GraphTraversalOptions{MaxDepth: 1, EdgeTypes: []EdgeType{PartOf}})
+				So(err, ShouldBeNil)
+				So(len(neighbors), ShouldEqual, 2)
+			})
+
+			Convey("Then a shared entity should not record a single owning chunk", func() {
+				node, err := graphStore.GetNode(ctx, "entity_Go")
+				So(err, ShouldBeNil)
+				So(node.Properties, ShouldNotContainKey, "chunk_id")
+				So(nodeChunkIDs(ctx, graphStore, node), ShouldResemble, []string{"a_chunk_0", "b_chunk_0"})
+			})
+		})
+
+		Convey("When a claim names an entity stored by an earlier chunk", func() {
+			So(persistChunkGraph(ctx, graphStore, newTestGraphChunk("a_chunk_0", "a", "Paris")), ShouldBeNil)
+			chunk := NewChunk("b_chunk_0", "content", "b")
+			chunk.AddClaim(*NewClaim("claim_paris", "Tourism", "grows in", "paris", "b"))
+			So(persistChunkGraph(ctx, graphStore, chunk), ShouldBeNil)
+
+			Convey("Then the claim should be linked to the existing entity", func() {
+				edge, err := graphStore.GetEdge(ctx, "related_to_claim_paris_entity_Paris")
+				So(err, ShouldBeNil)
+				So(edge.Properties["role"], ShouldEqual, "object")
+			})
+		})
+
+		Convey("When a chunk is stored twice", func() {
+			chunk := newTestGraphChunk("a_chunk_0", "a", "Go", "Rust")
+			So(persistChunkGraph(ctx, graphStore, chunk), ShouldBeNil)
+			edgeCount := len(graphStore.GetEdges())
+			So(persistChunkGraph(ctx, graphStore, chunk), ShouldBeNil)
+
+			Convey("Then structural edges should not be duplicated", func() {
+				So(len(graphStore.GetEdges()), ShouldEqual, edgeCount)
+			})
+		})
+	})
+}
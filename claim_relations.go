@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// claimRelation is how a new claim bears on a stored one
+type claimRelation int
+
+const (
+	claimUnrelated   claimRelation = iota
+	claimAgrees                    // Same subject, predicate, object and polarity
+	claimNegates                   // Same statement with the opposite polarity
+	claimContradicts               // Same subject and predicate with a different object
+)
+
+// claimNegations mark a claim as negated when they lead its object or end its predicate
+var claimNegations = map[string]bool{
+	"not": true, "never": true, "no": true, "cannot": true,
+}
+
+// claimPredicateForms folds the forms of common auxiliary verbs together so "is" and
+// "was" state the same relation
+var claimPredicateForms = map[string]string{
+	"is": "be", "are": "be", "was": "be", "were": "be", "am": "be", "be": "be", "been": "be", "being": "be",
+	"has": "have", "have": "have", "had": "have",
+	"does": "do", "do": "do", "did": "do", "done": "do", "doing": "do",
+}
+
+// multiValuedPredicates relate a subject to many objects at once, so a different object
+// adds to what is known rather than contradicting it
+var multiValuedPredicates = map[string]bool{
+	"have": true, "contain": true, "includ": true, "involv": true, "requir": true,
+}
+
+// claimStatement is a claim normalized for comparison: lower-cased words without
+// punctuation or leading articles, the predicate reduced to a base form and any negation
+// moved into a flag
+type claimStatement struct {
+	subject   string
+	predicate string
+	object    string
+	negated   bool
+}
+
+// parseClaimStatement normalizes the parts of a claim
+func parseClaimStatement(subject, predicate, object string) claimStatement {
+	statement := claimStatement{subject: strings.Join(claimWords(subject), " ")}
+
+	var predicateWords []string
+	for _, word := range claimWords(predicate) {
+		if claimNegations[word] {
+			statement.negated = !statement.negated
+			continue
+		}
+		if base, ok := claimPredicateForms[word]; ok {
+			word = base
+		} else {
+			word = porterStem(word)
+		}
+		predicateWords = append(predicateWords, word)
+	}
+	statement.predicate = strings.Join(predicateWords, " ")
+
+	objectWords := claimWords(object)
+	for len(objectWords) > 0 && claimNegations[objectWords[0]] {
+		statement.negated = !statement.negated
+		objectWords = trimClaimArticles(objectWords[1:])
+	}
+	statement.object = strings.Join(objectWords, " ")
+
+	return statement
+}
+
+// key identifies the subject and predicate claims must share to bear on each other
+func (s claimStatement) key() string {
+	return s.subject + "|" + s.predicate
+}
+
+// relate compares a new statement with a stored one
+func (s claimStatement) relate(stored claimStatement) claimRelation {
+	if s.subject == "" || s.predicate == "" || s.key() != stored.key() {
+		return claimUnrelated
+	}
+	switch {
+	case s.object == stored.object && s.negated == stored.negated:
+		return claimAgrees
+	case s.object == stored.object:
+		return claimNegates
+	case !s.negated && !stored.negated && !multiValuedPredicates[s.predicate]:
+		return claimContradicts
+	}
+	return claimUnrelated
+}
+
+// claimWords splits text into lower-cased words, expanding "n't" into "not" and dropping
+// leading articles
+func claimWords(text string) []string {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	}) {
+		word = strings.Trim(word, "'")
+		if stem, ok := strings.CutSuffix(word, "n't"); ok {
+			switch stem {
+			case "ca":
+				stem = "can"
+			case "wo":
+				stem = "will"
+			}
+			if stem != "" {
+				words = append(words, stem)
+			}
+			words = append(words, "not")
+			continue
+		}
+		if word != "" {
+			words = append(words, strings.ReplaceAll(word, "'", ""))
+		}
+	}
+	return trimClaimArticles(words)
+}
+
+// trimClaimArticles drops the articles leading a phrase
+func trimClaimArticles(words []string) []string {
+	for len(words) > 0 && (words[0] == "the" || words[0] == "a" || words[0] == "an") {
+		words = words[1:]
+	}
+	return words
+}
+
+// claimNodeStatement reads the statement a stored claim node makes
+func claimNodeStatement(node *Node) claimStatement {
+	subject, _ := node.Properties["subject"].(string)
+	predicate, _ := node.Properties["predicate"].(string)
+	object, _ := node.Properties["object"].(string)
+	return parseClaimStatement(subject, predicate, object)
+}
+
+// corroborate combines the confidences of two independent claims that agree, so each
+// agreeing source raises confidence without ever reaching certainty
+func corroborate(a, b float64) float64 {
+	return 1 - (1-a)*(1-b)
+}
+
+// relatedClaim is a stored claim a new claim agrees with or contradicts
+type relatedClaim struct {
+	node     *Node
+	relation claimRelation
+}
+
+// findRelatedClaims returns the stored claims, from other chunks, that share a claim's
+// subject and predicate and agree with or contradict it
+func findRelatedClaims(ctx context.Context, graphStore GraphStore, chunkID string, statement claimStatement) ([]relatedClaim, error) {
+	if statement.subject == "" || statement.predicate == "" {
+		return nil, nil
+	}
+
+	nodes, err := graphStore.FindNodesByType(ctx, ClaimNode, map[string]interface{}{"claim_key": statement.key()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find claims: %w", err)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+
+	var related []relatedClaim
+	for _, node := range nodes {
+		if node.Properties["chunk_id"] == chunkID {
+			continue
+		}
+		if relation := statement.relate(claimNodeStatement(node)); relation != claimUnrelated {
+			related = append(related, relatedClaim{node: node, relation: relation})
+		}
+	}
+	return related, nil
+}
+
+// linkRelatedClaims records how a new claim bears on the stored claims related to it: a
+// SUPPORTS edge to each claim it agrees with, whose confidence it raises, and a REFUTES
+// edge to each claim it negates or contradicts. Edges carry the new claim's chunk so the
+// conflicts a write caused can be found again.
+func linkRelatedClaims(ctx context.Context, graphStore GraphStore, claimID, chunkID string, confidence float64, related []relatedClaim) error {
+	for _, stored := range related {
+		properties := map[string]interface{}{"chunk_id": chunkID}
+
+		switch stored.relation {
+		case claimAgrees:
+			if err := linkOnce(ctx, graphStore, claimID, stored.node.ID, Supports, properties); err != nil {
+				return fmt.Errorf("failed to link supporting claim: %w", err)
+			}
+
+			updated := *stored.node
+			updated.Properties = make(map[string]interface{}, len(stored.node.Properties)+1)
+			for k, v := range stored.node.Properties {
+				updated.Properties[k] = v
+			}
+			storedConfidence, _ := filterNumber(stored.node.Properties["confidence"])
+			updated.Properties["confidence"] = corroborate(storedConfidence, confidence)
+			updated.UpdatedAt = time.Now()
+			if err := graphStore.UpdateNode(ctx, &updated); err != nil {
+				return fmt.Errorf("failed to raise confidence of claim %s: %w", stored.node.ID, err)
+			}
+
+		case claimNegates, claimContradicts:
+			properties["reason"], properties["severity"] = "contradiction", "medium"
+			if stored.relation == claimNegates {
+				properties["reason"], properties["severity"] = "negation", "high"
+			}
+			if err := linkOnce(ctx, graphStore, claimID, stored.node.ID, Refutes, properties); err != nil {
+				return fmt.Errorf("failed to link refuting claim: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// findClaimConflicts reports the REFUTES edges a chunk's claims have to stored claims
+func findClaimConflicts(ctx context.Context, graphStore GraphStore, chunk *Chunk) ([]ConflictInfo, error) {
+	if len(chunk.Claims) == 0 {
+		return nil, nil
+	}
+
+	edges, err := graphStore.FindEdgesByType(ctx, Refutes, map[string]interface{}{"chunk_id": chunk.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find refuting claims: %w", err)
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].ID < edges[j].ID })
+
+	claims := make(map[string]Claim, len(chunk.Claims))
+	for _, claim := range chunk.Claims {
+		claims[claim.ID] = claim
+	}
+
+	var conflicts []ConflictInfo
+	for _, edge := range edges {
+		claim, ok := claims[edge.From]
+		if !ok {
+			continue
+		}
+		severity, _ := edge.Properties["severity"].(string)
+		reason, _ := edge.Properties["reason"].(string)
+
+		verb := "contradicts"
+		if reason == "negation" {
+			verb = "negates"
+		}
+		description := fmt.Sprintf("Claim %q %s stored claim %s", claim.Triple(), verb, edge.To)
+		if stored, err := graphStore.GetNode(ctx, edge.To); err == nil {
+			description = fmt.Sprintf("Claim %q %s stored claim %q", claim.Triple(), verb, claimText(stored))
+		}
+
+		conflicts = append(conflicts, ConflictInfo{
+			ID:             fmt.Sprintf("claim_conflict_%s_%s", edge.From, edge.To),
+			Type:           "claim_contradiction",
+			Description:    description,
+			ConflictingIDs: []string{edge.From, edge.To},
+			Severity:       severity,
+		})
+	}
+	return conflicts, nil
+}
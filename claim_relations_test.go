@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestClaimStatements(t *testing.T) {
+	Convey("Given claims worded differently", t, func() {
+		Convey("Then articles, case, punctuation and verb forms should be folded away", func() {
+			a := parseClaimStatement("The Eiffel Tower", "is", "in Paris.")
+			b := parseClaimStatement("eiffel tower", "was", "in Paris")
+			So(a, ShouldResemble, b)
+			So(a.negated, ShouldBeFalse)
+		})
+
+		Convey("Then negation should be read from the object or the predicate", func() {
+			for _, parts := range [][3]string{
+				{"Paris", "is", "not the capital of Germany"},
+				{"Paris", "isn't", "the capital of Germany"},
+				{"Paris", "is", "never the capital of Germany"},
+			} {
+				statement := parseClaimStatement(parts[0], parts[1], parts[2])
+				So(statement.negated, ShouldBeTrue)
+				So(statement.predicate, ShouldEqual, "be")
+				So(statement.object, ShouldEqual, "capital of germany")
+			}
+		})
+
+		Convey("Then statements should relate only when they share subject and predicate", func() {
+			stored := parseClaimStatement("Paris", "is", "the capital of France")
+			So(parseClaimStatement("Paris", "was", "capital of France").relate(stored), ShouldEqual, claimAgrees)
+			So(parseClaimStatement("Paris", "is", "not the capital of France").relate(stored), ShouldEqual, claimNegates)
+			So(parseClaimStatement("Paris", "is", "the capital of Spain").relate(stored), ShouldEqual, claimContradicts)
+			So(parseClaimStatement("Lyon", "is", "the capital of Spain").relate(stored), ShouldEqual, claimUnrelated)
+			So(parseClaimStatement("Paris", "is", "not the capital of Spain").relate(stored), ShouldEqual, claimUnrelated)
+		})
+
+		Convey("Then different objects of a multi-valued predicate should not contradict", func() {
+			stored := parseClaimStatement("Go", "has", "goroutines")
+			So(parseClaimStatement("Go", "has", "channels").relate(stored), ShouldEqual, claimUnrelated)
+			So(parseClaimStatement("Go", "includes", "channels").relate(parseClaimStatement("Go", "includes", "maps")), ShouldEqual, claimUnrelated)
+		})
+	})
+}
+
+func TestPersistChunkGraphClaimRelations(t *testing.T) {
+	Convey("Given a stored claim", t, func() {
+		ctx := context.Background()
+		graphStore := NewMockGraphStore()
+
+		stored := NewClaim("claim_stored", "Paris", "is", "the capital of France", "atlas")
+		stored.Confidence = 0.6
+		chunk := NewChunk("atlas_chunk_0", "content", "atlas")
+		chunk.AddClaim(*stored)
+		So(persistChunkGraph(ctx, graphStore, chunk), ShouldBeNil)
+
+		persist := func(claim *Claim) *Chunk {
+			chunk := NewChunk("notes_chunk_0", "content", "notes")
+			chunk.AddClaim(*claim)
+			So(persistChunkGraph(ctx, graphStore, chunk), ShouldBeNil)
+			return chunk
+		}
+
+		Convey("When an agreeing claim is persisted", func() {
+			claim := NewClaim("claim_new", "paris", "was", "capital of France", "notes")
+			claim.Confidence = 0.5
+			chunk := persist(claim)
+
+			Convey("Then it should support the stored claim and both should gain confidence", func() {
+				edge, err := graphStore.GetEdge(ctx, graphEdgeID(Supports, "claim_new", "claim_stored"))
+				So(err, ShouldBeNil)
+				So(edge.Properties["chunk_id"], ShouldEqual, "notes_chunk_0")
+
+				node, _ := graphStore.GetNode(ctx, "claim_stored")
+				So(node.Properties["confidence"], ShouldAlmostEqual, 0.8)
+				node, _ = graphStore.GetNode(ctx, "claim_new")
+				So(node.Properties["confidence"], ShouldAlmostEqual, 0.8)
+
+				conflicts, err := findClaimConflicts(ctx, graphStore, chunk)
+				So(err, ShouldBeNil)
+				So(conflicts, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When a negating claim is persisted", func() {
+			chunk := persist(NewClaim("claim_new", "Paris", "is", "not the capital of France", "notes"))
+
+			Convey("Then it should refute the stored claim with high severity", func() {
+				edge, err := graphStore.GetEdge(ctx, graphEdgeID(Refutes, "claim_new", "claim_stored"))
+				So(err, ShouldBeNil)
+				So(edge.Properties["reason"], ShouldEqual, "negation")
+
+				conflicts, err := findClaimConflicts(ctx, graphStore, chunk)
+				So(err, ShouldBeNil)
+				So(conflicts, ShouldHaveLength, 1)
+				So(conflicts[0].Type, ShouldEqual, "claim_contradiction")
+				So(conflicts[0].Severity, ShouldEqual, "high")
+				So(conflicts[0].ConflictingIDs, ShouldResemble, []string{"claim_new", "claim_stored"})
+				So(conflicts[0].Description, ShouldContainSubstring, "negates stored claim \"Paris is the capital of France\"")
+
+				node, _ := graphStore.GetNode(ctx, "claim_stored")
+				So(node.Properties["confidence"], ShouldEqual, 0.6)
+			})
+		})
+
+		Convey("When a claim with a different object is persisted", func() {
+			chunk := persist(NewClaim("claim_new", "Paris", "is", "the capital of Spain", "notes"))
+
+			Convey("Then it should refute the stored claim with medium severity", func() {
+				conflicts, err := findClaimConflicts(ctx, graphStore, chunk)
+				So(err, ShouldBeNil)
+				So(conflicts, ShouldHaveLength, 1)
+				So(conflicts[0].Severity, ShouldEqual, "medium")
+				So(conflicts[0].Description, ShouldContainSubstring, "contradicts")
+			})
+		})
+
+		Convey("When the stored chunk is written again", func() {
+			So(persistChunkGraph(ctx, graphStore, chunk), ShouldBeNil)
+
+			Convey("Then its claim should not support itself", func() {
+				_, err := graphStore.GetEdge(ctx, graphEdgeID(Supports, "claim_stored", "claim_stored"))
+				So(err, ShouldNotBeNil)
+				edges, _ := graphStore.FindEdgesByType(ctx, Supports, nil)
+				So(edges, ShouldBeEmpty)
+			})
+		})
+	})
+}
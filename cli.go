@@ -0,0 +1,395 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// cliUsage describes the available subcommands
+const cliUsage = `Usage: agentic-memory-system <command> [flags]
+
+Commands:
+  serve     Run the MCP server (--transport stdio|http, --config file.json)
+  ingest    Write files or directories into memory
+  recall    Query memory and print the evidence
+  stats     Print memory statistics
+  config    Manage configuration files (init, validate)
+
+Run "agentic-memory-system <command> -h" for command flags.
+`
+
+// runCLI dispatches a subcommand and returns the process exit code
+func runCLI(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, cliUsage)
+		return 2
+	}
+
+	var err error
+	switch args[0] {
+	case "serve":
+		err = runServe(args[1:], stderr)
+	case "ingest":
+		err = runIngest(args[1:], stdout, stderr)
+	case "recall":
+		err = runRecall(args[1:], stdout, stderr)
+	case "stats":
+		err = runStats(args[1:], stdout, stderr)
+	case "config":
+		err = runConfig(args[1:], stdout, stderr)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, cliUsage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "unknown command: %s\n\n%s", args[0], cliUsage)
+		return 2
+	}
+
+	if err == flag.ErrHelp {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runServe starts the MCP server on the requested transport
+func runServe(args []string, stderr io.Writer) error {
+	flags := newFlagSet("serve", stderr)
+	configPath := flags.String("config", "", "path to a JSON config file")
+	transport := flags.String("transport", "stdio", "transport to serve on: stdio or http")
+	addr := flags.String("addr", "", "listen address for the http transport (defaults to server host:port)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	config, err := loadCLIConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	ams, err := NewAgenticMemoryServer(config)
+	if err != nil {
+		return err
+	}
+	defer ams.Close()
+
+	switch *transport {
+	case "stdio":
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return ams.Run(ctx, &mcp.StdioTransport{})
+	case "http":
+		listenAddr := *addr
+		if listenAddr == "" {
+			listenAddr = fmt.Sprintf("%s:%d", config.Server.Host, config.Server.Port)
+		}
+		return ams.RunHTTP(context.Background(), listenAddr)
+	default:
+		return fmt.Errorf("unsupported transport: %s", *transport)
+	}
+}
+
+// runIngest writes every file under the given paths into memory
+func runIngest(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet("ingest", stderr)
+	configPath := flags.String("config", "", "path to a JSON config file")
+	tags := flags.String("tags", "", "comma-separated tags to attach to every file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("ingest requires at least one file or directory")
+	}
+
+	config, err := loadCLIConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	ams, err := NewAgenticMemoryServer(config)
+	if err != nil {
+		return err
+	}
+	defer ams.Close()
+
+	if !isPersistentStorage(&config.Storage) {
+		fmt.Fprintln(stderr, "warning: storage is in-memory; ingested content will not outlive this process")
+	}
+
+	files, err := collectIngestFiles(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	var failed int
+	for _, path := range files {
+		result, err := ingestFile(ctx, ams, path, splitTags(*tags))
+		if err != nil {
+			failed++
+			fmt.Fprintf(stderr, "failed to ingest %s: %v\n", path, err)
+			continue
+		}
+		fmt.Fprintf(stdout, "ingested %s as %s (%d entities)\n", path, result.MemoryID, len(result.EntitiesLinked))
+	}
+
+	fmt.Fprintf(stdout, "%d of %d files ingested\n", len(files)-failed, len(files))
+	if failed > 0 {
+		return fmt.Errorf("%d files failed to ingest", failed)
+	}
+	return nil
+}
+
+// runRecall queries memory and prints the evidence
+func runRecall(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet("recall", stderr)
+	configPath := flags.String("config", "", "path to a JSON config file")
+	maxResults := flags.Int("max-results", 10, "maximum number of results")
+	asJSON := flags.Bool("json", false, "print the full result as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	query := strings.TrimSpace(strings.Join(flags.Args(), " "))
+	if query == "" {
+		return fmt.Errorf("recall requires a query")
+	}
+
+	config, err := loadCLIConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	ams, err := NewAgenticMemoryServer(config)
+	if err != nil {
+		return err
+	}
+	defer ams.Close()
+
+	_, result, err := ams.recallHandler.HandleRecall(context.Background(), nil, RecallArgs{
+		Query:      query,
+		MaxResults: *maxResults,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return writeJSON(stdout, result)
+	}
+
+	if len(result.Evidence) == 0 {
+		fmt.Fprintln(stdout, "no results")
+		return nil
+	}
+	for i, evidence := range result.Evidence {
+		fmt.Fprintf(stdout, "%d. [%.3f] %s\n", i+1, evidence.Confidence, evidence.Source)
+		if evidence.Breadcrumb != "" {
+			fmt.Fprintf(stdout, "   %s\n", evidence.Breadcrumb)
+		}
+		fmt.Fprintf(stdout, "   %s\n", strings.ReplaceAll(strings.TrimSpace(evidence.Content), "\n", "\n   "))
+	}
+	return nil
+}
+
+// runStats prints storage statistics as JSON
+func runStats(args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet("stats", stderr)
+	configPath := flags.String("config", "", "path to a JSON config file")
+	performance := flags.Bool("performance", false, "include performance metrics")
+	storage := flags.Bool("storage", false, "include storage usage totals")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	config, err := loadCLIConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	ams, err := NewAgenticMemoryServer(config)
+	if err != nil {
+		return err
+	}
+	defer ams.Close()
+
+	_, result, err := ams.statsHandler.HandleStats(context.Background(), nil, StatsArgs{
+		IncludePerformance: *performance,
+		IncludeStorage:     *storage,
+	})
+	if err != nil {
+		return err
+	}
+	return writeJSON(stdout, result)
+}
+
+// runConfig handles the config init and validate subcommands
+func runConfig(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("config requires a subcommand: init or validate")
+	}
+
+	switch args[0] {
+	case "init":
+		flags := newFlagSet("config init", stderr)
+		output := flags.String("output", "config.json", "path to write the default config to")
+		force := flags.Bool("force", false, "overwrite an existing file")
+		storage := flags.String("storage", "file", "storage provider for all views: file or memory")
+		dataDir := flags.String("data-dir", "data", "directory for file-backed storage")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *storage != "file" && *storage != "memory" {
+			return fmt.Errorf("unsupported storage provider: %s", *storage)
+		}
+		if _, err := os.Stat(*output); err == nil && !*force {
+			return fmt.Errorf("%s already exists (use --force to overwrite)", *output)
+		}
+
+		config := DefaultServerConfig()
+		config.Storage.VectorStore.Provider = *storage
+		config.Storage.GraphStore.Provider = *storage
+		config.Storage.SearchIndex.Provider = *storage
+		config.Storage.DataDir = *dataDir
+		if err := config.SaveConfig(*output); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "wrote default config to %s\n", *output)
+		return nil
+	case "validate":
+		flags := newFlagSet("config validate", stderr)
+		configPath := flags.String("config", "", "path to a JSON config file")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		path := *configPath
+		if path == "" && flags.NArg() > 0 {
+			path = flags.Arg(0)
+		}
+		if path == "" {
+			return fmt.Errorf("config validate requires a config file")
+		}
+		if _, err := LoadConfig(path); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "%s is valid\n", path)
+		return nil
+	default:
+		return fmt.Errorf("unknown config subcommand: %s", args[0])
+	}
+}
+
+// newFlagSet creates a flag set that reports errors instead of exiting
+func newFlagSet(name string, output io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(output)
+	return fs
+}
+
+// loadCLIConfig loads the config file, falling back to defaults when no path is given
+func loadCLIConfig(path string) (*ServerConfig, error) {
+	if path == "" {
+		return DefaultServerConfig(), nil
+	}
+	return LoadConfig(path)
+}
+
+// isPersistentStorage reports whether every view is backed by durable storage
+func isPersistentStorage(config *MultiViewStorageConfig) bool {
+	for _, provider := range []string{config.VectorStore.Provider, config.GraphStore.Provider, config.SearchIndex.Provider} {
+		switch strings.ToLower(provider) {
+		case "mock", "memory":
+			return false
+		}
+	}
+	return true
+}
+
+// collectIngestFiles expands directories into the regular, non-hidden files they contain
+func collectIngestFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, root := range paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", root, err)
+		}
+		if !info.IsDir() {
+			files = append(files, root)
+			continue
+		}
+
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.Type().IsRegular() {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk %s: %w", root, err)
+		}
+	}
+	return files, nil
+}
+
+// ingestFile writes a single text file into memory using its path as the source
+func ingestFile(ctx context.Context, ams *AgenticMemoryServer, path string, tags []string) (*WriteResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return nil, fmt.Errorf("file appears to be binary")
+	}
+	content := strings.TrimSpace(string(data))
+	if content == "" {
+		return nil, fmt.Errorf("file is empty")
+	}
+
+	// Files bypass the tool argument limits and go straight to the writer, which chunks them
+	metadata := ams.writeHandler.convertArgsToMetadata(WriteArgs{
+		Source: path,
+		Tags:   tags,
+	})
+	return ams.writeHandler.memoryWriter.Write(ctx, content, metadata)
+}
+
+// splitTags parses a comma-separated tag list
+func splitTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// writeJSON prints v as indented JSON
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// runCLIForTest runs a CLI command and captures its output
+func runCLIForTest(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := runCLI(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestCLI(t *testing.T) {
+	Convey("Given a config created with config init", t, func() {
+		dir := t.TempDir()
+		configPath := filepath.Join(dir, "config.json")
+		code, stdout, _ := runCLIForTest("config", "init", "--output", configPath, "--data-dir", filepath.Join(dir, "data"))
+		So(code, ShouldEqual, 0)
+		So(stdout, ShouldContainSubstring, "wrote default config")
+
+		Convey("When validating it", func() {
+			code, stdout, _ := runCLIForTest("config", "validate", configPath)
+
+			Convey("Then it should be valid and file-backed", func() {
+				So(code, ShouldEqual, 0)
+				So(stdout, ShouldContainSubstring, "is valid")
+
+				config, err := LoadConfig(configPath)
+				So(err, ShouldBeNil)
+				So(config.Storage.VectorStore.Provider, ShouldEqual, "file")
+			})
+		})
+
+		Convey("When running config init again without --force", func() {
+			code, _, stderr := runCLIForTest("config", "init", "--output", configPath)
+
+			Convey("Then it should refuse to overwrite", func() {
+				So(code, ShouldEqual, 1)
+				So(stderr, ShouldContainSubstring, "already exists")
+			})
+		})
+
+		Convey("When ingesting a directory and recalling in separate invocations", func() {
+			docs := filepath.Join(dir, "docs")
+			So(os.MkdirAll(filepath.Join(docs, ".hidden"), 0755), ShouldBeNil)
+			So(os.WriteFile(filepath.Join(docs, "plants.md"), []byte("Photosynthesis lets plants turn sunlight into chemical energy."), 0644), ShouldBeNil)
+			So(os.WriteFile(filepath.Join(docs, "markets.txt"), []byte("Bond yields rose after the central bank raised interest rates."), 0644), ShouldBeNil)
+			So(os.WriteFile(filepath.Join(docs, ".hidden", "secret.txt"), []byte("Should not be ingested."), 0644), ShouldBeNil)
+
+			code, stdout, _ := runCLIForTest("ingest", "--config", configPath, docs)
+			So(code, ShouldEqual, 0)
+			So(stdout, ShouldContainSubstring, "2 of 2 files ingested")
+
+			Convey("Then recall should find the ingested content", func() {
+				code, stdout, _ := runCLIForTest("recall", "--config", configPath, "photosynthesis sunlight")
+				So(code, ShouldEqual, 0)
+				So(stdout, ShouldContainSubstring, "Photosynthesis")
+				So(stdout, ShouldContainSubstring, "plants.md")
+			})
+
+			Convey("Then stats should report the stored documents", func() {
+				code, stdout, _ := runCLIForTest("stats", "--config", configPath, "--storage")
+				So(code, ShouldEqual, 0)
+
+				var stats StatsResult
+				So(json.Unmarshal([]byte(stdout), &stats), ShouldBeNil)
+				So(stats.TotalMemories, ShouldEqual, 2)
+				So(stats.GraphNodes, ShouldBeGreaterThan, 0)
+				So(stats.SystemHealth.Status, ShouldNotBeEmpty)
+				So(stats.StorageUsage, ShouldContainKey, "total_size_bytes")
+			})
+		})
+	})
+
+	Convey("Given invalid CLI usage", t, func() {
+		Convey("When no command is given", func() {
+			code, _, stderr := runCLIForTest()
+
+			Convey("Then it should print usage", func() {
+				So(code, ShouldEqual, 2)
+				So(stderr, ShouldContainSubstring, "Usage")
+			})
+		})
+
+		Convey("When the command is unknown", func() {
+			code, _, stderr := runCLIForTest("teleport")
+
+			Convey("Then it should report it", func() {
+				So(code, ShouldEqual, 2)
+				So(stderr, ShouldContainSubstring, "unknown command")
+			})
+		})
+
+		Convey("When recall has no query", func() {
+			code, _, stderr := runCLIForTest("recall")
+
+			Convey("Then it should return an error", func() {
+				So(code, ShouldEqual, 1)
+				So(stderr, ShouldContainSubstring, "requires a query")
+			})
+		})
+
+		Convey("When serve is given an unknown transport", func() {
+			code, _, stderr := runCLIForTest("serve", "--transport", "carrier-pigeon")
+
+			Convey("Then it should return an error", func() {
+				So(code, ShouldEqual, 1)
+				So(stderr, ShouldContainSubstring, "unsupported transport")
+			})
+		})
+	})
+}
@@ -0,0 +1,446 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// CommunitySummarizer builds extractive titles and summaries for graph communities,
+// caching them until the graph store reports a write
+type CommunitySummarizer struct {
+	graphStore  GraphStore
+	searchIndex SearchIndex
+	config      *CommunitySummarizerConfig
+
+	mu        sync.Mutex
+	version   uint64 // Graph store version the summaries were built from
+	summaries []CommunitySummary
+	byChunk   map[string][]int // chunk ID -> indexes into summaries
+}
+
+// CommunitySummarizerConfig holds configuration for community summarization
+type CommunitySummarizerConfig struct {
+	MinCommunitySize int                       `json:"min_community_size"`
+	MaxEntities      int                       `json:"max_entities"`
+	MaxClaims        int                       `json:"max_claims"`
+	MaxChunks        int                       `json:"max_chunks"`
+	SnippetLength    int                       `json:"snippet_length"`
+	Detection        CommunityDetectionOptions `json:"detection"`
+}
+
+// CommunitySummary is a detected community with its generated title and summary
+type CommunitySummary struct {
+	Community
+	Title    string   `json:"title"`
+	Entities []string `json:"entities"`
+	Claims   []string `json:"claims"`
+	ChunkIDs []string `json:"chunk_ids"`
+}
+
+// NewCommunitySummarizer creates a CommunitySummarizer with default configuration
+func NewCommunitySummarizer(graphStore GraphStore, searchIndex SearchIndex) *CommunitySummarizer {
+	return NewCommunitySummarizerWithConfig(graphStore, searchIndex, nil)
+}
+
+// NewCommunitySummarizerWithConfig creates a CommunitySummarizer with custom configuration
+func NewCommunitySummarizerWithConfig(graphStore GraphStore, searchIndex SearchIndex, config *CommunitySummarizerConfig) *CommunitySummarizer {
+	cfg := CommunitySummarizerConfig{}
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.MinCommunitySize <= 0 {
+		cfg.MinCommunitySize = 2
+	}
+	if cfg.MaxEntities <= 0 {
+		cfg.MaxEntities = 5
+	}
+	if cfg.MaxClaims <= 0 {
+		cfg.MaxClaims = 3
+	}
+	if cfg.MaxChunks <= 0 {
+		cfg.MaxChunks = 2
+	}
+	if cfg.SnippetLength <= 0 {
+		cfg.SnippetLength = 160
+	}
+	cfg.Detection = cfg.Detection.withDefaults()
+
+	return &CommunitySummarizer{
+		graphStore:  graphStore,
+		searchIndex: searchIndex,
+		config:      &cfg,
+	}
+}
+
+// Summaries returns a summary for every community, rebuilding them only when the graph has changed
+func (cs *CommunitySummarizer) Summaries(ctx context.Context) ([]CommunitySummary, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if err := cs.refresh(ctx); err != nil {
+		return nil, err
+	}
+	return cs.summaries, nil
+}
+
+// CardsForChunks returns cards for the communities that reference any of the given chunks,
+// ordered by how many of those chunks each community touches
+func (cs *CommunitySummarizer) CardsForChunks(ctx context.Context, chunkIDs []string, limit int) ([]CommunityCard, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if err := cs.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	hits := make(map[int]int)
+	var order []int
+	for _, chunkID := range chunkIDs {
+		for _, index := range cs.byChunk[chunkID] {
+			if hits[index] == 0 {
+				order = append(order, index)
+			}
+			hits[index]++
+		}
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return hits[order[i]] > hits[order[j]]
+	})
+	if limit > 0 && len(order) > limit {
+		order = order[:limit]
+	}
+
+	cards := make([]CommunityCard, 0, len(order))
+	for _, index := range order {
+		cards = append(cards, cs.summaries[index].Card())
+	}
+	return cards, nil
+}
+
+// Invalidate drops the cached summaries so the next call rebuilds them
+func (cs *CommunitySummarizer) Invalidate() {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.version = 0
+	cs.summaries = nil
+	cs.byChunk = nil
+}
+
+// Card converts a summary into the card returned by recall
+func (s CommunitySummary) Card() CommunityCard {
+	return CommunityCard{
+		ID:          s.ID,
+		Title:       s.Title,
+		Summary:     s.Summary,
+		EntityCount: len(s.Entities),
+		Entities:    append([]string{}, s.Entities...),
+	}
+}
+
+// refresh rebuilds the summaries if the graph has been written since they were built; callers hold cs.mu
+func (cs *CommunitySummarizer) refresh(ctx context.Context) error {
+	if cs.graphStore == nil {
+		return fmt.Errorf("graph store not initialized")
+	}
+
+	// Read the version first, so a write made while rebuilding triggers the next rebuild
+	version := cs.graphStore.Version()
+	if cs.summaries != nil && version == cs.version {
+		return nil
+	}
+
+	nodes, edges, err := cs.loadGraph(ctx)
+	if err != nil {
+		return err
+	}
+
+	communities, err := cs.graphStore.CommunityDetectionWithOptions(ctx, cs.config.Detection)
+	if err != nil {
+		return fmt.Errorf("community detection failed: %w", err)
+	}
+
+	nodeByID := make(map[string]*Node, len(nodes))
+	for _, node := range nodes {
+		nodeByID[node.ID] = node
+	}
+	chunkRefs := chunkReferences(nodeByID, edges)
+
+	summaries := make([]CommunitySummary, 0, len(communities))
+	byChunk := make(map[string][]int)
+	for _, community := range communities {
+		if len(community.Nodes) < cs.config.MinCommunitySize {
+			continue
+		}
+
+		summary := cs.summarize(ctx, community, nodeByID, edges, chunkRefs)
+		index := len(summaries)
+		summaries = append(summaries, summary)
+
+		for _, chunkID := range communityChunkIDs(community, chunkRefs) {
+			byChunk[chunkID] = append(byChunk[chunkID], index)
+		}
+	}
+
+	cs.version = version
+	cs.summaries = summaries
+	cs.byChunk = byChunk
+
+	return nil
+}
+
+// loadGraph reads every node and edge from the graph store
+func (cs *CommunitySummarizer) loadGraph(ctx context.Context) ([]*Node, []*Edge, error) {
+	var nodes []*Node
+	for _, nodeType := range allNodeTypes {
+		found, err := cs.graphStore.FindNodesByType(ctx, nodeType, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load %s nodes: %w", nodeType, err)
+		}
+		nodes = append(nodes, found...)
+	}
+
+	var edges []*Edge
+	for _, edgeType := range allEdgeTypes {
+		found, err := cs.graphStore.FindEdgesByType(ctx, edgeType, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load %s edges: %w", edgeType, err)
+		}
+		edges = append(edges, found...)
+	}
+
+	return nodes, edges, nil
+}
+
+// summarize builds the title and summary of a community from its top entities,
+// most-supported claims and representative chunks
+func (cs *CommunitySummarizer) summarize(ctx context.Context, community Community, nodeByID map[string]*Node, edges []*Edge, chunkRefs map[string][]string) CommunitySummary {
+	members := make(map[string]bool, len(community.Nodes))
+	for _, id := range community.Nodes {
+		members[id] = true
+	}
+
+	// Internal degree ranks entities; incoming SUPPORTS edges rank claims
+	degree := make(map[string]float64)
+	support := make(map[string]float64)
+	for _, edge := range edges {
+		if !members[edge.From] || !members[edge.To] {
+			continue
+		}
+		degree[edge.From] += edge.Weight
+		degree[edge.To] += edge.Weight
+		switch edge.Type {
+		case Supports:
+			support[edge.To] += edge.Weight
+		case Refutes:
+			support[edge.To] -= edge.Weight
+		}
+	}
+
+	var entityNodes, claimNodes []*Node
+	for _, id := range community.Nodes {
+		node, ok := nodeByID[id]
+		if !ok {
+			continue
+		}
+		switch node.Type {
+		case EntityNode:
+			if nodeName(node) != "" {
+				entityNodes = append(entityNodes, node)
+			}
+		case ClaimNode:
+			if claimText(node) != "" {
+				claimNodes = append(claimNodes, node)
+			}
+		}
+	}
+
+	sort.SliceStable(entityNodes, func(i, j int) bool {
+		if degree[entityNodes[i].ID] == degree[entityNodes[j].ID] {
+			return nodeName(entityNodes[i]) < nodeName(entityNodes[j])
+		}
+		return degree[entityNodes[i].ID] > degree[entityNodes[j].ID]
+	})
+	sort.SliceStable(claimNodes, func(i, j int) bool {
+		a, b := claimNodes[i], claimNodes[j]
+		if support[a.ID] != support[b.ID] {
+			return support[a.ID] > support[b.ID]
+		}
+		if nodeConfidence(a) != nodeConfidence(b) {
+			return nodeConfidence(a) > nodeConfidence(b)
+		}
+		return a.ID < b.ID
+	})
+
+	summary := CommunitySummary{Community: community}
+
+	seen := make(map[string]bool)
+	for _, node := range entityNodes {
+		name := nodeName(node)
+		if seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		summary.Entities = append(summary.Entities, name)
+		if len(summary.Entities) >= cs.config.MaxEntities {
+			break
+		}
+	}
+	for _, node := range claimNodes {
+		summary.Claims = append(summary.Claims, claimText(node))
+		if len(summary.Claims) >= cs.config.MaxClaims {
+			break
+		}
+	}
+
+	// Representative chunks are those referenced by the most community members
+	chunkIDs := communityChunkIDs(community, chunkRefs)
+	references := make(map[string]int)
+	for _, id := range community.Nodes {
+		for _, chunkID := range chunkRefs[id] {
+			references[chunkID]++
+		}
+	}
+	sort.SliceStable(chunkIDs, func(i, j int) bool {
+		return references[chunkIDs[i]] > references[chunkIDs[j]]
+	})
+
+	var snippets []string
+	for _, chunkID := range chunkIDs {
+		if len(summary.ChunkIDs) >= cs.config.MaxChunks {
+			break
+		}
+		if cs.searchIndex == nil {
+			break
+		}
+		doc, err := cs.searchIndex.GetDocument(ctx, chunkID)
+		if err != nil {
+			continue
+		}
+		summary.ChunkIDs = append(summary.ChunkIDs, chunkID)
+		snippets = append(snippets, snippet(doc.Content, cs.config.SnippetLength))
+	}
+
+	summary.Title = communityTitle(summary)
+	summary.Summary = communitySummaryText(summary, snippets)
+
+	return summary
+}
+
+// communityTitle names a community after its leading entities, falling back to its top claim
+func communityTitle(summary CommunitySummary) string {
+	switch {
+	case len(summary.Entities) == 1:
+		return summary.Entities[0]
+	case len(summary.Entities) == 2:
+		return summary.Entities[0] + " and " + summary.Entities[1]
+	case len(summary.Entities) > 2:
+		return fmt.Sprintf("%s, %s and %s", summary.Entities[0], summary.Entities[1], summary.Entities[2])
+	case len(summary.Claims) > 0:
+		return summary.Claims[0]
+	}
+	return fmt.Sprintf("Community %s", summary.ID)
+}
+
+// communitySummaryText joins entities, claims and chunk snippets into a short extractive summary
+func communitySummaryText(summary CommunitySummary, snippets []string) string {
+	var parts []string
+	if len(summary.Entities) > 0 {
+		parts = append(parts, fmt.Sprintf("Covers %s.", strings.Join(summary.Entities, ", ")))
+	}
+	if len(summary.Claims) > 0 {
+		parts = append(parts, fmt.Sprintf("Key claims: %s.", strings.Join(summary.Claims, "; ")))
+	}
+	for _, text := range snippets {
+		if text != "" {
+			parts = append(parts, fmt.Sprintf("%q", text))
+		}
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("%d related nodes.", len(summary.Nodes))
+	}
+	return strings.Join(parts, " ")
+}
+
+// communityChunkIDs returns the sorted, distinct chunks referenced by a community's nodes
+func communityChunkIDs(community Community, chunkRefs map[string][]string) []string {
+	seen := make(map[string]bool)
+	var chunkIDs []string
+	for _, id := range community.Nodes {
+		for _, chunkID := range chunkRefs[id] {
+			if seen[chunkID] {
+				continue
+			}
+			seen[chunkID] = true
+			chunkIDs = append(chunkIDs, chunkID)
+		}
+	}
+	sort.Strings(chunkIDs)
+	return chunkIDs
+}
+
+// chunkReferences maps each node to the chunks it belongs to: chunk and claim nodes record
+// their chunk, while an entity belongs to every chunk it is PART_OF
+func chunkReferences(nodeByID map[string]*Node, edges []*Edge) map[string][]string {
+	refs := make(map[string][]string)
+	for id, node := range nodeByID {
+		if node.Type == EntityNode {
+			continue
+		}
+		if chunkID, ok := node.Properties["chunk_id"].(string); ok && chunkID != "" {
+			refs[id] = append(refs[id], chunkID)
+		}
+	}
+	for _, edge := range edges {
+		from, to := nodeByID[edge.From], nodeByID[edge.To]
+		if edge.Type == PartOf && from != nil && from.Type == EntityNode && to != nil && to.Type == ChunkNode {
+			refs[edge.From] = append(refs[edge.From], edge.To)
+		}
+	}
+	return refs
+}
+
+// nodeName returns an entity node's name
+func nodeName(node *Node) string {
+	name, _ := node.Properties["name"].(string)
+	return strings.TrimSpace(name)
+}
+
+// claimText renders a claim node as a subject-predicate-object sentence
+func claimText(node *Node) string {
+	subject, _ := node.Properties["subject"].(string)
+	predicate, _ := node.Properties["predicate"].(string)
+	object, _ := node.Properties["object"].(string)
+	return strings.TrimSpace(strings.Join(strings.Fields(subject+" "+predicate+" "+object), " "))
+}
+
+// nodeConfidence reads a node's confidence property
+func nodeConfidence(node *Node) float64 {
+	confidence, _ := node.Properties["confidence"].(float64)
+	return confidence
+}
+
+// snippet returns the first sentence of content, cut to at most maxLength bytes on a word boundary
+func snippet(content string, maxLength int) string {
+	content = strings.Join(strings.Fields(content), " ")
+	if end := strings.IndexAny(content, ".!?"); end >= 0 {
+		content = content[:end+1]
+	}
+	if len(content) <= maxLength {
+		return content
+	}
+	cut := content[:maxLength]
+	if space := strings.LastIndex(cut, " "); space > 0 {
+		cut = cut[:space]
+	}
+	return cut + "..."
+}
+
+// GetConfig returns the current configuration
+func (cs *CommunitySummarizer) GetConfig() *CommunitySummarizerConfig {
+	return cs.config
+}
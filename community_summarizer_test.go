@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// newTestClaimNode creates a claim node about subject that references chunkID
+func newTestClaimNode(id, subject, predicate, object, chunkID string, confidence float64) *Node {
+	node := NewNode(id, ClaimNode)
+	node.Properties["subject"] = subject
+	node.Properties["predicate"] = predicate
+	node.Properties["object"] = object
+	node.Properties["confidence"] = confidence
+	node.Properties["chunk_id"] = chunkID
+	return node
+}
+
+func TestCommunitySummarizer(t *testing.T) {
+	Convey("Given a graph with two communities", t, func() {
+		ctx := context.Background()
+		graphStore := NewMockGraphStore()
+		searchIndex := NewMockSearchIndex()
+
+		for _, doc := range []IndexDocument{
+			{ID: "chunk_bees", Content: "Honeybees pollinate crops. They live in hives."},
+			{ID: "chunk_hive", Content: "A hive holds thousands of bees."},
+			{ID: "chunk_rust", Content: "Rust guarantees memory safety without a garbage collector."},
+		} {
+			So(searchIndex.Index(ctx, doc), ShouldBeNil)
+		}
+
+		So(createTestEntity(ctx, graphStore, "bee", "Honeybee", "chunk_bees", "chunk_hive"), ShouldBeNil)
+		So(createTestEntity(ctx, graphStore, "hive", "Hive", "chunk_bees", "chunk_hive"), ShouldBeNil)
+		So(createTestEntity(ctx, graphStore, "crop", "Crops", "chunk_bees"), ShouldBeNil)
+		So(graphStore.CreateNode(ctx, newTestClaimNode("claim_pollinate", "Honeybee", "pollinates", "crops", "chunk_bees", 0.6)), ShouldBeNil)
+		So(graphStore.CreateNode(ctx, newTestClaimNode("claim_hive", "Honeybee", "lives in", "hives", "chunk_hive", 0.9)), ShouldBeNil)
+		So(createTestEntity(ctx, graphStore, "rust", "Rust", "chunk_rust"), ShouldBeNil)
+		So(createTestEntity(ctx, graphStore, "gc", "Garbage Collector", "chunk_rust"), ShouldBeNil)
+		for _, edge := range []*Edge{
+			NewEdge("bee_hive", "bee", "hive", RelatedTo, 1.0),
+			NewEdge("bee_crop", "bee", "crop", RelatedTo, 1.0),
+			NewEdge("hive_crop", "hive", "crop", RelatedTo, 1.0),
+			NewEdge("bee_pollinate", "bee", "claim_pollinate", RelatedTo, 1.0),
+			NewEdge("bee_lives", "bee", "claim_hive", RelatedTo, 1.0),
+			NewEdge("crop_supports", "crop", "claim_pollinate", Supports, 1.0),
+			NewEdge("rust_gc", "rust", "gc", RelatedTo, 1.0),
+		} {
+			So(graphStore.CreateEdge(ctx, edge), ShouldBeNil)
+		}
+
+		summarizer := NewCommunitySummarizer(graphStore, searchIndex)
+
+		Convey("When summarizing", func() {
+			summaries, err := summarizer.Summaries(ctx)
+			So(err, ShouldBeNil)
+
+			Convey("Then each community should get an extractive title and summary", func() {
+				So(len(summaries), ShouldEqual, 2)
+
+				bees := summaries[0]
+				So(bees.Entities[0], ShouldEqual, "Honeybee")
+				So(bees.Title, ShouldStartWith, "Honeybee, ")
+				So(bees.Claims[0], ShouldEqual, "Honeybee pollinates crops")
+				So(bees.Claims[1], ShouldEqual, "Honeybee lives in hives")
+				So(bees.ChunkIDs[0], ShouldEqual, "chunk_bees")
+				So(bees.Summary, ShouldContainSubstring, "Honeybees pollinate crops.")
+				So(bees.Summary, ShouldNotContainSubstring, "They live in hives")
+
+				So(summaries[1].Title, ShouldEqual, "Garbage Collector and Rust")
+			})
+		})
+
+		Convey("When looking up cards for recalled chunks", func() {
+			cards, err := summarizer.CardsForChunks(ctx, []string{"chunk_rust", "chunk_unknown"}, 3)
+			So(err, ShouldBeNil)
+
+			Convey("Then only the communities containing those chunks should be returned", func() {
+				So(len(cards), ShouldEqual, 1)
+				So(cards[0].Title, ShouldEqual, "Garbage Collector and Rust")
+				So(cards[0].EntityCount, ShouldEqual, 2)
+			})
+		})
+
+		Convey("When the graph changes after summarizing", func() {
+			_, err := summarizer.Summaries(ctx)
+			So(err, ShouldBeNil)
+
+			So(createTestEntity(ctx, graphStore, "cargo", "Cargo", "chunk_rust"), ShouldBeNil)
+			So(graphStore.CreateEdge(ctx, NewEdge("rust_cargo", "rust", "cargo", RelatedTo, 1.0)), ShouldBeNil)
+			summaries, err := summarizer.Summaries(ctx)
+			So(err, ShouldBeNil)
+
+			Convey("Then the summaries should be rebuilt", func() {
+				var rust CommunitySummary
+				for _, summary := range summaries {
+					if summary.Entities[0] == "Rust" {
+						rust = summary
+					}
+				}
+				So(rust.Entities, ShouldContain, "Cargo")
+			})
+		})
+
+		Convey("When summarizing again without graph writes", func() {
+			counting := &countingGraphStore{GraphStore: graphStore}
+			summarizer := NewCommunitySummarizer(counting, searchIndex)
+			_, err := summarizer.Summaries(ctx)
+			So(err, ShouldBeNil)
+			loads := counting.finds
+
+			_, err = summarizer.Summaries(ctx)
+			So(err, ShouldBeNil)
+			_, err = summarizer.CardsForChunks(ctx, []string{"chunk_rust"}, 3)
+			So(err, ShouldBeNil)
+
+			Convey("Then the graph should not be read again", func() {
+				So(loads, ShouldBeGreaterThan, 0)
+				So(counting.finds, ShouldEqual, loads)
+			})
+
+			Convey("Then a write should make the next call read it again", func() {
+				So(createTestEntity(ctx, graphStore, "cargo", "Cargo", "chunk_rust"), ShouldBeNil)
+				_, err := summarizer.Summaries(ctx)
+				So(err, ShouldBeNil)
+				So(counting.finds, ShouldBeGreaterThan, loads)
+			})
+		})
+
+		Convey("When the graph store is missing", func() {
+			_, err := NewCommunitySummarizer(nil, searchIndex).Summaries(ctx)
+
+			Convey("Then it should return an error", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+
+	Convey("Given text longer than the snippet length", t, func() {
+		Convey("Then the snippet should stop at a word boundary", func() {
+			So(snippet("one two three four five", 10), ShouldEqual, "one two...")
+			So(snippet("First sentence. Second one.", 100), ShouldEqual, "First sentence.")
+		})
+	})
+}
+
+// countingGraphStore counts the type scans used to load the whole graph
+type countingGraphStore struct {
+	GraphStore
+	finds int
+}
+
+func (c *countingGraphStore) FindNodesByType(ctx context.Context, nodeType NodeType, filters map[string]interface{}) ([]*Node, error) {
+	c.finds++
+	return c.GraphStore.FindNodesByType(ctx, nodeType, filters)
+}
@@ -28,13 +28,21 @@ type ServerSettings struct {
 
 // ProcessingConfig holds content processing configuration
 type ProcessingConfig struct {
-	EmbeddingModel    string  `json:"embedding_model"`
-	EmbeddingProvider string  `json:"embedding_provider"`
-	MaxChunkSize      int     `json:"max_chunk_size"`
-	ChunkOverlap      int     `json:"chunk_overlap"`
-	MinConfidence     float64 `json:"min_confidence"`
-	EntityExtraction  bool    `json:"entity_extraction"`
-	ClaimExtraction   bool    `json:"claim_extraction"`
+	EmbeddingModel      string        `json:"embedding_model"`
+	EmbeddingProvider   string        `json:"embedding_provider"`
+	EmbeddingBaseURL    string        `json:"embedding_base_url,omitempty"`
+	EmbeddingAPIKeyEnv  string        `json:"embedding_api_key_env,omitempty"`
+	EmbeddingCacheDir   string        `json:"embedding_cache_dir,omitempty"`
+	EmbeddingMaxRetries int           `json:"embedding_max_retries"`
+	EmbeddingTimeout    time.Duration `json:"embedding_timeout"`
+	MaxChunkSize        int           `json:"max_chunk_size"`
+	ChunkOverlap        int           `json:"chunk_overlap"`
+	MinConfidence       float64       `json:"min_confidence"`
+	EntityExtraction    bool          `json:"entity_extraction"`
+	ClaimExtraction     bool          `json:"claim_extraction"`
+	DuplicatePolicy     string        `json:"duplicate_policy"`             // "reject", "merge", "link" or "off" for near-duplicate chunks
+	DuplicateDistance   int           `json:"duplicate_distance,omitempty"` // Fingerprint bits near-duplicates may differ in; 0 means 6
+	IdempotencyTTL      time.Duration `json:"idempotency_ttl,omitempty"`    // How long retried writes get the first result; 0 means 24h
 }
 
 // PerformanceConfig holds performance-related settings
@@ -84,16 +92,21 @@ func DefaultServerConfig() *ServerConfig {
 				URI:      "",
 				IndexName: "memory",
 			},
+			DataDir: "data",
 			Timeout: 10 * time.Second,
 		},
 		Processing: ProcessingConfig{
-			EmbeddingModel:    "text-embedding-ada-002",
-			EmbeddingProvider: "openai",
-			MaxChunkSize:      1000,
-			ChunkOverlap:      200,
-			MinConfidence:     0.5,
-			EntityExtraction:  true,
-			ClaimExtraction:   true,
+			EmbeddingModel:      "text-embedding-ada-002",
+			EmbeddingProvider:   "hash",
+			EmbeddingAPIKeyEnv:  "OPENAI_API_KEY",
+			EmbeddingMaxRetries: 3,
+			EmbeddingTimeout:    30 * time.Second,
+			MaxChunkSize:        1000,
+			ChunkOverlap:        200,
+			MinConfidence:       0.5,
+			EntityExtraction:    true,
+			ClaimExtraction:     true,
+			DuplicatePolicy:     "off",
 		},
 		Performance: PerformanceConfig{
 			MaxConcurrentRequests: 100,
@@ -219,6 +232,9 @@ func (v *VectorStoreConfig) Validate() error {
 	if v.IndexType == "" {
 		return fmt.Errorf("vector index type cannot be empty")
 	}
+	if _, err := ParseVectorMetric(v.Metric); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -252,6 +268,9 @@ func (p *ProcessingConfig) Validate() error {
 	if p.EmbeddingProvider == "" {
 		return fmt.Errorf("embedding provider cannot be empty")
 	}
+	if p.EmbeddingMaxRetries < 0 {
+		return fmt.Errorf("embedding max retries cannot be negative, got %d", p.EmbeddingMaxRetries)
+	}
 	if p.MaxChunkSize <= 0 {
 		return fmt.Errorf("max chunk size must be positive, got %d", p.MaxChunkSize)
 	}
@@ -264,6 +283,15 @@ func (p *ProcessingConfig) Validate() error {
 	if p.MinConfidence < 0 || p.MinConfidence > 1 {
 		return fmt.Errorf("min confidence must be between 0 and 1, got %f", p.MinConfidence)
 	}
+	if _, err := ParseDuplicatePolicy(p.DuplicatePolicy); err != nil {
+		return err
+	}
+	if p.DuplicateDistance < 0 || p.DuplicateDistance > maxDuplicateDistance {
+		return fmt.Errorf("duplicate distance must be between 0 and %d, got %d", maxDuplicateDistance, p.DuplicateDistance)
+	}
+	if p.IdempotencyTTL < 0 {
+		return fmt.Errorf("idempotency TTL cannot be negative, got %v", p.IdempotencyTTL)
+	}
 	return nil
 }
 
@@ -293,6 +293,26 @@ func TestProcessingConfigValidation(t *testing.T) {
 				So(err.Error(), ShouldContainSubstring, "min confidence must be between 0 and 1")
 			})
 		})
+		
+		Convey("When the duplicate policy is unknown", func() {
+			config.DuplicatePolicy = "overwrite"
+			err := config.Validate()
+			
+			Convey("Then validation should fail", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "unsupported duplicate policy")
+			})
+		})
+		
+		Convey("When the duplicate distance is out of range", func() {
+			config.DuplicateDistance = maxDuplicateDistance + 1
+			err := config.Validate()
+			
+			Convey("Then validation should fail", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "duplicate distance must be between")
+			})
+		})
 	})
 }
 
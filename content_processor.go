@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strings"
@@ -12,6 +13,7 @@ type ContentProcessor struct {
 	textChunker     *TextChunker
 	entityExtractor *EntityExtractor
 	claimExtractor  *ClaimExtractor
+	embedder        Embedder
 	config          *ContentProcessingConfig
 }
 
@@ -82,6 +84,11 @@ func NewContentProcessorWithConfig(config *ContentProcessingConfig) *ContentProc
 
 // Process processes the given content through the complete pipeline
 func (cp *ContentProcessor) Process(content, source string) (*ProcessingResult, error) {
+	return cp.ProcessContext(context.Background(), content, source)
+}
+
+// ProcessContext processes content and embeds the resulting chunks using ctx
+func (cp *ContentProcessor) ProcessContext(ctx context.Context, content, source string) (*ProcessingResult, error) {
 	startTime := time.Now()
 	
 	if content == "" {
@@ -99,7 +106,11 @@ func (cp *ContentProcessor) Process(content, source string) (*ProcessingResult,
 	// Preprocess content if enabled
 	processedContent := content
 	if cp.config.EnablePreprocessing {
-		processedContent = cp.Preprocess(content)
+		if cp.config.ChunkStrategy == "markdown" {
+			processedContent = cp.preprocessMarkdown(content)
+		} else {
+			processedContent = cp.Preprocess(content)
+		}
 	}
 	
 	// Step 1: Chunk the content
@@ -147,6 +158,9 @@ func (cp *ContentProcessor) Process(content, source string) (*ProcessingResult,
 		chunk.SetMetadata("original_end", chunkResult.End)
 		chunk.SetMetadata("entity_count", len(entities))
 		chunk.SetMetadata("claim_count", len(claims))
+		if len(chunkResult.Headings) > 0 {
+			chunk.SetMetadata("heading_path", strings.Join(chunkResult.Headings, headingPathSeparator))
+		}
 		
 		chunks = append(chunks, chunk)
 		allEntities = append(allEntities, entities...)
@@ -156,6 +170,11 @@ func (cp *ContentProcessor) Process(content, source string) (*ProcessingResult,
 	entityExtractionTime := time.Since(entityStart)
 	claimExtractionTime := time.Since(claimStart)
 	
+	// Step 3: Embed chunks if an embedder is configured
+	if err := cp.embedChunks(ctx, chunks); err != nil {
+		return nil, fmt.Errorf("chunk embedding failed: %w", err)
+	}
+	
 	// Create processing stats
 	stats := ProcessingStats{
 		OriginalLength:       len(content),
@@ -189,12 +208,41 @@ func (cp *ContentProcessor) Chunk(content string) []ChunkResult {
 		return cp.textChunker.ChunkBySentence(content)
 	case "paragraph":
 		return cp.textChunker.ChunkByParagraph(content)
+	case "markdown":
+		return cp.textChunker.ChunkByMarkdown(content)
 	default:
 		// Default to sentence-based chunking
 		return cp.textChunker.ChunkBySentence(content)
 	}
 }
 
+// embedChunks populates chunk embeddings in a single batch
+func (cp *ContentProcessor) embedChunks(ctx context.Context, chunks []*Chunk) error {
+	if cp.embedder == nil || len(chunks) == 0 {
+		return nil
+	}
+	
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.Content
+	}
+	
+	embeddings, err := cp.embedder.Embed(ctx, texts)
+	if err != nil {
+		return err
+	}
+	if len(embeddings) != len(chunks) {
+		return fmt.Errorf("embedder returned %d embeddings for %d chunks", len(embeddings), len(chunks))
+	}
+	
+	for i, chunk := range chunks {
+		chunk.Embedding = embeddings[i]
+		chunk.SetMetadata("embedding_model", cp.embedder.Name())
+	}
+	
+	return nil
+}
+
 // Preprocess cleans and normalizes content before processing
 func (cp *ContentProcessor) Preprocess(content string) string {
 	if content == "" {
@@ -216,6 +264,38 @@ func (cp *ContentProcessor) Preprocess(content string) string {
 	return content
 }
 
+// preprocessMarkdown preprocesses a Markdown document's prose line by line, so its structure
+// survives: code blocks and tables are left as written and list lines keep their indentation
+func (cp *ContentProcessor) preprocessMarkdown(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.ReplaceAll(content, "\r", "\n")
+	
+	var blocks []string
+	for _, block := range parseMarkdownBlocks(content) {
+		lines := make([]string, len(block.lines))
+		inFence := false
+		for i, line := range block.lines {
+			trimmed := strings.TrimLeft(line, " \t")
+			fence := markdownFencePattern.MatchString(line)
+			switch {
+			case block.kind == markdownCode || block.kind == markdownTable || inFence || fence:
+				// Code nested in a list item is left as written too
+				lines[i] = strings.TrimRight(line, " \t")
+			case block.kind == markdownList && trimmed != "":
+				lines[i] = line[:len(line)-len(trimmed)] + strings.TrimRight(cp.Preprocess(trimmed), " ")
+			default:
+				lines[i] = strings.TrimRight(cp.Preprocess(line), " ")
+			}
+			if fence && block.kind == markdownList {
+				inFence = !inFence
+			}
+		}
+		blocks = append(blocks, strings.Join(lines, "\n"))
+	}
+	
+	return strings.Join(blocks, "\n\n")
+}
+
 // SetChunkStrategy sets the chunking strategy
 func (cp *ContentProcessor) SetChunkStrategy(strategy string) {
 	cp.config.ChunkStrategy = strategy
@@ -245,6 +325,16 @@ func (cp *ContentProcessor) SetMinClaimConfidence(confidence float64) {
 	cp.claimExtractor.SetMinConfidence(confidence)
 }
 
+// SetEmbedder sets the embedder used to populate chunk embeddings
+func (cp *ContentProcessor) SetEmbedder(embedder Embedder) {
+	cp.embedder = embedder
+}
+
+// GetEmbedder returns the configured embedder, if any
+func (cp *ContentProcessor) GetEmbedder() Embedder {
+	return cp.embedder
+}
+
 // EnablePreprocessing enables or disables content preprocessing
 func (cp *ContentProcessor) EnablePreprocessing(enable bool) {
 	cp.config.EnablePreprocessing = enable
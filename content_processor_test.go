@@ -160,6 +160,23 @@ func TestContentProcessor(t *testing.T) {
 				}
 			})
 			
+			Convey("With markdown strategy", func() {
+				processor.SetChunkStrategy("markdown")
+				content := "# Setup\n\n## Database\n\nRun   the migrations,then seed.\n\n```sh\nmake  migrate:up\n```\n\n- one\n  - two,three"
+				
+				result, err := processor.Process(content, "guide")
+				So(err, ShouldBeNil)
+				So(result.Chunks, ShouldHaveLength, 1)
+				
+				chunk := result.Chunks[0]
+				So(chunk.Metadata["chunk_strategy"], ShouldEqual, "markdown")
+				So(chunk.Metadata["heading_path"], ShouldEqual, "Setup > Database")
+				// Prose is cleaned up while code and list nesting are kept as written
+				So(chunk.Content, ShouldContainSubstring, "Run the migrations, then seed.")
+				So(chunk.Content, ShouldContainSubstring, "```sh\nmake  migrate:up\n```")
+				So(chunk.Content, ShouldEndWith, "- one\n  - two, three")
+			})
+			
 			Convey("With unknown strategy defaults to sentence", func() {
 				processor.SetChunkStrategy("unknown")
 				content := "Test content for unknown strategy."
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Embedder converts text into dense vectors for the vector view
+type Embedder interface {
+	// Embed returns one embedding per input text, in the same order
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	// Dimensions returns the length of the produced embeddings
+	Dimensions() int
+	// Name identifies the provider and model in metadata
+	Name() string
+}
+
+// HashEmbedder produces deterministic embeddings by hashing word and character n-grams
+type HashEmbedder struct {
+	config *HashEmbedderConfig
+}
+
+// HashEmbedderConfig holds configuration for the hashed n-gram embedder
+type HashEmbedderConfig struct {
+	Dimensions   int     `json:"dimensions"`
+	MinCharNGram int     `json:"min_char_ngram"`
+	MaxCharNGram int     `json:"max_char_ngram"`
+	WordWeight   float64 `json:"word_weight"`
+	CharWeight   float64 `json:"char_weight"`
+	Seed         uint64  `json:"seed"`
+}
+
+// DefaultHashEmbedderDimensions is used when no dimension is configured
+const DefaultHashEmbedderDimensions = 256
+
+// NewHashEmbedder creates a HashEmbedder with default n-gram settings
+func NewHashEmbedder(dimensions int) *HashEmbedder {
+	return NewHashEmbedderWithConfig(&HashEmbedderConfig{Dimensions: dimensions})
+}
+
+// NewHashEmbedderWithConfig creates a HashEmbedder with custom configuration
+func NewHashEmbedderWithConfig(config *HashEmbedderConfig) *HashEmbedder {
+	cfg := HashEmbedderConfig{}
+	if config != nil {
+		cfg = *config
+	}
+
+	if cfg.Dimensions <= 0 {
+		cfg.Dimensions = DefaultHashEmbedderDimensions
+	}
+	if cfg.MinCharNGram <= 0 {
+		cfg.MinCharNGram = 3
+	}
+	if cfg.MaxCharNGram < cfg.MinCharNGram {
+		cfg.MaxCharNGram = cfg.MinCharNGram + 1
+	}
+	if cfg.WordWeight <= 0 {
+		cfg.WordWeight = 1.0
+	}
+	if cfg.CharWeight <= 0 {
+		cfg.CharWeight = 0.5
+	}
+
+	return &HashEmbedder{config: &cfg}
+}
+
+// Embed hashes each text into a unit-length vector
+func (he *HashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		embeddings[i] = he.embedText(text)
+	}
+	return embeddings, nil
+}
+
+// Dimensions returns the configured embedding dimension
+func (he *HashEmbedder) Dimensions() int {
+	return he.config.Dimensions
+}
+
+// Name returns the provider name
+func (he *HashEmbedder) Name() string {
+	return "hash"
+}
+
+// GetConfig returns the current configuration
+func (he *HashEmbedder) GetConfig() *HashEmbedderConfig {
+	return he.config
+}
+
+// embedText builds a single embedding from word and character n-gram features
+func (he *HashEmbedder) embedText(text string) []float32 {
+	vector := make([]float64, he.config.Dimensions)
+
+	for _, word := range he.tokenize(text) {
+		he.addFeature(vector, "w:"+word, he.config.WordWeight)
+
+		padded := []rune("<" + word + ">")
+		for n := he.config.MinCharNGram; n <= he.config.MaxCharNGram; n++ {
+			for i := 0; i+n <= len(padded); i++ {
+				he.addFeature(vector, "c:"+string(padded[i:i+n]), he.config.CharWeight)
+			}
+		}
+	}
+
+	// Sublinear scaling dampens very frequent features before normalizing
+	var norm float64
+	for i, v := range vector {
+		if v != 0 {
+			scaled := math.Copysign(math.Log1p(math.Abs(v)), v)
+			vector[i] = scaled
+			norm += scaled * scaled
+		}
+	}
+
+	embedding := make([]float32, len(vector))
+	if norm == 0 {
+		return embedding
+	}
+
+	norm = math.Sqrt(norm)
+	for i, v := range vector {
+		embedding[i] = float32(v / norm)
+	}
+
+	return embedding
+}
+
+// addFeature hashes a feature into a bucket with a hash-derived sign
+func (he *HashEmbedder) addFeature(vector []float64, feature string, weight float64) {
+	h := fnv.New64a()
+	var seed [8]byte
+	binary.LittleEndian.PutUint64(seed[:], he.config.Seed)
+	h.Write(seed[:])
+	h.Write([]byte(feature))
+	sum := h.Sum64()
+
+	bucket := int(sum % uint64(len(vector)))
+	if sum>>63 == 1 {
+		weight = -weight
+	}
+	vector[bucket] += weight
+}
+
+// tokenize lowercases text and splits on anything that is not a letter or digit
+func (he *HashEmbedder) tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// NewEmbedder creates the embedder selected by the processing configuration
+func NewEmbedder(config *ServerConfig) (Embedder, error) {
+	provider := strings.ToLower(config.Processing.EmbeddingProvider)
+
+	var embedder Embedder
+	switch {
+	case provider == "hash" || provider == "offline" || provider == "local":
+		embedder = NewHashEmbedder(config.Storage.VectorStore.Dimensions)
+	case isHTTPEmbeddingProvider(provider):
+		httpEmbedder, err := newHTTPEmbedderFromConfig(config, provider)
+		if err != nil {
+			return nil, err
+		}
+		embedder = httpEmbedder
+	default:
+		return nil, fmt.Errorf("unsupported embedding provider: %s", config.Processing.EmbeddingProvider)
+	}
+
+	// Wrap with the on-disk cache so unchanged content is never re-embedded
+	if config.Processing.EmbeddingCacheDir != "" {
+		cache, err := NewEmbeddingCache(config.Processing.EmbeddingCacheDir, config.Performance.CacheSize)
+		if err != nil {
+			return nil, err
+		}
+		embedder = NewCachedEmbedder(embedder, cache)
+	}
+
+	return embedder, nil
+}
+
+// embedSingle embeds one text with the given embedder
+func embedSingle(ctx context.Context, embedder Embedder, text string) ([]float32, error) {
+	embeddings, err := embedder.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	if len(embeddings) != 1 {
+		return nil, fmt.Errorf("embedder returned %d embeddings for 1 input", len(embeddings))
+	}
+	return embeddings[0], nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHashEmbedder(t *testing.T) {
+	Convey("Given a HashEmbedder", t, func() {
+		embedder := NewHashEmbedder(128)
+		ctx := context.Background()
+
+		Convey("When creating with a non-positive dimension", func() {
+			defaulted := NewHashEmbedder(0)
+
+			Convey("Then it should fall back to the default dimension", func() {
+				So(defaulted.Dimensions(), ShouldEqual, DefaultHashEmbedderDimensions)
+			})
+		})
+
+		Convey("When embedding texts", func() {
+			embeddings, err := embedder.Embed(ctx, []string{"machine learning models", "the weather in Paris"})
+
+			Convey("Then it should return one unit vector per text", func() {
+				So(err, ShouldBeNil)
+				So(len(embeddings), ShouldEqual, 2)
+				for _, embedding := range embeddings {
+					So(len(embedding), ShouldEqual, 128)
+
+					var norm float64
+					for _, v := range embedding {
+						norm += float64(v) * float64(v)
+					}
+					So(math.Sqrt(norm), ShouldAlmostEqual, 1.0, 1e-5)
+				}
+			})
+		})
+
+		Convey("When embedding the same text twice", func() {
+			first, err := embedSingle(ctx, embedder, "Deterministic embeddings")
+			So(err, ShouldBeNil)
+			second, err := embedSingle(ctx, NewHashEmbedder(128), "Deterministic embeddings")
+			So(err, ShouldBeNil)
+
+			Convey("Then the embeddings should be identical", func() {
+				So(first, ShouldResemble, second)
+			})
+		})
+
+		Convey("When comparing related and unrelated texts", func() {
+			searcher := NewVectorSearcher()
+			query, _ := embedSingle(ctx, embedder, "neural network training")
+			related, _ := embedSingle(ctx, embedder, "training a neural network on images")
+			unrelated, _ := embedSingle(ctx, embedder, "recipe for banana bread")
+
+			Convey("Then related text should be more similar", func() {
+				So(searcher.CosineSimilarity(query, related), ShouldBeGreaterThan, searcher.CosineSimilarity(query, unrelated))
+			})
+		})
+
+		Convey("When embedding text without any tokens", func() {
+			embedding, err := embedSingle(ctx, embedder, "  ...  ")
+
+			Convey("Then it should return a zero vector", func() {
+				So(err, ShouldBeNil)
+				So(len(embedding), ShouldEqual, 128)
+				for _, v := range embedding {
+					So(v, ShouldEqual, 0)
+				}
+			})
+		})
+
+		Convey("When the context is cancelled", func() {
+			cancelled, cancel := context.WithCancel(ctx)
+			cancel()
+			_, err := embedder.Embed(cancelled, []string{"text"})
+
+			Convey("Then it should return an error", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+func TestNewEmbedder(t *testing.T) {
+	Convey("Given a server configuration", t, func() {
+		config := DefaultServerConfig()
+		config.Storage.VectorStore.Dimensions = 64
+
+		Convey("When the provider is the offline hash embedder", func() {
+			config.Processing.EmbeddingProvider = "hash"
+			embedder, err := NewEmbedder(config)
+
+			Convey("Then it should create a HashEmbedder with the configured dimension", func() {
+				So(err, ShouldBeNil)
+				So(embedder.Name(), ShouldEqual, "hash")
+				So(embedder.Dimensions(), ShouldEqual, 64)
+			})
+		})
+
+		Convey("When the provider is an OpenAI-compatible endpoint", func() {
+			config.Processing.EmbeddingProvider = "ollama"
+			config.Processing.EmbeddingModel = "nomic-embed-text"
+			embedder, err := NewEmbedder(config)
+
+			Convey("Then it should create an HTTPEmbedder with the provider default URL", func() {
+				So(err, ShouldBeNil)
+				httpEmbedder, ok := embedder.(*HTTPEmbedder)
+				So(ok, ShouldBeTrue)
+				So(httpEmbedder.GetConfig().BaseURL, ShouldEqual, "http://localhost:11434/v1")
+				So(httpEmbedder.GetConfig().BatchSize, ShouldEqual, config.Performance.BatchSize)
+				So(embedder.Name(), ShouldEqual, "ollama:nomic-embed-text")
+			})
+		})
+
+		Convey("When a cache directory is configured", func() {
+			config.Processing.EmbeddingCacheDir = t.TempDir()
+			embedder, err := NewEmbedder(config)
+
+			Convey("Then the embedder should be wrapped with the cache", func() {
+				So(err, ShouldBeNil)
+				_, ok := embedder.(*CachedEmbedder)
+				So(ok, ShouldBeTrue)
+			})
+		})
+
+		Convey("When the provider is unknown", func() {
+			config.Processing.EmbeddingProvider = "carrier-pigeon"
+			embedder, err := NewEmbedder(config)
+
+			Convey("Then it should return an error", func() {
+				So(err, ShouldNotBeNil)
+				So(embedder, ShouldBeNil)
+				So(err.Error(), ShouldContainSubstring, "unsupported embedding provider")
+			})
+		})
+	})
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// EmbeddingCache stores embeddings on disk keyed by a hash of the embedded content
+type EmbeddingCache struct {
+	mu          sync.RWMutex
+	dir         string
+	memory      map[string][]float32
+	memoryLimit int
+	hits        int64
+	misses      int64
+}
+
+// NewEmbeddingCache creates a cache rooted at dir, keeping up to memoryLimit entries in memory
+func NewEmbeddingCache(dir string, memoryLimit int) (*EmbeddingCache, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("embedding cache directory cannot be empty")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create embedding cache directory: %w", err)
+	}
+
+	return &EmbeddingCache{
+		dir:         dir,
+		memory:      make(map[string][]float32),
+		memoryLimit: memoryLimit,
+	}, nil
+}
+
+// Key builds the cache key for a text embedded by the named model
+func (ec *EmbeddingCache) Key(model string, dimensions int, text string) string {
+	h := sha256.New()
+	h.Write([]byte(model))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.Itoa(dimensions)))
+	h.Write([]byte{0})
+	h.Write([]byte(text))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get returns the cached embedding for key, if present
+func (ec *EmbeddingCache) Get(key string) ([]float32, bool) {
+	ec.mu.RLock()
+	embedding, ok := ec.memory[key]
+	ec.mu.RUnlock()
+	if ok {
+		ec.recordLookup(true)
+		return embedding, true
+	}
+
+	data, err := os.ReadFile(ec.pathFor(key))
+	if err != nil || len(data)%4 != 0 || len(data) == 0 {
+		ec.recordLookup(false)
+		return nil, false
+	}
+
+	embedding = make([]float32, len(data)/4)
+	for i := range embedding {
+		embedding[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+	}
+
+	ec.mu.Lock()
+	ec.remember(key, embedding)
+	ec.mu.Unlock()
+
+	ec.recordLookup(true)
+	return embedding, true
+}
+
+// Put stores an embedding under key, writing it atomically to disk
+func (ec *EmbeddingCache) Put(key string, embedding []float32) error {
+	data := make([]byte, len(embedding)*4)
+	for i, v := range embedding {
+		binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(v))
+	}
+
+	path := ec.pathFor(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache shard: %w", err)
+	}
+
+	// Each Put writes its own temporary file, so concurrent Puts of one key never share one
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cache entry: %w", err)
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to commit cache entry: %w", err)
+	}
+
+	ec.mu.Lock()
+	ec.remember(key, embedding)
+	ec.mu.Unlock()
+
+	return nil
+}
+
+// Stats returns cache hit and miss counters
+func (ec *EmbeddingCache) Stats() map[string]interface{} {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+
+	return map[string]interface{}{
+		"hits":           ec.hits,
+		"misses":         ec.misses,
+		"memory_entries": len(ec.memory),
+		"dir":            ec.dir,
+	}
+}
+
+// pathFor shards entries by the first two hex characters of the key
+func (ec *EmbeddingCache) pathFor(key string) string {
+	return filepath.Join(ec.dir, key[:2], key+".bin")
+}
+
+// remember keeps an entry in memory while under the limit; caller holds the lock
+func (ec *EmbeddingCache) remember(key string, embedding []float32) {
+	if ec.memoryLimit > 0 && len(ec.memory) >= ec.memoryLimit {
+		return
+	}
+	ec.memory[key] = embedding
+}
+
+// recordLookup updates hit and miss counters
+func (ec *EmbeddingCache) recordLookup(hit bool) {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	if hit {
+		ec.hits++
+	} else {
+		ec.misses++
+	}
+}
+
+// CachedEmbedder wraps an Embedder so repeated content is served from the cache
+type CachedEmbedder struct {
+	embedder Embedder
+	cache    *EmbeddingCache
+}
+
+// NewCachedEmbedder creates a CachedEmbedder around embedder
+func NewCachedEmbedder(embedder Embedder, cache *EmbeddingCache) *CachedEmbedder {
+	return &CachedEmbedder{
+		embedder: embedder,
+		cache:    cache,
+	}
+}
+
+// Embed returns cached embeddings and only sends uncached texts to the wrapped embedder
+func (ce *CachedEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	keys := make([]string, len(texts))
+	var missing []string
+	var missingIdx []int
+
+	for i, text := range texts {
+		keys[i] = ce.cache.Key(ce.embedder.Name(), ce.embedder.Dimensions(), text)
+		if embedding, ok := ce.cache.Get(keys[i]); ok {
+			embeddings[i] = embedding
+			continue
+		}
+		missing = append(missing, text)
+		missingIdx = append(missingIdx, i)
+	}
+
+	if len(missing) == 0 {
+		return embeddings, nil
+	}
+
+	computed, err := ce.embedder.Embed(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	if len(computed) != len(missing) {
+		return nil, fmt.Errorf("embedder returned %d embeddings for %d inputs", len(computed), len(missing))
+	}
+
+	// The embeddings are good whether or not they reach the cache, so a failed write is only logged
+	for j, idx := range missingIdx {
+		embeddings[idx] = computed[j]
+		if err := ce.cache.Put(keys[idx], computed[j]); err != nil {
+			log.Printf("Failed to cache embedding: %v", err)
+		}
+	}
+
+	return embeddings, nil
+}
+
+// Dimensions returns the wrapped embedder's dimension
+func (ce *CachedEmbedder) Dimensions() int {
+	return ce.embedder.Dimensions()
+}
+
+// Name returns the wrapped embedder's name
+func (ce *CachedEmbedder) Name() string {
+	return ce.embedder.Name()
+}
+
+// GetCache returns the underlying cache
+func (ce *CachedEmbedder) GetCache() *EmbeddingCache {
+	return ce.cache
+}
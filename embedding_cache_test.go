@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// countingEmbedder records how many texts reach the wrapped embedder
+type countingEmbedder struct {
+	*HashEmbedder
+	embedded int32
+}
+
+func (c *countingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	atomic.AddInt32(&c.embedded, int32(len(texts)))
+	return c.HashEmbedder.Embed(ctx, texts)
+}
+
+func TestEmbeddingCache(t *testing.T) {
+	Convey("Given an EmbeddingCache", t, func() {
+		dir := t.TempDir()
+		cache, err := NewEmbeddingCache(dir, 10)
+		So(err, ShouldBeNil)
+
+		Convey("When storing and reading an embedding", func() {
+			key := cache.Key("model", 3, "content")
+			err := cache.Put(key, []float32{0.25, -0.5, 1})
+			So(err, ShouldBeNil)
+
+			Convey("Then it should be readable from a fresh cache on the same directory", func() {
+				reopened, err := NewEmbeddingCache(dir, 10)
+				So(err, ShouldBeNil)
+
+				embedding, ok := reopened.Get(key)
+				So(ok, ShouldBeTrue)
+				So(embedding, ShouldResemble, []float32{0.25, -0.5, 1})
+			})
+		})
+
+		Convey("When storing one key concurrently", func() {
+			key := cache.Key("model", 3, "content")
+			errs := make(chan error, 8)
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					errs <- cache.Put(key, []float32{float32(i), 0, 1})
+				}(i)
+			}
+			wg.Wait()
+			close(errs)
+
+			Convey("Then every write should succeed and leave one complete entry", func() {
+				for err := range errs {
+					So(err, ShouldBeNil)
+				}
+				entries, err := os.ReadDir(filepath.Join(dir, key[:2]))
+				So(err, ShouldBeNil)
+				So(len(entries), ShouldEqual, 1)
+				So(entries[0].Name(), ShouldEqual, key+".bin")
+
+				reopened, err := NewEmbeddingCache(dir, 10)
+				So(err, ShouldBeNil)
+				embedding, ok := reopened.Get(key)
+				So(ok, ShouldBeTrue)
+				So(len(embedding), ShouldEqual, 3)
+			})
+		})
+
+		Convey("When building keys", func() {
+			Convey("Then they should differ by model, dimension and text", func() {
+				base := cache.Key("model", 3, "content")
+				So(cache.Key("model", 3, "content"), ShouldEqual, base)
+				So(cache.Key("other", 3, "content"), ShouldNotEqual, base)
+				So(cache.Key("model", 4, "content"), ShouldNotEqual, base)
+				So(cache.Key("model", 3, "other"), ShouldNotEqual, base)
+			})
+		})
+
+		Convey("When reading a missing key", func() {
+			_, ok := cache.Get(cache.Key("model", 3, "missing"))
+
+			Convey("Then it should miss", func() {
+				So(ok, ShouldBeFalse)
+				So(cache.Stats()["misses"], ShouldEqual, 1)
+			})
+		})
+
+		Convey("When creating a cache without a directory", func() {
+			_, err := NewEmbeddingCache("", 10)
+
+			Convey("Then it should return an error", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+func TestCachedEmbedder(t *testing.T) {
+	Convey("Given a CachedEmbedder", t, func() {
+		ctx := context.Background()
+		cache, err := NewEmbeddingCache(t.TempDir(), 100)
+		So(err, ShouldBeNil)
+
+		inner := &countingEmbedder{HashEmbedder: NewHashEmbedder(32)}
+		embedder := NewCachedEmbedder(inner, cache)
+
+		Convey("When re-embedding the same content", func() {
+			first, err := embedder.Embed(ctx, []string{"alpha", "beta"})
+			So(err, ShouldBeNil)
+			second, err := embedder.Embed(ctx, []string{"beta", "gamma", "alpha"})
+			So(err, ShouldBeNil)
+
+			Convey("Then only new texts should reach the wrapped embedder", func() {
+				So(atomic.LoadInt32(&inner.embedded), ShouldEqual, 3)
+				So(second[0], ShouldResemble, first[1])
+				So(second[2], ShouldResemble, first[0])
+				So(len(second[1]), ShouldEqual, 32)
+			})
+		})
+
+		Convey("When the cache cannot be written", func() {
+			dir := filepath.Join(t.TempDir(), "cache")
+			broken, err := NewEmbeddingCache(dir, 100)
+			So(err, ShouldBeNil)
+			So(os.RemoveAll(dir), ShouldBeNil)
+			So(os.WriteFile(dir, nil, 0644), ShouldBeNil)
+
+			embeddings, err := NewCachedEmbedder(inner, broken).Embed(ctx, []string{"alpha"})
+
+			Convey("Then the embeddings should still be returned", func() {
+				So(err, ShouldBeNil)
+				So(len(embeddings), ShouldEqual, 1)
+				So(len(embeddings[0]), ShouldEqual, 32)
+			})
+		})
+
+		Convey("When inspecting the wrapper", func() {
+			Convey("Then it should expose the wrapped name and dimension", func() {
+				So(embedder.Name(), ShouldEqual, "hash")
+				So(embedder.Dimensions(), ShouldEqual, 32)
+				So(embedder.GetCache(), ShouldEqual, cache)
+			})
+		})
+	})
+}
@@ -12,6 +12,7 @@ import (
 type EntityResolver struct {
 	storage             *MultiViewStorage
 	similarityThreshold float64
+	embedder            Embedder
 	config              *EntityResolverConfig
 }
 
@@ -84,8 +85,22 @@ func (er *EntityResolver) Deduplicate(entities []Entity, target Entity) *Entity
 	return &target
 }
 
+// SetEmbedder sets the embedder used to embed entity names for vector matching
+func (er *EntityResolver) SetEmbedder(embedder Embedder) {
+	er.embedder = embedder
+}
+
 // Link links an entity to existing entities in the storage system
 func (er *EntityResolver) Link(ctx context.Context, entity Entity) (Entity, error) {
+	// Attach an embedding so the entity can be matched and stored by vector
+	if er.embedder != nil {
+		embedded, err := er.attachEmbedding(ctx, entity)
+		if err != nil {
+			return entity, fmt.Errorf("failed to embed entity: %w", err)
+		}
+		entity = embedded
+	}
+
 	// Search for similar entities in the vector store
 	candidates, err := er.findSimilarEntities(ctx, entity)
 	if err != nil {
@@ -143,12 +158,36 @@ func extractEmbedding(v interface{}) ([]float32, bool) {
 	return nil, false
 }
 
+// attachEmbedding returns a copy of the entity with an embedding of its name
+func (er *EntityResolver) attachEmbedding(ctx context.Context, entity Entity) (Entity, error) {
+	if _, ok := extractEmbedding(entity.Properties["embedding"]); ok {
+		return entity, nil
+	}
+
+	embedding, err := embedSingle(ctx, er.embedder, entity.Name)
+	if err != nil {
+		return entity, err
+	}
+
+	properties := make(map[string]interface{}, len(entity.Properties)+1)
+	for k, v := range entity.Properties {
+		properties[k] = v
+	}
+	properties["embedding"] = embedding
+	entity.Properties = properties
+
+	return entity, nil
+}
+
 // findSimilarEntities searches for entities similar to the target entity
 func (er *EntityResolver) findSimilarEntities(ctx context.Context, entity Entity) ([]Entity, error) {
 	// Check if entity has embedding for vector-based similarity
 	if raw, ok := entity.Properties["embedding"]; ok {
 		if embeddingSlice, ok := extractEmbedding(raw); ok && len(embeddingSlice) > 0 {
-			return er.findEntitiesByEmbedding(ctx, embeddingSlice)
+			candidates, err := er.findEntitiesByEmbedding(ctx, embeddingSlice)
+			if err != nil || len(candidates) > 0 {
+				return candidates, err
+			}
 		}
 	}
 
@@ -176,8 +215,8 @@ func (er *EntityResolver) findEntitiesByEmbedding(ctx context.Context, embedding
 		if len(result.Embedding) == 0 || len(result.Embedding) != len(embedding) {
 			continue
 		}
-		// Calculate cosine similarity
-		similarity := er.calculateCosineSimilarity(embedding, result.Embedding)
+		// Score with the store's metric, mapped onto [0, 1] so the threshold means the same for every metric
+		similarity := er.calculateVectorConfidence(embedding, result.Embedding)
 
 		// Skip entities below similarity threshold
 		if similarity < er.config.SimilarityThreshold {
@@ -255,6 +294,9 @@ func (er *EntityResolver) findBestMatch(target Entity, candidates []Entity) *Ent
 	bestSimilarity := 0.0
 
 	for _, candidate := range candidates {
+		if candidate.ID == target.ID {
+			continue // Already the same entity
+		}
 		similarity := er.calculateSimilarity(target, candidate)
 		if similarity > er.similarityThreshold && similarity > bestSimilarity {
 			bestSimilarity = similarity
@@ -332,35 +374,29 @@ func (er *EntityResolver) jaccardSimilarity(s1, s2 string) float64 {
 	return float64(intersection) / float64(union)
 }
 
-// calculateCosineSimilarity calculates cosine similarity between two vectors
-// Used for vector-based entity similarity in entity resolution
-func (er *EntityResolver) calculateCosineSimilarity(v1, v2 []float32) float64 {
-	if len(v1) != len(v2) {
+// calculateVectorConfidence scores two embeddings with the vector store's metric and maps
+// the similarity onto [0, 1]
+func (er *EntityResolver) calculateVectorConfidence(v1, v2 []float32) float64 {
+	if len(v1) != len(v2) || len(v1) == 0 {
 		return 0.0
 	}
 
-	if len(v1) == 0 {
-		return 0.0
-	}
-
-	var dotProduct, norm1, norm2 float64
-	for i := 0; i < len(v1); i++ {
-		dotProduct += float64(v1[i] * v2[i])
-		norm1 += float64(v1[i] * v1[i])
-		norm2 += float64(v2[i] * v2[i])
-	}
+	metric := er.vectorMetric()
+	return metric.Confidence(metric.Similarity(v1, v2))
+}
 
-	if norm1 == 0 || norm2 == 0 {
-		return 0.0
+// vectorMetric returns the metric the entity embeddings are stored under
+func (er *EntityResolver) vectorMetric() VectorMetric {
+	if er.storage == nil || er.storage.vectorStore == nil {
+		return VectorMetricCosine
 	}
-
-	return dotProduct / (math.Sqrt(norm1) * math.Sqrt(norm2))
+	return vectorStoreMetric(er.storage.vectorStore)
 }
 
-// CalculateVectorSimilarity is a public wrapper for calculateCosineSimilarity
+// CalculateVectorSimilarity is a public wrapper for calculateVectorConfidence
 // This ensures the method is recognized as used by static analysis tools
 func (er *EntityResolver) CalculateVectorSimilarity(v1, v2 []float32) float64 {
-	return er.calculateCosineSimilarity(v1, v2)
+	return er.calculateVectorConfidence(v1, v2)
 }
 
 // mergeEntities merges two entities, combining their properties
@@ -399,7 +435,7 @@ func (er *EntityResolver) createEntityLink(ctx context.Context, entity1, entity2
 	weight := er.calculateSimilarity(entity1, entity2)
 	if v1, ok1 := extractEmbedding(entity1.Properties["embedding"]); ok1 {
 		if v2, ok2 := extractEmbedding(entity2.Properties["embedding"]); ok2 && len(v1) == len(v2) {
-			weight = er.calculateCosineSimilarity(v1, v2)
+			weight = er.calculateVectorConfidence(v1, v2)
 		}
 	}
 	edge := &Edge{
@@ -415,5 +451,23 @@ func (er *EntityResolver) createEntityLink(ctx context.Context, entity1, entity2
 		CreatedAt: time.Now(),
 	}
 
+	// The new entity may not be stored yet; create its node so the edge has a source
+	if _, err := er.storage.graphStore.GetNode(ctx, entity1.ID); err != nil {
+		node := &Node{
+			ID:   entity1.ID,
+			Type: EntityNode,
+			Properties: map[string]interface{}{
+				"name":       entity1.Name,
+				"type":       entity1.Type,
+				"confidence": entity1.Confidence,
+			},
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		if err := er.storage.graphStore.CreateNode(ctx, node); err != nil {
+			return fmt.Errorf("failed to create entity node: %w", err)
+		}
+	}
+
 	return er.storage.graphStore.CreateEdge(ctx, edge)
 }
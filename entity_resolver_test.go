@@ -303,6 +303,44 @@ func TestEntityResolverLink(t *testing.T) {
 				So(len(edges), ShouldEqual, 0)
 			})
 		})
+
+		Convey("When linking with an embedder against a stored entity embedding", func() {
+			ctx := context.Background()
+			embedder := NewHashEmbedder(64)
+			resolver.SetEmbedder(embedder)
+
+			existingEmbedding, err := embedSingle(ctx, embedder, "Ada Lovelace")
+			So(err, ShouldBeNil)
+			err = mockVectorStore.Store(ctx, "existing_ada", existingEmbedding, map[string]interface{}{
+				"type":        "entity",
+				"name":        "Ada Lovelace",
+				"entity_type": "Person",
+			})
+			So(err, ShouldBeNil)
+			err = mockGraphStore.CreateNode(ctx, &Node{ID: "existing_ada", Type: EntityNode})
+			So(err, ShouldBeNil)
+
+			entity := Entity{
+				ID:         "new_ada",
+				Name:       "Ada Lovelace",
+				Type:       "Person",
+				Confidence: 0.7,
+				Properties: make(map[string]interface{}),
+			}
+
+			linked, err := resolver.Link(ctx, entity)
+
+			Convey("Then it should link to the stored entity by vector similarity", func() {
+				So(err, ShouldBeNil)
+				So(linked.ID, ShouldEqual, "new_ada")
+				So(linked.Properties["embedding"], ShouldNotBeNil)
+
+				edges := mockGraphStore.GetEdges()
+				So(len(edges), ShouldEqual, 1)
+				So(edges[0].From, ShouldEqual, "new_ada")
+				So(edges[0].To, ShouldEqual, "existing_ada")
+			})
+		})
 	})
 }
 
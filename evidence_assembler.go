@@ -59,6 +59,7 @@ type AssemblyInput struct {
 	MatchedTerms    []string               `json:"matched_terms,omitempty"`
 	GraphPath       []string               `json:"graph_path,omitempty"`
 	RelatedEntities []string               `json:"related_entities,omitempty"`
+	MethodScores    map[string]float64     `json:"method_scores,omitempty"` // Per-view scores when the input came from fusion
 }
 
 // AssemblyResponse contains the assembled evidence and statistics
@@ -230,9 +231,14 @@ func (ea *EvidenceAssembler) Assemble(ctx context.Context, inputs []AssemblyInpu
 
 // AddProvenance adds provenance information to evidence
 func (ea *EvidenceAssembler) AddProvenance(evidence *Evidence, input AssemblyInput, assemblyCtx *AssemblyContext) {
+	timestamp := input.Timestamp
+	if timestamp.IsZero() {
+		timestamp = assemblyCtx.RequestTime
+	}
+
 	provenance := ProvenanceInfo{
 		Source:    input.Source,
-		Timestamp: input.Timestamp.Format(time.RFC3339),
+		Timestamp: timestamp.Format(time.RFC3339),
 		Version:   "1.0",
 	}
 
@@ -248,6 +254,10 @@ func (ea *EvidenceAssembler) AddProvenance(evidence *Evidence, input AssemblyInp
 			}
 		}
 
+		if userID, ok := input.Metadata["user_id"].(string); ok && provenance.UserID == "" {
+			provenance.UserID = userID
+		}
+
 		if author, exists := input.Metadata["author"]; exists {
 			if authorStr, ok := author.(string); ok {
 				if provenance.UserID == "" {
@@ -304,7 +314,9 @@ func (ea *EvidenceAssembler) createEvidence(input AssemblyInput, assemblyCtx *As
 	}
 
 	evidence := &Evidence{
+		MemoryID:    input.ID,
 		Content:     content,
+		Breadcrumb:  headingPath(input.Metadata),
 		Source:      input.Source,
 		Confidence:  input.Score,
 		WhySelected: ea.generateWhySelected(input, assemblyCtx),
@@ -329,6 +341,13 @@ func (ea *EvidenceAssembler) generateWhySelected(input AssemblyInput, _ *Assembl
 		reasons = append(reasons, "moderate relevance score")
 	}
 
+	// Add retrieval view reasons
+	if len(input.MethodScores) > 1 {
+		reasons = append(reasons, fmt.Sprintf("found by multi-view fusion (%s)", strings.Join(sortedMethods(input.MethodScores), ", ")))
+	} else if len(input.MethodScores) == 1 {
+		reasons = append(reasons, fmt.Sprintf("found by %s search", sortedMethods(input.MethodScores)[0]))
+	}
+
 	// Add term matching reasons
 	if len(input.MatchedTerms) > 0 {
 		switch ea.config.WhySelectedDetail {
@@ -405,6 +424,11 @@ func (ea *EvidenceAssembler) addRelationMap(evidence *Evidence, input AssemblyIn
 		}
 	}
 
+	// Add the score each retrieval view gave the input
+	for method, score := range input.MethodScores {
+		evidence.RelationMap[method] = fmt.Sprintf("%.3f", score)
+	}
+
 	// Add source relation
 	if input.Source != "" {
 		evidence.RelationMap["source"] = input.Source
@@ -426,6 +450,16 @@ func (ea *EvidenceAssembler) addRelationMap(evidence *Evidence, input AssemblyIn
 	}
 }
 
+// sortedMethods returns the retrieval methods of a score map in name order
+func sortedMethods(methodScores map[string]float64) []string {
+	methods := make([]string, 0, len(methodScores))
+	for method := range methodScores {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
 // filterByConfidence filters inputs by minimum confidence threshold
 func (ea *EvidenceAssembler) filterByConfidence(inputs []AssemblyInput) []AssemblyInput {
 	if ea.config.MinConfidence <= 0 {
@@ -484,9 +518,10 @@ func (ea *EvidenceAssembler) calculateContentSimilarity(content1, content2 strin
 		return 0.0
 	}
 
-	// Simple Jaccard similarity based on words
-	words1 := ea.tokenizeContent(content1)
-	words2 := ea.tokenizeContent(content2)
+	// Jaccard similarity over analyzed terms
+	analyzer := AnalyzerForLanguage(defaultAnalyzerLanguage)
+	words1 := analyzer.Analyze(content1)
+	words2 := analyzer.Analyze(content2)
 
 	if len(words1) == 0 || len(words2) == 0 {
 		return 0.0
@@ -522,22 +557,6 @@ func (ea *EvidenceAssembler) calculateContentSimilarity(content1, content2 strin
 	return float64(intersection) / float64(union)
 }
 
-// tokenizeContent tokenizes content into words
-func (ea *EvidenceAssembler) tokenizeContent(content string) []string {
-	// Simple tokenization - split on whitespace and convert to lowercase
-	words := strings.Fields(strings.ToLower(content))
-
-	// Filter out very short words
-	var filtered []string
-	for _, word := range words {
-		if len(word) >= 3 {
-			filtered = append(filtered, word)
-		}
-	}
-
-	return filtered
-}
-
 // calculateConfidenceDistribution calculates distribution of confidence scores
 func (ea *EvidenceAssembler) calculateConfidenceDistribution(evidence []Evidence) map[string]int {
 	distribution := map[string]int{
@@ -572,11 +591,12 @@ func (ea *EvidenceAssembler) AssembleFromFusedResults(ctx context.Context, fused
 
 	for i, result := range fusedResults {
 		inputs[i] = AssemblyInput{
-			ID:       result.ID,
-			Content:  result.Content,
-			Score:    result.FinalScore,
-			Source:   "fused_search",
-			Metadata: result.Metadata,
+			ID:           result.ID,
+			Content:      result.Content,
+			Score:        result.FinalScore,
+			Source:       "fused_search",
+			Metadata:     result.Metadata,
+			MethodScores: result.MethodScores,
 		}
 
 		// Extract matched terms from method scores
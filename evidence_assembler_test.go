@@ -529,6 +529,23 @@ func TestEvidenceAssembler_AssembleFromResults(t *testing.T) {
 			So(response.Evidence[0].Content, ShouldEqual, "Keyword search result")
 			So(response.Metadata["retrieval_method"], ShouldEqual, "keyword")
 		})
+
+		Convey("When assembling a Markdown chunk", func() {
+			keywordResults := []KeywordSearchResult{
+				{
+					ID:       "guide_chunk_2",
+					Content:  "Run make migrate before deploying.",
+					Score:    0.8,
+					Metadata: map[string]interface{}{"heading_path": "Setup > Database > Migrations"},
+				},
+			}
+
+			response, err := assembler.AssembleFromKeywordResults(ctx, keywordResults, &AssemblyContext{Query: "migrations"})
+
+			So(err, ShouldBeNil)
+			So(response.Evidence[0].Breadcrumb, ShouldEqual, "Setup > Database > Migrations")
+			So(response.Evidence[0].Content, ShouldEqual, "Run make migrate before deploying.")
+		})
 	})
 }
 
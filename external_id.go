@@ -0,0 +1,114 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// externalChunkID derives the ID of a chunk written under an external ID from the ID and
+// the chunk's position, so every version of a document stores its chunks under the same IDs
+func externalChunkID(externalID string, index int) string {
+	hash := sha256.Sum256([]byte(externalID))
+	return fmt.Sprintf("ext_%x_chunk_%d", hash[:8], index)
+}
+
+// externalIDIndex maps the external IDs chunks were written under to the chunks' IDs
+type externalIDIndex struct {
+	chunks map[string]map[string]bool // External ID -> chunk IDs
+	owners map[string]string          // Chunk ID -> external ID
+}
+
+// newExternalIDIndex creates an empty index
+func newExternalIDIndex() *externalIDIndex {
+	return &externalIDIndex{
+		chunks: make(map[string]map[string]bool),
+		owners: make(map[string]string),
+	}
+}
+
+// add records the external ID a chunk's metadata carries, replacing any it had before
+func (ei *externalIDIndex) add(chunkID string, metadata map[string]interface{}) {
+	ei.remove(chunkID)
+	externalID, _ := metadata["external_id"].(string)
+	if externalID == "" {
+		return
+	}
+	if ei.chunks[externalID] == nil {
+		ei.chunks[externalID] = make(map[string]bool)
+	}
+	ei.chunks[externalID][chunkID] = true
+	ei.owners[chunkID] = externalID
+}
+
+// remove drops a chunk from the index
+func (ei *externalIDIndex) remove(chunkID string) {
+	externalID, exists := ei.owners[chunkID]
+	if !exists {
+		return
+	}
+	delete(ei.owners, chunkID)
+	delete(ei.chunks[externalID], chunkID)
+	if len(ei.chunks[externalID]) == 0 {
+		delete(ei.chunks, externalID)
+	}
+}
+
+// lookup returns the IDs of the chunks written under an external ID, sorted
+func (ei *externalIDIndex) lookup(externalID string) []string {
+	ids := make([]string, 0, len(ei.chunks[externalID]))
+	for id := range ei.chunks[externalID] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// documentVersion reads the version a stored chunk was written as, 1 for chunks written
+// before versions were recorded
+func documentVersion(metadata map[string]interface{}) int {
+	if version, ok := filterNumber(metadata["version"]); ok && version >= 1 {
+		return int(version)
+	}
+	return 1
+}
+
+// keyLocks serializes work per key, such as the writes replacing one external ID's chunks,
+// keeping a mutex only while someone holds or waits for it
+type keyLocks struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+// keyLock is the mutex of one key and the number of callers holding or waiting for it
+type keyLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lock locks key and returns the function that unlocks it
+func (kl *keyLocks) lock(key string) func() {
+	kl.mu.Lock()
+	if kl.locks == nil {
+		kl.locks = make(map[string]*keyLock)
+	}
+	lock := kl.locks[key]
+	if lock == nil {
+		lock = &keyLock{}
+		kl.locks[key] = lock
+	}
+	lock.refs++
+	kl.mu.Unlock()
+
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+		kl.mu.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(kl.locks, key)
+		}
+		kl.mu.Unlock()
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestExternalIDIndex(t *testing.T) {
+	Convey("Given an external ID index", t, func() {
+		index := newExternalIDIndex()
+		index.add("b", map[string]interface{}{"external_id": "doc"})
+		index.add("a", map[string]interface{}{"external_id": "doc"})
+		index.add("c", map[string]interface{}{})
+
+		Convey("Then chunks should be found by the external ID they carry, sorted", func() {
+			So(index.lookup("doc"), ShouldResemble, []string{"a", "b"})
+			So(index.lookup("other"), ShouldBeEmpty)
+		})
+
+		Convey("Then re-adding a chunk should move it to its new external ID", func() {
+			index.add("a", map[string]interface{}{"external_id": "other"})
+			So(index.lookup("doc"), ShouldResemble, []string{"b"})
+			So(index.lookup("other"), ShouldResemble, []string{"a"})
+		})
+
+		Convey("Then removed chunks should no longer be found", func() {
+			index.remove("a")
+			index.remove("b")
+			index.remove("missing")
+			So(index.lookup("doc"), ShouldBeEmpty)
+			So(index.chunks, ShouldBeEmpty)
+		})
+	})
+
+	Convey("Given external IDs", t, func() {
+		So(externalChunkID("doc", 0), ShouldEqual, externalChunkID("doc", 0))
+		So(externalChunkID("doc", 0), ShouldNotEqual, externalChunkID("doc", 1))
+		So(externalChunkID("doc", 0), ShouldNotEqual, externalChunkID("other", 0))
+	})
+}
+
+func TestMultiViewStorageReplaceChunks(t *testing.T) {
+	Convey("Given storage holding a chunk written under an external ID", t, func() {
+		ctx := context.Background()
+		vectorStore := NewMockVectorStore()
+		graphStore := NewMockGraphStore()
+		searchIndex := NewMockSearchIndex()
+		storage := NewMultiViewStorage(vectorStore, graphStore, searchIndex, nil)
+
+		old := NewChunk("doc_chunk_0", "Paris is the capital of France.", "atlas")
+		old.Embedding = []float32{1, 0, 0}
+		old.SetMetadata("external_id", "doc")
+		old.AddClaim(*NewClaim("claim_old", "Paris", "is", "the capital of France", "atlas"))
+		So(storage.StoreChunk(ctx, old), ShouldBeNil)
+
+		replacement := func() *Chunk {
+			chunk := NewChunk("doc_chunk_0", "Paris is not the capital of France.", "atlas")
+			chunk.Embedding = []float32{0, 1, 0}
+			chunk.SetMetadata("external_id", "doc")
+			chunk.AddClaim(*NewClaim("claim_new", "Paris", "is", "not the capital of France", "atlas"))
+			return chunk
+		}
+
+		Convey("Then the chunk should be found by its external ID, even before the index exists", func() {
+			storage.externalIDs = nil
+			docs, err := storage.ExternalChunks(ctx, "doc")
+			So(err, ShouldBeNil)
+			So(docs, ShouldHaveLength, 1)
+			So(docs[0].ID, ShouldEqual, "doc_chunk_0")
+		})
+
+		Convey("When the chunk is replaced", func() {
+			_, err := storage.ExternalChunks(ctx, "doc")
+			So(err, ShouldBeNil)
+			So(storage.ReplaceChunks(ctx, []string{"doc_chunk_0"}, []*Chunk{replacement()}), ShouldBeNil)
+
+			Convey("Then every view should hold only the new version", func() {
+				doc, err := searchIndex.GetDocument(ctx, "doc_chunk_0")
+				So(err, ShouldBeNil)
+				So(doc.Content, ShouldEqual, "Paris is not the capital of France.")
+
+				vector, err := vectorStore.GetByID(ctx, "doc_chunk_0")
+				So(err, ShouldBeNil)
+				So(vector.Embedding, ShouldResemble, []float32{0, 1, 0})
+
+				_, err = graphStore.GetNode(ctx, "claim_old")
+				So(err, ShouldNotBeNil)
+				_, err = graphStore.GetNode(ctx, "claim_new")
+				So(err, ShouldBeNil)
+
+				// The old claim was gone before the new one was compared with stored claims
+				edges, err := graphStore.FindEdgesByType(ctx, Refutes, nil)
+				So(err, ShouldBeNil)
+				So(edges, ShouldBeEmpty)
+
+				docs, err := storage.ExternalChunks(ctx, "doc")
+				So(err, ShouldBeNil)
+				So(docs, ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When replacing fails part way through", func() {
+			failing := NewMultiViewStorage(vectorStore, &failingGraphStore{GraphStore: graphStore, failOn: "claim_new"}, searchIndex, nil)
+			err := failing.ReplaceChunks(ctx, []string{"doc_chunk_0"}, []*Chunk{replacement()})
+
+			Convey("Then the old version should be restored in every view", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "rolled back")
+
+				doc, err := searchIndex.GetDocument(ctx, "doc_chunk_0")
+				So(err, ShouldBeNil)
+				So(doc.Content, ShouldEqual, "Paris is the capital of France.")
+
+				vector, err := vectorStore.GetByID(ctx, "doc_chunk_0")
+				So(err, ShouldBeNil)
+				So(vector.Embedding, ShouldResemble, []float32{1, 0, 0})
+
+				_, err = graphStore.GetNode(ctx, "claim_old")
+				So(err, ShouldBeNil)
+				_, err = graphStore.GetEdge(ctx, graphEdgeID(PartOf, "claim_old", "doc_chunk_0"))
+				So(err, ShouldBeNil)
+			})
+		})
+	})
+}
+
+func TestKeyLocks(t *testing.T) {
+	Convey("Given per-key locks", t, func() {
+		var locks keyLocks
+
+		Convey("Then a key should be held by one caller at a time", func() {
+			unlock := locks.lock("doc")
+			acquired := make(chan struct{})
+			go func() {
+				defer locks.lock("doc")()
+				close(acquired)
+			}()
+
+			select {
+			case <-acquired:
+				t.Fatal("the key was locked twice")
+			case <-time.After(20 * time.Millisecond):
+			}
+			unlock()
+			<-acquired
+		})
+
+		Convey("Then other keys should not wait", func() {
+			unlock := locks.lock("doc")
+			defer unlock()
+			locks.lock("other")()
+		})
+
+		Convey("Then unlocked keys should be forgotten", func() {
+			locks.lock("doc")()
+			So(locks.locks, ShouldBeEmpty)
+		})
+	})
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Date histogram intervals for facets over time fields
+const (
+	FacetIntervalDay   = "day"
+	FacetIntervalWeek  = "week"
+	FacetIntervalMonth = "month"
+)
+
+// defaultFacetSize is how many values a value facet keeps when no size is requested
+const defaultFacetSize = 10
+
+// FacetRequest asks for counts over one metadata field. Without an interval the most
+// frequent values are counted; with one the field is read as a time and bucketed.
+type FacetRequest struct {
+	Field    string `json:"field"`
+	Interval string `json:"interval,omitempty"` // "day", "week" or "month" for a date histogram
+	Size     int    `json:"size,omitempty"`     // Values kept for a value facet; 0 means 10
+}
+
+// Key returns the name the facet's counts are reported under, e.g. "source" or "timestamp:week"
+func (r FacetRequest) Key() string {
+	if r.Interval == "" {
+		return r.Field
+	}
+	return r.Field + ":" + r.Interval
+}
+
+// ParseFacetRequest parses a facet spec of the form "field" or "field:interval"
+func ParseFacetRequest(spec string) (FacetRequest, error) {
+	field, interval, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	request := FacetRequest{
+		Field:    strings.TrimSpace(field),
+		Interval: strings.ToLower(strings.TrimSpace(interval)),
+	}
+	if err := request.Validate(); err != nil {
+		return FacetRequest{}, err
+	}
+	return request, nil
+}
+
+// Validate checks the field is set and the interval is one of day, week or month
+func (r FacetRequest) Validate() error {
+	if r.Field == "" {
+		return fmt.Errorf("facet field cannot be empty")
+	}
+	switch r.Interval {
+	case "", FacetIntervalDay, FacetIntervalWeek, FacetIntervalMonth:
+	default:
+		return fmt.Errorf("facet %q has unknown interval %q, expected day, week or month", r.Field, r.Interval)
+	}
+	if r.Size < 0 {
+		return fmt.Errorf("facet %q size cannot be negative", r.Field)
+	}
+	return nil
+}
+
+// computeFacets counts the requested facets over the metadata of every matching document.
+// Value facets are ordered by count then value; histograms are ordered by bucket.
+func computeFacets(metadata []map[string]interface{}, requests []FacetRequest) map[string][]Facet {
+	facets := make(map[string][]Facet, len(requests))
+	for _, request := range requests {
+		counts := make(map[string]int)
+		for _, fields := range metadata {
+			for _, value := range facetValues(fields[request.Field], request.Interval) {
+				counts[value]++
+			}
+		}
+
+		buckets := make([]Facet, 0, len(counts))
+		for value, count := range counts {
+			buckets = append(buckets, Facet{Value: value, Count: count})
+		}
+
+		if request.Interval != "" {
+			sort.Slice(buckets, func(i, j int) bool {
+				return buckets[i].Value < buckets[j].Value
+			})
+		} else {
+			sort.Slice(buckets, func(i, j int) bool {
+				if buckets[i].Count != buckets[j].Count {
+					return buckets[i].Count > buckets[j].Count
+				}
+				return buckets[i].Value < buckets[j].Value
+			})
+			size := request.Size
+			if size == 0 {
+				size = defaultFacetSize
+			}
+			if len(buckets) > size {
+				buckets = buckets[:size]
+			}
+		}
+
+		facets[request.Key()] = buckets
+	}
+	return facets
+}
+
+// facetValues returns the distinct facet values one document contributes for a field
+func facetValues(value interface{}, interval string) []string {
+	if value == nil {
+		return nil
+	}
+
+	items, ok := filterList(value)
+	if !ok {
+		items = []interface{}{value}
+	}
+
+	seen := make(map[string]bool, len(items))
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		var key string
+		if interval != "" {
+			t, ok := filterTime(item)
+			if !ok {
+				continue
+			}
+			key = facetBucket(t, interval)
+		} else {
+			if item == nil {
+				continue
+			}
+			key = fmt.Sprint(item)
+		}
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		values = append(values, key)
+	}
+	return values
+}
+
+// facetBucket names the histogram bucket containing t: the date for a day, the Monday
+// starting the ISO week for a week, and year-month for a month
+func facetBucket(t time.Time, interval string) string {
+	t = t.UTC()
+	switch interval {
+	case FacetIntervalWeek:
+		offset := (int(t.Weekday()) + 6) % 7
+		return t.AddDate(0, 0, -offset).Format("2006-01-02")
+	case FacetIntervalMonth:
+		return t.Format("2006-01")
+	}
+	return t.Format("2006-01-02")
+}
+
+// newFacetedSearchResponse pages through every matching result and counts the requested
+// facets over all of them, so facet counts do not depend on the page returned
+func newFacetedSearchResponse(results []SearchResult, options SearchIndexOptions) *SearchResponse {
+	response := NewSearchResponse()
+	response.TotalResults = len(results)
+	response.SearchStats.TextResults = len(results)
+
+	if len(options.Facets) > 0 {
+		metadata := make([]map[string]interface{}, len(results))
+		for i, result := range results {
+			metadata[i] = result.Metadata
+		}
+		response.Facets = computeFacets(metadata, options.Facets)
+	}
+
+	start := options.Offset
+	if start < 0 {
+		start = 0
+	}
+	if start >= len(results) {
+		return response
+	}
+
+	end := len(results)
+	if options.Limit > 0 && start+options.Limit < end {
+		end = start + options.Limit
+		response.HasMore = true
+		response.NextOffset = end
+	}
+	response.Results = append(response.Results, results[start:end]...)
+	return response
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseFacetRequest(t *testing.T) {
+	Convey("Given facet specs", t, func() {
+		Convey("When a spec names only a field", func() {
+			request, err := ParseFacetRequest("source")
+
+			Convey("Then it should be a value facet keyed by the field", func() {
+				So(err, ShouldBeNil)
+				So(request, ShouldResemble, FacetRequest{Field: "source"})
+				So(request.Key(), ShouldEqual, "source")
+			})
+		})
+
+		Convey("When a spec adds an interval", func() {
+			request, err := ParseFacetRequest(" timestamp : Week ")
+
+			Convey("Then it should be a histogram keyed by field and interval", func() {
+				So(err, ShouldBeNil)
+				So(request.Interval, ShouldEqual, FacetIntervalWeek)
+				So(request.Key(), ShouldEqual, "timestamp:week")
+			})
+		})
+
+		Convey("When a spec is empty or has an unknown interval", func() {
+			_, emptyErr := ParseFacetRequest(":day")
+			_, intervalErr := ParseFacetRequest("timestamp:year")
+
+			Convey("Then it should be rejected", func() {
+				So(emptyErr, ShouldNotBeNil)
+				So(intervalErr, ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+func TestComputeFacets(t *testing.T) {
+	Convey("Given metadata from several documents", t, func() {
+		metadata := []map[string]interface{}{
+			{"source": "slack", "tags": []string{"go", "search", "go"}, "timestamp": "2026-10-12T09:00:00Z"},
+			{"source": "slack", "tags": []interface{}{"go"}, "timestamp": "2026-10-13T09:00:00Z"},
+			{"source": "wiki", "tags": []string{"graph"}, "timestamp": time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC)},
+			{"source": "email"},
+		}
+
+		Convey("When counting value facets", func() {
+			facets := computeFacets(metadata, []FacetRequest{{Field: "source"}, {Field: "tags", Size: 2}})
+
+			Convey("Then values should be ordered by count then value and truncated to the size", func() {
+				So(facets["source"], ShouldResemble, []Facet{{"slack", 2}, {"email", 1}, {"wiki", 1}})
+				So(facets["tags"], ShouldResemble, []Facet{{"go", 2}, {"graph", 1}})
+			})
+		})
+
+		Convey("When counting date histograms", func() {
+			facets := computeFacets(metadata, []FacetRequest{
+				{Field: "timestamp", Interval: FacetIntervalDay},
+				{Field: "timestamp", Interval: FacetIntervalWeek},
+				{Field: "timestamp", Interval: FacetIntervalMonth},
+			})
+
+			Convey("Then times should be bucketed in chronological order", func() {
+				So(facets["timestamp:day"], ShouldResemble, []Facet{{"2026-09-30", 1}, {"2026-10-12", 1}, {"2026-10-13", 1}})
+				So(facets["timestamp:week"], ShouldResemble, []Facet{{"2026-09-28", 1}, {"2026-10-12", 2}})
+				So(facets["timestamp:month"], ShouldResemble, []Facet{{"2026-09", 1}, {"2026-10", 2}})
+			})
+		})
+
+		Convey("When a field is missing everywhere", func() {
+			facets := computeFacets(metadata, []FacetRequest{{Field: "entity_types"}})
+
+			Convey("Then the facet should be present but empty", func() {
+				So(facets, ShouldContainKey, "entity_types")
+				So(facets["entity_types"], ShouldBeEmpty)
+			})
+		})
+	})
+}
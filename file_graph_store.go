@@ -6,12 +6,13 @@ import (
 	"fmt"
 	"math"
 	"os"
-	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 )
 
-// FileGraphStore implements GraphStore interface with JSON file persistence
+// FileGraphStore implements GraphStore interface with a binary snapshot and an
+// append-only log of changes
 type FileGraphStore struct {
 	mu       sync.RWMutex
 	filePath string
@@ -19,10 +20,22 @@ type FileGraphStore struct {
 	edges    map[string]*Edge
 	// Adjacency list for efficient graph operations
 	adjacencyList map[string][]string // nodeID -> list of connected nodeIDs
+	nodeEdges     map[string]map[string]bool // nodeID -> IDs of the edges into and out of it
+	entityNames   map[string]map[string]bool // lowercased name -> IDs of the entities with it
+	version       uint64                     // bumped by every write and load
+	log           *storeLog
 	closed        bool
 }
 
-// GraphStoreData represents the JSON structure for persistence
+// Operations recorded in a graph store's log
+const (
+	graphOpPutNode byte = iota + 1
+	graphOpDeleteNode
+	graphOpPutEdge
+	graphOpDeleteEdge
+)
+
+// GraphStoreData represents the JSON structure earlier versions persisted, still read for migration
 type GraphStoreData struct {
 	Nodes map[string]*Node `json:"nodes"`
 	Edges map[string]*Edge `json:"edges"`
@@ -35,6 +48,9 @@ func NewFileGraphStore(filePath string) *FileGraphStore {
 		nodes:         make(map[string]*Node),
 		edges:         make(map[string]*Edge),
 		adjacencyList: make(map[string][]string),
+		nodeEdges:     make(map[string]map[string]bool),
+		entityNames:   make(map[string]map[string]bool),
+		log:           newStoreLog(filePath),
 	}
 }
 
@@ -51,14 +67,23 @@ func (f *FileGraphStore) CreateNode(ctx context.Context, node *Node) error {
 		return fmt.Errorf("invalid node: %w", err)
 	}
 	
+	record, err := encodeNodeRecord(node)
+	if err != nil {
+		return err
+	}
+	
+	if existing, exists := f.nodes[node.ID]; exists {
+		f.unindexNode(existing)
+	}
 	f.nodes[node.ID] = node
+	f.indexNode(node)
 	
 	// Initialize adjacency list entry if not exists
 	if _, exists := f.adjacencyList[node.ID]; !exists {
 		f.adjacencyList[node.ID] = []string{}
 	}
 	
-	return f.save()
+	return f.persist(record)
 }
 
 // CreateEdge creates a new edge in the graph
@@ -82,12 +107,21 @@ func (f *FileGraphStore) CreateEdge(ctx context.Context, edge *Edge) error {
 		return fmt.Errorf("target node %s does not exist", edge.To)
 	}
 	
+	record, err := encodeEdgeRecord(edge)
+	if err != nil {
+		return err
+	}
+	
+	if oldEdge, exists := f.edges[edge.ID]; exists {
+		f.unindexEdge(oldEdge)
+	}
 	f.edges[edge.ID] = edge
 	
 	// Update adjacency list
 	f.addToAdjacencyList(edge.From, edge.To)
+	f.indexEdge(edge)
 	
-	return f.save()
+	return f.persist(record)
 }
 
 // GetNode retrieves a node by ID
@@ -137,12 +171,20 @@ func (f *FileGraphStore) UpdateNode(ctx context.Context, node *Node) error {
 		return fmt.Errorf("invalid node: %w", err)
 	}
 	
-	if _, exists := f.nodes[node.ID]; !exists {
+	existing, exists := f.nodes[node.ID]
+	if !exists {
 		return fmt.Errorf("node with ID %s not found", node.ID)
 	}
 	
+	record, err := encodeNodeRecord(node)
+	if err != nil {
+		return err
+	}
+	
+	f.unindexNode(existing)
 	f.nodes[node.ID] = node
-	return f.save()
+	f.indexNode(node)
+	return f.persist(record)
 }
 
 // UpdateEdge updates an existing edge
@@ -163,14 +205,21 @@ func (f *FileGraphStore) UpdateEdge(ctx context.Context, edge *Edge) error {
 		return fmt.Errorf("edge with ID %s not found", edge.ID)
 	}
 	
+	record, err := encodeEdgeRecord(edge)
+	if err != nil {
+		return err
+	}
+	
 	// Update adjacency list if endpoints changed
 	if oldEdge.From != edge.From || oldEdge.To != edge.To {
 		f.removeFromAdjacencyList(oldEdge.From, oldEdge.To)
 		f.addToAdjacencyList(edge.From, edge.To)
+		f.unindexEdge(oldEdge)
+		f.indexEdge(edge)
 	}
 	
 	f.edges[edge.ID] = edge
-	return f.save()
+	return f.persist(record)
 }
 
 // DeleteNode deletes a node and all its edges
@@ -182,29 +231,28 @@ func (f *FileGraphStore) DeleteNode(ctx context.Context, id string) error {
 		return fmt.Errorf("graph store is closed")
 	}
 	
-	if _, exists := f.nodes[id]; !exists {
+	node, exists := f.nodes[id]
+	if !exists {
 		return fmt.Errorf("node with ID %s not found", id)
 	}
 	
 	// Remove all edges connected to this node
-	var edgesToDelete []string
-	for edgeID, edge := range f.edges {
-		if edge.From == id || edge.To == id {
-			edgesToDelete = append(edgesToDelete, edgeID)
-		}
-	}
-	
-	for _, edgeID := range edgesToDelete {
+	records := make([]storeRecord, 0, len(f.nodeEdges[id])+1)
+	for edgeID := range f.nodeEdges[id] {
 		edge := f.edges[edgeID]
 		f.removeFromAdjacencyList(edge.From, edge.To)
+		f.unindexEdge(edge)
 		delete(f.edges, edgeID)
+		records = append(records, storeRecord{op: graphOpDeleteEdge, key: edgeID})
 	}
 	
 	// Remove node
+	f.unindexNode(node)
 	delete(f.nodes, id)
 	delete(f.adjacencyList, id)
+	records = append(records, storeRecord{op: graphOpDeleteNode, key: id})
 	
-	return f.save()
+	return f.persist(records...)
 }
 
 // DeleteEdge deletes an edge by ID
@@ -222,9 +270,10 @@ func (f *FileGraphStore) DeleteEdge(ctx context.Context, id string) error {
 	}
 	
 	f.removeFromAdjacencyList(edge.From, edge.To)
+	f.unindexEdge(edge)
 	delete(f.edges, id)
 	
-	return f.save()
+	return f.persist(storeRecord{op: graphOpDeleteEdge, key: id})
 }
 
 // FindPaths finds paths between two nodes with traversal options
@@ -277,15 +326,24 @@ func (f *FileGraphStore) PageRank(ctx context.Context, options PageRankOptions)
 	scores := make(map[string]float64)
 	newScores := make(map[string]float64)
 	
+	// Seeds personalize the walk: all teleport and dangling mass returns to them
+	teleport := pageRankTeleport(f.nodes, options.Seeds, 1.0)
+	if teleport == nil {
+		teleport = make(map[string]float64, nodeCount)
+		for nodeID := range f.nodes {
+			teleport[nodeID] = 1.0 / float64(nodeCount)
+		}
+	}
+	
 	for nodeID := range f.nodes {
-		scores[nodeID] = 1.0 / float64(nodeCount)
+		scores[nodeID] = teleport[nodeID]
 	}
 	
 	// Iterative PageRank computation
 	for iter := 0; iter < options.MaxIter; iter++ {
 		// Reset new scores
 		for nodeID := range f.nodes {
-			newScores[nodeID] = (1.0 - options.Alpha) / float64(nodeCount)
+			newScores[nodeID] = (1.0 - options.Alpha) * teleport[nodeID]
 		}
 		
 		// Calculate contributions from each node
@@ -297,10 +355,9 @@ func (f *FileGraphStore) PageRank(ctx context.Context, options PageRankOptions)
 					newScores[neighbor] += contribution
 				}
 			} else {
-				// Handle dangling nodes - distribute their score evenly
-				contribution := options.Alpha * scores[nodeID] / float64(nodeCount)
-				for neighborID := range f.nodes {
-					newScores[neighborID] += contribution
+				// Handle dangling nodes - redistribute their score by the teleport vector
+				for targetID, share := range teleport {
+					newScores[targetID] += options.Alpha * scores[nodeID] * share
 				}
 			}
 		}
@@ -329,6 +386,11 @@ func (f *FileGraphStore) PageRank(ctx context.Context, options PageRankOptions)
 
 // CommunityDetection performs community detection using Louvain algorithm
 func (f *FileGraphStore) CommunityDetection(ctx context.Context) ([]Community, error) {
+	return f.CommunityDetectionWithOptions(ctx, DefaultCommunityDetectionOptions())
+}
+
+// CommunityDetectionWithOptions performs Louvain community detection over weighted edges
+func (f *FileGraphStore) CommunityDetectionWithOptions(ctx context.Context, options CommunityDetectionOptions) ([]Community, error) {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
 	
@@ -336,28 +398,60 @@ func (f *FileGraphStore) CommunityDetection(ctx context.Context) ([]Community, e
 		return nil, fmt.Errorf("graph store is closed")
 	}
 	
-	// Simple community detection - group nodes by type
-	communities := make(map[NodeType][]string)
+	nodeIDs := make([]string, 0, len(f.nodes))
+	for nodeID := range f.nodes {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
 	
-	for nodeID, node := range f.nodes {
-		communities[node.Type] = append(communities[node.Type], nodeID)
+	edges := make([]*Edge, 0, len(f.edges))
+	for _, edge := range f.edges {
+		edges = append(edges, edge)
 	}
 	
-	var result []Community
-	communityID := 0
-	for nodeType, nodeIDs := range communities {
-		if len(nodeIDs) > 0 {
-			result = append(result, Community{
-				ID:      fmt.Sprintf("community_%d", communityID),
-				Nodes:   nodeIDs,
-				Score:   1.0,
-				Summary: fmt.Sprintf("Community of %s nodes", nodeType),
-			})
-			communityID++
-		}
+	return DetectCommunitiesLouvain(nodeIDs, edges, options), nil
+}
+
+// Version returns a number that changes whenever a node or edge is written or the graph is loaded
+func (f *FileGraphStore) Version() uint64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	
+	return f.version
+}
+
+// GetNodeEdges returns the edges into and out of a node, ordered by ID
+func (f *FileGraphStore) GetNodeEdges(ctx context.Context, nodeID string) ([]*Edge, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	
+	if f.closed {
+		return nil, fmt.Errorf("graph store is closed")
 	}
 	
-	return result, nil
+	edges := make([]*Edge, 0, len(f.nodeEdges[nodeID]))
+	for edgeID := range f.nodeEdges[nodeID] {
+		edges = append(edges, f.edges[edgeID])
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].ID < edges[j].ID })
+	return edges, nil
+}
+
+// FindEntitiesByName returns the entity nodes named name, ignoring case, ordered by ID
+func (f *FileGraphStore) FindEntitiesByName(ctx context.Context, name string) ([]*Node, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	
+	if f.closed {
+		return nil, fmt.Errorf("graph store is closed")
+	}
+	
+	ids := f.entityNames[entityNameKey(name)]
+	nodes := make([]*Node, 0, len(ids))
+	for id := range ids {
+		nodes = append(nodes, f.nodes[id])
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes, nil
 }
 
 // GetNeighbors returns neighboring nodes of a given node
@@ -413,12 +507,17 @@ func (f *FileGraphStore) FindEdgesByType(ctx context.Context, edgeType EdgeType,
 		return nil, fmt.Errorf("graph store is closed")
 	}
 	
+	filter, err := ParseMetadataFilter(filters)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filters: %w", err)
+	}
+	
 	var result []*Edge
 	
 	for _, edge := range f.edges {
 		if edge.Type == edgeType {
 			// Apply filters
-			if f.matchesFilters(edge.Properties, filters) {
+			if filter.Matches(edge.Properties) {
 				result = append(result, edge)
 			}
 		}
@@ -436,12 +535,17 @@ func (f *FileGraphStore) FindNodesByType(ctx context.Context, nodeType NodeType,
 		return nil, fmt.Errorf("graph store is closed")
 	}
 	
+	filter, err := ParseMetadataFilter(filters)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filters: %w", err)
+	}
+	
 	var result []*Node
 	
 	for _, node := range f.nodes {
 		if node.Type == nodeType {
 			// Apply filters
-			if f.matchesFilters(node.Properties, filters) {
+			if filter.Matches(node.Properties) {
 				result = append(result, node)
 			}
 		}
@@ -474,12 +578,23 @@ func (f *FileGraphStore) EdgeCount(ctx context.Context) (int64, error) {
 	return int64(len(f.edges)), nil
 }
 
-// Close closes the graph store connection
+// FilePath returns the path the store is persisted to
+func (f *FileGraphStore) FilePath() string {
+	return f.filePath
+}
+
+// Close closes the graph store, first compacting any logged changes into a snapshot
 func (f *FileGraphStore) Close() error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 	
+	if f.closed {
+		return nil
+	}
 	f.closed = true
+	if f.log.pending > 0 {
+		return f.save()
+	}
 	return nil
 }
 
@@ -500,83 +615,138 @@ func (f *FileGraphStore) Health(ctx context.Context) error {
 	return nil
 }
 
-// Load loads the graph from the JSON file
+// Load loads the graph from the snapshot and replays the changes logged since. A JSON file
+// written by earlier versions is loaded as the snapshot and rewritten on the next change.
 func (f *FileGraphStore) Load() error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 	
-	if _, err := os.Stat(f.filePath); os.IsNotExist(err) {
-		// File doesn't exist, start with empty graph
-		f.nodes = make(map[string]*Node)
-		f.edges = make(map[string]*Edge)
-		f.adjacencyList = make(map[string][]string)
+	f.nodes = make(map[string]*Node)
+	f.edges = make(map[string]*Edge)
+	legacy := func(data []byte) error {
+		var graphData GraphStoreData
+		if err := json.Unmarshal(data, &graphData); err != nil {
+			return fmt.Errorf("failed to unmarshal graph data: %w", err)
+		}
+		if graphData.Nodes != nil {
+			f.nodes = graphData.Nodes
+		}
+		if graphData.Edges != nil {
+			f.edges = graphData.Edges
+		}
 		return nil
 	}
 	
-	data, err := os.ReadFile(f.filePath)
-	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
-	}
-	
-	if len(data) == 0 {
-		f.nodes = make(map[string]*Node)
-		f.edges = make(map[string]*Edge)
-		f.adjacencyList = make(map[string][]string)
-		return nil
+	if err := f.log.loadSnapshot(legacy, f.applyRecord); err != nil {
+		return err
 	}
-	
-	var graphData GraphStoreData
-	if err := json.Unmarshal(data, &graphData); err != nil {
-		return fmt.Errorf("failed to unmarshal graph data: %w", err)
+	if err := f.log.replay(f.applyRecord); err != nil {
+		return err
 	}
 	
-	f.nodes = graphData.Nodes
-	f.edges = graphData.Edges
-	
 	// Rebuild adjacency list
 	f.adjacencyList = make(map[string][]string)
+	f.nodeEdges = make(map[string]map[string]bool)
+	f.entityNames = make(map[string]map[string]bool)
 	for _, node := range f.nodes {
 		f.adjacencyList[node.ID] = []string{}
+		f.indexNode(node)
 	}
 	
 	for _, edge := range f.edges {
 		f.addToAdjacencyList(edge.From, edge.To)
+		f.indexEdge(edge)
 	}
+	f.version++
 	
 	return nil
 }
 
-// Save saves the graph to the JSON file
+// Save compacts the graph into a new snapshot, leaving the log empty
 func (f *FileGraphStore) Save() error {
-	f.mu.RLock()
-	defer f.mu.RUnlock()
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	
 	return f.save()
 }
 
-// save is the internal save method (assumes lock is held)
+// persist records a change, appending it to the log or, when the log is due for compaction,
+// writing a snapshot that already includes it (assumes lock is held)
+func (f *FileGraphStore) persist(records ...storeRecord) error {
+	f.version++
+	if f.log.needsSnapshot(len(f.nodes) + len(f.edges)) {
+		return f.save()
+	}
+	return f.log.append(records...)
+}
+
+// save writes every node and edge as a new snapshot (assumes lock is held)
 func (f *FileGraphStore) save() error {
-	// Create directory if it doesn't exist
-	dir := filepath.Dir(f.filePath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
-	}
-	
-	graphData := GraphStoreData{
-		Nodes: f.nodes,
-		Edges: f.edges,
+	return f.log.snapshot(func(emit func(storeRecord)) error {
+		for _, node := range f.nodes {
+			record, err := encodeNodeRecord(node)
+			if err != nil {
+				return err
+			}
+			emit(record)
+		}
+		for _, edge := range f.edges {
+			record, err := encodeEdgeRecord(edge)
+			if err != nil {
+				return err
+			}
+			emit(record)
+		}
+		return nil
+	})
+}
+
+// applyRecord replays one persisted change to the nodes and edges; Load rebuilds the
+// adjacency list and edge index afterwards (assumes lock is held)
+func (f *FileGraphStore) applyRecord(record storeRecord) error {
+	switch record.op {
+	case graphOpPutNode:
+		node := &Node{}
+		embedding, err := decodeStoreValue(record.value, node)
+		if err != nil {
+			return fmt.Errorf("failed to decode node %s: %w", record.key, err)
+		}
+		node.Embedding = embedding
+		f.nodes[record.key] = node
+	case graphOpDeleteNode:
+		delete(f.nodes, record.key)
+	case graphOpPutEdge:
+		edge := &Edge{}
+		if _, err := decodeStoreValue(record.value, edge); err != nil {
+			return fmt.Errorf("failed to decode edge %s: %w", record.key, err)
+		}
+		f.edges[record.key] = edge
+	case graphOpDeleteEdge:
+		delete(f.edges, record.key)
+	default:
+		return fmt.Errorf("unknown graph store operation %d", record.op)
 	}
-	
-	data, err := json.MarshalIndent(graphData, "", "  ")
+	return nil
+}
+
+// encodeNodeRecord encodes a node as a put record, its embedding stored as raw float32s
+func encodeNodeRecord(node *Node) (storeRecord, error) {
+	rest := *node
+	rest.Embedding = nil
+	value, err := encodeStoreValue(node.Embedding, &rest)
 	if err != nil {
-		return fmt.Errorf("failed to marshal graph data: %w", err)
+		return storeRecord{}, fmt.Errorf("failed to marshal node %s: %w", node.ID, err)
 	}
-	
-	if err := os.WriteFile(f.filePath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+	return storeRecord{op: graphOpPutNode, key: node.ID, value: value}, nil
+}
+
+// encodeEdgeRecord encodes an edge as a put record
+func encodeEdgeRecord(edge *Edge) (storeRecord, error) {
+	value, err := encodeStoreValue(nil, edge)
+	if err != nil {
+		return storeRecord{}, fmt.Errorf("failed to marshal edge %s: %w", edge.ID, err)
 	}
-	
-	return nil
+	return storeRecord{op: graphOpPutEdge, key: edge.ID, value: value}, nil
 }
 
 // Helper methods
@@ -597,6 +767,58 @@ func (f *FileGraphStore) addToAdjacencyList(from, to string) {
 	f.adjacencyList[from] = append(f.adjacencyList[from], to)
 }
 
+// indexEdge records an edge against both of its nodes
+func (f *FileGraphStore) indexEdge(edge *Edge) {
+	for _, nodeID := range []string{edge.From, edge.To} {
+		if f.nodeEdges[nodeID] == nil {
+			f.nodeEdges[nodeID] = make(map[string]bool)
+		}
+		f.nodeEdges[nodeID][edge.ID] = true
+	}
+}
+
+// unindexEdge forgets an edge on both of its nodes
+func (f *FileGraphStore) unindexEdge(edge *Edge) {
+	for _, nodeID := range []string{edge.From, edge.To} {
+		delete(f.nodeEdges[nodeID], edge.ID)
+		if len(f.nodeEdges[nodeID]) == 0 {
+			delete(f.nodeEdges, nodeID)
+		}
+	}
+}
+
+// indexNode records an entity node under its name
+func (f *FileGraphStore) indexNode(node *Node) {
+	if node.Type != EntityNode {
+		return
+	}
+	key := entityNameKey(nodeName(node))
+	if key == "" {
+		return
+	}
+	if f.entityNames[key] == nil {
+		f.entityNames[key] = make(map[string]bool)
+	}
+	f.entityNames[key][node.ID] = true
+}
+
+// unindexNode forgets an entity node under its name
+func (f *FileGraphStore) unindexNode(node *Node) {
+	if node.Type != EntityNode {
+		return
+	}
+	key := entityNameKey(nodeName(node))
+	delete(f.entityNames[key], node.ID)
+	if len(f.entityNames[key]) == 0 {
+		delete(f.entityNames, key)
+	}
+}
+
+// entityNameKey normalizes an entity name for lookups by name
+func entityNameKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
 // removeFromAdjacencyList removes an edge from the adjacency list
 func (f *FileGraphStore) removeFromAdjacencyList(from, to string) {
 	if neighbors, exists := f.adjacencyList[from]; exists {
@@ -698,18 +920,3 @@ func (f *FileGraphStore) findPathsBFS(from, to string, options GraphTraversalOpt
 	
 	return paths
 }
-
-// matchesFilters checks if properties match the given filters
-func (f *FileGraphStore) matchesFilters(properties map[string]interface{}, filters map[string]interface{}) bool {
-	if filters == nil {
-		return true
-	}
-	
-	for key, expectedValue := range filters {
-		if actualValue, exists := properties[key]; !exists || actualValue != expectedValue {
-			return false
-		}
-	}
-	
-	return true
-}
\ No newline at end of file
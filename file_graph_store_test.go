@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -209,16 +210,26 @@ func TestFileGraphStore(t *testing.T) {
 				So(totalScore, ShouldBeGreaterThan, 0.5)
 				So(totalScore, ShouldBeLessThan, 10.0)
 			})
+			
+			Convey("Then seeding it should personalize the scores", func() {
+				options.Seeds = []string{"page2"}
+				personalized, err := store.PageRank(ctx, options)
+				So(err, ShouldBeNil)
+				So(personalized["page1"], ShouldEqual, 0)
+				So(personalized["page2"], ShouldBeGreaterThan, 0)
+				So(personalized["page3"], ShouldBeGreaterThan, 0)
+			})
 		})
 		
 		Convey("When performing community detection", func() {
-			// Create nodes of different types
+			// Two tightly linked groups joined by a single weak edge
 			nodes := []*Node{
 				NewNode("entity1", EntityNode),
 				NewNode("entity2", EntityNode),
 				NewNode("claim1", ClaimNode),
 				NewNode("claim2", ClaimNode),
 				NewNode("event1", EventNode),
+				NewNode("event2", EventNode),
 			}
 			
 			for _, node := range nodes {
@@ -226,18 +237,34 @@ func TestFileGraphStore(t *testing.T) {
 				So(err, ShouldBeNil)
 			}
 			
+			edges := []*Edge{
+				NewEdge("e1", "entity1", "entity2", RelatedTo, 1.0),
+				NewEdge("e2", "entity2", "claim1", RelatedTo, 1.0),
+				NewEdge("e3", "claim1", "entity1", RelatedTo, 1.0),
+				NewEdge("e4", "claim2", "event1", RelatedTo, 1.0),
+				NewEdge("e5", "event1", "event2", RelatedTo, 1.0),
+				NewEdge("e6", "event2", "claim2", RelatedTo, 1.0),
+				NewEdge("bridge", "claim1", "claim2", RelatedTo, 0.1),
+			}
+			
+			for _, edge := range edges {
+				err := store.CreateEdge(ctx, edge)
+				So(err, ShouldBeNil)
+			}
+			
 			communities, err := store.CommunityDetection(ctx)
 			
-			Convey("Then it should detect communities", func() {
+			Convey("Then it should detect the two groups with positive modularity", func() {
 				So(err, ShouldBeNil)
-				So(len(communities), ShouldEqual, 3) // 3 different node types
+				So(len(communities), ShouldEqual, 2)
+				So(communities[0].Nodes, ShouldResemble, []string{"claim1", "entity1", "entity2"})
+				So(communities[1].Nodes, ShouldResemble, []string{"claim2", "event1", "event2"})
 				
-				// Check that communities are grouped by type
 				for _, community := range communities {
-					So(len(community.Nodes), ShouldBeGreaterThan, 0)
 					So(community.ID, ShouldStartWith, "community_")
-					So(community.Score, ShouldEqual, 1.0)
+					So(community.Score, ShouldBeGreaterThan, 0)
 				}
+				So(Modularity(communities), ShouldBeGreaterThan, 0.4)
 			})
 		})
 		
@@ -374,6 +401,41 @@ func TestFileGraphStore(t *testing.T) {
 				_, err = store.GetEdge(ctx, "delete_edge")
 				So(err, ShouldNotBeNil)
 			})
+			
+			Convey("Then the other end should no longer list the edge", func() {
+				edges, err := store.GetNodeEdges(ctx, "delete2")
+				So(err, ShouldBeNil)
+				So(edges, ShouldBeEmpty)
+			})
+		})
+		
+		Convey("When entities are looked up by name", func() {
+			for _, node := range []*Node{NewNode("name_b", EntityNode), NewNode("name_a", EntityNode), NewNode("name_claim", ClaimNode)} {
+				node.Properties["name"] = "Paris"
+				So(store.CreateNode(ctx, node), ShouldBeNil)
+			}
+			
+			Convey("Then entities with that name should be found ignoring case", func() {
+				nodes, err := store.FindEntitiesByName(ctx, " paris ")
+				So(err, ShouldBeNil)
+				So(nodes, ShouldHaveLength, 2)
+				So(nodes[0].ID, ShouldEqual, "name_a")
+				So(nodes[1].ID, ShouldEqual, "name_b")
+			})
+			
+			Convey("Then renamed and deleted entities should no longer be found", func() {
+				renamed := NewNode("name_a", EntityNode)
+				renamed.Properties["name"] = "Lyon"
+				So(store.UpdateNode(ctx, renamed), ShouldBeNil)
+				So(store.DeleteNode(ctx, "name_b"), ShouldBeNil)
+				
+				nodes, err := store.FindEntitiesByName(ctx, "Paris")
+				So(err, ShouldBeNil)
+				So(nodes, ShouldBeEmpty)
+				nodes, err = store.FindEntitiesByName(ctx, "lyon")
+				So(err, ShouldBeNil)
+				So(nodes, ShouldHaveLength, 1)
+			})
 		})
 		
 		Convey("When getting counts", func() {
@@ -553,4 +615,92 @@ func BenchmarkFileGraphStore(b *testing.B) {
 			store.GetNode(ctx, nodeID)
 		}
 	})
-}
\ No newline at end of file
+}
+func TestFileGraphStorePersistence(t *testing.T) {
+	Convey("Given a loaded FileGraphStore with logged changes", t, func() {
+		ctx := context.Background()
+		filePath := filepath.Join(t.TempDir(), "graph.json")
+		store := NewFileGraphStore(filePath)
+		So(store.Load(), ShouldBeNil)
+		
+		for _, id := range []string{"a", "b", "c"} {
+			node := NewNode(id, EntityNode)
+			node.Embedding = []float32{0.5, 1.5}
+			node.Properties["name"] = "Node " + id
+			So(store.CreateNode(ctx, node), ShouldBeNil)
+		}
+		So(store.CreateEdge(ctx, NewEdge("ab", "a", "b", RelatedTo, 0.5)), ShouldBeNil)
+		So(store.CreateEdge(ctx, NewEdge("bc", "b", "c", RelatedTo, 0.7)), ShouldBeNil)
+		So(store.DeleteNode(ctx, "c"), ShouldBeNil)
+		
+		Convey("When the store is reloaded without saving", func() {
+			_, err := os.Stat(filePath + ".log")
+			So(err, ShouldBeNil)
+			
+			reloaded := NewFileGraphStore(filePath)
+			So(reloaded.Load(), ShouldBeNil)
+			
+			Convey("Then the log should replay nodes, embeddings and cascaded deletes", func() {
+				nodeCount, err := reloaded.NodeCount(ctx)
+				So(err, ShouldBeNil)
+				So(nodeCount, ShouldEqual, 2)
+				edgeCount, err := reloaded.EdgeCount(ctx)
+				So(err, ShouldBeNil)
+				So(edgeCount, ShouldEqual, 1)
+				
+				node, err := reloaded.GetNode(ctx, "a")
+				So(err, ShouldBeNil)
+				So(node.Embedding, ShouldResemble, []float32{0.5, 1.5})
+				
+				neighbors, err := reloaded.GetNeighbors(ctx, "a", GraphTraversalOptions{MaxDepth: 1})
+				So(err, ShouldBeNil)
+				So(len(neighbors), ShouldEqual, 1)
+			})
+			
+			Convey("Then entities should be indexed by name", func() {
+				nodes, err := reloaded.FindEntitiesByName(ctx, "node a")
+				So(err, ShouldBeNil)
+				So(nodes, ShouldHaveLength, 1)
+				So(nodes[0].ID, ShouldEqual, "a")
+			})
+			
+			Convey("Then each node's edges should be indexed in both directions", func() {
+				edges, err := reloaded.GetNodeEdges(ctx, "b")
+				So(err, ShouldBeNil)
+				So(edges, ShouldHaveLength, 1)
+				So(edges[0].ID, ShouldEqual, "ab")
+				
+				edges, err = reloaded.GetNodeEdges(ctx, "c")
+				So(err, ShouldBeNil)
+				So(edges, ShouldBeEmpty)
+			})
+		})
+	})
+	
+	Convey("Given a graph file in the JSON format", t, func() {
+		ctx := context.Background()
+		filePath := filepath.Join(t.TempDir(), "graph.json")
+		legacy := `{"nodes": {"a": {"id": "a", "type": "Entity", "properties": {}}, "b": {"id": "b", "type": "Entity", "properties": {}}},
+			"edges": {"ab": {"id": "ab", "from": "a", "to": "b", "type": "RELATED_TO", "weight": 1}}}`
+		So(os.WriteFile(filePath, []byte(legacy), 0644), ShouldBeNil)
+		
+		store := NewFileGraphStore(filePath)
+		So(store.Load(), ShouldBeNil)
+		So(store.CreateNode(ctx, NewNode("c", EntityNode)), ShouldBeNil)
+		
+		Convey("Then it should load and be rewritten as a binary snapshot", func() {
+			data, err := os.ReadFile(filePath)
+			So(err, ShouldBeNil)
+			So(string(data[:len(storeLogMagic)]), ShouldEqual, string(storeLogMagic))
+			
+			reloaded := NewFileGraphStore(filePath)
+			So(reloaded.Load(), ShouldBeNil)
+			nodeCount, err := reloaded.NodeCount(ctx)
+			So(err, ShouldBeNil)
+			So(nodeCount, ShouldEqual, 3)
+			edge, err := reloaded.GetEdge(ctx, "ab")
+			So(err, ShouldBeNil)
+			So(edge.To, ShouldEqual, "b")
+		})
+	})
+}
@@ -4,38 +4,109 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
-	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
+	"unicode"
+	"unicode/utf8"
 )
 
-// FileSearchIndex implements SearchIndex interface with JSON file persistence
+// FileSearchIndex implements SearchIndex interface with a binary snapshot of its documents
+// and an append-only log of changes; postings are rebuilt from the documents when loading
 type FileSearchIndex struct {
 	mu        sync.RWMutex
 	filePath  string
+	config    *FileSearchIndexConfig
+	analyzers *AnalyzerRegistry
 	documents map[string]IndexDocument
-	// Inverted index: term -> document IDs
-	invertedIndex map[string][]string
-	closed        bool
+	// Positional postings: term -> document ID -> token positions; the term frequency
+	// is the number of positions and the document frequency the number of documents
+	postings    map[string]map[string][]int
+	docLengths  map[string]int
+	totalLength int
+	log         *storeLog
+	closed      bool
 }
 
-// SearchIndexData represents the JSON structure for persistence
+// Operations recorded in a search index's log
+const (
+	searchOpPut byte = iota + 1
+	searchOpDelete
+)
+
+// FileSearchIndexConfig holds the BM25 parameters used to score documents
+type FileSearchIndexConfig struct {
+	BM25K1 float64 `json:"bm25_k1"` // Term frequency saturation parameter
+	BM25B  float64 `json:"bm25_b"`  // Length normalization parameter
+}
+
+// SearchIndexData represents the JSON structure earlier versions persisted, still read for migration
 type SearchIndexData struct {
-	Documents     map[string]IndexDocument `json:"documents"`
-	InvertedIndex map[string][]string      `json:"inverted_index"`
+	Documents  map[string]IndexDocument    `json:"documents"`
+	Postings   map[string]map[string][]int `json:"postings"`
+	DocLengths map[string]int              `json:"doc_lengths"`
+}
+
+// indexPhrase is a quoted query phrase; a positive slop turns it into a proximity match
+// allowing that many extra positions between its terms in any order
+type indexPhrase struct {
+	terms []string
+	slop  int
+}
+
+// DefaultFileSearchIndexConfig returns the standard BM25 parameters
+func DefaultFileSearchIndexConfig() *FileSearchIndexConfig {
+	return &FileSearchIndexConfig{
+		BM25K1: 1.2,
+		BM25B:  0.75,
+	}
 }
 
 // NewFileSearchIndex creates a new file-based search index
 func NewFileSearchIndex(filePath string) *FileSearchIndex {
+	return NewFileSearchIndexWithConfig(filePath, nil)
+}
+
+// NewFileSearchIndexWithConfig creates a file-based search index with custom BM25 parameters
+func NewFileSearchIndexWithConfig(filePath string, config *FileSearchIndexConfig) *FileSearchIndex {
+	if config == nil {
+		config = DefaultFileSearchIndexConfig()
+	}
+
 	return &FileSearchIndex{
-		filePath:      filePath,
-		documents:     make(map[string]IndexDocument),
-		invertedIndex: make(map[string][]string),
+		filePath:   filePath,
+		config:     config,
+		analyzers:  defaultAnalyzers,
+		documents:  make(map[string]IndexDocument),
+		postings:   make(map[string]map[string][]int),
+		docLengths: make(map[string]int),
+		log:        newStoreLog(filePath),
 	}
 }
 
+// SetAnalyzers replaces the registry that picks each document's and query's analyzer. Documents
+// already indexed keep the terms they were analyzed into until the index is next loaded, so set
+// it before loading or indexing.
+func (f *FileSearchIndex) SetAnalyzers(analyzers *AnalyzerRegistry) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.analyzers = analyzers
+}
+
+// Analyzers returns the registry that picks each document's and query's analyzer
+func (f *FileSearchIndex) Analyzers() *AnalyzerRegistry {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.analyzers
+}
+
+// BM25Parameters returns the k1 and b parameters the index scores results with
+func (f *FileSearchIndex) BM25Parameters() (float64, float64) {
+	return f.config.BM25K1, f.config.BM25B
+}
+
 // Index indexes a document
 func (f *FileSearchIndex) Index(ctx context.Context, doc IndexDocument) error {
 	f.mu.Lock()
@@ -45,21 +116,29 @@ func (f *FileSearchIndex) Index(ctx context.Context, doc IndexDocument) error {
 		return fmt.Errorf("search index is closed")
 	}
 	
+	record, err := encodeDocumentRecord(doc)
+	if err != nil {
+		return err
+	}
+	
 	// Remove old document from index if it exists
 	if oldDoc, exists := f.documents[doc.ID]; exists {
-		f.removeFromInvertedIndex(doc.ID, oldDoc.Content)
+		f.removePostings(doc.ID, oldDoc)
 	}
 	
 	// Store document
 	f.documents[doc.ID] = doc
 	
 	// Add to inverted index
-	f.addToInvertedIndex(doc.ID, doc.Content)
+	f.addPostings(doc.ID, doc)
 	
-	return f.save()
+	return f.persist(record)
 }
 
-// Search performs text search with options
+// Search scores documents against the query with BM25, analyzing it in options.Language.
+// Quoted phrases such as "vector store" must appear verbatim, and "vector store"~3 requires
+// the terms within three extra positions of each other in any order; unquoted terms only add
+// to the score.
 func (f *FileSearchIndex) Search(ctx context.Context, query string, options SearchIndexOptions) ([]SearchResult, error) {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
@@ -68,30 +147,80 @@ func (f *FileSearchIndex) Search(ctx context.Context, query string, options Sear
 		return nil, fmt.Errorf("search index is closed")
 	}
 	
-	// Tokenize query
-	queryTerms := f.tokenize(strings.ToLower(query))
-	if len(queryTerms) == 0 {
+	results, err := f.matchDocuments(query, options)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Apply pagination
+	start := options.Offset
+	end := start + options.Limit
+	
+	if start >= len(results) {
 		return []SearchResult{}, nil
 	}
 	
-	// Find matching documents
-	docScores := make(map[string]float64)
+	if end > len(results) {
+		end = len(results)
+	}
 	
-	for _, term := range queryTerms {
-		if docIDs, exists := f.invertedIndex[term]; exists {
-			for _, docID := range docIDs {
-				docScores[docID] += 1.0 // Simple TF scoring
-			}
+	if options.Limit > 0 {
+		results = results[start:end]
+	}
+	
+	return results, nil
+}
+
+// SearchWithFacets performs text search and counts the requested facets over every match
+func (f *FileSearchIndex) SearchWithFacets(ctx context.Context, query string, options SearchIndexOptions) (*SearchResponse, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	
+	if f.closed {
+		return nil, fmt.Errorf("search index is closed")
+	}
+	
+	for _, request := range options.Facets {
+		if err := request.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid facets: %w", err)
 		}
 	}
 	
-	// Convert to results and apply filters
+	results, err := f.matchDocuments(query, options)
+	if err != nil {
+		return nil, err
+	}
+	
+	return newFacetedSearchResponse(results, options), nil
+}
+
+// matchDocuments scores, filters and sorts every document matching the query; callers hold the lock
+func (f *FileSearchIndex) matchDocuments(query string, options SearchIndexOptions) ([]SearchResult, error) {
+	filter, err := ParseMetadataFilter(options.Filters)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filters: %w", err)
+	}
+	
+	// Parse the query into scoring terms and required phrases
+	queryTerms, phrases := f.parseQuery(query, options.Language)
+	if len(queryTerms) == 0 {
+		return []SearchResult{}, nil
+	}
+	
+	// Find matching documents
+	docScores := f.scoreBM25(queryTerms)
+	
+	// Convert to results and apply phrase constraints and filters
 	var results []SearchResult
 	for docID, score := range docScores {
 		doc := f.documents[docID]
 		
+		if !f.matchesPhrases(docID, phrases) {
+			continue
+		}
+		
 		// Apply filters
-		if !f.matchesFilters(doc.Metadata, options.Filters) {
+		if !filter.Matches(doc.Metadata) {
 			continue
 		}
 		
@@ -113,22 +242,6 @@ func (f *FileSearchIndex) Search(ctx context.Context, query string, options Sear
 	// Sort results
 	f.sortResults(results, options.SortBy, options.SortOrder)
 	
-	// Apply pagination
-	start := options.Offset
-	end := start + options.Limit
-	
-	if start >= len(results) {
-		return []SearchResult{}, nil
-	}
-	
-	if end > len(results) {
-		end = len(results)
-	}
-	
-	if options.Limit > 0 {
-		results = results[start:end]
-	}
-	
 	return results, nil
 }
 
@@ -147,12 +260,12 @@ func (f *FileSearchIndex) Delete(ctx context.Context, id string) error {
 	}
 	
 	// Remove from inverted index
-	f.removeFromInvertedIndex(id, doc.Content)
+	f.removePostings(id, doc)
 	
 	// Remove document
 	delete(f.documents, id)
 	
-	return f.save()
+	return f.persist(storeRecord{op: searchOpDelete, key: id})
 }
 
 // BatchIndex indexes multiple documents in a single operation
@@ -164,20 +277,27 @@ func (f *FileSearchIndex) BatchIndex(ctx context.Context, docs []IndexDocument)
 		return fmt.Errorf("search index is closed")
 	}
 	
+	records := make([]storeRecord, 0, len(docs))
 	for _, doc := range docs {
+		record, err := encodeDocumentRecord(doc)
+		if err != nil {
+			return err
+		}
+		records = append(records, record)
+		
 		// Remove old document from index if it exists
 		if oldDoc, exists := f.documents[doc.ID]; exists {
-			f.removeFromInvertedIndex(doc.ID, oldDoc.Content)
+			f.removePostings(doc.ID, oldDoc)
 		}
 		
 		// Store document
 		f.documents[doc.ID] = doc
 		
 		// Add to inverted index
-		f.addToInvertedIndex(doc.ID, doc.Content)
+		f.addPostings(doc.ID, doc)
 	}
 	
-	return f.save()
+	return f.persist(records...)
 }
 
 // Update updates an existing document
@@ -189,19 +309,24 @@ func (f *FileSearchIndex) Update(ctx context.Context, id string, doc IndexDocume
 		return fmt.Errorf("search index is closed")
 	}
 	
+	// Update document ID to match parameter
+	doc.ID = id
+	record, err := encodeDocumentRecord(doc)
+	if err != nil {
+		return err
+	}
+	
 	// Remove old document from index if it exists
 	if oldDoc, exists := f.documents[id]; exists {
-		f.removeFromInvertedIndex(id, oldDoc.Content)
+		f.removePostings(id, oldDoc)
 	}
 	
-	// Update document ID to match parameter
-	doc.ID = id
 	f.documents[id] = doc
 	
 	// Add updated document to index
-	f.addToInvertedIndex(id, doc.Content)
+	f.addPostings(id, doc)
 	
-	return f.save()
+	return f.persist(record)
 }
 
 // GetDocument retrieves a document by ID
@@ -246,6 +371,31 @@ func (f *FileSearchIndex) DocumentCount(ctx context.Context) (int64, error) {
 	return int64(len(f.documents)), nil
 }
 
+// ListDocuments returns every indexed document ordered by ID
+func (f *FileSearchIndex) ListDocuments(ctx context.Context) ([]IndexDocument, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	
+	if f.closed {
+		return nil, fmt.Errorf("search index is closed")
+	}
+	
+	docs := make([]IndexDocument, 0, len(f.documents))
+	for _, doc := range f.documents {
+		docs = append(docs, doc)
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		return docs[i].ID < docs[j].ID
+	})
+	
+	return docs, nil
+}
+
+// FilePath returns the path the index is persisted to
+func (f *FileSearchIndex) FilePath() string {
+	return f.filePath
+}
+
 // IndexSize returns the index size
 func (f *FileSearchIndex) IndexSize(ctx context.Context) (int64, error) {
 	f.mu.RLock()
@@ -272,15 +422,8 @@ func (f *FileSearchIndex) Suggest(ctx context.Context, query string, field strin
 		return nil, fmt.Errorf("search index is closed")
 	}
 	
-	queryLower := strings.ToLower(query)
-	var suggestions []string
-	
-	// Find terms that start with the query
-	for term := range f.invertedIndex {
-		if strings.HasPrefix(term, queryLower) {
-			suggestions = append(suggestions, term)
-		}
-	}
+	// Suggest whole words as written, not the stemmed terms in the postings
+	suggestions := suggestWords(f.documents, query)
 	
 	// Sort and limit
 	sort.Strings(suggestions)
@@ -321,12 +464,18 @@ func (f *FileSearchIndex) RefreshIndex(ctx context.Context) error {
 	return nil // No-op for file-based implementation
 }
 
-// Close closes the search index connection
+// Close closes the search index, first compacting any logged changes into a snapshot
 func (f *FileSearchIndex) Close() error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 	
+	if f.closed {
+		return nil
+	}
 	f.closed = true
+	if f.log.pending > 0 {
+		return f.save()
+	}
 	return nil
 }
 
@@ -347,176 +496,428 @@ func (f *FileSearchIndex) Health(ctx context.Context) error {
 	return nil
 }
 
-// Load loads the search index from the JSON file
+// Load loads the documents from the snapshot, replays the changes logged since and indexes
+// them. A JSON file written by earlier versions is loaded as the snapshot, keeping the postings
+// it carries, and rewritten on the next change.
 func (f *FileSearchIndex) Load() error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 	
-	if _, err := os.Stat(f.filePath); os.IsNotExist(err) {
-		// File doesn't exist, start with empty index
-		f.documents = make(map[string]IndexDocument)
-		f.invertedIndex = make(map[string][]string)
-		return nil
+	f.resetIndex()
+	if err := f.log.loadSnapshot(f.loadLegacy, f.applyRecord); err != nil {
+		return err
+	}
+	return f.log.replay(f.applyRecord)
+}
+
+// loadLegacy loads a search index from the JSON format earlier versions wrote (assumes lock is held)
+func (f *FileSearchIndex) loadLegacy(data []byte) error {
+	var indexData SearchIndexData
+	if err := json.Unmarshal(data, &indexData); err != nil {
+		return fmt.Errorf("failed to unmarshal search index data: %w", err)
 	}
 	
-	data, err := os.ReadFile(f.filePath)
-	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+	if indexData.Documents != nil {
+		f.documents = indexData.Documents
 	}
 	
-	if len(data) == 0 {
-		f.documents = make(map[string]IndexDocument)
-		f.invertedIndex = make(map[string][]string)
+	// Files written before positional postings only carry documents; rebuild from those
+	if indexData.Postings == nil || indexData.DocLengths == nil {
+		for id, doc := range f.documents {
+			f.addPostings(id, doc)
+		}
 		return nil
 	}
 	
-	var indexData SearchIndexData
-	if err := json.Unmarshal(data, &indexData); err != nil {
-		return fmt.Errorf("failed to unmarshal search index data: %w", err)
+	f.postings = indexData.Postings
+	f.docLengths = indexData.DocLengths
+	for _, length := range f.docLengths {
+		f.totalLength += length
 	}
 	
-	f.documents = indexData.Documents
-	f.invertedIndex = indexData.InvertedIndex
-	
 	return nil
 }
 
-// Save saves the search index to the JSON file
+// Save compacts the index into a new snapshot, leaving the log empty
 func (f *FileSearchIndex) Save() error {
-	f.mu.RLock()
-	defer f.mu.RUnlock()
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	
 	return f.save()
 }
 
-// save is the internal save method (assumes lock is held)
+// persist records a change, appending it to the log or, when the log is due for compaction,
+// writing a snapshot that already includes it (assumes lock is held)
+func (f *FileSearchIndex) persist(records ...storeRecord) error {
+	if f.log.needsSnapshot(len(f.documents)) {
+		return f.save()
+	}
+	return f.log.append(records...)
+}
+
+// save writes every document as a new snapshot (assumes lock is held)
 func (f *FileSearchIndex) save() error {
-	// Create directory if it doesn't exist
-	dir := filepath.Dir(f.filePath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+	return f.log.snapshot(func(emit func(storeRecord)) error {
+		for _, doc := range f.documents {
+			record, err := encodeDocumentRecord(doc)
+			if err != nil {
+				return err
+			}
+			emit(record)
+		}
+		return nil
+	})
+}
+
+// applyRecord replays one persisted change, indexing the document (assumes lock is held)
+func (f *FileSearchIndex) applyRecord(record storeRecord) error {
+	if oldDoc, exists := f.documents[record.key]; exists {
+		f.removePostings(record.key, oldDoc)
+		delete(f.documents, record.key)
+	}
+	
+	switch record.op {
+	case searchOpPut:
+		var doc IndexDocument
+		if _, err := decodeStoreValue(record.value, &doc); err != nil {
+			return fmt.Errorf("failed to decode document %s: %w", record.key, err)
+		}
+		doc.ID = record.key
+		f.documents[record.key] = doc
+		f.addPostings(record.key, doc)
+	case searchOpDelete:
+	default:
+		return fmt.Errorf("unknown search index operation %d", record.op)
 	}
-	
-	indexData := SearchIndexData{
-		Documents:     f.documents,
-		InvertedIndex: f.invertedIndex,
+	return nil
+}
+
+// encodeDocumentRecord encodes a document as a put record
+func encodeDocumentRecord(doc IndexDocument) (storeRecord, error) {
+	value, err := encodeStoreValue(nil, doc)
+	if err != nil {
+		return storeRecord{}, fmt.Errorf("failed to marshal document %s: %w", doc.ID, err)
+	}
+	return storeRecord{op: searchOpPut, key: doc.ID, value: value}, nil
+}
+
+// Helper methods
+
+// tokenize runs text through the analyzer for a language
+func (f *FileSearchIndex) tokenize(text, language string) []string {
+	return f.analyzers.ForLanguage(language).Analyze(text)
+}
+
+// suggestWords returns the distinct unstemmed words in the documents that start with prefix
+func suggestWords(documents map[string]IndexDocument, prefix string) []string {
+	prefix = foldAccents(strings.ToLower(prefix))
+	seen := make(map[string]bool)
+	var words []string
+	
+	for _, doc := range documents {
+		for _, word := range surfaceAnalyzer.Analyze(documentText(doc.Content, doc.Metadata)) {
+			if !seen[word] && strings.HasPrefix(word, prefix) {
+				seen[word] = true
+				words = append(words, word)
+			}
+		}
 	}
 	
-	data, err := json.MarshalIndent(indexData, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal search index data: %w", err)
+	return words
+}
+
+// highlightSnippet finds term in content and returns the text around the word it starts with
+// that word emphasized, since terms may be stemmed. Offsets are found in content itself rather
+// than a lowercased copy, whose byte length can differ.
+func highlightSnippet(content, term string) (string, bool) {
+	index, matchEnd := indexFold(content, term)
+	if index < 0 {
+		return "", false
 	}
+	wordEnd := wordEndAt(content, matchEnd)
 	
-	if err := os.WriteFile(f.filePath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+	start := index - 20
+	if start < 0 {
+		start = 0
+	}
+	for start < index && !utf8.RuneStart(content[start]) {
+		start++
+	}
+	end := wordEnd + 20
+	if end > len(content) {
+		end = len(content)
+	}
+	for end < len(content) && !utf8.RuneStart(content[end]) {
+		end++
 	}
 	
-	return nil
+	snippet := content[start:end]
+	word := content[index:wordEnd]
+	return strings.ReplaceAll(snippet, word, fmt.Sprintf("<em>%s</em>", word)), true
 }
 
-// Helper methods
+// indexFold returns the byte offsets in s where the first case-insensitive match of term starts
+// and ends, or -1 when there is none. Runes are compared one by one so the offsets stay valid
+// in s even where lowercasing would change a rune's encoded length.
+func indexFold(s, term string) (int, int) {
+	if term == "" {
+		return -1, -1
+	}
+	for start := range s {
+		end := start
+		matched := true
+		for _, want := range term {
+			if end >= len(s) {
+				matched = false
+				break
+			}
+			got, size := utf8.DecodeRuneInString(s[end:])
+			if unicode.ToLower(got) != unicode.ToLower(want) {
+				matched = false
+				break
+			}
+			end += size
+		}
+		if matched {
+			return start, end
+		}
+	}
+	return -1, -1
+}
 
-// tokenize splits text into terms
-func (f *FileSearchIndex) tokenize(text string) []string {
-	// Simple tokenization - split on whitespace and punctuation
-	text = strings.ToLower(text)
-	words := strings.FieldsFunc(text, func(c rune) bool {
-		return !((c >= 'a' && c <= 'z') || (c >= '0' && c <= '9'))
-	})
-	
-	// Remove empty strings
-	var result []string
-	for _, word := range words {
-		if len(word) > 0 {
-			result = append(result, word)
+// wordEndAt returns the byte offset where the word running through index ends
+func wordEndAt(text string, index int) int {
+	for index < len(text) {
+		r, size := utf8.DecodeRuneInString(text[index:])
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			break
 		}
+		index += size
+	}
+	return index
+}
+
+// resetIndex empties the documents and postings (assumes lock is held)
+func (f *FileSearchIndex) resetIndex() {
+	f.documents = make(map[string]IndexDocument)
+	f.postings = make(map[string]map[string][]int)
+	f.docLengths = make(map[string]int)
+	f.totalLength = 0
+}
+
+// addPostings records the position of every term of a document and its length, analyzing the
+// content in the language named by its metadata
+func (f *FileSearchIndex) addPostings(docID string, doc IndexDocument) {
+	terms := f.tokenize(documentText(doc.Content, doc.Metadata), documentLanguage(doc.Metadata))
+	
+	for position, term := range terms {
+		docs, exists := f.postings[term]
+		if !exists {
+			docs = make(map[string][]int)
+			f.postings[term] = docs
+		}
+		docs[docID] = append(docs[docID], position)
 	}
 	
-	return result
+	f.docLengths[docID] = len(terms)
+	f.totalLength += len(terms)
 }
 
-// addToInvertedIndex adds a document to the inverted index
-func (f *FileSearchIndex) addToInvertedIndex(docID, content string) {
-	terms := f.tokenize(content)
+// removePostings removes a document's postings and length
+func (f *FileSearchIndex) removePostings(docID string, doc IndexDocument) {
+	for _, term := range f.tokenize(documentText(doc.Content, doc.Metadata), documentLanguage(doc.Metadata)) {
+		if docs, exists := f.postings[term]; exists {
+			delete(docs, docID)
+			
+			// Remove term if no documents left
+			if len(docs) == 0 {
+				delete(f.postings, term)
+			}
+		}
+	}
 	
-	for _, term := range terms {
-		if _, exists := f.invertedIndex[term]; !exists {
-			f.invertedIndex[term] = []string{}
+	f.totalLength -= f.docLengths[docID]
+	delete(f.docLengths, docID)
+}
+
+// parseQuery splits a query into its distinct terms and its quoted phrases. A phrase may be
+// followed by ~N for a proximity match; an unterminated quote is read as plain terms.
+func (f *FileSearchIndex) parseQuery(query, language string) ([]string, []indexPhrase) {
+	var plain strings.Builder
+	var phrases []indexPhrase
+	
+	rest := query
+	for {
+		open := strings.IndexByte(rest, '"')
+		if open < 0 {
+			break
+		}
+		closing := strings.IndexByte(rest[open+1:], '"')
+		if closing < 0 {
+			break
 		}
+		closing += open + 1
 		
-		// Add document ID if not already present
-		found := false
-		for _, id := range f.invertedIndex[term] {
-			if id == docID {
-				found = true
-				break
+		plain.WriteString(rest[:open])
+		plain.WriteByte(' ')
+		phrase := indexPhrase{terms: f.tokenize(rest[open+1:closing], language)}
+		rest = rest[closing+1:]
+		
+		// Proximity suffix: "a b"~N
+		if strings.HasPrefix(rest, "~") {
+			digits := 0
+			for digits+1 < len(rest) && rest[digits+1] >= '0' && rest[digits+1] <= '9' {
+				phrase.slop = phrase.slop*10 + int(rest[digits+1]-'0')
+				digits++
 			}
+			rest = rest[digits+1:]
 		}
 		
-		if !found {
-			f.invertedIndex[term] = append(f.invertedIndex[term], docID)
+		if len(phrase.terms) > 0 {
+			phrases = append(phrases, phrase)
+		}
+	}
+	plain.WriteString(rest)
+	
+	var terms []string
+	seen := make(map[string]bool)
+	addTerms := func(tokens []string) {
+		for _, term := range tokens {
+			if !seen[term] {
+				seen[term] = true
+				terms = append(terms, term)
+			}
 		}
 	}
+	addTerms(f.tokenize(plain.String(), language))
+	for _, phrase := range phrases {
+		addTerms(phrase.terms)
+	}
+	
+	return terms, phrases
 }
 
-// removeFromInvertedIndex removes a document from the inverted index
-func (f *FileSearchIndex) removeFromInvertedIndex(docID, content string) {
-	terms := f.tokenize(content)
+// scoreBM25 scores every document containing at least one of the terms with Okapi BM25
+// using the corpus document frequencies and average document length
+func (f *FileSearchIndex) scoreBM25(terms []string) map[string]float64 {
+	scores := make(map[string]float64)
+	docCount := float64(len(f.documents))
+	if docCount == 0 {
+		return scores
+	}
+	
+	avgLength := float64(f.totalLength) / docCount
+	if avgLength == 0 {
+		avgLength = 1
+	}
+	k1, b := f.config.BM25K1, f.config.BM25B
 	
 	for _, term := range terms {
-		if docIDs, exists := f.invertedIndex[term]; exists {
-			// Remove document ID from the list
-			for i, id := range docIDs {
-				if id == docID {
-					f.invertedIndex[term] = append(docIDs[:i], docIDs[i+1:]...)
-					break
-				}
+		docs := f.postings[term]
+		if len(docs) == 0 {
+			continue
+		}
+		
+		df := float64(len(docs))
+		idf := math.Log(1 + (docCount-df+0.5)/(df+0.5))
+		
+		for docID, positions := range docs {
+			tf := float64(len(positions))
+			norm := 1 - b + b*float64(f.docLengths[docID])/avgLength
+			scores[docID] += idf * tf * (k1 + 1) / (tf + k1*norm)
+		}
+	}
+	
+	return scores
+}
+
+// matchesPhrases reports whether a document satisfies every phrase
+func (f *FileSearchIndex) matchesPhrases(docID string, phrases []indexPhrase) bool {
+	for _, phrase := range phrases {
+		positions := make([][]int, len(phrase.terms))
+		for i, term := range phrase.terms {
+			positions[i] = f.postings[term][docID]
+			if len(positions[i]) == 0 {
+				return false
 			}
-			
-			// Remove term if no documents left
-			if len(f.invertedIndex[term]) == 0 {
-				delete(f.invertedIndex, term)
+		}
+		
+		if phrase.slop == 0 {
+			if !containsExactPhrase(positions) {
+				return false
+			}
+		} else if !containsWithinWindow(positions, len(phrase.terms)-1+phrase.slop) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsExactPhrase reports whether the terms occur consecutively in order, given each
+// term's sorted positions
+func containsExactPhrase(positions [][]int) bool {
+	for _, start := range positions[0] {
+		matched := true
+		for i := 1; i < len(positions); i++ {
+			index := sort.SearchInts(positions[i], start+i)
+			if index >= len(positions[i]) || positions[i][index] != start+i {
+				matched = false
+				break
 			}
 		}
+		if matched {
+			return true
+		}
 	}
+	return false
 }
 
-// matchesFilters checks if document metadata matches filters
-func (f *FileSearchIndex) matchesFilters(metadata map[string]interface{}, filters map[string]interface{}) bool {
-	if filters == nil {
-		return true
+// containsWithinWindow reports whether one occurrence of every term fits in a span of at most
+// window positions, in any order
+func containsWithinWindow(positions [][]int, window int) bool {
+	type occurrence struct {
+		position int
+		term     int
+	}
+	var occurrences []occurrence
+	for term, list := range positions {
+		for _, position := range list {
+			occurrences = append(occurrences, occurrence{position, term})
+		}
 	}
+	sort.Slice(occurrences, func(i, j int) bool {
+		return occurrences[i].position < occurrences[j].position
+	})
 	
-	for key, expectedValue := range filters {
-		if actualValue, exists := metadata[key]; !exists || actualValue != expectedValue {
-			return false
+	// Slide a window over the occurrences, shrinking from the left while it still covers every term
+	counts := make([]int, len(positions))
+	covered, left := 0, 0
+	for _, occ := range occurrences {
+		if counts[occ.term] == 0 {
+			covered++
+		}
+		counts[occ.term]++
+		
+		for covered == len(positions) {
+			if occ.position-occurrences[left].position <= window {
+				return true
+			}
+			counts[occurrences[left].term]--
+			if counts[occurrences[left].term] == 0 {
+				covered--
+			}
+			left++
 		}
 	}
-	
-	return true
+	return false
 }
 
 // generateHighlights creates highlighted snippets
 func (f *FileSearchIndex) generateHighlights(content string, queryTerms []string) []string {
 	var highlights []string
-	contentLower := strings.ToLower(content)
 	
 	for _, term := range queryTerms {
-		if strings.Contains(contentLower, term) {
-			// Find the term and create a snippet around it
-			index := strings.Index(contentLower, term)
-			start := index - 20
-			if start < 0 {
-				start = 0
-			}
-			end := index + len(term) + 20
-			if end > len(content) {
-				end = len(content)
-			}
-			
-			snippet := content[start:end]
-			// Highlight the term (simple approach)
-			highlighted := strings.ReplaceAll(snippet, term, fmt.Sprintf("<em>%s</em>", term))
+		if highlighted, ok := highlightSnippet(content, term); ok {
 			highlights = append(highlights, highlighted)
 		}
 	}
@@ -3,8 +3,10 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
 	"path/filepath"
 	"testing"
+	"unicode/utf8"
 
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -321,6 +323,15 @@ func TestFileSearchIndex(t *testing.T) {
 				So(err, ShouldBeNil)
 				So(size, ShouldBeGreaterThan, 0)
 			})
+			
+			docs, err := index.ListDocuments(ctx)
+			
+			Convey("And listing should return every document in ID order", func() {
+				So(err, ShouldBeNil)
+				So(len(docs), ShouldEqual, 5)
+				So(docs[0].ID, ShouldEqual, "count_0")
+				So(docs[4].ID, ShouldEqual, "count_4")
+			})
 		})
 		
 		Convey("When checking health", func() {
@@ -473,6 +484,123 @@ func TestFileSearchIndexEdgeCases(t *testing.T) {
 	})
 }
 
+func TestFileSearchIndexBM25AndPhrases(t *testing.T) {
+	Convey("Given a FileSearchIndex with a small corpus", t, func() {
+		tempDir := t.TempDir()
+		filePath := filepath.Join(tempDir, "search_index.json")
+		index := NewFileSearchIndex(filePath)
+		ctx := context.Background()
+		
+		docs := []IndexDocument{
+			{ID: "short", Content: "vector store design"},
+			{ID: "long", Content: "the vector database keeps embeddings and the store keeps documents for design reviews"},
+			{ID: "reversed", Content: "store vector notes"},
+			{ID: "other", Content: "graph traversal with personalized pagerank"},
+		}
+		So(index.BatchIndex(ctx, docs), ShouldBeNil)
+		
+		ids := func(results []SearchResult) []string {
+			var out []string
+			for _, result := range results {
+				out = append(out, result.ID)
+			}
+			return out
+		}
+		
+		Convey("When searching with plain terms", func() {
+			results, err := index.Search(ctx, "vector design", SearchIndexOptions{})
+			
+			Convey("Then shorter documents with more matches should rank first", func() {
+				So(err, ShouldBeNil)
+				So(len(results), ShouldEqual, 3)
+				So(results[0].ID, ShouldEqual, "short")
+				So(ids(results), ShouldNotContain, "other")
+			})
+		})
+		
+		Convey("When searching for a rare term", func() {
+			rare, err := index.Search(ctx, "pagerank", SearchIndexOptions{})
+			So(err, ShouldBeNil)
+			common, err := index.Search(ctx, "vector", SearchIndexOptions{})
+			So(err, ShouldBeNil)
+			
+			Convey("Then it should carry a higher inverse document frequency", func() {
+				So(rare[0].Score, ShouldBeGreaterThan, common[len(common)-1].Score)
+			})
+		})
+		
+		Convey("When searching for a quoted phrase", func() {
+			results, err := index.Search(ctx, `"vector store"`, SearchIndexOptions{})
+			
+			Convey("Then only documents with the terms adjacent and in order should match", func() {
+				So(err, ShouldBeNil)
+				So(ids(results), ShouldResemble, []string{"short"})
+			})
+		})
+		
+		Convey("When searching for a proximity phrase", func() {
+			results, err := index.Search(ctx, `"vector store"~1`, SearchIndexOptions{})
+			So(err, ShouldBeNil)
+			wide, err := index.Search(ctx, `"vector store"~6`, SearchIndexOptions{})
+			So(err, ShouldBeNil)
+			
+			Convey("Then terms within the slop should match in any order", func() {
+				So(ids(results), ShouldContain, "short")
+				So(ids(results), ShouldContain, "reversed")
+				So(ids(results), ShouldNotContain, "long")
+				So(ids(wide), ShouldContain, "long")
+			})
+		})
+		
+		Convey("When a document is deleted", func() {
+			So(index.Delete(ctx, "short"), ShouldBeNil)
+			results, err := index.Search(ctx, `"vector store"`, SearchIndexOptions{})
+			
+			Convey("Then its postings should be gone", func() {
+				So(err, ShouldBeNil)
+				So(results, ShouldBeEmpty)
+			})
+		})
+		
+		Convey("When the index is reloaded from disk", func() {
+			reloaded := NewFileSearchIndex(filePath)
+			So(reloaded.Load(), ShouldBeNil)
+			results, err := reloaded.Search(ctx, `"vector store"~1`, SearchIndexOptions{})
+			
+			Convey("Then positions and lengths should survive", func() {
+				So(err, ShouldBeNil)
+				So(len(results), ShouldEqual, 2)
+				So(reloaded.totalLength, ShouldEqual, index.totalLength)
+			})
+		})
+		
+		Convey("When loading a file written without postings", func() {
+			legacyPath := filepath.Join(tempDir, "legacy.json")
+			legacy := `{"documents": {"a": {"id": "a", "content": "vector store design"}}, "inverted_index": {"vector": ["a"]}}`
+			So(os.WriteFile(legacyPath, []byte(legacy), 0644), ShouldBeNil)
+			
+			loaded := NewFileSearchIndex(legacyPath)
+			So(loaded.Load(), ShouldBeNil)
+			results, err := loaded.Search(ctx, `"store design"`, SearchIndexOptions{})
+			
+			Convey("Then the postings should be rebuilt from the documents", func() {
+				So(err, ShouldBeNil)
+				So(ids(results), ShouldResemble, []string{"a"})
+			})
+		})
+		
+		Convey("When configured with custom BM25 parameters", func() {
+			custom := NewFileSearchIndexWithConfig(filepath.Join(tempDir, "custom.json"), &FileSearchIndexConfig{BM25K1: 2.0, BM25B: 0})
+			k1, b := custom.BM25Parameters()
+			
+			Convey("Then it should report them", func() {
+				So(k1, ShouldEqual, 2.0)
+				So(b, ShouldEqual, 0)
+			})
+		})
+	})
+}
+
 func BenchmarkFileSearchIndex(b *testing.B) {
 	tempDir := b.TempDir()
 	filePath := filepath.Join(tempDir, "bench_search_index.json")
@@ -536,4 +664,140 @@ func containsMiddle(text, substr string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+func TestFileSearchIndexSearchWithFacets(t *testing.T) {
+	Convey("Given a FileSearchIndex with documents from several sources", t, func() {
+		index := NewFileSearchIndex(filepath.Join(t.TempDir(), "search_index.json"))
+		ctx := context.Background()
+		
+		docs := []IndexDocument{
+			{ID: "a", Content: "memory retrieval notes", Metadata: map[string]interface{}{"source": "slack", "tags": []string{"recall"}, "timestamp": "2026-10-01T10:00:00Z"}},
+			{ID: "b", Content: "memory graph notes", Metadata: map[string]interface{}{"source": "slack", "tags": []string{"graph"}, "timestamp": "2026-10-02T10:00:00Z"}},
+			{ID: "c", Content: "memory vector notes", Metadata: map[string]interface{}{"source": "wiki", "tags": []string{"recall"}, "timestamp": "2026-11-05T10:00:00Z"}},
+			{ID: "d", Content: "unrelated content", Metadata: map[string]interface{}{"source": "email"}},
+		}
+		So(index.BatchIndex(ctx, docs), ShouldBeNil)
+		
+		Convey("When searching one page with facets", func() {
+			response, err := index.SearchWithFacets(ctx, "memory", SearchIndexOptions{
+				Limit:  1,
+				Facets: []FacetRequest{{Field: "source"}, {Field: "tags"}, {Field: "timestamp", Interval: FacetIntervalMonth}},
+			})
+			
+			Convey("Then facets should count every match, not just the page", func() {
+				So(err, ShouldBeNil)
+				So(response.Results, ShouldHaveLength, 1)
+				So(response.TotalResults, ShouldEqual, 3)
+				So(response.HasMore, ShouldBeTrue)
+				So(response.NextOffset, ShouldEqual, 1)
+				So(response.Facets["source"], ShouldResemble, []Facet{{"slack", 2}, {"wiki", 1}})
+				So(response.Facets["tags"], ShouldResemble, []Facet{{"recall", 2}, {"graph", 1}})
+				So(response.Facets["timestamp:month"], ShouldResemble, []Facet{{"2026-10", 2}, {"2026-11", 1}})
+			})
+		})
+		
+		Convey("When filters narrow the matches", func() {
+			response, err := index.SearchWithFacets(ctx, "memory", SearchIndexOptions{
+				Filters: map[string]interface{}{"source": "slack"},
+				Facets:  []FacetRequest{{Field: "tags"}},
+			})
+			
+			Convey("Then facets should reflect only the filtered matches", func() {
+				So(err, ShouldBeNil)
+				So(response.TotalResults, ShouldEqual, 2)
+				So(response.HasMore, ShouldBeFalse)
+				So(response.Facets["tags"], ShouldResemble, []Facet{{"graph", 1}, {"recall", 1}})
+			})
+		})
+		
+		Convey("When a facet has an unknown interval", func() {
+			_, err := index.SearchWithFacets(ctx, "memory", SearchIndexOptions{
+				Facets: []FacetRequest{{Field: "timestamp", Interval: "year"}},
+			})
+			
+			Convey("Then an error should be returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+func TestFileSearchIndexPersistence(t *testing.T) {
+	Convey("Given a loaded FileSearchIndex", t, func() {
+		ctx := context.Background()
+		filePath := filepath.Join(t.TempDir(), "search_index.json")
+		index := NewFileSearchIndex(filePath)
+		So(index.Load(), ShouldBeNil)
+		
+		So(index.Index(ctx, IndexDocument{ID: "a", Content: "append only logs survive crashes"}), ShouldBeNil)
+		So(index.Index(ctx, IndexDocument{ID: "b", Content: "snapshots are renamed into place"}), ShouldBeNil)
+		So(index.Update(ctx, "a", IndexDocument{Content: "append only logs are compacted"}), ShouldBeNil)
+		So(index.Delete(ctx, "b"), ShouldBeNil)
+		
+		Convey("When the index is reloaded without saving", func() {
+			reloaded := NewFileSearchIndex(filePath)
+			So(reloaded.Load(), ShouldBeNil)
+			
+			Convey("Then the logged changes should be replayed and indexed", func() {
+				count, err := reloaded.DocumentCount(ctx)
+				So(err, ShouldBeNil)
+				So(count, ShouldEqual, 1)
+				
+				results, err := reloaded.Search(ctx, `"logs are compacted"`, SearchIndexOptions{})
+				So(err, ShouldBeNil)
+				So(len(results), ShouldEqual, 1)
+				results, err = reloaded.Search(ctx, "crashes", SearchIndexOptions{})
+				So(err, ShouldBeNil)
+				So(results, ShouldBeEmpty)
+				So(reloaded.totalLength, ShouldEqual, index.totalLength)
+			})
+		})
+		
+		Convey("When the log grows past the live documents", func() {
+			docs := make([]IndexDocument, storeLogCompactAfter)
+			for i := range docs {
+				docs[i] = IndexDocument{ID: "a", Content: fmt.Sprintf("revision %d", i)}
+			}
+			So(index.BatchIndex(ctx, docs), ShouldBeNil)
+			So(index.log.pending, ShouldBeGreaterThanOrEqualTo, storeLogCompactAfter)
+			So(index.Index(ctx, IndexDocument{ID: "c", Content: "compaction trigger"}), ShouldBeNil)
+			
+			Convey("Then the next change should compact it into a snapshot", func() {
+				So(index.log.pending, ShouldEqual, 0)
+				_, err := os.Stat(filePath + ".log")
+				So(os.IsNotExist(err), ShouldBeTrue)
+				
+				reloaded := NewFileSearchIndex(filePath)
+				So(reloaded.Load(), ShouldBeNil)
+				doc, err := reloaded.GetDocument(ctx, "a")
+				So(err, ShouldBeNil)
+				So(doc.Content, ShouldEqual, fmt.Sprintf("revision %d", storeLogCompactAfter-1))
+			})
+		})
+	})
+}
+
+func TestHighlightSnippet(t *testing.T) {
+	Convey("Given content whose runes change length when lowercased", t, func() {
+		// 'Ⱥ' is two bytes but lowercases to the three-byte 'ⱥ'
+		content := "ȺȺȺȺȺȺȺȺȺȺȺȺ Migrations run before deploying"
+
+		Convey("Then the snippet should be cut from the content at rune boundaries", func() {
+			highlighted, ok := highlightSnippet(content, "migrat")
+			So(ok, ShouldBeTrue)
+			So(utf8.ValidString(highlighted), ShouldBeTrue)
+			So(highlighted, ShouldContainSubstring, "<em>Migrations</em>")
+		})
+
+		Convey("Then terms should match runes of either case", func() {
+			highlighted, ok := highlightSnippet(content, "ⱥⱥ")
+			So(ok, ShouldBeTrue)
+			So(highlighted, ShouldStartWith, "<em>ȺȺȺȺȺȺȺȺȺȺȺȺ</em>")
+		})
+
+		Convey("Then a missing term should give no snippet", func() {
+			_, ok := highlightSnippet(content, "rollback")
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
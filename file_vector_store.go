@@ -4,21 +4,48 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"math"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 )
 
-// FileVectorStore implements VectorStore interface with JSON file persistence
+// Vector index types a FileVectorStore can search with
+const (
+	VectorIndexFlat = "flat"
+	VectorIndexHNSW = "hnsw"
+)
+
+// FileVectorStore implements VectorStore interface with a binary snapshot and an
+// append-only log of changes, embeddings encoded as raw float32s
 type FileVectorStore struct {
 	mu       sync.RWMutex
 	filePath string
+	config   *FileVectorStoreConfig
 	vectors  map[string]VectorStoreRecord
+	index    *hnswIndex // nil for a flat index, which scans every vector
+	log      *storeLog
 	closed   bool
 }
 
+// Operations recorded in a vector store's log
+const (
+	vectorOpPut      byte = iota + 1 // Embedding and metadata
+	vectorOpDelete                   // Key only
+	vectorOpMetadata                 // Metadata only, keeping the embedding
+)
+
+// FileVectorStoreConfig selects the metric and index a FileVectorStore searches with
+type FileVectorStoreConfig struct {
+	Metric         VectorMetric `json:"metric"`          // "cosine", "dot" or "l2"
+	IndexType      string       `json:"index_type"`      // "flat" or "hnsw"
+	M              int          `json:"m"`               // HNSW links per node; layer 0 keeps twice as many
+	EfConstruction int          `json:"ef_construction"` // HNSW beam width while inserting
+	EfSearch       int          `json:"ef_search"`       // HNSW beam width while searching, raised to k when smaller
+}
+
 // VectorStoreRecord represents a stored vector record
 type VectorStoreRecord struct {
 	ID        string                 `json:"id"`
@@ -26,12 +53,54 @@ type VectorStoreRecord struct {
 	Metadata  map[string]interface{} `json:"metadata"`
 }
 
+// DefaultFileVectorStoreConfig returns a flat cosine index with the usual HNSW parameters
+func DefaultFileVectorStoreConfig() *FileVectorStoreConfig {
+	return &FileVectorStoreConfig{
+		Metric:         VectorMetricCosine,
+		IndexType:      VectorIndexFlat,
+		M:              16,
+		EfConstruction: 200,
+		EfSearch:       64,
+	}
+}
+
 // NewFileVectorStore creates a new file-based vector store
 func NewFileVectorStore(filePath string) *FileVectorStore {
-	return &FileVectorStore{
+	return NewFileVectorStoreWithConfig(filePath, nil)
+}
+
+// NewFileVectorStoreWithConfig creates a file-based vector store with the given index configuration
+func NewFileVectorStoreWithConfig(filePath string, config *FileVectorStoreConfig) *FileVectorStore {
+	if config == nil {
+		config = DefaultFileVectorStoreConfig()
+	}
+
+	store := &FileVectorStore{
 		filePath: filePath,
+		config:   config,
 		vectors:  make(map[string]VectorStoreRecord),
+		log:      newStoreLog(filePath),
+	}
+	if config.Metric == "" {
+		config.Metric = VectorMetricCosine
+	}
+	if config.IndexType == VectorIndexHNSW {
+		store.index = newHNSWIndex(config.Metric, config.M, config.EfConstruction, config.EfSearch)
+	}
+	return store
+}
+
+// Metric returns the metric search results are scored with
+func (f *FileVectorStore) Metric() VectorMetric {
+	return f.config.Metric
+}
+
+// IndexType returns the index the store searches with, "flat" or "hnsw"
+func (f *FileVectorStore) IndexType() string {
+	if f.index != nil {
+		return VectorIndexHNSW
 	}
+	return VectorIndexFlat
 }
 
 // Store saves a vector embedding with associated metadata
@@ -47,13 +116,22 @@ func (f *FileVectorStore) Store(ctx context.Context, id string, embedding []floa
 		metadata = make(map[string]interface{})
 	}
 
-	f.vectors[id] = VectorStoreRecord{
+	record := VectorStoreRecord{
 		ID:        id,
 		Embedding: embedding,
 		Metadata:  metadata,
 	}
+	logRecord, err := encodeVectorRecord(record)
+	if err != nil {
+		return err
+	}
 
-	return f.save()
+	f.vectors[id] = record
+	if f.index != nil {
+		f.index.insert(id, embedding)
+	}
+
+	return f.persist(logRecord)
 }
 
 // Search performs similarity search and returns top k results
@@ -65,16 +143,26 @@ func (f *FileVectorStore) Search(ctx context.Context, query []float32, k int, fi
 		return nil, fmt.Errorf("vector store is closed")
 	}
 
+	filter, err := ParseMetadataFilter(filters)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filters: %w", err)
+	}
+
+	// The approximate index answers top-k queries; asking for everything still scans
+	if f.index != nil && k > 0 {
+		return f.searchIndex(query, k, filter), nil
+	}
+
 	results := make([]VectorResult, 0, len(f.vectors))
 
 	for _, record := range f.vectors {
 		// Apply filters
-		if !f.matchesFilters(record.Metadata, filters) {
+		if !filter.Matches(record.Metadata) {
 			continue
 		}
 
-		// Calculate cosine similarity
-		score := f.cosineSimilarity(query, record.Embedding)
+		// Score with the configured metric
+		score := f.config.Metric.Similarity(query, record.Embedding)
 
 		result := VectorResult{
 			ID:        record.ID,
@@ -83,6 +171,10 @@ func (f *FileVectorStore) Search(ctx context.Context, query []float32, k int, fi
 			Metadata:  record.Metadata,
 		}
 
+		if content, ok := record.Metadata["content"].(string); ok {
+			result.Content = content
+		}
+
 		results = append(results, result)
 	}
 
@@ -113,7 +205,10 @@ func (f *FileVectorStore) Delete(ctx context.Context, id string) error {
 	}
 
 	delete(f.vectors, id)
-	return f.save()
+	if f.index != nil {
+		f.index.remove(id)
+	}
+	return f.persist(storeRecord{op: vectorOpDelete, key: id})
 }
 
 // BatchStore stores multiple vectors in a single operation
@@ -125,15 +220,25 @@ func (f *FileVectorStore) BatchStore(ctx context.Context, items []VectorStoreIte
 		return fmt.Errorf("vector store is closed")
 	}
 
+	records := make([]storeRecord, 0, len(items))
 	for _, item := range items {
 		if item.Metadata == nil {
 			item.Metadata = make(map[string]interface{})
 		}
 
+		logRecord, err := encodeVectorRecord(VectorStoreRecord(item))
+		if err != nil {
+			return err
+		}
+		records = append(records, logRecord)
+
 		f.vectors[item.ID] = VectorStoreRecord(item)
+		if f.index != nil {
+			f.index.insert(item.ID, item.Embedding)
+		}
 	}
 
-	return f.save()
+	return f.persist(records...)
 }
 
 // Update updates the metadata for an existing vector
@@ -154,10 +259,15 @@ func (f *FileVectorStore) Update(ctx context.Context, id string, metadata map[st
 		metadata = make(map[string]interface{})
 	}
 
+	value, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
 	record.Metadata = metadata
 	f.vectors[id] = record
 
-	return f.save()
+	return f.persist(storeRecord{op: vectorOpMetadata, key: id, value: value})
 }
 
 // GetByID retrieves a vector by ID
@@ -181,6 +291,10 @@ func (f *FileVectorStore) GetByID(ctx context.Context, id string) (*VectorResult
 		Metadata:  record.Metadata,
 	}
 
+	if content, ok := record.Metadata["content"].(string); ok {
+		result.Content = content
+	}
+
 	return result, nil
 }
 
@@ -196,12 +310,23 @@ func (f *FileVectorStore) Count(ctx context.Context) (int64, error) {
 	return int64(len(f.vectors)), nil
 }
 
-// Close closes the vector store connection
+// FilePath returns the path the store is persisted to
+func (f *FileVectorStore) FilePath() string {
+	return f.filePath
+}
+
+// Close closes the vector store, first compacting any logged changes into a snapshot
 func (f *FileVectorStore) Close() error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
+	if f.closed {
+		return nil
+	}
 	f.closed = true
+	if f.log.pending > 0 {
+		return f.save()
+	}
 	return nil
 }
 
@@ -222,94 +347,184 @@ func (f *FileVectorStore) Health(ctx context.Context) error {
 	return nil
 }
 
-// Load loads vectors from the JSON file
+// Load loads vectors from the snapshot and replays the changes logged since. A JSON file
+// written by earlier versions is loaded as the snapshot and rewritten on the next change.
 func (f *FileVectorStore) Load() error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
-	if _, err := os.Stat(f.filePath); os.IsNotExist(err) {
-		// File doesn't exist, start with empty store
-		f.vectors = make(map[string]VectorStoreRecord)
+	f.vectors = make(map[string]VectorStoreRecord)
+	legacy := func(data []byte) error {
+		if err := json.Unmarshal(data, &f.vectors); err != nil {
+			return fmt.Errorf("failed to unmarshal vectors: %w", err)
+		}
 		return nil
 	}
 
-	data, err := os.ReadFile(f.filePath)
-	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+	// The HNSW graph on disk matches the snapshot, so restore it before replaying the log
+	if err := f.log.loadSnapshot(legacy, func(record storeRecord) error {
+		return f.applyRecord(record, false)
+	}); err != nil {
+		return err
+	}
+	if err := f.loadIndex(); err != nil {
+		return err
 	}
+	return f.log.replay(func(record storeRecord) error {
+		return f.applyRecord(record, true)
+	})
+}
 
-	if len(data) == 0 {
-		f.vectors = make(map[string]VectorStoreRecord)
+// loadIndex restores the HNSW graph saved beside the vectors, rebuilding it when the
+// file is missing, unreadable or out of step with the vectors (assumes lock is held)
+func (f *FileVectorStore) loadIndex() error {
+	if f.index == nil {
 		return nil
 	}
 
-	if err := json.Unmarshal(data, &f.vectors); err != nil {
-		return fmt.Errorf("failed to unmarshal vectors: %w", err)
+	data, err := os.ReadFile(f.indexFilePath())
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read vector index: %w", err)
+	}
+
+	var indexData hnswIndexData
+	if len(data) > 0 && json.Unmarshal(data, &indexData) == nil && f.index.restore(indexData, f.vectors) {
+		return nil
 	}
 
+	f.index.rebuild(f.vectors)
 	return nil
 }
 
-// Save saves vectors to the JSON file
+// indexFilePath is where the HNSW graph is persisted, next to the vectors file
+func (f *FileVectorStore) indexFilePath() string {
+	ext := filepath.Ext(f.filePath)
+	return strings.TrimSuffix(f.filePath, ext) + ".hnsw" + ext
+}
+
+// Save compacts the store into a new snapshot, leaving the log empty
 func (f *FileVectorStore) Save() error {
-	f.mu.RLock()
-	defer f.mu.RUnlock()
+	f.mu.Lock()
+	defer f.mu.Unlock()
 
 	return f.save()
 }
 
-// save is the internal save method (assumes lock is held)
-func (f *FileVectorStore) save() error {
-	// Create directory if it doesn't exist
-	dir := filepath.Dir(f.filePath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+// persist records a change, appending it to the log or, when the log is due for compaction,
+// writing a snapshot that already includes it (assumes lock is held)
+func (f *FileVectorStore) persist(records ...storeRecord) error {
+	if f.log.needsSnapshot(len(f.vectors)) {
+		return f.save()
 	}
+	return f.log.append(records...)
+}
 
-	data, err := json.MarshalIndent(f.vectors, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal vectors: %w", err)
+// save writes every vector as a new snapshot and the HNSW graph beside it (assumes lock is held)
+func (f *FileVectorStore) save() error {
+	if err := f.log.snapshot(func(emit func(storeRecord)) error {
+		for _, record := range f.vectors {
+			logRecord, err := encodeVectorRecord(record)
+			if err != nil {
+				return err
+			}
+			emit(logRecord)
+		}
+		return nil
+	}); err != nil {
+		return err
 	}
 
-	if err := os.WriteFile(f.filePath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+	if f.index != nil {
+		indexData, err := json.Marshal(f.index.export())
+		if err != nil {
+			return fmt.Errorf("failed to marshal vector index: %w", err)
+		}
+		if err := writeFileAtomic(f.indexFilePath(), func(w io.Writer) error {
+			_, err := w.Write(indexData)
+			return err
+		}); err != nil {
+			return fmt.Errorf("failed to write vector index: %w", err)
+		}
 	}
 
 	return nil
 }
 
-// matchesFilters checks if metadata matches the given filters
-func (f *FileVectorStore) matchesFilters(metadata map[string]interface{}, filters map[string]interface{}) bool {
-	if filters == nil {
-		return true
-	}
-
-	for key, expectedValue := range filters {
-		if actualValue, exists := metadata[key]; !exists || actualValue != expectedValue {
-			return false
+// applyRecord replays one persisted change, updating the HNSW graph too when index is set
+// (assumes lock is held)
+func (f *FileVectorStore) applyRecord(record storeRecord, index bool) error {
+	switch record.op {
+	case vectorOpPut:
+		var metadata map[string]interface{}
+		embedding, err := decodeStoreValue(record.value, &metadata)
+		if err != nil {
+			return fmt.Errorf("failed to decode vector %s: %w", record.key, err)
+		}
+		if metadata == nil {
+			metadata = make(map[string]interface{})
+		}
+		f.vectors[record.key] = VectorStoreRecord{ID: record.key, Embedding: embedding, Metadata: metadata}
+		if index && f.index != nil {
+			f.index.insert(record.key, embedding)
+		}
+	case vectorOpDelete:
+		delete(f.vectors, record.key)
+		if index && f.index != nil {
+			f.index.remove(record.key)
 		}
+	case vectorOpMetadata:
+		existing, exists := f.vectors[record.key]
+		if !exists {
+			return nil
+		}
+		var metadata map[string]interface{}
+		if err := json.Unmarshal(record.value, &metadata); err != nil {
+			return fmt.Errorf("failed to decode metadata for vector %s: %w", record.key, err)
+		}
+		if metadata == nil {
+			metadata = make(map[string]interface{})
+		}
+		existing.Metadata = metadata
+		f.vectors[record.key] = existing
+	default:
+		return fmt.Errorf("unknown vector store operation %d", record.op)
 	}
-
-	return true
+	return nil
 }
 
-// cosineSimilarity calculates cosine similarity between two vectors
-func (f *FileVectorStore) cosineSimilarity(a, b []float32) float64 {
-	if len(a) != len(b) {
-		return 0.0
+// encodeVectorRecord encodes a vector as a put record
+func encodeVectorRecord(record VectorStoreRecord) (storeRecord, error) {
+	value, err := encodeStoreValue(record.Embedding, record.Metadata)
+	if err != nil {
+		return storeRecord{}, fmt.Errorf("failed to marshal vector %s: %w", record.ID, err)
 	}
+	return storeRecord{op: vectorOpPut, key: record.ID, value: value}, nil
+}
 
-	var dotProduct, normA, normB float64
-
-	for i := 0; i < len(a); i++ {
-		dotProduct += float64(a[i] * b[i])
-		normA += float64(a[i] * a[i])
-		normB += float64(b[i] * b[i])
+// searchIndex answers a top-k query from the HNSW graph, scoring the hits exactly (assumes lock is held)
+func (f *FileVectorStore) searchIndex(query []float32, k int, filter MetadataFilter) []VectorResult {
+	accept := func(id string) bool {
+		return filter.Matches(f.vectors[id].Metadata)
 	}
 
-	if normA == 0.0 || normB == 0.0 {
-		return 0.0
+	ids := f.index.search(query, k, f.config.EfSearch, accept)
+	results := make([]VectorResult, 0, len(ids))
+	for _, id := range ids {
+		record := f.vectors[id]
+		result := VectorResult{
+			ID:        record.ID,
+			Score:     f.config.Metric.Similarity(query, record.Embedding),
+			Embedding: record.Embedding,
+			Metadata:  record.Metadata,
+		}
+		if content, ok := record.Metadata["content"].(string); ok {
+			result.Content = content
+		}
+		results = append(results, result)
 	}
 
-	return dotProduct / (math.Sqrt(normA) * math.Sqrt(normB))
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results
 }
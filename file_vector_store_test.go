@@ -3,6 +3,8 @@ package main
 import (
 	"context"
 	"fmt"
+	"math/rand"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -72,6 +74,27 @@ func TestFileVectorStore(t *testing.T) {
 					So(result.Metadata["category"], ShouldEqual, "A")
 				}
 			})
+			
+			Convey("And tag filters should match after reloading from disk", func() {
+				So(store.Store(ctx, "tagged", []float32{0.5, 0.5, 0.0}, map[string]interface{}{"tags": []string{"go", "storage"}}), ShouldBeNil)
+				So(store.Save(), ShouldBeNil)
+				
+				reloaded := NewFileVectorStore(filePath)
+				So(reloaded.Load(), ShouldBeNil)
+				
+				filters := map[string]interface{}{"tags": map[string]interface{}{"contains": []interface{}{"go", "storage"}}}
+				results, err := reloaded.Search(ctx, query, 10, filters)
+				So(err, ShouldBeNil)
+				So(len(results), ShouldEqual, 1)
+				So(results[0].ID, ShouldEqual, "tagged")
+			})
+			
+			Convey("And malformed filters should be reported", func() {
+				filters := map[string]interface{}{"category": map[string]interface{}{"like": "A"}}
+				_, err := store.Search(ctx, query, 10, filters)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "invalid filters")
+			})
 		})
 		
 		Convey("When batch storing vectors", func() {
@@ -285,4 +308,325 @@ func BenchmarkFileVectorStore(b *testing.B) {
 			store.GetByID(ctx, id)
 		}
 	})
-}
\ No newline at end of file
+}
+func TestFileVectorStoreHNSW(t *testing.T) {
+	Convey("Given a FileVectorStore with an HNSW index", t, func() {
+		ctx := context.Background()
+		filePath := filepath.Join(t.TempDir(), "vectors.json")
+		config := DefaultFileVectorStoreConfig()
+		config.IndexType = VectorIndexHNSW
+		store := NewFileVectorStoreWithConfig(filePath, config)
+		flat := NewFileVectorStore(filepath.Join(t.TempDir(), "flat.json"))
+
+		items := randomVectorItems(1000, 24, 7)
+		So(store.BatchStore(ctx, items), ShouldBeNil)
+		So(flat.BatchStore(ctx, items), ShouldBeNil)
+		queries := randomVectorItems(50, 24, 11)
+
+		Convey("Then top-k searches should agree closely with the flat index", func() {
+			So(store.IndexType(), ShouldEqual, VectorIndexHNSW)
+			recall := vectorStoreRecall(ctx, store, flat, queries, 10, nil)
+			So(recall, ShouldBeGreaterThanOrEqualTo, 0.95)
+		})
+
+		Convey("Then filtered searches should only return matching vectors", func() {
+			filters := map[string]interface{}{"group": "g3"}
+			results, err := store.Search(ctx, queries[0].Embedding, 10, filters)
+			So(err, ShouldBeNil)
+			So(results, ShouldHaveLength, 10)
+			for _, result := range results {
+				So(result.Metadata["group"], ShouldEqual, "g3")
+			}
+			So(vectorStoreRecall(ctx, store, flat, queries, 10, filters), ShouldBeGreaterThanOrEqualTo, 0.95)
+		})
+
+		Convey("When vectors are deleted", func() {
+			for _, item := range items[:100] {
+				So(store.Delete(ctx, item.ID), ShouldBeNil)
+				So(flat.Delete(ctx, item.ID), ShouldBeNil)
+			}
+
+			Convey("Then they should no longer be found and recall should hold", func() {
+				results, err := store.Search(ctx, items[0].Embedding, 1, nil)
+				So(err, ShouldBeNil)
+				So(results[0].ID, ShouldNotEqual, items[0].ID)
+				So(vectorStoreRecall(ctx, store, flat, queries, 10, nil), ShouldBeGreaterThanOrEqualTo, 0.95)
+			})
+		})
+
+		Convey("When the store is reloaded", func() {
+			before, err := store.Search(ctx, queries[0].Embedding, 10, nil)
+			So(err, ShouldBeNil)
+
+			reloaded := NewFileVectorStoreWithConfig(filePath, config)
+			So(reloaded.Load(), ShouldBeNil)
+
+			Convey("Then the persisted graph should give the same results", func() {
+				_, err := os.Stat(filepath.Join(filepath.Dir(filePath), "vectors.hnsw.json"))
+				So(err, ShouldBeNil)
+				after, err := reloaded.Search(ctx, queries[0].Embedding, 10, nil)
+				So(err, ShouldBeNil)
+				So(after, ShouldResemble, before)
+			})
+		})
+
+		Convey("When the graph file is missing on load", func() {
+			So(os.Remove(filepath.Join(filepath.Dir(filePath), "vectors.hnsw.json")), ShouldBeNil)
+			reloaded := NewFileVectorStoreWithConfig(filePath, config)
+			So(reloaded.Load(), ShouldBeNil)
+
+			Convey("Then the graph should be rebuilt from the vectors", func() {
+				So(reloaded.index.len(), ShouldEqual, len(items))
+				So(vectorStoreRecall(ctx, reloaded, flat, queries, 10, nil), ShouldBeGreaterThanOrEqualTo, 0.95)
+			})
+		})
+	})
+}
+
+func TestHNSWIndexLinks(t *testing.T) {
+	Convey("Given an HNSW graph with vectors inserted and removed", t, func() {
+		index := newHNSWIndex(VectorMetricCosine, 8, 64, 32)
+		items := randomVectorItems(300, 16, 5)
+		vectors := make(map[string]VectorStoreRecord, len(items))
+		for _, item := range items {
+			index.insert(item.ID, item.Embedding)
+			vectors[item.ID] = VectorStoreRecord{ID: item.ID, Embedding: item.Embedding}
+		}
+		for _, item := range items[:100] {
+			index.remove(item.ID)
+			delete(vectors, item.ID)
+		}
+
+		// assertLinksMatch checks every link is recorded on both of its ends
+		assertLinksMatch := func(h *hnswIndex) {
+			for slot, node := range h.nodes {
+				if node == nil {
+					continue
+				}
+				for l := range node.neighbors {
+					for _, neighbor := range node.neighbors[l] {
+						So(h.nodes[neighbor], ShouldNotBeNil)
+						So(h.nodes[neighbor].linkedFrom[l][int32(slot)], ShouldBeTrue)
+					}
+					for from := range node.linkedFrom[l] {
+						So(h.nodes[from], ShouldNotBeNil)
+						So(h.nodes[from].neighbors[l], ShouldContain, int32(slot))
+					}
+				}
+			}
+		}
+
+		Convey("Then every node should know which nodes link to it", func() {
+			So(index.len(), ShouldEqual, 200)
+			assertLinksMatch(index)
+		})
+
+		Convey("Then a restored graph should rebuild the reverse links", func() {
+			restored := newHNSWIndex(VectorMetricCosine, 8, 64, 32)
+			So(restored.restore(index.export(), vectors), ShouldBeTrue)
+			assertLinksMatch(restored)
+		})
+	})
+}
+
+func BenchmarkFileVectorStoreIndexes(b *testing.B) {
+	ctx := context.Background()
+	items := randomVectorItems(10000, 64, 7)
+	queries := randomVectorItems(100, 64, 11)
+
+	flat := NewFileVectorStore(filepath.Join(b.TempDir(), "flat.json"))
+	flat.BatchStore(ctx, items)
+
+	config := DefaultFileVectorStoreConfig()
+	config.IndexType = VectorIndexHNSW
+	hnsw := NewFileVectorStoreWithConfig(filepath.Join(b.TempDir(), "hnsw.json"), config)
+	hnsw.BatchStore(ctx, items)
+
+	for _, store := range []*FileVectorStore{flat, hnsw} {
+		store := store
+		b.Run(store.IndexType(), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				store.Search(ctx, queries[i%len(queries)].Embedding, 10, nil)
+			}
+			b.StopTimer()
+			b.ReportMetric(vectorStoreRecall(ctx, store, flat, queries, 10, nil), "recall@10")
+		})
+	}
+}
+
+// randomVectorItems returns reproducible random vectors spread over ten metadata groups
+func randomVectorItems(n, dimensions int, seed int64) []VectorStoreItem {
+	rng := rand.New(rand.NewSource(seed))
+	items := make([]VectorStoreItem, n)
+	for i := range items {
+		embedding := make([]float32, dimensions)
+		for j := range embedding {
+			embedding[j] = float32(rng.NormFloat64())
+		}
+		items[i] = VectorStoreItem{
+			ID:        fmt.Sprintf("vec_%d_%d", seed, i),
+			Embedding: embedding,
+			Metadata:  map[string]interface{}{"group": fmt.Sprintf("g%d", i%10)},
+		}
+	}
+	return items
+}
+
+// vectorStoreRecall is the fraction of the exact top-k neighbors that a store returns
+func vectorStoreRecall(ctx context.Context, store, exact *FileVectorStore, queries []VectorStoreItem, k int, filters map[string]interface{}) float64 {
+	found, total := 0, 0
+	for _, query := range queries {
+		want, _ := exact.Search(ctx, query.Embedding, k, filters)
+		got, _ := store.Search(ctx, query.Embedding, k, filters)
+		ids := make(map[string]bool, len(got))
+		for _, result := range got {
+			ids[result.ID] = true
+		}
+		for _, result := range want {
+			if ids[result.ID] {
+				found++
+			}
+		}
+		total += len(want)
+	}
+	if total == 0 {
+		return 1
+	}
+	return float64(found) / float64(total)
+}
+
+func TestFileVectorStoreMetrics(t *testing.T) {
+	Convey("Given FileVectorStores configured with different metrics", t, func() {
+		ctx := context.Background()
+		items := []VectorStoreItem{
+			{ID: "near", Embedding: []float32{1, 1}},
+			{ID: "long", Embedding: []float32{5, 4}},
+		}
+		query := []float32{1, 0.9}
+
+		for _, indexType := range []string{VectorIndexFlat, VectorIndexHNSW} {
+			dotConfig := DefaultFileVectorStoreConfig()
+			dotConfig.Metric = VectorMetricDot
+			dotConfig.IndexType = indexType
+			dot := NewFileVectorStoreWithConfig(filepath.Join(t.TempDir(), "dot.json"), dotConfig)
+			So(dot.BatchStore(ctx, items), ShouldBeNil)
+
+			l2Config := DefaultFileVectorStoreConfig()
+			l2Config.Metric = VectorMetricL2
+			l2Config.IndexType = indexType
+			l2 := NewFileVectorStoreWithConfig(filepath.Join(t.TempDir(), "l2.json"), l2Config)
+			So(l2.BatchStore(ctx, items), ShouldBeNil)
+
+			Convey("Then a "+indexType+" index should rank by the configured metric", func() {
+				dotResults, err := dot.Search(ctx, query, 1, nil)
+				So(err, ShouldBeNil)
+				So(dotResults[0].ID, ShouldEqual, "long")
+				So(dotResults[0].Score, ShouldAlmostEqual, 8.6, 1e-5)
+
+				l2Results, err := l2.Search(ctx, query, 1, nil)
+				So(err, ShouldBeNil)
+				So(l2Results[0].ID, ShouldEqual, "near")
+				So(l2.Metric(), ShouldEqual, VectorMetricL2)
+			})
+		}
+	})
+}
+
+func TestFileVectorStorePersistence(t *testing.T) {
+	Convey("Given a loaded FileVectorStore", t, func() {
+		ctx := context.Background()
+		filePath := filepath.Join(t.TempDir(), "vectors.json")
+		config := DefaultFileVectorStoreConfig()
+		config.IndexType = VectorIndexHNSW
+		store := NewFileVectorStoreWithConfig(filePath, config)
+		So(store.Load(), ShouldBeNil)
+
+		So(store.BatchStore(ctx, randomVectorItems(50, 8, 3)), ShouldBeNil)
+		So(store.Save(), ShouldBeNil)
+		snapshot, err := os.Stat(filePath)
+		So(err, ShouldBeNil)
+
+		So(store.Store(ctx, "added", []float32{1, 2, 3, 4, 5, 6, 7, 8}, map[string]interface{}{"content": "added"}), ShouldBeNil)
+		So(store.Update(ctx, "vec_3_0", map[string]interface{}{"content": "updated"}), ShouldBeNil)
+		So(store.Delete(ctx, "vec_3_1"), ShouldBeNil)
+
+		Convey("When changes are made after a snapshot", func() {
+			Convey("Then they should be appended to the log rather than rewriting the snapshot", func() {
+				after, err := os.Stat(filePath)
+				So(err, ShouldBeNil)
+				So(after.ModTime(), ShouldEqual, snapshot.ModTime())
+				So(after.Size(), ShouldEqual, snapshot.Size())
+				_, err = os.Stat(filePath + ".log")
+				So(err, ShouldBeNil)
+			})
+
+			Convey("Then reloading should replay them over the snapshot and its graph", func() {
+				reloaded := NewFileVectorStoreWithConfig(filePath, config)
+				So(reloaded.Load(), ShouldBeNil)
+
+				count, err := reloaded.Count(ctx)
+				So(err, ShouldBeNil)
+				So(count, ShouldEqual, 50)
+				_, err = reloaded.GetByID(ctx, "vec_3_1")
+				So(err, ShouldNotBeNil)
+				updated, err := reloaded.GetByID(ctx, "vec_3_0")
+				So(err, ShouldBeNil)
+				So(updated.Content, ShouldEqual, "updated")
+				So(updated.Embedding, ShouldResemble, store.vectors["vec_3_0"].Embedding)
+				So(reloaded.index.len(), ShouldEqual, 50)
+
+				results, err := reloaded.Search(ctx, []float32{1, 2, 3, 4, 5, 6, 7, 8}, 1, nil)
+				So(err, ShouldBeNil)
+				So(results[0].ID, ShouldEqual, "added")
+			})
+		})
+
+		Convey("When the store is closed", func() {
+			So(store.Close(), ShouldBeNil)
+
+			Convey("Then the log should be compacted into the snapshot", func() {
+				_, err := os.Stat(filePath + ".log")
+				So(os.IsNotExist(err), ShouldBeTrue)
+
+				reloaded := NewFileVectorStoreWithConfig(filePath, config)
+				So(reloaded.Load(), ShouldBeNil)
+				count, err := reloaded.Count(ctx)
+				So(err, ShouldBeNil)
+				So(count, ShouldEqual, 50)
+			})
+		})
+	})
+
+	Convey("Given a vectors file in the JSON format", t, func() {
+		ctx := context.Background()
+		filePath := filepath.Join(t.TempDir(), "vectors.json")
+		legacy := `{"doc1": {"id": "doc1", "embedding": [0.5, 0.25], "metadata": {"content": "legacy"}}}`
+		So(os.WriteFile(filePath, []byte(legacy), 0644), ShouldBeNil)
+
+		store := NewFileVectorStore(filePath)
+		So(store.Load(), ShouldBeNil)
+
+		Convey("Then its vectors should load", func() {
+			result, err := store.GetByID(ctx, "doc1")
+			So(err, ShouldBeNil)
+			So(result.Content, ShouldEqual, "legacy")
+			So(result.Embedding, ShouldResemble, []float32{0.5, 0.25})
+		})
+
+		Convey("When the store is next changed", func() {
+			So(store.Store(ctx, "doc2", []float32{1, 0}, nil), ShouldBeNil)
+
+			Convey("Then the file should be rewritten as a binary snapshot", func() {
+				data, err := os.ReadFile(filePath)
+				So(err, ShouldBeNil)
+				So(string(data[:len(storeLogMagic)]), ShouldEqual, string(storeLogMagic))
+
+				reloaded := NewFileVectorStore(filePath)
+				So(reloaded.Load(), ShouldBeNil)
+				count, err := reloaded.Count(ctx)
+				So(err, ShouldBeNil)
+				So(count, ShouldEqual, 2)
+			})
+		})
+	})
+}
@@ -15,6 +15,7 @@ const (
 	TaskNode         NodeType = "Task"
 	ConversationNode NodeType = "ConversationTurn"
 	SourceNode       NodeType = "Source"
+	ChunkNode        NodeType = "Chunk"
 )
 
 // EdgeType represents the type of a graph edge
@@ -141,12 +142,41 @@ func (n *Node) SetEmbedding(embedding []float32) {
 	n.UpdatedAt = time.Now()
 }
 
+// allNodeTypes lists every supported node type
+var allNodeTypes = []NodeType{
+	EntityNode, ClaimNode, EventNode, TaskNode, ConversationNode, SourceNode, ChunkNode,
+}
+
+// allEdgeTypes lists every supported edge type
+var allEdgeTypes = []EdgeType{
+	RelatedTo, PartOf, Supports, Refutes, TemporalNext, CausedBy,
+}
+
+// pageRankTeleport spreads total teleport mass evenly over the seeds that exist in nodes,
+// returning nil when there are none so callers fall back to uniform PageRank
+func pageRankTeleport(nodes map[string]*Node, seeds []string, total float64) map[string]float64 {
+	var valid []string
+	seen := make(map[string]bool)
+	for _, seed := range seeds {
+		if _, exists := nodes[seed]; exists && !seen[seed] {
+			seen[seed] = true
+			valid = append(valid, seed)
+		}
+	}
+	if len(valid) == 0 {
+		return nil
+	}
+
+	teleport := make(map[string]float64, len(valid))
+	for _, seed := range valid {
+		teleport[seed] = total / float64(len(valid))
+	}
+	return teleport
+}
+
 // isValidNodeType checks if the given node type is valid
 func isValidNodeType(nodeType NodeType) bool {
-	validTypes := []NodeType{
-		EntityNode, ClaimNode, EventNode, TaskNode, ConversationNode, SourceNode,
-	}
-	for _, validType := range validTypes {
+	for _, validType := range allNodeTypes {
 		if nodeType == validType {
 			return true
 		}
@@ -156,10 +186,7 @@ func isValidNodeType(nodeType NodeType) bool {
 
 // isValidEdgeType checks if the given edge type is valid
 func isValidEdgeType(edgeType EdgeType) bool {
-	validTypes := []EdgeType{
-		RelatedTo, PartOf, Supports, Refutes, TemporalNext, CausedBy,
-	}
-	for _, validType := range validTypes {
+	for _, validType := range allEdgeTypes {
 		if edgeType == validType {
 			return true
 		}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GraphSearcher retrieves chunks reachable from entities mentioned in a query
+type GraphSearcher struct {
+	graphStore  GraphStore
+	searchIndex SearchIndex
+	config      *GraphSearchConfig
+}
+
+// GraphSearchConfig holds configuration for graph retrieval
+type GraphSearchConfig struct {
+	MaxDepth          int     `json:"max_depth"`
+	MaxSeeds          int     `json:"max_seeds"`
+	MaxResults        int     `json:"max_results"`
+	PageRankAlpha     float64 `json:"pagerank_alpha"`
+	PageRankMaxIter   int     `json:"pagerank_max_iter"`
+	PageRankTolerance float64 `json:"pagerank_tolerance"`
+}
+
+// GraphRetrievalResult is a chunk reached from a query entity through the graph
+type GraphRetrievalResult struct {
+	ID       string                 `json:"id"`
+	Content  string                 `json:"content"`
+	Score    float64                `json:"score"`
+	Path     []string               `json:"path"`
+	Seeds    []string               `json:"seeds"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+// GraphSearchResponse contains the complete graph retrieval results
+type GraphSearchResponse struct {
+	Results    []GraphRetrievalResult `json:"results"`
+	QueryTime  float64                `json:"query_time_ms"`
+	TotalFound int                    `json:"total_found"`
+	Metadata   map[string]interface{} `json:"metadata"`
+}
+
+// NewGraphSearcher creates a new GraphSearcher instance
+func NewGraphSearcher() *GraphSearcher {
+	return NewGraphSearcherWithStore(nil, nil, nil)
+}
+
+// NewGraphSearcherWithStore creates a GraphSearcher over a graph store, reading chunk content from the search index
+func NewGraphSearcherWithStore(graphStore GraphStore, searchIndex SearchIndex, config *GraphSearchConfig) *GraphSearcher {
+	cfg := GraphSearchConfig{}
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.MaxDepth <= 0 {
+		cfg.MaxDepth = 2
+	}
+	if cfg.MaxSeeds <= 0 {
+		cfg.MaxSeeds = 10
+	}
+	if cfg.MaxResults <= 0 {
+		cfg.MaxResults = 100
+	}
+	if cfg.PageRankAlpha <= 0 || cfg.PageRankAlpha >= 1 {
+		cfg.PageRankAlpha = 0.85
+	}
+	if cfg.PageRankMaxIter <= 0 {
+		cfg.PageRankMaxIter = 50
+	}
+	if cfg.PageRankTolerance <= 0 {
+		cfg.PageRankTolerance = 1e-6
+	}
+
+	return &GraphSearcher{
+		graphStore:  graphStore,
+		searchIndex: searchIndex,
+		config:      &cfg,
+	}
+}
+
+// Search links query entities to entity nodes, expands their neighborhood and ranks
+// the chunks reached with PageRank personalized to those entities. Chunks whose
+// metadata does not satisfy filters are dropped.
+func (gs *GraphSearcher) Search(ctx context.Context, entities []string, k int, filters map[string]interface{}) (*GraphSearchResponse, error) {
+	startTime := time.Now()
+
+	if gs.graphStore == nil {
+		return nil, fmt.Errorf("graph store not initialized")
+	}
+
+	if k <= 0 || k > gs.config.MaxResults {
+		k = gs.config.MaxResults
+	}
+
+	filter, err := ParseMetadataFilter(filters)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filters: %w", err)
+	}
+
+	response := &GraphSearchResponse{
+		Results:  []GraphRetrievalResult{},
+		Metadata: make(map[string]interface{}),
+	}
+
+	seeds, err := gs.LinkEntities(ctx, entities)
+	if err != nil {
+		return nil, fmt.Errorf("entity linking failed: %w", err)
+	}
+	response.Metadata["seed_count"] = len(seeds)
+	if len(seeds) == 0 {
+		return response, nil
+	}
+
+	seedIDs := make([]string, len(seeds))
+	for i, seed := range seeds {
+		seedIDs[i] = seed.ID
+	}
+
+	scores, err := gs.graphStore.PageRank(ctx, PageRankOptions{
+		Alpha:     gs.config.PageRankAlpha,
+		MaxIter:   gs.config.PageRankMaxIter,
+		Tolerance: gs.config.PageRankTolerance,
+		Seeds:     seedIDs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("personalized pagerank failed: %w", err)
+	}
+
+	reached := gs.expand(ctx, seeds)
+
+	// Each chunk takes the score and path of the best-ranked node that references it
+	chunks := make(map[string]*GraphRetrievalResult)
+	for _, visit := range reached {
+		score := scores[visit.node.ID]
+		for _, chunkID := range nodeChunkIDs(ctx, gs.graphStore, visit.node) {
+			existing, exists := chunks[chunkID]
+			if exists && existing.Score >= score {
+				continue
+			}
+
+			// A chunk node reached directly already ends its own path
+			path := append([]string{}, visit.path...)
+			if visit.node.ID != chunkID {
+				path = append(path, chunkID)
+			}
+			chunks[chunkID] = &GraphRetrievalResult{
+				ID:    chunkID,
+				Score: score,
+				Path:  path,
+				Seeds: []string{visit.path[0]},
+			}
+		}
+	}
+
+	for _, result := range chunks {
+		if gs.searchIndex != nil {
+			doc, err := gs.searchIndex.GetDocument(ctx, result.ID)
+			if err != nil {
+				// The node outlived its chunk; nothing to return
+				continue
+			}
+			if !filter.Matches(doc.Metadata) {
+				continue
+			}
+			result.Content = doc.Content
+			result.Metadata = copyGraphMetadata(doc.Metadata)
+		} else if len(filters) > 0 {
+			// Without chunk metadata the filters cannot be checked
+			continue
+		} else {
+			result.Metadata = make(map[string]interface{})
+		}
+		result.Metadata["graph_path"] = result.Path
+		response.Results = append(response.Results, *result)
+	}
+
+	sort.Slice(response.Results, func(i, j int) bool {
+		if response.Results[i].Score == response.Results[j].Score {
+			return response.Results[i].ID < response.Results[j].ID
+		}
+		return response.Results[i].Score > response.Results[j].Score
+	})
+	if len(response.Results) > k {
+		response.Results = response.Results[:k]
+	}
+
+	response.TotalFound = len(response.Results)
+	response.QueryTime = float64(time.Since(startTime).Nanoseconds()) / 1e6
+	response.Metadata["reached_nodes"] = len(reached)
+	response.Metadata["max_depth"] = gs.config.MaxDepth
+
+	return response, nil
+}
+
+// LinkEntities matches query entity mentions to entity nodes by name
+func (gs *GraphSearcher) LinkEntities(ctx context.Context, mentions []string) ([]*Node, error) {
+	if len(mentions) == 0 {
+		return []*Node{}, nil
+	}
+
+	nodes, err := gs.graphStore.FindNodesByType(ctx, EntityNode, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	type linkedNode struct {
+		node  *Node
+		score float64
+	}
+
+	var linked []linkedNode
+	for _, node := range nodes {
+		name, _ := node.Properties["name"].(string)
+		if name == "" {
+			continue
+		}
+
+		best := 0.0
+		for _, mention := range mentions {
+			if score := entityLinkScore(mention, name); score > best {
+				best = score
+			}
+		}
+		if best > 0 {
+			linked = append(linked, linkedNode{node: node, score: best})
+		}
+	}
+
+	sort.Slice(linked, func(i, j int) bool {
+		if linked[i].score == linked[j].score {
+			return linked[i].node.ID < linked[j].node.ID
+		}
+		return linked[i].score > linked[j].score
+	})
+
+	seeds := make([]*Node, 0, len(linked))
+	for _, link := range linked {
+		seeds = append(seeds, link.node)
+		if len(seeds) >= gs.config.MaxSeeds {
+			break
+		}
+	}
+
+	return seeds, nil
+}
+
+// graphVisit is a node reached during expansion and the path of node IDs leading to it
+type graphVisit struct {
+	node *Node
+	path []string
+}
+
+// expand walks breadth-first from the seeds up to MaxDepth hops, keeping the shortest path to each node
+func (gs *GraphSearcher) expand(ctx context.Context, seeds []*Node) []graphVisit {
+	visited := make(map[string]bool)
+	var reached []graphVisit
+	var frontier []graphVisit
+
+	for _, seed := range seeds {
+		if visited[seed.ID] {
+			continue
+		}
+		visited[seed.ID] = true
+		visit := graphVisit{node: seed, path: []string{seed.ID}}
+		reached = append(reached, visit)
+		frontier = append(frontier, visit)
+	}
+
+	for depth := 0; depth < gs.config.MaxDepth && len(frontier) > 0; depth++ {
+		var next []graphVisit
+		for _, current := range frontier {
+			neighbors, err := gs.graphStore.GetNeighbors(ctx, current.node.ID, GraphTraversalOptions{MaxDepth: 1})
+			if err != nil {
+				continue
+			}
+			for i := range neighbors {
+				neighbor := neighbors[i]
+				if visited[neighbor.ID] {
+					continue
+				}
+				visited[neighbor.ID] = true
+				visit := graphVisit{
+					node: &neighbor,
+					path: append(append([]string{}, current.path...), neighbor.ID),
+				}
+				reached = append(reached, visit)
+				next = append(next, visit)
+			}
+		}
+		frontier = next
+	}
+
+	return reached
+}
+
+// entityLinkScore scores how well a query mention names an entity: 1 for an exact
+// match, 0.5 when every mention token appears in the name, otherwise 0
+func entityLinkScore(mention, name string) float64 {
+	mention = strings.ToLower(strings.TrimSpace(mention))
+	name = strings.ToLower(strings.TrimSpace(name))
+	if mention == "" || name == "" {
+		return 0
+	}
+	if mention == name {
+		return 1.0
+	}
+
+	nameTokens := make(map[string]bool)
+	for _, token := range strings.Fields(name) {
+		nameTokens[token] = true
+	}
+	for _, token := range strings.Fields(mention) {
+		if !nameTokens[token] {
+			return 0
+		}
+	}
+
+	return 0.5
+}
+
+// copyGraphMetadata copies document metadata so the graph path can be attached safely
+func copyGraphMetadata(source map[string]interface{}) map[string]interface{} {
+	target := make(map[string]interface{}, len(source)+1)
+	for key, value := range source {
+		target[key] = value
+	}
+	return target
+}
+
+// GetConfig returns the current configuration
+func (gs *GraphSearcher) GetConfig() *GraphSearchConfig {
+	return gs.config
+}
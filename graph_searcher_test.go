@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// createTestEntity creates an entity node named name that is PART_OF a node for each of chunkIDs
+func createTestEntity(ctx context.Context, graphStore GraphStore, id, name string, chunkIDs ...string) error {
+	node := NewNode(id, EntityNode)
+	node.Properties["name"] = name
+	if err := graphStore.CreateNode(ctx, node); err != nil {
+		return err
+	}
+	for _, chunkID := range chunkIDs {
+		if _, err := graphStore.GetNode(ctx, chunkID); err != nil {
+			chunk := NewNode(chunkID, ChunkNode)
+			chunk.Properties["chunk_id"] = chunkID
+			if err := graphStore.CreateNode(ctx, chunk); err != nil {
+				return err
+			}
+		}
+		if err := linkOnce(ctx, graphStore, id, chunkID, PartOf, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestGraphSearcher(t *testing.T) {
+	Convey("Given a GraphSearcher over linked entities", t, func() {
+		ctx := context.Background()
+		graphStore := NewMockGraphStore()
+		searchIndex := NewMockSearchIndex()
+		searcher := NewGraphSearcherWithStore(graphStore, searchIndex, nil)
+
+		for _, doc := range []IndexDocument{
+			{ID: "chunk_paris", Content: "Paris is the capital of France.", Metadata: map[string]interface{}{"source": "geo"}},
+			{ID: "chunk_france", Content: "France is a country in Europe.", Metadata: map[string]interface{}{"source": "geo"}},
+			{ID: "chunk_europe", Content: "Europe is a continent.", Metadata: map[string]interface{}{"source": "geo"}},
+			{ID: "chunk_tokyo", Content: "Tokyo is the capital of Japan.", Metadata: map[string]interface{}{"source": "geo"}},
+		} {
+			So(searchIndex.Index(ctx, doc), ShouldBeNil)
+		}
+
+		So(createTestEntity(ctx, graphStore, "paris", "Paris", "chunk_paris"), ShouldBeNil)
+		So(createTestEntity(ctx, graphStore, "france", "France", "chunk_france"), ShouldBeNil)
+		So(createTestEntity(ctx, graphStore, "europe", "Europe", "chunk_europe"), ShouldBeNil)
+		So(createTestEntity(ctx, graphStore, "tokyo", "Tokyo", "chunk_tokyo"), ShouldBeNil)
+		So(createTestEntity(ctx, graphStore, "eiffel", "Eiffel Tower", "chunk_deleted"), ShouldBeNil)
+		So(graphStore.CreateEdge(ctx, NewEdge("paris_france", "paris", "france", PartOf, 1.0)), ShouldBeNil)
+		So(graphStore.CreateEdge(ctx, NewEdge("france_europe", "france", "europe", PartOf, 1.0)), ShouldBeNil)
+
+		Convey("When searching from a query entity", func() {
+			response, err := searcher.Search(ctx, []string{"Paris"}, 10, nil)
+			So(err, ShouldBeNil)
+
+			Convey("Then chunks reachable from the seed should be ranked by personalized PageRank", func() {
+				So(len(response.Results), ShouldEqual, 3)
+				So(response.Results[0].ID, ShouldEqual, "chunk_paris")
+				So(response.Results[0].Content, ShouldEqual, "Paris is the capital of France.")
+				for _, result := range response.Results {
+					So(result.ID, ShouldNotEqual, "chunk_tokyo")
+					So(result.Seeds, ShouldResemble, []string{"paris"})
+				}
+			})
+
+			Convey("Then each result should carry the path that reached it", func() {
+				paths := make(map[string][]string)
+				for _, result := range response.Results {
+					paths[result.ID] = result.Path
+				}
+				So(paths["chunk_paris"], ShouldResemble, []string{"paris", "chunk_paris"})
+				So(paths["chunk_france"], ShouldResemble, []string{"paris", "france", "chunk_france"})
+				So(paths["chunk_europe"], ShouldResemble, []string{"paris", "france", "europe", "chunk_europe"})
+				So(response.Results[1].Metadata["graph_path"], ShouldResemble, response.Results[1].Path)
+				So(response.Results[1].Metadata["source"], ShouldEqual, "geo")
+			})
+		})
+
+		Convey("When searching with metadata filters", func() {
+			So(searchIndex.Index(ctx, IndexDocument{ID: "chunk_france", Content: "France is a country in Europe.", Metadata: map[string]interface{}{"source": "atlas"}}), ShouldBeNil)
+			response, err := searcher.Search(ctx, []string{"Paris"}, 10, map[string]interface{}{"source": "geo"})
+			So(err, ShouldBeNil)
+
+			Convey("Then chunks whose metadata does not match should be dropped", func() {
+				ids := make([]string, 0, len(response.Results))
+				for _, result := range response.Results {
+					ids = append(ids, result.ID)
+				}
+				So(ids, ShouldContain, "chunk_paris")
+				So(ids, ShouldNotContain, "chunk_france")
+			})
+		})
+
+		Convey("When the expansion depth is limited", func() {
+			shallow := NewGraphSearcherWithStore(graphStore, searchIndex, &GraphSearchConfig{MaxDepth: 1})
+			response, err := shallow.Search(ctx, []string{"Paris"}, 10, nil)
+			So(err, ShouldBeNil)
+
+			Convey("Then only nodes within that many hops should contribute", func() {
+				So(len(response.Results), ShouldEqual, 2)
+			})
+		})
+
+		Convey("When linking entity mentions", func() {
+			seeds, err := searcher.LinkEntities(ctx, []string{"eiffel", "TOKYO"})
+			So(err, ShouldBeNil)
+
+			Convey("Then exact names should rank above partial matches", func() {
+				So(len(seeds), ShouldEqual, 2)
+				So(seeds[0].ID, ShouldEqual, "tokyo")
+				So(seeds[1].ID, ShouldEqual, "eiffel")
+			})
+		})
+
+		Convey("When a linked entity references a deleted chunk", func() {
+			response, err := searcher.Search(ctx, []string{"Eiffel Tower"}, 10, nil)
+			So(err, ShouldBeNil)
+
+			Convey("Then it should be skipped", func() {
+				So(response.Results, ShouldBeEmpty)
+				So(response.Metadata["seed_count"], ShouldEqual, 1)
+			})
+		})
+
+		Convey("When no entity matches the query", func() {
+			response, err := searcher.Search(ctx, []string{"Atlantis"}, 10, nil)
+			So(err, ShouldBeNil)
+
+			Convey("Then no results should be returned", func() {
+				So(response.Results, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the searcher has no graph store", func() {
+			_, err := NewGraphSearcher().Search(ctx, []string{"Paris"}, 10, nil)
+
+			Convey("Then it should return an error", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
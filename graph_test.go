@@ -204,7 +204,7 @@ func TestEdge(t *testing.T) {
 func TestNodeTypeValidation(t *testing.T) {
 	Convey("Given node type validation", t, func() {
 		validTypes := []NodeType{
-			EntityNode, ClaimNode, EventNode, TaskNode, ConversationNode, SourceNode,
+			EntityNode, ClaimNode, EventNode, TaskNode, ConversationNode, SourceNode, ChunkNode,
 		}
 		
 		Convey("When checking valid node types", func() {
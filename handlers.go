@@ -13,8 +13,10 @@ type RecallArgs struct {
 	Query        string                 `json:"query" jsonschema:"Query to search for in memory"`
 	MaxResults   int                    `json:"maxResults,omitempty" jsonschema:"Maximum number of results to return"`
 	TimeBudget   int                    `json:"timeBudget,omitempty" jsonschema:"Time budget in milliseconds"`
-	Filters      map[string]interface{} `json:"filters,omitempty" jsonschema:"Additional filters to apply"`
+	Filters      map[string]interface{} `json:"filters,omitempty" jsonschema:"Metadata filters: field values for equality, or operator objects using eq, ne, in, range, exists, prefix, contains, combined with and, or and not"`
 	IncludeGraph bool                   `json:"includeGraph,omitempty" jsonschema:"Include graph relationships in response"`
+	Language     string                 `json:"language,omitempty" jsonschema:"Language code of the query, used to analyze keyword terms (default en)"`
+	Facets       []string               `json:"facets,omitempty" jsonschema:"Fields to count over the retrieved candidates, e.g. source, tags, content_type, entity_types, or timestamp:day, timestamp:week, timestamp:month for a date histogram"`
 }
 
 type WriteArgs struct {
@@ -23,13 +25,19 @@ type WriteArgs struct {
 	Tags            []string               `json:"tags,omitempty" jsonschema:"Tags to associate with content"`
 	Metadata        map[string]interface{} `json:"metadata,omitempty" jsonschema:"Additional metadata"`
 	RequireEvidence bool                   `json:"requireEvidence,omitempty" jsonschema:"Require evidence for claims"`
+	ExternalID      string                 `json:"externalId,omitempty" jsonschema:"Stable ID of the document; writing it again replaces the previous version"`
+	IdempotencyKey  string                 `json:"idempotencyKey,omitempty" jsonschema:"Key identifying this write; a retry with the same key returns the original result"`
+	Reingest        bool                   `json:"reingest,omitempty" jsonschema:"Treat the content as the full new text of its source: unchanged chunks are kept, new ones stored and vanished ones deleted"`
 }
 
 type ManageArgs struct {
-	Operation  string   `json:"operation" jsonschema:"Operation to perform (pin, forget, decay)"`
+	Operation  string   `json:"operation" jsonschema:"Operation to perform (pin, forget, decay, merge)"`
 	MemoryIDs  []string `json:"memoryIds,omitempty" jsonschema:"Memory IDs to operate on"`
 	Query      string   `json:"query,omitempty" jsonschema:"Query to select memories"`
 	Confidence float64  `json:"confidence,omitempty" jsonschema:"Confidence threshold"`
+	TTL        string   `json:"ttl,omitempty" jsonschema:"Decay half-life as a duration, e.g. 720h"`
+	Force      bool     `json:"force,omitempty" jsonschema:"Apply the operation to pinned memories"`
+	DryRun     bool     `json:"dryRun,omitempty" jsonschema:"Preview the operation without changing memory"`
 }
 
 type StatsArgs struct {
@@ -39,26 +47,34 @@ type StatsArgs struct {
 
 // Tool result structures
 type RecallResult struct {
-	Evidence       []Evidence      `json:"evidence"`
-	CommunityCards []CommunityCard `json:"communityCards,omitempty"`
-	Conflicts      []ConflictInfo  `json:"conflicts,omitempty"`
-	Stats          RetrievalStats  `json:"stats"`
-	SelfCritique   string          `json:"selfCritique,omitempty"`
+	Evidence       []Evidence         `json:"evidence"`
+	CommunityCards []CommunityCard    `json:"communityCards,omitempty"`
+	Conflicts      []ConflictInfo     `json:"conflicts,omitempty"`
+	Stats          RetrievalStats     `json:"stats"`
+	SelfCritique   string             `json:"selfCritique,omitempty"`
+	Facets         map[string][]Facet `json:"facets,omitempty"`
 }
 
 type WriteResult struct {
-	MemoryID       string         `json:"memoryId"`
-	CandidateCount int            `json:"candidateCount"`
-	ConflictsFound []ConflictInfo `json:"conflictsFound,omitempty"`
-	EntitiesLinked []string       `json:"entitiesLinked"`
-	ProvenanceID   string         `json:"provenanceId"`
+	MemoryID        string         `json:"memoryId"`
+	CandidateCount  int            `json:"candidateCount"`
+	ConflictsFound  []ConflictInfo `json:"conflictsFound,omitempty"`
+	EntitiesLinked  []string       `json:"entitiesLinked"`
+	ProvenanceID    string         `json:"provenanceId"`
+	ChunksCreated   int            `json:"chunksCreated"`
+	ChunksUnchanged int            `json:"chunksUnchanged,omitempty"`
+	ChunksRemoved   int            `json:"chunksRemoved,omitempty"`
+	GraphUpdates    GraphUpdates   `json:"graphUpdates"`
 }
 
 type ManageResult struct {
-	Operation     string `json:"operation"`
-	AffectedCount int    `json:"affectedCount"`
-	Success       bool   `json:"success"`
-	Message       string `json:"message"`
+	Operation     string   `json:"operation"`
+	AffectedCount int      `json:"affectedCount"`
+	Success       bool     `json:"success"`
+	Message       string   `json:"message"`
+	DryRun        bool     `json:"dryRun,omitempty"`
+	Preview       []string `json:"preview,omitempty"`
+	Errors        []string `json:"errors,omitempty"`
 }
 
 type StatsResult struct {
@@ -68,11 +84,15 @@ type StatsResult struct {
 	VectorDimensions int                    `json:"vectorDimensions"`
 	StorageUsage     map[string]interface{} `json:"storageUsage"`
 	PerformanceStats map[string]interface{} `json:"performanceStats"`
+	MemoryStats      MemoryStats            `json:"memoryStats"`
+	SystemHealth     SystemHealthStats      `json:"systemHealth"`
 }
 
 // Placeholder data structures (will be implemented in later tasks)
 type Evidence struct {
+	MemoryID    string            `json:"memory_id,omitempty"` // Chunk the evidence was drawn from
 	Content     string            `json:"content"`
+	Breadcrumb  string            `json:"breadcrumb,omitempty"` // Headings a Markdown chunk falls under
 	Source      string            `json:"source"`
 	Confidence  float64           `json:"confidence"`
 	WhySelected string            `json:"why_selected"`
@@ -130,7 +150,7 @@ func (ams *AgenticMemoryServer) registerTools() error {
 	// Register memory_manage tool
 	mcp.AddTool(ams.server, &mcp.Tool{
 		Name:        "memory_manage",
-		Description: "Manage memory lifecycle (pin, forget, decay operations)",
+		Description: "Manage memory lifecycle (pin, forget, decay, merge operations)",
 	}, ams.handleManage)
 
 	// Register memory_stats tool
@@ -219,9 +239,14 @@ func (ams *AgenticMemoryServer) handleWrite(ctx context.Context, req *mcp.CallTo
 
 // handleManage handles memory management requests
 func (ams *AgenticMemoryServer) handleManage(ctx context.Context, req *mcp.CallToolRequest, args ManageArgs) (*mcp.CallToolResult, ManageResult, error) {
+	// Use the dedicated manage handler if available
+	if ams.manageHandler != nil {
+		return ams.manageHandler.HandleManage(ctx, req, args)
+	}
+
+	// Fallback to placeholder implementation
 	log.Printf("Handling manage request: operation=%s, memoryIds=%v", args.Operation, args.MemoryIDs)
 
-	// Placeholder implementation - will be replaced with actual governance engine
 	result := ManageResult{
 		Operation:     args.Operation,
 		AffectedCount: len(args.MemoryIDs),
@@ -241,9 +266,14 @@ func (ams *AgenticMemoryServer) handleManage(ctx context.Context, req *mcp.CallT
 
 // handleStats handles memory statistics requests
 func (ams *AgenticMemoryServer) handleStats(ctx context.Context, req *mcp.CallToolRequest, args StatsArgs) (*mcp.CallToolResult, StatsResult, error) {
+	// Use the dedicated stats handler if available
+	if ams.statsHandler != nil {
+		return ams.statsHandler.HandleStats(ctx, req, args)
+	}
+
+	// Fallback to placeholder implementation
 	log.Printf("Handling stats request: includePerformance=%t, includeStorage=%t", args.IncludePerformance, args.IncludeStorage)
 
-	// Placeholder implementation - will be replaced with actual stats collection
 	result := StatsResult{
 		TotalMemories:    0,
 		GraphNodes:       0,
@@ -155,10 +155,21 @@ func TestHandleManage(t *testing.T) {
 		ctx := context.Background()
 		req := &mcp.CallToolRequest{}
 
+		_, first, err := server.handleWrite(ctx, req, WriteArgs{
+			Content: "The Eiffel Tower is located in Paris and was completed in 1889.",
+			Source:  "manage-test-landmarks",
+		})
+		So(err, ShouldBeNil)
+		_, second, err := server.handleWrite(ctx, req, WriteArgs{
+			Content: "Photosynthesis converts sunlight into chemical energy in plants.",
+			Source:  "manage-test-biology",
+		})
+		So(err, ShouldBeNil)
+
 		Convey("When handling a pin operation", func() {
 			args := ManageArgs{
 				Operation: "pin",
-				MemoryIDs: []string{"mem_1", "mem_2"},
+				MemoryIDs: []string{first.MemoryID, second.MemoryID},
 			}
 
 			result, manageResult, err := server.handleManage(ctx, req, args)
@@ -176,7 +187,7 @@ func TestHandleManage(t *testing.T) {
 		Convey("When handling a forget operation", func() {
 			args := ManageArgs{
 				Operation: "forget",
-				MemoryIDs: []string{"mem_3"},
+				MemoryIDs: []string{first.MemoryID},
 			}
 
 			result, manageResult, err := server.handleManage(ctx, req, args)
@@ -187,6 +198,26 @@ func TestHandleManage(t *testing.T) {
 				So(manageResult.Operation, ShouldEqual, "forget")
 				So(manageResult.AffectedCount, ShouldEqual, 1)
 				So(manageResult.Success, ShouldBeTrue)
+
+				exists, err := server.storage.searchIndex.DocumentExists(ctx, first.MemoryID)
+				So(err, ShouldBeNil)
+				So(exists, ShouldBeFalse)
+			})
+		})
+
+		Convey("When handling an operation on unknown memory IDs", func() {
+			args := ManageArgs{
+				Operation: "pin",
+				MemoryIDs: []string{"mem_missing"},
+			}
+
+			_, manageResult, err := server.handleManage(ctx, req, args)
+
+			Convey("Then it should report the missing memory", func() {
+				So(err, ShouldBeNil)
+				So(manageResult.AffectedCount, ShouldEqual, 0)
+				So(manageResult.Success, ShouldBeFalse)
+				So(manageResult.Errors[0], ShouldContainSubstring, "memory not found")
 			})
 		})
 
@@ -271,6 +302,23 @@ func TestHandleStats(t *testing.T) {
 				So(statsResult.StorageUsage, ShouldContainKey, "search_index")
 				So(statsResult.PerformanceStats, ShouldContainKey, "avg_query_time")
 				So(statsResult.PerformanceStats, ShouldContainKey, "cache_hit_rate")
+				So(statsResult.SystemHealth.Status, ShouldEqual, "healthy")
+			})
+		})
+
+		Convey("When memories have been written and recalled", func() {
+			_, _, err := server.handleWrite(ctx, req, WriteArgs{Content: "Honeybees communicate the location of flowers through a waggle dance.", Source: "insects"})
+			So(err, ShouldBeNil)
+			_, _, err = server.handleRecall(ctx, req, RecallArgs{Query: "waggle dance"})
+			So(err, ShouldBeNil)
+
+			_, statsResult, err := server.handleStats(ctx, req, StatsArgs{IncludePerformance: true, IncludeStorage: true})
+
+			Convey("Then the stats should report them", func() {
+				So(err, ShouldBeNil)
+				So(statsResult.TotalMemories, ShouldEqual, 1)
+				So(statsResult.MemoryStats.BySource["insects"], ShouldEqual, 1)
+				So(statsResult.PerformanceStats["avg_query_time"], ShouldNotEqual, "0s")
 			})
 		})
 	})
@@ -0,0 +1,584 @@
+package main
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// hnswSeed makes level assignment, and so the built graph, reproducible
+const hnswSeed = 42
+
+// hnswIndex is a Hierarchical Navigable Small World graph (Malkov & Yashunin, 2016)
+// under a configurable metric. Every node lives on layer 0 and, with exponentially
+// decreasing probability, on the layers above; search descends greedily from the
+// sparse top layer and widens to efSearch candidates on layer 0. Nodes are kept in
+// slots addressed by int32 so traversal avoids hashing string IDs.
+type hnswIndex struct {
+	metric         VectorMetric
+	m              int
+	efConstruction int
+	efSearch       int
+	levelMult      float64
+	nodes          []*hnswNode      // Slot -> node; nil for a free slot
+	slots          map[string]int32 // Vector ID -> slot
+	free           []int32
+	entryPoint     int32 // -1 when the graph is empty
+	maxLevel       int
+	rng            *rand.Rand
+	visited        sync.Pool // *hnswVisited, so concurrent searches do not share marks
+}
+
+// hnswNode holds a node's vector, prepared for the metric, its neighbor slots on each layer it
+// occupies and, on each layer, the slots of the nodes that list it as a neighbor
+type hnswNode struct {
+	id         string
+	level      int
+	vector     []float32
+	neighbors  [][]int32
+	linkedFrom []map[int32]bool
+}
+
+// hnswCandidate is a node slot together with its distance to the vector being searched for
+type hnswCandidate struct {
+	slot     int32
+	distance float64
+}
+
+// hnswVisited marks the slots one search has seen; bumping the generation clears it
+type hnswVisited struct {
+	marks      []uint32
+	generation uint32
+}
+
+// hnswIndexData is the persisted form of the graph; vectors stay in the vector store file
+type hnswIndexData struct {
+	Metric         VectorMetric            `json:"metric,omitempty"`
+	M              int                     `json:"m"`
+	EfConstruction int                     `json:"ef_construction"`
+	EntryPoint     string                  `json:"entry_point"`
+	MaxLevel       int                     `json:"max_level"`
+	Nodes          map[string]hnswNodeData `json:"nodes"`
+}
+
+// hnswNodeData is the persisted form of one node, with neighbors named by vector ID
+type hnswNodeData struct {
+	Level     int        `json:"level"`
+	Neighbors [][]string `json:"neighbors"`
+}
+
+// newHNSWNode creates a node with no links on each of its layers
+func newHNSWNode(id string, level int, vector []float32) *hnswNode {
+	node := &hnswNode{
+		id:         id,
+		level:      level,
+		vector:     vector,
+		neighbors:  make([][]int32, level+1),
+		linkedFrom: make([]map[int32]bool, level+1),
+	}
+	for l := range node.linkedFrom {
+		node.linkedFrom[l] = make(map[int32]bool)
+	}
+	return node
+}
+
+// newHNSWIndex creates an empty graph under a metric with m links per node and the given beam widths
+func newHNSWIndex(metric VectorMetric, m, efConstruction, efSearch int) *hnswIndex {
+	if m < 2 {
+		m = 2
+	}
+	h := &hnswIndex{
+		metric:         metric,
+		m:              m,
+		efConstruction: efConstruction,
+		efSearch:       efSearch,
+		levelMult:      1 / math.Log(float64(m)),
+	}
+	h.reset()
+	return h
+}
+
+// reset empties the graph and restarts the level generator
+func (h *hnswIndex) reset() {
+	h.nodes = nil
+	h.slots = make(map[string]int32)
+	h.free = nil
+	h.entryPoint = -1
+	h.maxLevel = 0
+	h.rng = rand.New(rand.NewSource(hnswSeed))
+}
+
+// len returns the number of indexed vectors
+func (h *hnswIndex) len() int {
+	return len(h.slots)
+}
+
+// insert adds a vector to the graph, replacing any previous vector with the same ID
+func (h *hnswIndex) insert(id string, vector []float32) {
+	if _, exists := h.slots[id]; exists {
+		h.remove(id)
+	}
+
+	level := h.randomLevel()
+	node := newHNSWNode(id, level, h.metric.Prepare(vector))
+	slot := h.allocate(node)
+
+	if h.entryPoint < 0 {
+		h.entryPoint = slot
+		h.maxLevel = level
+		return
+	}
+
+	entry := []hnswCandidate{{slot: h.entryPoint, distance: h.distance(node.vector, h.nodes[h.entryPoint].vector)}}
+	for l := h.maxLevel; l > level; l-- {
+		entry = h.searchLayer(node.vector, entry, 1, l, nil)[:1]
+	}
+
+	for l := min(level, h.maxLevel); l >= 0; l-- {
+		found := h.searchLayer(node.vector, entry, h.efConstruction, l, nil)
+		h.setNeighbors(slot, l, h.selectNeighbors(found, h.m))
+		for _, neighbor := range node.neighbors[l] {
+			h.link(neighbor, slot, l)
+		}
+		entry = found
+	}
+
+	if level > h.maxLevel {
+		h.maxLevel = level
+		h.entryPoint = slot
+	}
+}
+
+// remove deletes a vector and reconnects every node that linked to it through the
+// removed node's own neighbors, so the graph stays navigable without a rebuild. Only the
+// nodes linking to the removed one are visited.
+func (h *hnswIndex) remove(id string) {
+	slot, exists := h.slots[id]
+	if !exists {
+		return
+	}
+	removed := h.nodes[slot]
+	for l, neighbors := range removed.neighbors {
+		for _, neighbor := range neighbors {
+			if target := h.nodes[neighbor]; target != nil && l <= target.level {
+				delete(target.linkedFrom[l], slot)
+			}
+		}
+	}
+	h.nodes[slot] = nil
+	delete(h.slots, id)
+	h.free = append(h.free, slot)
+
+	for l, linkedFrom := range removed.linkedFrom {
+		for nodeSlot := range linkedFrom {
+			node := h.nodes[nodeSlot]
+			if node == nil || l > node.level {
+				continue
+			}
+			candidates := make(map[int32]bool, len(node.neighbors[l])+len(removed.neighbors[l]))
+			for _, neighbor := range node.neighbors[l] {
+				candidates[neighbor] = true
+			}
+			for _, neighbor := range removed.neighbors[l] {
+				candidates[neighbor] = true
+			}
+			delete(candidates, slot)
+			delete(candidates, nodeSlot)
+			h.setNeighbors(nodeSlot, l, h.selectNeighbors(h.candidatesFor(node.vector, candidates), h.maxConnections(l)))
+		}
+	}
+
+	if h.entryPoint == slot {
+		h.entryPoint = -1
+		h.maxLevel = 0
+		for nodeSlot, node := range h.nodes {
+			if node != nil && (h.entryPoint < 0 || node.level > h.maxLevel) {
+				h.entryPoint = int32(nodeSlot)
+				h.maxLevel = node.level
+			}
+		}
+	}
+}
+
+// search returns the IDs of up to k accepted vectors closest to the query, nearest first;
+// accept may be nil. Rejected nodes are still traversed, so a selective filter widens the
+// search rather than cutting it short.
+func (h *hnswIndex) search(query []float32, k, ef int, accept func(string) bool) []string {
+	if h.entryPoint < 0 || k <= 0 {
+		return nil
+	}
+	if ef < k {
+		ef = k
+	}
+
+	query = h.metric.Prepare(query)
+	entry := []hnswCandidate{{slot: h.entryPoint, distance: h.distance(query, h.nodes[h.entryPoint].vector)}}
+	for l := h.maxLevel; l > 0; l-- {
+		entry = h.searchLayer(query, entry, 1, l, nil)[:1]
+	}
+
+	found := h.searchLayer(query, entry, ef, 0, accept)
+	if len(found) > k {
+		found = found[:k]
+	}
+	ids := make([]string, len(found))
+	for i, candidate := range found {
+		ids[i] = h.nodes[candidate.slot].id
+	}
+	return ids
+}
+
+// searchLayer runs a best-first beam search of width ef on one layer and returns the
+// accepted nodes found, nearest first
+func (h *hnswIndex) searchLayer(query []float32, entry []hnswCandidate, ef, level int, accept func(string) bool) []hnswCandidate {
+	visited := h.acquireVisited()
+	defer h.visited.Put(visited)
+
+	candidates := &hnswMinHeap{}
+	results := &hnswMaxHeap{}
+	for _, e := range entry {
+		visited.marks[e.slot] = visited.generation
+		heap.Push(candidates, e)
+		if accept == nil || accept(h.nodes[e.slot].id) {
+			heap.Push(results, e)
+		}
+	}
+
+	for candidates.Len() > 0 {
+		current := heap.Pop(candidates).(hnswCandidate)
+		if results.Len() >= ef && current.distance > (*results)[0].distance {
+			break
+		}
+
+		node := h.nodes[current.slot]
+		if node == nil || level > node.level {
+			continue
+		}
+		for _, slot := range node.neighbors[level] {
+			if visited.marks[slot] == visited.generation {
+				continue
+			}
+			visited.marks[slot] = visited.generation
+
+			neighbor := h.nodes[slot]
+			if neighbor == nil {
+				continue
+			}
+			distance := h.distance(query, neighbor.vector)
+			if results.Len() < ef || distance < (*results)[0].distance {
+				heap.Push(candidates, hnswCandidate{slot: slot, distance: distance})
+				if accept == nil || accept(neighbor.id) {
+					heap.Push(results, hnswCandidate{slot: slot, distance: distance})
+					if results.Len() > ef {
+						heap.Pop(results)
+					}
+				}
+			}
+		}
+	}
+
+	found := make([]hnswCandidate, results.Len())
+	for i := len(found) - 1; i >= 0; i-- {
+		found[i] = heap.Pop(results).(hnswCandidate)
+	}
+	return found
+}
+
+// selectNeighbors picks up to m of the candidates, given nearest first, with the diversity
+// heuristic: a candidate is kept only if it is closer to the base than to any neighbor
+// already kept; pruned candidates fill any remaining slots
+func (h *hnswIndex) selectNeighbors(candidates []hnswCandidate, m int) []int32 {
+	selected := make([]int32, 0, m)
+	var pruned []int32
+
+	for _, candidate := range candidates {
+		if len(selected) >= m {
+			break
+		}
+		vector := h.nodes[candidate.slot].vector
+		diverse := true
+		for _, slot := range selected {
+			if h.distance(vector, h.nodes[slot].vector) < candidate.distance {
+				diverse = false
+				break
+			}
+		}
+		if diverse {
+			selected = append(selected, candidate.slot)
+		} else {
+			pruned = append(pruned, candidate.slot)
+		}
+	}
+
+	for _, slot := range pruned {
+		if len(selected) >= m {
+			break
+		}
+		selected = append(selected, slot)
+	}
+	return selected
+}
+
+// link adds a connection from one node to another on a layer, pruning the node's
+// neighbors when it exceeds the layer's connection limit
+func (h *hnswIndex) link(from, to int32, level int) {
+	node := h.nodes[from]
+	if node == nil || level > node.level || slices.Contains(node.neighbors[level], to) {
+		return
+	}
+	neighbors := append(slices.Clone(node.neighbors[level]), to)
+
+	if limit := h.maxConnections(level); len(neighbors) > limit {
+		candidates := make(map[int32]bool, len(neighbors))
+		for _, slot := range neighbors {
+			candidates[slot] = true
+		}
+		neighbors = h.selectNeighbors(h.candidatesFor(node.vector, candidates), limit)
+	}
+	h.setNeighbors(from, level, neighbors)
+}
+
+// setNeighbors replaces a node's neighbors on a layer, keeping the reverse links of the
+// nodes it stops and starts linking to in step
+func (h *hnswIndex) setNeighbors(slot int32, level int, neighbors []int32) {
+	node := h.nodes[slot]
+	for _, old := range node.neighbors[level] {
+		if target := h.nodes[old]; target != nil && level <= target.level {
+			delete(target.linkedFrom[level], slot)
+		}
+	}
+	node.neighbors[level] = neighbors
+	for _, neighbor := range neighbors {
+		if target := h.nodes[neighbor]; target != nil && level <= target.level {
+			target.linkedFrom[level][slot] = true
+		}
+	}
+}
+
+// candidatesFor orders existing node slots by distance to a vector
+func (h *hnswIndex) candidatesFor(vector []float32, slots map[int32]bool) []hnswCandidate {
+	candidates := make([]hnswCandidate, 0, len(slots))
+	for slot := range slots {
+		if node := h.nodes[slot]; node != nil {
+			candidates = append(candidates, hnswCandidate{slot: slot, distance: h.distance(vector, node.vector)})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].slot < candidates[j].slot
+	})
+	return candidates
+}
+
+// allocate stores a node in a free slot, or a new one, and records its ID
+func (h *hnswIndex) allocate(node *hnswNode) int32 {
+	var slot int32
+	if n := len(h.free); n > 0 {
+		slot = h.free[n-1]
+		h.free = h.free[:n-1]
+		h.nodes[slot] = node
+	} else {
+		slot = int32(len(h.nodes))
+		h.nodes = append(h.nodes, node)
+	}
+	h.slots[node.id] = slot
+	return slot
+}
+
+// acquireVisited takes a visited set covering every slot with all marks cleared
+func (h *hnswIndex) acquireVisited() *hnswVisited {
+	visited, _ := h.visited.Get().(*hnswVisited)
+	if visited == nil {
+		visited = &hnswVisited{}
+	}
+	if len(visited.marks) < len(h.nodes) {
+		visited.marks = make([]uint32, len(h.nodes)+len(h.nodes)/4+1)
+		visited.generation = 0
+	}
+	visited.generation++
+	if visited.generation == 0 {
+		clear(visited.marks)
+		visited.generation = 1
+	}
+	return visited
+}
+
+// maxConnections is 2M on layer 0, where every node lives, and M above it
+func (h *hnswIndex) maxConnections(level int) int {
+	if level == 0 {
+		return 2 * h.m
+	}
+	return h.m
+}
+
+// randomLevel draws a node's top layer from an exponential distribution with mean 1/ln(M)
+func (h *hnswIndex) randomLevel() int {
+	return int(math.Floor(-math.Log(1-h.rng.Float64()) * h.levelMult))
+}
+
+// distance orders prepared vectors for the metric, lower being closer: cosine distance
+// of unit vectors, the negated inner product, or the squared Euclidean distance
+func (h *hnswIndex) distance(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return math.Inf(1)
+	}
+	if h.metric == VectorMetricL2 {
+		var sum float32
+		for i := range a {
+			d := a[i] - b[i]
+			sum += d * d
+		}
+		return float64(sum)
+	}
+	var dot float32
+	for i := range a {
+		dot += a[i] * b[i]
+	}
+	if h.metric == VectorMetricDot {
+		return -float64(dot)
+	}
+	return 1 - float64(dot)
+}
+
+// export returns the graph in its persisted form
+func (h *hnswIndex) export() hnswIndexData {
+	data := hnswIndexData{
+		Metric:         h.metric,
+		M:              h.m,
+		EfConstruction: h.efConstruction,
+		MaxLevel:       h.maxLevel,
+		Nodes:          make(map[string]hnswNodeData, len(h.slots)),
+	}
+	if h.entryPoint >= 0 {
+		data.EntryPoint = h.nodes[h.entryPoint].id
+	}
+	for _, node := range h.nodes {
+		if node == nil {
+			continue
+		}
+		neighbors := make([][]string, len(node.neighbors))
+		for l, slots := range node.neighbors {
+			neighbors[l] = make([]string, len(slots))
+			for i, slot := range slots {
+				neighbors[l][i] = h.nodes[slot].id
+			}
+		}
+		data.Nodes[node.id] = hnswNodeData{Level: node.level, Neighbors: neighbors}
+	}
+	return data
+}
+
+// restore loads a persisted graph over the given vectors. It returns false, leaving the
+// index empty, when the graph was built with other parameters or does not cover exactly
+// the stored vectors, in which case the caller should rebuild it.
+func (h *hnswIndex) restore(data hnswIndexData, vectors map[string]VectorStoreRecord) bool {
+	h.reset()
+	metric := data.Metric
+	if metric == "" {
+		metric = VectorMetricCosine
+	}
+	if metric != h.metric || data.M != h.m || data.EfConstruction != h.efConstruction || len(data.Nodes) != len(vectors) {
+		return false
+	}
+
+	ids := make([]string, 0, len(data.Nodes))
+	for id, nodeData := range data.Nodes {
+		record, exists := vectors[id]
+		if !exists || len(nodeData.Neighbors) != nodeData.Level+1 {
+			h.reset()
+			return false
+		}
+		h.allocate(newHNSWNode(id, nodeData.Level, h.metric.Prepare(record.Embedding)))
+		ids = append(ids, id)
+	}
+
+	for _, id := range ids {
+		for l, neighborIDs := range data.Nodes[id].Neighbors {
+			neighbors := make([]int32, 0, len(neighborIDs))
+			for _, neighborID := range neighborIDs {
+				slot, exists := h.slots[neighborID]
+				if !exists {
+					h.reset()
+					return false
+				}
+				neighbors = append(neighbors, slot)
+			}
+			h.setNeighbors(h.slots[id], l, neighbors)
+		}
+	}
+
+	if len(vectors) > 0 {
+		slot, exists := h.slots[data.EntryPoint]
+		if !exists {
+			h.reset()
+			return false
+		}
+		h.entryPoint = slot
+		h.maxLevel = data.MaxLevel
+	}
+	return true
+}
+
+// rebuild indexes every stored vector from scratch, in ID order so the graph is reproducible
+func (h *hnswIndex) rebuild(vectors map[string]VectorStoreRecord) {
+	h.reset()
+
+	ids := make([]string, 0, len(vectors))
+	for id := range vectors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		h.insert(id, vectors[id].Embedding)
+	}
+}
+
+// normalizeVector returns a unit-length copy of v, or a zero copy when v has no length
+func normalizeVector(v []float32) []float32 {
+	var norm float64
+	for _, x := range v {
+		norm += float64(x) * float64(x)
+	}
+	normalized := make([]float32, len(v))
+	if norm == 0 {
+		return normalized
+	}
+	norm = math.Sqrt(norm)
+	for i, x := range v {
+		normalized[i] = float32(float64(x) / norm)
+	}
+	return normalized
+}
+
+// hnswMinHeap orders candidates nearest first
+type hnswMinHeap []hnswCandidate
+
+func (q hnswMinHeap) Len() int            { return len(q) }
+func (q hnswMinHeap) Less(i, j int) bool  { return q[i].distance < q[j].distance }
+func (q hnswMinHeap) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *hnswMinHeap) Push(x interface{}) { *q = append(*q, x.(hnswCandidate)) }
+func (q *hnswMinHeap) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// hnswMaxHeap orders candidates farthest first, so the worst kept result is on top
+type hnswMaxHeap []hnswCandidate
+
+func (q hnswMaxHeap) Len() int            { return len(q) }
+func (q hnswMaxHeap) Less(i, j int) bool  { return q[i].distance > q[j].distance }
+func (q hnswMaxHeap) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *hnswMaxHeap) Push(x interface{}) { *q = append(*q, x.(hnswCandidate)) }
+func (q *hnswMaxHeap) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
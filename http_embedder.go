@@ -0,0 +1,301 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTTPEmbedder calls an OpenAI-compatible /v1/embeddings endpoint
+type HTTPEmbedder struct {
+	client *http.Client
+	config *HTTPEmbedderConfig
+}
+
+// HTTPEmbedderConfig holds configuration for the HTTP embedding client
+type HTTPEmbedderConfig struct {
+	Provider       string        `json:"provider"`
+	BaseURL        string        `json:"base_url"`
+	APIKey         string        `json:"-"`
+	Model          string        `json:"model"`
+	Dimensions     int           `json:"dimensions"`
+	BatchSize      int           `json:"batch_size"`
+	MaxRetries     int           `json:"max_retries"`
+	InitialBackoff time.Duration `json:"initial_backoff"`
+	MaxBackoff     time.Duration `json:"max_backoff"`
+	Timeout        time.Duration `json:"timeout"`
+}
+
+// embeddingRequest is the OpenAI embeddings request body
+type embeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// embeddingResponse is the OpenAI embeddings response body
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// defaultEmbeddingBaseURLs maps known providers to their usual local or hosted endpoints
+var defaultEmbeddingBaseURLs = map[string]string{
+	"openai":   "https://api.openai.com/v1",
+	"ollama":   "http://localhost:11434/v1",
+	"llamacpp": "http://localhost:8080/v1",
+	"vllm":     "http://localhost:8000/v1",
+}
+
+// NewHTTPEmbedder creates an HTTPEmbedder, filling defaults for unset fields
+func NewHTTPEmbedder(config *HTTPEmbedderConfig) (*HTTPEmbedder, error) {
+	if config == nil {
+		return nil, fmt.Errorf("http embedder config cannot be nil")
+	}
+
+	cfg := *config
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultEmbeddingBaseURLs[cfg.Provider]
+	}
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("embedding base URL is required for provider %q", cfg.Provider)
+	}
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("embedding model cannot be empty")
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = 500 * time.Millisecond
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 30 * time.Second
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+
+	return &HTTPEmbedder{
+		client: &http.Client{Timeout: cfg.Timeout},
+		config: &cfg,
+	}, nil
+}
+
+// Embed sends texts to the endpoint in batches and returns embeddings in input order
+func (he *HTTPEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, 0, len(texts))
+
+	for start := 0; start < len(texts); start += he.config.BatchSize {
+		end := start + he.config.BatchSize
+		if end > len(texts) {
+			end = len(texts)
+		}
+
+		batch, err := he.embedBatchWithRetry(ctx, texts[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("embedding batch %d-%d failed: %w", start, end, err)
+		}
+		embeddings = append(embeddings, batch...)
+	}
+
+	return embeddings, nil
+}
+
+// Dimensions returns the expected embedding dimension
+func (he *HTTPEmbedder) Dimensions() int {
+	return he.config.Dimensions
+}
+
+// Name returns the provider and model
+func (he *HTTPEmbedder) Name() string {
+	return he.config.Provider + ":" + he.config.Model
+}
+
+// GetConfig returns the current configuration
+func (he *HTTPEmbedder) GetConfig() *HTTPEmbedderConfig {
+	return he.config
+}
+
+// embedBatchWithRetry retries retryable failures with exponential backoff
+func (he *HTTPEmbedder) embedBatchWithRetry(ctx context.Context, texts []string) ([][]float32, error) {
+	backoff := he.config.InitialBackoff
+	var lastErr error
+
+	for attempt := 0; attempt <= he.config.MaxRetries; attempt++ {
+		embeddings, retryAfter, err := he.embedBatch(ctx, texts)
+		if err == nil {
+			return embeddings, nil
+		}
+		lastErr = err
+
+		var retryable *retryableError
+		if !errors.As(err, &retryable) || attempt == he.config.MaxRetries {
+			break
+		}
+
+		wait := backoff
+		if retryAfter > 0 {
+			wait = retryAfter
+		}
+		if wait > he.config.MaxBackoff {
+			wait = he.config.MaxBackoff
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+
+		backoff *= 2
+		if backoff > he.config.MaxBackoff {
+			backoff = he.config.MaxBackoff
+		}
+	}
+
+	return nil, lastErr
+}
+
+// embedBatch performs a single embeddings request
+func (he *HTTPEmbedder) embedBatch(ctx context.Context, texts []string) ([][]float32, time.Duration, error) {
+	body, err := json.Marshal(embeddingRequest{Model: he.config.Model, Input: texts})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to marshal embedding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, he.endpoint(), bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create embedding request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if he.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+he.config.APIKey)
+	}
+
+	resp, err := he.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, 0, ctx.Err()
+		}
+		return nil, 0, &retryableError{err: fmt.Errorf("embedding request failed: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, &retryableError{err: fmt.Errorf("failed to read embedding response: %w", err)}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		statusErr := fmt.Errorf("embedding endpoint returned %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			return nil, parseRetryAfter(resp.Header.Get("Retry-After")), &retryableError{err: statusErr}
+		}
+		return nil, 0, statusErr
+	}
+
+	var parsed embeddingResponse
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse embedding response: %w", err)
+	}
+	if parsed.Error != nil {
+		return nil, 0, fmt.Errorf("embedding endpoint error: %s", parsed.Error.Message)
+	}
+	if len(parsed.Data) != len(texts) {
+		return nil, 0, fmt.Errorf("embedding endpoint returned %d embeddings for %d inputs", len(parsed.Data), len(texts))
+	}
+
+	// Order by index since the API does not guarantee response order
+	sort.Slice(parsed.Data, func(i, j int) bool {
+		return parsed.Data[i].Index < parsed.Data[j].Index
+	})
+
+	embeddings := make([][]float32, len(parsed.Data))
+	for i, item := range parsed.Data {
+		if he.config.Dimensions > 0 && len(item.Embedding) != he.config.Dimensions {
+			return nil, 0, fmt.Errorf("embedding dimension mismatch: expected %d, got %d", he.config.Dimensions, len(item.Embedding))
+		}
+		embeddings[i] = item.Embedding
+	}
+
+	return embeddings, 0, nil
+}
+
+// endpoint resolves the embeddings URL from the configured base URL
+func (he *HTTPEmbedder) endpoint() string {
+	base := strings.TrimRight(he.config.BaseURL, "/")
+	if strings.HasSuffix(base, "/embeddings") {
+		return base
+	}
+	if strings.HasSuffix(base, "/v1") {
+		return base + "/embeddings"
+	}
+	return base + "/v1/embeddings"
+}
+
+// retryableError marks failures worth retrying
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// isHTTPEmbeddingProvider reports whether provider is served by HTTPEmbedder
+func isHTTPEmbeddingProvider(provider string) bool {
+	switch provider {
+	case "openai", "openai-compatible", "ollama", "llamacpp", "vllm":
+		return true
+	}
+	return false
+}
+
+// newHTTPEmbedderFromConfig builds an HTTPEmbedder from the server configuration
+func newHTTPEmbedderFromConfig(config *ServerConfig, provider string) (*HTTPEmbedder, error) {
+	apiKey := ""
+	if config.Processing.EmbeddingAPIKeyEnv != "" {
+		apiKey = os.Getenv(config.Processing.EmbeddingAPIKeyEnv)
+	}
+
+	return NewHTTPEmbedder(&HTTPEmbedderConfig{
+		Provider:   provider,
+		BaseURL:    config.Processing.EmbeddingBaseURL,
+		APIKey:     apiKey,
+		Model:      config.Processing.EmbeddingModel,
+		Dimensions: config.Storage.VectorStore.Dimensions,
+		BatchSize:  config.Performance.BatchSize,
+		MaxRetries: config.Processing.EmbeddingMaxRetries,
+		Timeout:    config.Processing.EmbeddingTimeout,
+	})
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// newEmbeddingTestServer returns an httptest server that answers like /v1/embeddings
+func newEmbeddingTestServer(dimensions int, failures int32, requests *int32, batchSizes *[]int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count := atomic.AddInt32(requests, 1)
+		if r.URL.Path != "/v1/embeddings" {
+			http.NotFound(w, r)
+			return
+		}
+		if count <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var req embeddingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if batchSizes != nil {
+			*batchSizes = append(*batchSizes, len(req.Input))
+		}
+
+		type item struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		}
+		data := make([]item, len(req.Input))
+		// Respond in reverse order to exercise index-based reordering
+		for i := range req.Input {
+			embedding := make([]float32, dimensions)
+			embedding[0] = float32(len(req.Input[i]))
+			data[len(req.Input)-1-i] = item{Index: i, Embedding: embedding}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+}
+
+func TestHTTPEmbedder(t *testing.T) {
+	Convey("Given an OpenAI-compatible embedding endpoint", t, func() {
+		ctx := context.Background()
+		var requests int32
+		var batchSizes []int
+		server := newEmbeddingTestServer(8, 0, &requests, &batchSizes)
+		defer server.Close()
+
+		embedder, err := NewHTTPEmbedder(&HTTPEmbedderConfig{
+			Provider:       "openai-compatible",
+			BaseURL:        server.URL,
+			Model:          "test-model",
+			Dimensions:     8,
+			BatchSize:      2,
+			MaxRetries:     2,
+			InitialBackoff: time.Millisecond,
+		})
+		So(err, ShouldBeNil)
+
+		Convey("When embedding more texts than the batch size", func() {
+			embeddings, err := embedder.Embed(ctx, []string{"a", "bb", "ccc", "dddd", "eeeee"})
+
+			Convey("Then it should batch requests and preserve input order", func() {
+				So(err, ShouldBeNil)
+				So(len(embeddings), ShouldEqual, 5)
+				So(batchSizes, ShouldResemble, []int{2, 2, 1})
+				for i, embedding := range embeddings {
+					So(len(embedding), ShouldEqual, 8)
+					So(embedding[0], ShouldEqual, float32(i+1))
+				}
+			})
+		})
+
+		Convey("When the configured dimension does not match the response", func() {
+			mismatched, err := NewHTTPEmbedder(&HTTPEmbedderConfig{
+				Provider:   "openai-compatible",
+				BaseURL:    server.URL + "/v1",
+				Model:      "test-model",
+				Dimensions: 16,
+			})
+			So(err, ShouldBeNil)
+
+			_, err = mismatched.Embed(ctx, []string{"text"})
+
+			Convey("Then it should return a dimension error", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "dimension mismatch")
+			})
+		})
+	})
+
+	Convey("Given an endpoint that fails transiently", t, func() {
+		var requests int32
+		server := newEmbeddingTestServer(4, 2, &requests, nil)
+		defer server.Close()
+
+		embedder, err := NewHTTPEmbedder(&HTTPEmbedderConfig{
+			Provider:       "openai-compatible",
+			BaseURL:        server.URL,
+			Model:          "test-model",
+			Dimensions:     4,
+			MaxRetries:     3,
+			InitialBackoff: time.Millisecond,
+		})
+		So(err, ShouldBeNil)
+
+		Convey("When embedding", func() {
+			embeddings, err := embedder.Embed(context.Background(), []string{"retry me"})
+
+			Convey("Then it should retry until the request succeeds", func() {
+				So(err, ShouldBeNil)
+				So(len(embeddings), ShouldEqual, 1)
+				So(atomic.LoadInt32(&requests), ShouldEqual, 3)
+			})
+		})
+
+		Convey("When retries are exhausted", func() {
+			embedder.GetConfig().MaxRetries = 1
+			_, err := embedder.Embed(context.Background(), []string{"give up"})
+
+			Convey("Then it should return the last error", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "503")
+				So(atomic.LoadInt32(&requests), ShouldEqual, 2)
+			})
+		})
+	})
+
+	Convey("Given an endpoint that rejects the request", t, func() {
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		embedder, err := NewHTTPEmbedder(&HTTPEmbedderConfig{
+			Provider:       "openai-compatible",
+			BaseURL:        server.URL,
+			Model:          "test-model",
+			MaxRetries:     3,
+			InitialBackoff: time.Millisecond,
+		})
+		So(err, ShouldBeNil)
+
+		Convey("When embedding", func() {
+			_, err := embedder.Embed(context.Background(), []string{"text"})
+
+			Convey("Then it should fail without retrying", func() {
+				So(err, ShouldNotBeNil)
+				So(atomic.LoadInt32(&requests), ShouldEqual, 1)
+			})
+		})
+	})
+
+	Convey("Given an HTTP provider without a base URL", t, func() {
+		_, err := NewHTTPEmbedder(&HTTPEmbedderConfig{Provider: "openai-compatible", Model: "m"})
+
+		Convey("Then creation should fail", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "base URL is required")
+		})
+	})
+}
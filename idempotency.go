@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultIdempotencyTTL is how long a write's result is kept for retries carrying its key
+const defaultIdempotencyTTL = 24 * time.Hour
+
+// idempotentWrite is a write recorded under an idempotency key. Done is closed once the
+// write finishes; until then retries wait on it.
+type idempotentWrite struct {
+	request  string
+	done     chan struct{}
+	result   *WriteResult
+	finished time.Time
+}
+
+// idempotencyCache remembers the result of each write made with an idempotency key so a
+// retry returns it instead of writing again. A retry arriving while the first write is still
+// running waits for it. Failed writes are forgotten so they can be retried.
+type idempotencyCache struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	writes map[string]*idempotentWrite
+}
+
+// newIdempotencyCache creates a cache keeping results for ttl, or the default when ttl is 0
+func newIdempotencyCache(ttl time.Duration) *idempotencyCache {
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+	return &idempotencyCache{
+		ttl:    ttl,
+		writes: make(map[string]*idempotentWrite),
+	}
+}
+
+// do runs write once per key and returns a copy of its result to every call with that key.
+// The request fingerprint guards against a key being reused for a different write. A retry
+// waiting on the first write gives up when its context ends.
+func (c *idempotencyCache) do(ctx context.Context, key, request string, write func() (*WriteResult, error)) (*WriteResult, error) {
+	for {
+		c.mu.Lock()
+		c.expire(time.Now())
+		existing, exists := c.writes[key]
+		if !exists {
+			break
+		}
+		c.mu.Unlock()
+
+		if existing.request != request {
+			return nil, fmt.Errorf("idempotency key %q was already used for a different write", key)
+		}
+		select {
+		case <-existing.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if existing.result != nil {
+			return copyWriteResult(existing.result), nil
+		}
+		// The first write failed and was forgotten; try again
+	}
+
+	entry := &idempotentWrite{request: request, done: make(chan struct{})}
+	c.writes[key] = entry
+	c.mu.Unlock()
+
+	result, err := write()
+
+	c.mu.Lock()
+	if err != nil {
+		delete(c.writes, key)
+	} else {
+		entry.result = copyWriteResult(result)
+		entry.finished = time.Now()
+	}
+	c.mu.Unlock()
+	close(entry.done)
+
+	return result, err
+}
+
+// expire forgets the finished writes older than the TTL (assumes lock is held)
+func (c *idempotencyCache) expire(now time.Time) {
+	for key, entry := range c.writes {
+		if entry.result != nil && now.Sub(entry.finished) > c.ttl {
+			delete(c.writes, key)
+		}
+	}
+}
+
+// idempotencyRequest fingerprints what a write stores, so reusing a key for other content or
+// metadata can be told apart from a retry. The timestamp is left out: a retry is stamped
+// afresh but is still the same write.
+func idempotencyRequest(content string, metadata WriteMetadata) (string, error) {
+	metadata.Timestamp = time.Time{}
+	metadata.IdempotencyKey = ""
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return "", fmt.Errorf("failed to fingerprint write metadata: %w", err)
+	}
+	hash := sha256.New()
+	hash.Write(encoded)
+	hash.Write([]byte{0})
+	hash.Write([]byte(content))
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+// copyWriteResult copies a result so callers cannot change the one kept for retries
+func copyWriteResult(result *WriteResult) *WriteResult {
+	if result == nil {
+		return nil
+	}
+	copied := *result
+	copied.ConflictsFound = append([]ConflictInfo(nil), result.ConflictsFound...)
+	copied.EntitiesLinked = append([]string(nil), result.EntitiesLinked...)
+	return &copied
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIdempotencyCache(t *testing.T) {
+	Convey("Given an idempotency cache", t, func() {
+		ctx := context.Background()
+		cache := newIdempotencyCache(0)
+		So(cache.ttl, ShouldEqual, defaultIdempotencyTTL)
+
+		calls := 0
+		write := func() (*WriteResult, error) {
+			calls++
+			return &WriteResult{MemoryID: fmt.Sprintf("memory_%d", calls), EntitiesLinked: []string{"entity"}}, nil
+		}
+
+		Convey("Then a key should run its write once", func() {
+			first, err := cache.do(ctx, "key", "request", write)
+			So(err, ShouldBeNil)
+			again, err := cache.do(ctx, "key", "request", write)
+			So(err, ShouldBeNil)
+			So(calls, ShouldEqual, 1)
+			So(again, ShouldResemble, first)
+
+			// Callers get copies, so changing one leaves the kept result alone
+			again.EntitiesLinked[0] = "changed"
+			third, _ := cache.do(ctx, "key", "request", write)
+			So(third.EntitiesLinked, ShouldResemble, []string{"entity"})
+		})
+
+		Convey("Then concurrent retries should wait for the first write", func() {
+			release := make(chan struct{})
+			slow := func() (*WriteResult, error) {
+				<-release
+				return write()
+			}
+
+			var wg sync.WaitGroup
+			results := make([]*WriteResult, 4)
+			for i := range results {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					results[i], _ = cache.do(ctx, "key", "request", slow)
+				}(i)
+			}
+			close(release)
+			wg.Wait()
+
+			So(calls, ShouldEqual, 1)
+			for _, result := range results {
+				So(result.MemoryID, ShouldEqual, "memory_1")
+			}
+		})
+
+		Convey("Then failed writes should be forgotten so they can be retried", func() {
+			_, err := cache.do(ctx, "key", "request", func() (*WriteResult, error) {
+				return nil, fmt.Errorf("storage unavailable")
+			})
+			So(err, ShouldNotBeNil)
+
+			result, err := cache.do(ctx, "key", "request", write)
+			So(err, ShouldBeNil)
+			So(result.MemoryID, ShouldEqual, "memory_1")
+		})
+
+		Convey("Then a key reused for another request should be refused", func() {
+			_, err := cache.do(ctx, "key", "request", write)
+			So(err, ShouldBeNil)
+			_, err = cache.do(ctx, "key", "other request", write)
+			So(err, ShouldNotBeNil)
+			So(calls, ShouldEqual, 1)
+		})
+
+		Convey("Then a retry should stop waiting when its context ends", func() {
+			release := make(chan struct{})
+			defer close(release)
+			started := make(chan struct{})
+			go cache.do(ctx, "key", "request", func() (*WriteResult, error) {
+				close(started)
+				<-release
+				return write()
+			})
+			<-started
+
+			cancelled, cancel := context.WithCancel(ctx)
+			cancel()
+			_, err := cache.do(cancelled, "key", "request", write)
+			So(err, ShouldEqual, context.Canceled)
+		})
+
+		Convey("Then results should expire after the TTL", func() {
+			_, err := cache.do(ctx, "key", "request", write)
+			So(err, ShouldBeNil)
+			cache.expire(time.Now().Add(defaultIdempotencyTTL + time.Second))
+			So(cache.writes, ShouldBeEmpty)
+		})
+	})
+}
+
+func TestIdempotencyRequest(t *testing.T) {
+	Convey("Given a write's content and metadata", t, func() {
+		metadata := WriteMetadata{Source: "notes", Timestamp: time.Now(), Tags: []string{"a"}, IdempotencyKey: "key"}
+		request, err := idempotencyRequest("content", metadata)
+		So(err, ShouldBeNil)
+
+		Convey("Then a retry stamped later should have the same fingerprint", func() {
+			retry := metadata
+			retry.Timestamp = metadata.Timestamp.Add(time.Minute)
+			again, err := idempotencyRequest("content", retry)
+			So(err, ShouldBeNil)
+			So(again, ShouldEqual, request)
+		})
+
+		Convey("Then changing anything the write stores should change the fingerprint", func() {
+			changes := []func(m *WriteMetadata){
+				func(m *WriteMetadata) { m.Tags = []string{"b"} },
+				func(m *WriteMetadata) { m.Metadata = map[string]interface{}{"project": "atlas"} },
+				func(m *WriteMetadata) { m.Reingest = true },
+				func(m *WriteMetadata) { m.UserID = "user" },
+				func(m *WriteMetadata) { m.Confidence = 0.9 },
+			}
+			for _, change := range changes {
+				changed := metadata
+				change(&changed)
+				other, err := idempotencyRequest("content", changed)
+				So(err, ShouldBeNil)
+				So(other, ShouldNotEqual, request)
+			}
+		})
+	})
+}
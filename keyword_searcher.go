@@ -6,13 +6,14 @@ import (
 	"math"
 	"sort"
 	"strings"
-	"unicode"
 )
 
 // KeywordSearcher handles text-based keyword matching and BM25 scoring
 type KeywordSearcher struct {
 	searchIndex SearchIndex
 	config      *KeywordSearchConfig
+	analyzer    Analyzer
+	language    string // Language code the analyzer was picked for
 }
 
 // KeywordSearchConfig holds configuration for keyword search
@@ -23,7 +24,7 @@ type KeywordSearchConfig struct {
 	BM25K1        float64 `json:"bm25_k1"`        // Term frequency saturation parameter
 	BM25B         float64 `json:"bm25_b"`         // Length normalization parameter
 	CaseSensitive bool    `json:"case_sensitive"`
-	StemWords     bool    `json:"stem_words"`
+	StemWords     bool    `json:"stem_words"` // Stem query and content terms where the language has a stemmer
 }
 
 // KeywordSearchResult represents a single keyword search result
@@ -54,6 +55,18 @@ type TermFrequency struct {
 	TFIDF     float64 `json:"tfidf"`
 }
 
+// bm25SearchIndex is implemented by search indexes that already score results with
+// corpus-level BM25, so their scores are used as is
+type bm25SearchIndex interface {
+	BM25Parameters() (k1, b float64)
+}
+
+// analyzingSearchIndex is implemented by search indexes that pick analyzers from their own
+// registry, so queries and matched content are analyzed the way the index analyzed documents
+type analyzingSearchIndex interface {
+	Analyzers() *AnalyzerRegistry
+}
+
 // NewKeywordSearcher creates a new KeywordSearcher instance
 func NewKeywordSearcher() *KeywordSearcher {
 	config := &KeywordSearchConfig{
@@ -63,12 +76,10 @@ func NewKeywordSearcher() *KeywordSearcher {
 		BM25K1:        1.2,
 		BM25B:         0.75,
 		CaseSensitive: false,
-		StemWords:     false,
+		StemWords:     true,
 	}
 
-	return &KeywordSearcher{
-		config: config,
-	}
+	return newKeywordSearcher(nil, config)
 }
 
 // NewKeywordSearcherWithIndex creates a KeywordSearcher with a specific search index
@@ -81,18 +92,51 @@ func NewKeywordSearcherWithIndex(index SearchIndex, config *KeywordSearchConfig)
 			BM25K1:        1.2,
 			BM25B:         0.75,
 			CaseSensitive: false,
-			StemWords:     false,
+			StemWords:     true,
 		}
 	}
 
-	return &KeywordSearcher{
+	return newKeywordSearcher(index, config)
+}
+
+// newKeywordSearcher creates a KeywordSearcher analyzing English until a search names a language
+func newKeywordSearcher(index SearchIndex, config *KeywordSearchConfig) *KeywordSearcher {
+	ks := &KeywordSearcher{
 		searchIndex: index,
 		config:      config,
 	}
+	ks.analyzer, ks.language = keywordAnalyzer(index, config, "")
+	return ks
 }
 
-// Search performs keyword-based text search
+// keywordAnalyzer returns the analyzer for a query language and the language code it was picked
+// for: the index's own when it has a registry, otherwise the built-in one, stemming only when the
+// config asks for it
+func keywordAnalyzer(index SearchIndex, config *KeywordSearchConfig, language string) (Analyzer, string) {
+	analyzerConfig := AnalyzerConfigForLanguage(language)
+	if analyzing, ok := index.(analyzingSearchIndex); ok {
+		return analyzing.Analyzers().ForLanguage(analyzerConfig.Language), analyzerConfig.Language
+	}
+	analyzerConfig.Stem = analyzerConfig.Stem && config.StemWords
+	return NewTextAnalyzer(analyzerConfig), analyzerConfig.Language
+}
+
+// Search performs keyword-based text search on an English query
 func (ks *KeywordSearcher) Search(ctx context.Context, query string, k int, filters map[string]interface{}) (*KeywordSearchResponse, error) {
+	return ks.SearchInLanguage(ctx, query, "", k, filters)
+}
+
+// SearchInLanguage performs keyword search analyzing the query, and matching content, with the
+// analyzer for language; an empty language means English
+func (ks *KeywordSearcher) SearchInLanguage(ctx context.Context, query, language string, k int, filters map[string]interface{}) (*KeywordSearchResponse, error) {
+	// The index's registry is consulted on every search, since its analyzers may be replaced
+	_, analyzing := ks.searchIndex.(analyzingSearchIndex)
+	if analyzing || (normalizeLanguage(language) != "" && normalizeLanguage(language) != ks.language) {
+		searcher := *ks
+		searcher.analyzer, searcher.language = keywordAnalyzer(ks.searchIndex, ks.config, language)
+		ks = &searcher
+	}
+
 	if ks.searchIndex == nil {
 		return nil, fmt.Errorf("search index not initialized")
 	}
@@ -123,25 +167,39 @@ func (ks *KeywordSearcher) Search(ctx context.Context, query string, k int, filt
 
 	// Perform search using the search index
 	searchOptions := SearchIndexOptions{
-		Limit: k * 2, // Get more results for better ranking
+		Limit:    k * 2, // Get more results for better ranking
+		Filters:  filters,
+		Language: language,
 	}
 	searchResults, err := ks.searchIndex.Search(ctx, processedQuery, searchOptions)
 	if err != nil {
 		return nil, fmt.Errorf("search index query failed: %w", err)
 	}
 
+	k1, b := ks.config.BM25K1, ks.config.BM25B
+	scorer, indexBM25 := ks.searchIndex.(bm25SearchIndex)
+	if indexBM25 {
+		k1, b = scorer.BM25Parameters()
+	}
+
 	// Convert to KeywordSearchResult format and calculate scores
 	results := make([]KeywordSearchResult, 0, len(searchResults))
 	for _, sr := range searchResults {
-		// Match keywords and calculate score
-		matchedTerms, highlights := ks.MatchKeywords(sr.Content, queryTerms)
+		// Match keywords and calculate score against the text the document was indexed under
+		text := documentText(sr.Content, sr.Metadata)
+		matchedTerms, highlights := ks.MatchKeywords(text, queryTerms)
 		
 		if len(matchedTerms) == 0 {
 			continue // Skip if no terms matched
 		}
 
-		// Calculate BM25 score
-		bm25Score := ks.calculateBM25Score(sr.Content, queryTerms, sr.Metadata)
+		// Use the index's BM25 score when it has corpus statistics, otherwise estimate one
+		var bm25Score float64
+		if indexBM25 {
+			bm25Score = sr.Score
+		} else {
+			bm25Score = ks.calculateBM25Score(text, queryTerms, sr.Metadata)
+		}
 		
 		// Use BM25 score as primary score, fall back to search index score
 		finalScore := bm25Score
@@ -184,8 +242,9 @@ func (ks *KeywordSearcher) Search(ctx context.Context, query string, k int, filt
 	// Add search metadata
 	response.Metadata["query_terms"] = queryTerms
 	response.Metadata["processed_query"] = processedQuery
-	response.Metadata["bm25_k1"] = ks.config.BM25K1
-	response.Metadata["bm25_b"] = ks.config.BM25B
+	response.Metadata["bm25_k1"] = k1
+	response.Metadata["bm25_b"] = b
+	response.Metadata["language"] = ks.language
 
 	return response, nil
 }
@@ -210,7 +269,7 @@ func (ks *KeywordSearcher) MatchKeywords(content string, queryTerms []string) ([
 
 	// Find matches
 	for _, queryTerm := range queryTerms {
-		if freq, exists := termFreq[queryTerm]; exists && freq > 0 {
+		if freq, exists := termFreq[ks.termKey(queryTerm)]; exists && freq > 0 {
 			matchedTerms = append(matchedTerms, queryTerm)
 			
 			// Generate highlight snippet
@@ -262,62 +321,33 @@ func (ks *KeywordSearcher) preprocessText(text string) string {
 	return strings.Join(words, " ")
 }
 
-// tokenize splits text into individual terms
+// tokenize splits text into terms with the searcher's analyzer
 func (ks *KeywordSearcher) tokenize(text string) []string {
 	if text == "" {
 		return []string{}
 	}
 
-	// Preprocess text first
-	text = ks.preprocessText(text)
-
-	// Simple tokenization - split on whitespace and punctuation
-	var tokens []string
-	var currentToken strings.Builder
-
-	for _, char := range text {
-		if unicode.IsLetter(char) || unicode.IsDigit(char) {
-			currentToken.WriteRune(char)
-		} else {
-			if currentToken.Len() > 0 {
-				token := currentToken.String()
-				if len(token) >= 2 { // Filter out single characters
-					tokens = append(tokens, token)
-				}
-				currentToken.Reset()
-			}
-		}
-	}
+	return ks.analyzer.Analyze(ks.preprocessText(text))
+}
 
-	// Add final token if exists
-	if currentToken.Len() > 0 {
-		token := currentToken.String()
-		if len(token) >= 2 {
-			tokens = append(tokens, token)
-		}
+// termKey analyzes a single query term so it compares equal to analyzed content terms
+func (ks *KeywordSearcher) termKey(term string) string {
+	if terms := ks.analyzer.Analyze(term); len(terms) == 1 {
+		return terms[0]
 	}
-
-	return ks.filterStopWords(tokens)
+	return strings.ToLower(term)
 }
 
-// filterStopWords removes common stop words
+// filterStopWords removes the analyzer's stop words, if it has a list of them
 func (ks *KeywordSearcher) filterStopWords(tokens []string) []string {
-	stopWords := map[string]bool{
-		"the": true, "a": true, "an": true, "and": true, "or": true, "but": true,
-		"in": true, "on": true, "at": true, "to": true, "for": true, "of": true,
-		"with": true, "by": true, "is": true, "are": true, "was": true, "were": true,
-		"be": true, "been": true, "have": true, "has": true, "had": true, "do": true,
-		"does": true, "did": true, "will": true, "would": true, "could": true, "should": true,
-		"may": true, "might": true, "must": true, "can": true, "this": true, "that": true,
-		"these": true, "those": true, "i": true, "you": true, "he": true, "she": true,
-		"it": true, "we": true, "they": true, "me": true, "him": true, "her": true,
-		"us": true, "them": true, "my": true, "your": true, "his": true, "its": true,
-		"our": true, "their": true,
+	stopWords, ok := ks.analyzer.(interface{ IsStopWord(token string) bool })
+	if !ok {
+		return tokens
 	}
 
 	var filtered []string
 	for _, token := range tokens {
-		if !stopWords[token] {
+		if !stopWords.IsStopWord(token) {
 			filtered = append(filtered, token)
 		}
 	}
@@ -395,7 +425,7 @@ func (ks *KeywordSearcher) calculateBM25Score(content string, queryTerms []strin
 	var bm25Score float64
 
 	for _, queryTerm := range queryTerms {
-		tf := float64(termFreq[queryTerm])
+		tf := float64(termFreq[ks.termKey(queryTerm)])
 		if tf == 0 {
 			continue
 		}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 
 	. "github.com/smartystreets/goconvey/convey"
@@ -179,6 +180,97 @@ func TestKeywordSearcherMatchKeywords(t *testing.T) {
 	})
 }
 
+func TestKeywordSearcherWithBM25Index(t *testing.T) {
+	Convey("Given a KeywordSearcher over a FileSearchIndex", t, func() {
+		ctx := context.Background()
+		index := NewFileSearchIndexWithConfig(filepath.Join(t.TempDir(), "search_index.json"), &FileSearchIndexConfig{BM25K1: 1.5, BM25B: 0.5})
+		ks := NewKeywordSearcherWithIndex(index, nil)
+
+		index.Index(ctx, IndexDocument{ID: "doc1", Content: "machine learning algorithms for data science"})
+		index.Index(ctx, IndexDocument{ID: "doc2", Content: "learning machine shop tools"})
+
+		Convey("When searching with a quoted phrase", func() {
+			result, err := ks.Search(ctx, `"machine learning" algorithms`, 5, nil)
+
+			Convey("Then the index should enforce the phrase and supply the BM25 scores", func() {
+				So(err, ShouldBeNil)
+				So(len(result.Results), ShouldEqual, 1)
+				So(result.Results[0].ID, ShouldEqual, "doc1")
+				So(result.Results[0].BM25Score, ShouldBeGreaterThan, 0)
+				So(result.Metadata["bm25_k1"], ShouldEqual, 1.5)
+				So(result.Metadata["bm25_b"], ShouldEqual, 0.5)
+			})
+		})
+	})
+}
+
+// synonymAnalyzer analyzes with a base analyzer, then replaces terms with their canonical synonym
+type synonymAnalyzer struct {
+	base     Analyzer
+	synonyms map[string]string
+}
+
+func (a synonymAnalyzer) Analyze(text string) []string {
+	terms := a.base.Analyze(text)
+	for i, term := range terms {
+		if canonical, ok := a.synonyms[term]; ok {
+			terms[i] = canonical
+		}
+	}
+	return terms
+}
+
+func TestKeywordSearcherIndexAnalyzers(t *testing.T) {
+	Convey("Given a FileSearchIndex with a custom English analyzer", t, func() {
+		ctx := context.Background()
+		index := NewFileSearchIndex(filepath.Join(t.TempDir(), "search_index.json"))
+		ks := NewKeywordSearcherWithIndex(index, nil)
+
+		registry := NewAnalyzerRegistry()
+		registry.Register("en", synonymAnalyzer{base: AnalyzerForLanguage("en"), synonyms: map[string]string{"automobil": "car"}})
+		index.SetAnalyzers(registry)
+		So(index.Index(ctx, IndexDocument{ID: "doc1", Content: "The automobile was parked outside"}), ShouldBeNil)
+
+		Convey("When searching for a term only the custom analyzer relates to the content", func() {
+			result, err := ks.Search(ctx, "car", 5, nil)
+
+			Convey("Then the index's hit should be kept and matched with the index's analyzer", func() {
+				So(err, ShouldBeNil)
+				So(result.Results, ShouldHaveLength, 1)
+				So(result.Results[0].ID, ShouldEqual, "doc1")
+				So(result.Results[0].MatchedTerms, ShouldResemble, []string{"car"})
+			})
+		})
+	})
+}
+
+func TestKeywordSearcherHeadingPath(t *testing.T) {
+	Convey("Given indexes holding a Markdown chunk with a heading breadcrumb", t, func() {
+		ctx := context.Background()
+		doc := IndexDocument{
+			ID:       "guide_chunk_2",
+			Content:  "Run make migrate before deploying.",
+			Metadata: map[string]interface{}{"heading_path": "Setup > Database > Migrations"},
+		}
+
+		for name, index := range map[string]SearchIndex{
+			"mock": NewMockSearchIndex(),
+			"file": NewFileSearchIndex(filepath.Join(t.TempDir(), "search_index.json")),
+		} {
+			So(index.Index(ctx, doc), ShouldBeNil)
+			ks := NewKeywordSearcherWithIndex(index, nil)
+
+			Convey("Then a query naming the section should find it in the "+name+" index", func() {
+				result, err := ks.Search(ctx, "setup", 5, nil)
+				So(err, ShouldBeNil)
+				So(result.Results, ShouldHaveLength, 1)
+				So(result.Results[0].ID, ShouldEqual, "guide_chunk_2")
+				So(result.Results[0].Content, ShouldEqual, "Run make migrate before deploying.")
+			})
+		}
+	})
+}
+
 func TestKeywordSearcherScoreResults(t *testing.T) {
 	Convey("Given a KeywordSearcher", t, func() {
 		ks := NewKeywordSearcher()
@@ -241,9 +333,10 @@ func TestKeywordSearcherTextProcessing(t *testing.T) {
 			})
 		})
 
-		Convey("When tokenizing text", func() {
+		Convey("When tokenizing text without stemming", func() {
+			unstemmed := NewKeywordSearcherWithIndex(nil, &KeywordSearchConfig{DefaultK: 10, MaxResults: 100, StemWords: false})
 			text := "Machine learning, algorithms & neural networks!"
-			tokens := ks.tokenize(text)
+			tokens := unstemmed.tokenize(text)
 
 			Convey("Then it should split on punctuation and filter stop words", func() {
 				So(tokens, ShouldContain, "machine")
@@ -256,6 +349,14 @@ func TestKeywordSearcherTextProcessing(t *testing.T) {
 			})
 		})
 
+		Convey("When tokenizing text with stemming", func() {
+			tokens := ks.tokenize("Running networks, connected résumés")
+
+			Convey("Then terms should be stemmed and folded", func() {
+				So(tokens, ShouldResemble, []string{"run", "network", "connect", "resum"})
+			})
+		})
+
 		Convey("When filtering stop words", func() {
 			tokens := []string{"the", "machine", "learning", "is", "good", "and", "useful"}
 			filtered := ks.filterStopWords(tokens)
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// louvainGraph is an undirected weighted graph over dense node indexes
+type louvainGraph struct {
+	adj    []map[int]float64 // neighbor -> weight, excluding self-loops
+	loops  []float64         // self-loop weight per node
+	degree []float64         // weighted degree, self-loops counted twice
+	total  float64           // total edge weight m
+}
+
+// DetectCommunitiesLouvain partitions nodes into communities by greedily maximizing
+// modularity with the Louvain method. Edges are treated as undirected and parallel
+// edges are summed. Each Community.Score is that community's contribution to the
+// partition's modularity, so the scores sum to the overall modularity.
+func DetectCommunitiesLouvain(nodeIDs []string, edges []*Edge, options CommunityDetectionOptions) []Community {
+	if len(nodeIDs) == 0 {
+		return []Community{}
+	}
+	options = options.withDefaults()
+
+	ids := append([]string(nil), nodeIDs...)
+	sort.Strings(ids)
+	index := make(map[string]int, len(ids))
+	for i, id := range ids {
+		index[id] = i
+	}
+
+	graph := newLouvainGraph(len(ids))
+	for _, edge := range edges {
+		from, okFrom := index[edge.From]
+		to, okTo := index[edge.To]
+		if !okFrom || !okTo || edge.Weight <= 0 {
+			continue
+		}
+		graph.addEdge(from, to, edge.Weight)
+	}
+
+	rng := rand.New(rand.NewSource(options.Seed))
+
+	// membership maps each original node to its community at the current level
+	membership := make([]int, len(ids))
+	for i := range membership {
+		membership[i] = i
+	}
+
+	current := graph
+	for level := 0; level < options.MaxLevels; level++ {
+		partition, moved := current.oneLevel(options, rng)
+		if !moved {
+			break
+		}
+
+		renumbered := renumberPartition(partition)
+		for i := range membership {
+			membership[i] = renumbered[membership[i]]
+		}
+		current = current.aggregate(renumbered)
+	}
+
+	return buildCommunities(ids, membership, graph, options.Resolution)
+}
+
+// newLouvainGraph creates an empty graph with n nodes
+func newLouvainGraph(n int) *louvainGraph {
+	graph := &louvainGraph{
+		adj:    make([]map[int]float64, n),
+		loops:  make([]float64, n),
+		degree: make([]float64, n),
+	}
+	for i := range graph.adj {
+		graph.adj[i] = make(map[int]float64)
+	}
+	return graph
+}
+
+// addEdge adds an undirected weighted edge
+func (g *louvainGraph) addEdge(from, to int, weight float64) {
+	if from == to {
+		g.loops[from] += weight
+		g.degree[from] += 2 * weight
+	} else {
+		g.adj[from][to] += weight
+		g.adj[to][from] += weight
+		g.degree[from] += weight
+		g.degree[to] += weight
+	}
+	g.total += weight
+}
+
+// sortedNeighbors returns a node's neighbors in index order for deterministic iteration
+func (g *louvainGraph) sortedNeighbors(node int) []int {
+	neighbors := make([]int, 0, len(g.adj[node]))
+	for neighbor := range g.adj[node] {
+		neighbors = append(neighbors, neighbor)
+	}
+	sort.Ints(neighbors)
+	return neighbors
+}
+
+// oneLevel moves single nodes between communities until no move improves modularity,
+// returning the partition and whether any node changed community
+func (g *louvainGraph) oneLevel(options CommunityDetectionOptions, rng *rand.Rand) ([]int, bool) {
+	n := len(g.adj)
+	community := make([]int, n)
+	totals := make([]float64, n)
+	for i := 0; i < n; i++ {
+		community[i] = i
+		totals[i] = g.degree[i]
+	}
+
+	if g.total == 0 {
+		return community, false
+	}
+
+	twoM := 2 * g.total
+	order := rng.Perm(n)
+	movedAny := false
+
+	for pass := 0; pass < options.MaxIterations; pass++ {
+		moved := false
+		for _, node := range order {
+			current := community[node]
+
+			// Weight from node to each neighboring community
+			links := make(map[int]float64)
+			var candidates []int
+			for _, neighbor := range g.sortedNeighbors(node) {
+				c := community[neighbor]
+				if _, seen := links[c]; !seen {
+					candidates = append(candidates, c)
+				}
+				links[c] += g.adj[node][neighbor]
+			}
+
+			// Take the node out of its community before scoring every option
+			totals[current] -= g.degree[node]
+
+			best := current
+			bestGain := links[current] - options.Resolution*totals[current]*g.degree[node]/twoM
+			for _, c := range candidates {
+				gain := links[c] - options.Resolution*totals[c]*g.degree[node]/twoM
+				if gain > bestGain+options.MinGain {
+					best = c
+					bestGain = gain
+				}
+			}
+
+			totals[best] += g.degree[node]
+			if best != current {
+				community[node] = best
+				moved = true
+				movedAny = true
+			}
+		}
+		if !moved {
+			break
+		}
+	}
+
+	return community, movedAny
+}
+
+// renumberPartition maps community labels to 0..k-1 in order of first appearance
+func renumberPartition(partition []int) []int {
+	labels := make(map[int]int)
+	renumbered := make([]int, len(partition))
+	for i, c := range partition {
+		label, exists := labels[c]
+		if !exists {
+			label = len(labels)
+			labels[c] = label
+		}
+		renumbered[i] = label
+	}
+	return renumbered
+}
+
+// aggregate collapses each community into a single node, keeping internal weight as self-loops
+func (g *louvainGraph) aggregate(partition []int) *louvainGraph {
+	size := 0
+	for _, c := range partition {
+		if c+1 > size {
+			size = c + 1
+		}
+	}
+
+	aggregated := newLouvainGraph(size)
+	for node, neighbors := range g.adj {
+		from := partition[node]
+		aggregated.loops[from] += g.loops[node]
+		for neighbor, weight := range neighbors {
+			to := partition[neighbor]
+			if from == to {
+				// Each internal edge is seen from both ends
+				aggregated.loops[from] += weight / 2
+			} else {
+				aggregated.adj[from][to] += weight
+			}
+		}
+	}
+	for node := range aggregated.adj {
+		aggregated.degree[node] = 2 * aggregated.loops[node]
+		for _, weight := range aggregated.adj[node] {
+			aggregated.degree[node] += weight
+		}
+	}
+	aggregated.total = g.total
+
+	return aggregated
+}
+
+// buildCommunities groups node IDs by final membership and scores each community's modularity
+func buildCommunities(ids []string, membership []int, graph *louvainGraph, resolution float64) []Community {
+	groups := make(map[int][]int)
+	for node, c := range membership {
+		groups[c] = append(groups[c], node)
+	}
+
+	var communities []Community
+	for c, members := range groups {
+		internal := 0.0
+		totalDegree := 0.0
+		nodes := make([]string, 0, len(members))
+		for _, node := range members {
+			nodes = append(nodes, ids[node])
+			totalDegree += graph.degree[node]
+			internal += graph.loops[node]
+			for neighbor, weight := range graph.adj[node] {
+				if membership[neighbor] == c && neighbor > node {
+					internal += weight
+				}
+			}
+		}
+		sort.Strings(nodes)
+
+		score := 0.0
+		if graph.total > 0 {
+			fraction := totalDegree / (2 * graph.total)
+			score = internal/graph.total - resolution*fraction*fraction
+		}
+
+		communities = append(communities, Community{
+			Nodes: nodes,
+			Score: score,
+		})
+	}
+
+	// Largest communities first, ties broken by their first node for stable IDs
+	sort.Slice(communities, func(i, j int) bool {
+		if len(communities[i].Nodes) == len(communities[j].Nodes) {
+			return communities[i].Nodes[0] < communities[j].Nodes[0]
+		}
+		return len(communities[i].Nodes) > len(communities[j].Nodes)
+	})
+	for i := range communities {
+		communities[i].ID = fmt.Sprintf("community_%d", i)
+	}
+
+	return communities
+}
+
+// Modularity returns the total modularity of a set of communities
+func Modularity(communities []Community) float64 {
+	total := 0.0
+	for _, community := range communities {
+		total += community.Score
+	}
+	return total
+}
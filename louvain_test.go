@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// cliqueRing builds count cliques of size nodes each, joined in a ring by single edges
+func cliqueRing(count, size int) ([]string, []*Edge) {
+	var nodeIDs []string
+	var edges []*Edge
+	for c := 0; c < count; c++ {
+		for i := 0; i < size; i++ {
+			nodeIDs = append(nodeIDs, fmt.Sprintf("c%d_n%d", c, i))
+			for j := 0; j < i; j++ {
+				edges = append(edges, NewEdge(fmt.Sprintf("c%d_%d_%d", c, j, i), fmt.Sprintf("c%d_n%d", c, j), fmt.Sprintf("c%d_n%d", c, i), RelatedTo, 1.0))
+			}
+		}
+		next := (c + 1) % count
+		edges = append(edges, NewEdge(fmt.Sprintf("ring_%d", c), fmt.Sprintf("c%d_n0", c), fmt.Sprintf("c%d_n0", next), RelatedTo, 1.0))
+	}
+	return nodeIDs, edges
+}
+
+func TestDetectCommunitiesLouvain(t *testing.T) {
+	Convey("Given a ring of cliques", t, func() {
+		nodeIDs, edges := cliqueRing(4, 5)
+
+		Convey("When detecting communities with default options", func() {
+			communities := DetectCommunitiesLouvain(nodeIDs, edges, DefaultCommunityDetectionOptions())
+
+			Convey("Then each clique should be its own community", func() {
+				So(len(communities), ShouldEqual, 4)
+				for _, community := range communities {
+					So(len(community.Nodes), ShouldEqual, 5)
+					prefix := community.Nodes[0][:3]
+					for _, node := range community.Nodes {
+						So(node, ShouldStartWith, prefix)
+					}
+				}
+			})
+
+			Convey("Then the scores should sum to the partition modularity", func() {
+				// 4 cliques of 10 internal edges, 4 ring edges: m = 44, each clique has degree 22
+				expected := 4 * (10.0/44.0 - (22.0/88.0)*(22.0/88.0))
+				So(Modularity(communities), ShouldAlmostEqual, expected, 1e-9)
+			})
+		})
+
+		Convey("When the same seed is used twice", func() {
+			options := CommunityDetectionOptions{Seed: 42}
+			first := DetectCommunitiesLouvain(nodeIDs, edges, options)
+			second := DetectCommunitiesLouvain(nodeIDs, edges, options)
+
+			Convey("Then the results should be identical", func() {
+				So(second, ShouldResemble, first)
+			})
+		})
+
+		Convey("When the resolution is very low", func() {
+			communities := DetectCommunitiesLouvain(nodeIDs, edges, CommunityDetectionOptions{Resolution: 0.01})
+
+			Convey("Then the cliques should merge into fewer communities", func() {
+				So(len(communities), ShouldBeLessThan, 4)
+			})
+		})
+	})
+
+	Convey("Given nodes whose grouping depends on edge weights", t, func() {
+		nodeIDs := []string{"a", "b", "c", "d"}
+		edges := []*Edge{
+			NewEdge("ab", "a", "b", RelatedTo, 10.0),
+			NewEdge("cd", "c", "d", RelatedTo, 10.0),
+			NewEdge("bc", "b", "c", RelatedTo, 1.0),
+			NewEdge("ad", "a", "d", RelatedTo, 1.0),
+		}
+
+		communities := DetectCommunitiesLouvain(nodeIDs, edges, DefaultCommunityDetectionOptions())
+
+		Convey("Then heavy edges should hold their endpoints together", func() {
+			So(len(communities), ShouldEqual, 2)
+			So(communities[0].Nodes, ShouldResemble, []string{"a", "b"})
+			So(communities[1].Nodes, ShouldResemble, []string{"c", "d"})
+		})
+	})
+
+	Convey("Given nodes without edges", t, func() {
+		communities := DetectCommunitiesLouvain([]string{"x", "y"}, nil, DefaultCommunityDetectionOptions())
+
+		Convey("Then each node should be a singleton with zero modularity", func() {
+			So(len(communities), ShouldEqual, 2)
+			So(communities[0].Score, ShouldEqual, 0)
+			So(Modularity(communities), ShouldEqual, 0)
+		})
+	})
+
+	Convey("Given no nodes", t, func() {
+		communities := DetectCommunitiesLouvain(nil, nil, DefaultCommunityDetectionOptions())
+
+		Convey("Then no communities should be returned", func() {
+			So(communities, ShouldBeEmpty)
+		})
+	})
+}
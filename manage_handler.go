@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ManageHandler handles memory management operations
+type ManageHandler struct {
+	memoryManager *MemoryManager
+	config        *ManageHandlerConfig
+}
+
+// ManageHandlerConfig holds configuration for the manage handler
+type ManageHandlerConfig struct {
+	DefaultBatchSize  int           `json:"default_batch_size"`
+	ProcessingTimeout time.Duration `json:"processing_timeout"`
+}
+
+// NewManageHandler creates a new ManageHandler instance
+func NewManageHandler(memoryManager *MemoryManager) *ManageHandler {
+	config := &ManageHandlerConfig{
+		DefaultBatchSize:  100,
+		ProcessingTimeout: 30 * time.Second,
+	}
+
+	return &ManageHandler{
+		memoryManager: memoryManager,
+		config:        config,
+	}
+}
+
+// HandleManage processes a memory management request
+func (mh *ManageHandler) HandleManage(ctx context.Context, req *mcp.CallToolRequest, args ManageArgs) (*mcp.CallToolResult, ManageResult, error) {
+	log.Printf("Handling manage request: operation=%s, memoryIds=%v, query=%s, dryRun=%t", args.Operation, args.MemoryIDs, args.Query, args.DryRun)
+
+	options, err := mh.convertArgsToOptions(args)
+	if err != nil {
+		return nil, ManageResult{}, fmt.Errorf("argument validation failed: %w", err)
+	}
+
+	manageCtx, cancel := context.WithTimeout(ctx, mh.config.ProcessingTimeout)
+	defer cancel()
+
+	response, err := mh.memoryManager.Manage(manageCtx, options)
+	if err != nil {
+		return nil, ManageResult{}, fmt.Errorf("manage operation failed: %w", err)
+	}
+
+	result := ManageResult{
+		Operation:     response.Operation,
+		AffectedCount: response.AffectedCount,
+		Success:       response.Success,
+		Message:       response.Message,
+		DryRun:        options.DryRun,
+		Preview:       response.Preview,
+		Errors:        response.Errors,
+	}
+
+	mcpResult := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: result.Message,
+			},
+		},
+	}
+
+	log.Printf("Manage completed in %v: %s", response.ProcessingTime, result.Message)
+
+	return mcpResult, result, nil
+}
+
+// convertArgsToOptions converts ManageArgs to ManageOptions
+func (mh *ManageHandler) convertArgsToOptions(args ManageArgs) (*ManageOptions, error) {
+	options := NewManageOptions(strings.ToLower(strings.TrimSpace(args.Operation)))
+	options.MemoryIDs = args.MemoryIDs
+	options.Query = strings.TrimSpace(args.Query)
+	options.Confidence = args.Confidence
+	options.Force = args.Force
+	options.DryRun = args.DryRun
+	options.BatchSize = mh.config.DefaultBatchSize
+
+	// An unset half-life defers to the manager's default rather than the 24h option default
+	options.TTL = 0
+	if args.TTL != "" {
+		ttl, err := time.ParseDuration(args.TTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ttl %q: %w", args.TTL, err)
+		}
+		options.TTL = ttl
+	}
+
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	return options, nil
+}
+
+// GetConfig returns the current configuration
+func (mh *ManageHandler) GetConfig() *ManageHandlerConfig {
+	return mh.config
+}
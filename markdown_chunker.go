@@ -0,0 +1,431 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// headingPathSeparator joins the headings of a Markdown chunk's breadcrumb
+const headingPathSeparator = " > "
+
+// markdownBlockKind classifies the structural blocks of a Markdown document
+type markdownBlockKind int
+
+const (
+	markdownParagraph markdownBlockKind = iota
+	markdownHeading
+	markdownCode // Fenced code blocks and front matter
+	markdownTable
+	markdownList
+)
+
+// markdownBlock is a run of lines forming one block of a Markdown document
+type markdownBlock struct {
+	kind   markdownBlockKind
+	lines  []string
+	starts []int  // Rune offset of each line in the document
+	level  int    // Heading level, for headings
+	title  string // Heading text, for headings
+}
+
+var (
+	markdownHeadingPattern        = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	markdownSetextPattern         = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	markdownFencePattern          = regexp.MustCompile("^[ \t]*(`{3,}|~{3,})")
+	markdownBreakPattern          = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	markdownListItemPattern       = regexp.MustCompile(`^([ \t]*)(?:[-*+]|\d{1,9}[.)])(?:[ \t]+|$)`)
+	markdownTableRulePattern      = regexp.MustCompile(`^[ \t]*\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	markdownFrontMatterKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+:`)
+)
+
+// ChunkByMarkdown splits Markdown into chunks along its structure. Chunks never cross a
+// heading and record the headings they fall under. Fenced code blocks are never split; tables
+// and lists that exceed the maximum size are split only between rows and top-level items,
+// with each piece of a table repeating its header.
+func (tc *TextChunker) ChunkByMarkdown(text string) []ChunkResult {
+	if strings.TrimSpace(text) == "" {
+		return []ChunkResult{}
+	}
+
+	var chunks []ChunkResult
+	var headings []markdownBlock // The headings enclosing the current section, outermost first
+	var parts []string
+	var chunkStart, chunkEnd, currentSize int
+	titleOnly := false // Whether the pending chunk holds nothing but the section's heading
+
+	path := func() []string {
+		titles := make([]string, 0, len(headings))
+		for _, heading := range headings {
+			if heading.title != "" {
+				titles = append(titles, heading.title)
+			}
+		}
+		return titles
+	}
+
+	flush := func() {
+		if len(parts) > 0 && !titleOnly {
+			chunks = append(chunks, ChunkResult{
+				Text:     strings.Join(parts, "\n\n"),
+				Start:    chunkStart,
+				End:      chunkEnd,
+				Strategy: "markdown",
+				Headings: path(),
+			})
+		}
+		parts = nil
+		currentSize = 0
+		titleOnly = false
+	}
+
+	add := func(piece ChunkResult) {
+		size := utf8.RuneCountInString(piece.Text)
+		// A heading always stays with the block that follows it
+		if len(parts) > 0 && !titleOnly && currentSize+2+size > tc.maxChunkSize {
+			flush()
+		}
+		if len(parts) == 0 {
+			chunkStart = piece.Start
+		} else {
+			currentSize += 2
+		}
+		parts = append(parts, piece.Text)
+		currentSize += size
+		chunkEnd = piece.End
+		titleOnly = false
+	}
+
+	for _, block := range parseMarkdownBlocks(text) {
+		if block.kind == markdownHeading {
+			// A heading with nothing under it lives on only in its subsections' breadcrumbs
+			flush()
+			for len(headings) > 0 && headings[len(headings)-1].level >= block.level {
+				headings = headings[:len(headings)-1]
+			}
+			headings = append(headings, block)
+
+			add(block.piece(0, len(block.lines)))
+			titleOnly = true
+			continue
+		}
+
+		for _, piece := range tc.markdownPieces(block) {
+			add(piece)
+		}
+	}
+	flush()
+
+	if len(chunks) == 0 {
+		// Nothing but headings
+		trimmed := strings.TrimSpace(text)
+		return []ChunkResult{{
+			Text:     trimmed,
+			Start:    0,
+			End:      utf8.RuneCountInString(text),
+			Strategy: "markdown",
+		}}
+	}
+
+	return chunks
+}
+
+// markdownPieces splits a block into pieces that fit the maximum chunk size where its kind
+// allows it
+func (tc *TextChunker) markdownPieces(block markdownBlock) []ChunkResult {
+	whole := block.piece(0, len(block.lines))
+	if utf8.RuneCountInString(whole.Text) <= tc.maxChunkSize {
+		return []ChunkResult{whole}
+	}
+
+	switch block.kind {
+	case markdownParagraph:
+		var pieces []ChunkResult
+		for _, sentences := range tc.ChunkBySentence(whole.Text) {
+			pieces = append(pieces, ChunkResult{
+				Text:  sentences.Text,
+				Start: whole.Start + sentences.Start,
+				End:   whole.Start + sentences.End,
+			})
+		}
+		return pieces
+	case markdownTable:
+		if len(block.lines) <= 3 {
+			return []ChunkResult{whole}
+		}
+		// Every piece repeats the header and its rule so the rows keep their columns
+		header := strings.Join(block.lines[:2], "\n")
+		var runs [][2]int
+		for i := 2; i < len(block.lines); i++ {
+			runs = append(runs, [2]int{i, i + 1})
+		}
+		return tc.packMarkdownRuns(block, header, runs)
+	case markdownList:
+		return tc.packMarkdownRuns(block, "", block.listItems())
+	default:
+		return []ChunkResult{whole}
+	}
+}
+
+// packMarkdownRuns groups consecutive runs of a block's lines into pieces no longer than the
+// maximum chunk size, never breaking a run. The header, if any, opens every piece.
+func (tc *TextChunker) packMarkdownRuns(block markdownBlock, header string, runs [][2]int) []ChunkResult {
+	var pieces []ChunkResult
+	from := -1
+	var to int
+
+	emit := func() {
+		piece := block.piece(runs[from][0], to)
+		if header != "" {
+			piece.Text = header + "\n" + piece.Text
+		}
+		if len(pieces) == 0 {
+			piece.Start = block.starts[0]
+		}
+		pieces = append(pieces, piece)
+	}
+
+	for i, run := range runs {
+		if from >= 0 {
+			candidate := block.piece(runs[from][0], run[1]).Text
+			if header != "" {
+				candidate = header + "\n" + candidate
+			}
+			if utf8.RuneCountInString(candidate) > tc.maxChunkSize {
+				emit()
+				from = -1
+			}
+		}
+		if from < 0 {
+			from = i
+		}
+		to = run[1]
+	}
+	if from >= 0 {
+		emit()
+	}
+
+	return pieces
+}
+
+// piece returns lines [from, to) of the block as a chunk, without trailing blank lines
+func (b markdownBlock) piece(from, to int) ChunkResult {
+	for to > from+1 && strings.TrimSpace(b.lines[to-1]) == "" {
+		to--
+	}
+	last := to - 1
+	return ChunkResult{
+		Text:  strings.Join(b.lines[from:to], "\n"),
+		Start: b.starts[from],
+		End:   b.starts[last] + utf8.RuneCountInString(b.lines[last]),
+	}
+}
+
+// listItems returns the line ranges of a list's top-level items
+func (b markdownBlock) listItems() [][2]int {
+	indent := markdownIndent(b.lines[0])
+	var items [][2]int
+	start := 0
+	for i := 1; i < len(b.lines); i++ {
+		if markdownListItemPattern.MatchString(b.lines[i]) && markdownIndent(b.lines[i]) <= indent {
+			items = append(items, [2]int{start, i})
+			start = i
+		}
+	}
+	return append(items, [2]int{start, len(b.lines)})
+}
+
+// parseMarkdownBlocks splits a Markdown document into blocks, keeping fenced code blocks,
+// tables and lists whole. Blank lines and thematic breaks separate blocks and belong to none.
+func parseMarkdownBlocks(text string) []markdownBlock {
+	lines, starts := markdownLines(text)
+	var blocks []markdownBlock
+
+	block := func(kind markdownBlockKind, from, to int) markdownBlock {
+		return markdownBlock{kind: kind, lines: lines[from:to], starts: starts[from:to]}
+	}
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+
+		case i == 0 && markdownFrontMatterEnd(lines) > 0:
+			end := markdownFrontMatterEnd(lines)
+			blocks = append(blocks, block(markdownCode, 0, end))
+			i = end
+
+		case markdownFencePattern.MatchString(line):
+			end := markdownFenceEnd(lines, i)
+			blocks = append(blocks, block(markdownCode, i, end))
+			i = end
+
+		case markdownHeadingPattern.MatchString(line):
+			match := markdownHeadingPattern.FindStringSubmatch(line)
+			heading := block(markdownHeading, i, i+1)
+			heading.level = len(match[1])
+			heading.title = strings.TrimSpace(match[2])
+			blocks = append(blocks, heading)
+			i++
+
+		case markdownBreakPattern.MatchString(line):
+			i++
+
+		case markdownTableStart(lines, i):
+			end := i + 2
+			for end < len(lines) && strings.TrimSpace(lines[end]) != "" && strings.Contains(lines[end], "|") {
+				end++
+			}
+			blocks = append(blocks, block(markdownTable, i, end))
+			i = end
+
+		case markdownListItemPattern.MatchString(line):
+			end := markdownListEnd(lines, i)
+			blocks = append(blocks, block(markdownList, i, end))
+			i = end
+
+		case i+1 < len(lines) && markdownSetextPattern.MatchString(lines[i+1]):
+			heading := block(markdownHeading, i, i+2)
+			heading.level = 2
+			if strings.Contains(lines[i+1], "=") {
+				heading.level = 1
+			}
+			heading.title = strings.TrimSpace(line)
+			blocks = append(blocks, heading)
+			i += 2
+
+		default:
+			end := i + 1
+			for end < len(lines) && strings.TrimSpace(lines[end]) != "" && !markdownStartsBlock(lines, end) {
+				end++
+			}
+			blocks = append(blocks, block(markdownParagraph, i, end))
+			i = end
+		}
+	}
+
+	return blocks
+}
+
+// markdownLines splits text into lines and the rune offset each starts at
+func markdownLines(text string) ([]string, []int) {
+	var lines []string
+	var starts []int
+	offset := 0
+	for _, raw := range strings.SplitAfter(text, "\n") {
+		if raw == "" {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(raw, "\r\n"))
+		starts = append(starts, offset)
+		offset += utf8.RuneCountInString(raw)
+	}
+	return lines, starts
+}
+
+// markdownStartsBlock reports whether the line interrupts a paragraph by opening another block
+func markdownStartsBlock(lines []string, i int) bool {
+	line := lines[i]
+	return markdownFencePattern.MatchString(line) ||
+		markdownHeadingPattern.MatchString(line) ||
+		markdownBreakPattern.MatchString(line) ||
+		markdownListItemPattern.MatchString(line) ||
+		markdownTableStart(lines, i)
+}
+
+// markdownTableStart reports whether a table's header row and rule start at the line
+func markdownTableStart(lines []string, i int) bool {
+	return i+1 < len(lines) &&
+		strings.Contains(lines[i], "|") &&
+		strings.Contains(lines[i+1], "|") &&
+		markdownTableRulePattern.MatchString(lines[i+1])
+}
+
+// markdownFenceEnd returns the index just past the line closing the fence opened at start, or
+// the number of lines when the fence is never closed
+func markdownFenceEnd(lines []string, start int) int {
+	fence := markdownFencePattern.FindStringSubmatch(lines[start])[1]
+	for i := start + 1; i < len(lines); i++ {
+		closing := strings.TrimSpace(lines[i])
+		if len(closing) >= len(fence) && strings.Trim(closing, fence[:1]) == "" {
+			return i + 1
+		}
+	}
+	return len(lines)
+}
+
+// markdownFrontMatterEnd returns the index just past the line closing the YAML front matter
+// that opens the document, or -1 when the document does not open with front matter
+func markdownFrontMatterEnd(lines []string) int {
+	if len(lines) < 3 || strings.TrimSpace(lines[0]) != "---" || !markdownFrontMatterKeyPattern.MatchString(lines[1]) {
+		return -1
+	}
+	for i := 2; i < len(lines); i++ {
+		if closing := strings.TrimSpace(lines[i]); closing == "---" || closing == "..." {
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// markdownListEnd returns the index just past the last line of the list starting at start.
+// Items, indented lines and lazily continued lines belong to the list; a blank line ends it
+// unless another item or an indented line follows.
+func markdownListEnd(lines []string, start int) int {
+	end := start + 1
+	inFence := false
+	for end < len(lines) {
+		line := lines[end]
+		if markdownFencePattern.MatchString(line) && markdownIndent(line) > 0 {
+			inFence = !inFence
+			end++
+			continue
+		}
+		if inFence {
+			end++
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			next := end + 1
+			for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+				next++
+			}
+			if next < len(lines) && (markdownListItemPattern.MatchString(lines[next]) || markdownIndent(lines[next]) >= 2) {
+				end = next
+				continue
+			}
+			break
+		}
+
+		if markdownIndent(line) == 0 && !markdownListItemPattern.MatchString(line) && markdownStartsBlock(lines, end) {
+			break
+		}
+		end++
+	}
+	return end
+}
+
+// markdownIndent measures a line's indentation, counting a tab as four spaces
+func markdownIndent(line string) int {
+	indent := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			indent++
+		case '\t':
+			indent += 4
+		default:
+			return indent
+		}
+	}
+	return indent
+}
+
+// headingPath reads the breadcrumb of headings a Markdown chunk falls under from its metadata
+func headingPath(metadata map[string]interface{}) string {
+	path, _ := metadata["heading_path"].(string)
+	return path
+}
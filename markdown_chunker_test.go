@@ -0,0 +1,168 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTextChunkerMarkdown(t *testing.T) {
+	Convey("Given a Markdown document", t, func() {
+		doc := strings.Join([]string{
+			"---",
+			"title: Guide",
+			"---",
+			"",
+			"# Setup",
+			"",
+			"Install the tools first.",
+			"",
+			"## Database",
+			"",
+			"### Migrations",
+			"",
+			"Run migrations with make:",
+			"",
+			"```sh",
+			"make migrate",
+			"",
+			"make seed",
+			"```",
+			"",
+			"| Name | Value |",
+			"|------|-------|",
+			"| a | 1 |",
+			"",
+			"Settings",
+			"--------",
+			"",
+			"- first item",
+			"  continued",
+			"",
+			"- second item",
+			"  - nested item",
+		}, "\n")
+
+		Convey("When it is chunked with room for whole sections", func() {
+			chunks := NewTextChunker(200, 20).ChunkByMarkdown(doc)
+
+			Convey("Then chunks should follow the sections and record their headings", func() {
+				So(chunks, ShouldHaveLength, 4)
+				So(chunks[0].Text, ShouldEqual, "---\ntitle: Guide\n---")
+				So(chunks[0].Headings, ShouldBeEmpty)
+
+				So(chunks[1].Text, ShouldEqual, "# Setup\n\nInstall the tools first.")
+				So(chunks[1].Headings, ShouldResemble, []string{"Setup"})
+
+				// The empty Database section is only part of the breadcrumb
+				So(chunks[2].Text, ShouldStartWith, "### Migrations\n\nRun migrations with make:\n\n```sh\nmake migrate\n\nmake seed\n```")
+				So(chunks[2].Text, ShouldEndWith, "| Name | Value |\n|------|-------|\n| a | 1 |")
+				So(chunks[2].Headings, ShouldResemble, []string{"Setup", "Database", "Migrations"})
+
+				So(chunks[3].Text, ShouldEqual, "Settings\n--------\n\n- first item\n  continued\n\n- second item\n  - nested item")
+				So(chunks[3].Headings, ShouldResemble, []string{"Setup", "Settings"})
+
+				for _, chunk := range chunks {
+					So(chunk.Strategy, ShouldEqual, "markdown")
+					So(string([]rune(doc)[chunk.Start:chunk.End]), ShouldEqual, chunk.Text)
+				}
+			})
+		})
+
+		Convey("When it is chunked with little room", func() {
+			chunks := NewTextChunker(30, 0).ChunkByMarkdown(doc)
+
+			Convey("Then code blocks, tables and list items should stay whole", func() {
+				var texts []string
+				for _, chunk := range chunks {
+					texts = append(texts, chunk.Text)
+				}
+				So(texts, ShouldContain, "```sh\nmake migrate\n\nmake seed\n```")
+				So(texts, ShouldContain, "| Name | Value |\n|------|-------|\n| a | 1 |")
+				So(texts, ShouldContain, "Settings\n--------\n\n- first item\n  continued")
+				So(texts, ShouldContain, "- second item\n  - nested item")
+			})
+
+			Convey("Then a heading should stay with the block that follows it", func() {
+				So(chunks[1].Text, ShouldEqual, "# Setup\n\nInstall the tools first.")
+				for _, chunk := range chunks {
+					So(markdownHeadingPattern.MatchString(chunk.Text), ShouldBeFalse)
+				}
+			})
+		})
+	})
+
+	Convey("Given an oversized table", t, func() {
+		rows := []string{"| Key | Value |", "| --- | ----- |"}
+		for i := 0; i < 6; i++ {
+			rows = append(rows, "| key | value |")
+		}
+		chunks := NewTextChunker(60, 0).ChunkByMarkdown(strings.Join(rows, "\n"))
+
+		Convey("Then it should be split between rows, each piece repeating the header", func() {
+			So(len(chunks), ShouldBeGreaterThan, 1)
+			total := 0
+			for _, chunk := range chunks {
+				So(chunk.Text, ShouldStartWith, "| Key | Value |\n| --- | ----- |\n| key | value |")
+				So(utf8.RuneCountInString(chunk.Text), ShouldBeLessThanOrEqualTo, 60)
+				total += strings.Count(chunk.Text, "| key | value |")
+			}
+			So(total, ShouldEqual, 6)
+		})
+	})
+
+	Convey("Given a document of headings alone", t, func() {
+		chunks := NewTextChunker(100, 0).ChunkByMarkdown("# Title\n\n## Empty")
+
+		Convey("Then the whole document should be one chunk", func() {
+			So(chunks, ShouldHaveLength, 1)
+			So(chunks[0].Text, ShouldEqual, "# Title\n\n## Empty")
+		})
+	})
+
+	Convey("Given empty text", t, func() {
+		So(NewTextChunker(100, 0).ChunkByMarkdown(" \n"), ShouldBeEmpty)
+	})
+}
+
+func TestParseMarkdownBlocks(t *testing.T) {
+	Convey("Given Markdown with every kind of block", t, func() {
+		blocks := parseMarkdownBlocks(strings.Join([]string{
+			"## Title ##",
+			"Some text",
+			"continued",
+			"* * *",
+			"~~~",
+			"# not a heading",
+			"~~~",
+			"1. one",
+			"2. two",
+			"Name | Value",
+			"--- | ---",
+			"x | y",
+		}, "\n"))
+
+		Convey("Then each should be recognized", func() {
+			var kinds []markdownBlockKind
+			for _, block := range blocks {
+				kinds = append(kinds, block.kind)
+			}
+			So(kinds, ShouldResemble, []markdownBlockKind{markdownHeading, markdownParagraph, markdownCode, markdownList, markdownTable})
+			So(blocks[0].level, ShouldEqual, 2)
+			So(blocks[0].title, ShouldEqual, "Title")
+			So(blocks[1].lines, ShouldResemble, []string{"Some text", "continued"})
+			So(blocks[2].lines, ShouldHaveLength, 3)
+		})
+	})
+
+	Convey("Given a document opening with a thematic break", t, func() {
+		blocks := parseMarkdownBlocks("---\n\nText\n\n---\n\nMore")
+
+		Convey("Then it should not be read as front matter", func() {
+			So(blocks, ShouldHaveLength, 2)
+			So(blocks[0].kind, ShouldEqual, markdownParagraph)
+		})
+	})
+}
@@ -0,0 +1,562 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// MemoryManager implements governance operations over stored memories
+type MemoryManager struct {
+	storage       *MultiViewStorage
+	recallHandler *RecallHandler
+	searcher      *VectorSearcher
+	config        *MemoryManagerConfig
+}
+
+// MemoryManagerConfig holds configuration for memory governance
+type MemoryManagerConfig struct {
+	DefaultHalfLife   time.Duration `json:"default_half_life"`
+	MergeSimilarity   float64       `json:"merge_similarity"`
+	MaxQuerySelection int           `json:"max_query_selection"`
+}
+
+// managedMemory is a memory's state as read from the vector and search views
+type managedMemory struct {
+	ID        string
+	Content   string
+	Embedding []float32
+	Metadata  map[string]interface{}
+	inVector  bool
+	inSearch  bool
+}
+
+// NewMemoryManager creates a new MemoryManager with default configuration
+func NewMemoryManager(storage *MultiViewStorage, recallHandler *RecallHandler) *MemoryManager {
+	return NewMemoryManagerWithConfig(storage, recallHandler, nil)
+}
+
+// NewMemoryManagerWithConfig creates a new MemoryManager with custom configuration
+func NewMemoryManagerWithConfig(storage *MultiViewStorage, recallHandler *RecallHandler, config *MemoryManagerConfig) *MemoryManager {
+	cfg := MemoryManagerConfig{}
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.DefaultHalfLife <= 0 {
+		cfg.DefaultHalfLife = 30 * 24 * time.Hour
+	}
+	if cfg.MergeSimilarity <= 0 {
+		cfg.MergeSimilarity = 0.92
+	}
+	if cfg.MaxQuerySelection <= 0 {
+		cfg.MaxQuerySelection = 50
+	}
+
+	return &MemoryManager{
+		storage:       storage,
+		recallHandler: recallHandler,
+		searcher:      NewVectorSearcher(),
+		config:        &cfg,
+	}
+}
+
+// Manage selects memories by ID or query and applies the requested operation
+func (mm *MemoryManager) Manage(ctx context.Context, options *ManageOptions) (*ManageResponse, error) {
+	startTime := time.Now()
+
+	if options == nil {
+		return nil, fmt.Errorf("manage options cannot be nil")
+	}
+	if err := options.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid manage options: %w", err)
+	}
+
+	response := NewManageResponse(options.Operation)
+	response.Success = true
+
+	memories, err := mm.selectMemories(ctx, options, response)
+	if err != nil {
+		return nil, err
+	}
+
+	switch options.Operation {
+	case "pin":
+		mm.pin(ctx, memories, options, response)
+	case "forget":
+		mm.forget(ctx, memories, options, response)
+	case "decay":
+		mm.decay(ctx, memories, options, response)
+	case "merge":
+		mm.merge(ctx, memories, options, response)
+	}
+
+	response.ProcessingTime = time.Since(startTime)
+	response.Message = mm.summarize(options, response, len(memories))
+
+	return response, nil
+}
+
+// selectMemories resolves explicit IDs, or runs a recall query when none are given
+func (mm *MemoryManager) selectMemories(ctx context.Context, options *ManageOptions, response *ManageResponse) ([]*managedMemory, error) {
+	ids := options.MemoryIDs
+	if len(ids) == 0 && options.Query != "" {
+		if mm.recallHandler == nil {
+			return nil, fmt.Errorf("query selection requires a recall handler")
+		}
+		selected, err := mm.recallHandler.SelectMemoryIDs(ctx, options.Query, mm.config.MaxQuerySelection)
+		if err != nil {
+			return nil, fmt.Errorf("failed to select memories by query: %w", err)
+		}
+		ids = selected
+	}
+
+	seen := make(map[string]bool, len(ids))
+	memories := make([]*managedMemory, 0, len(ids))
+	for i, id := range ids {
+		if i > 0 && i%options.BatchSize == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		memory, err := mm.loadMemory(ctx, id)
+		if err != nil {
+			response.AddError(err.Error())
+			continue
+		}
+		memories = append(memories, memory)
+	}
+
+	return memories, nil
+}
+
+// loadMemory reads a memory from the vector and search views
+func (mm *MemoryManager) loadMemory(ctx context.Context, id string) (*managedMemory, error) {
+	memory := &managedMemory{ID: id, Metadata: make(map[string]interface{})}
+
+	if record, err := mm.storage.vectorStore.GetByID(ctx, id); err == nil {
+		if record.Metadata["type"] == "entity" {
+			return nil, fmt.Errorf("memory %s is an entity, not a stored memory", id)
+		}
+		memory.inVector = true
+		memory.Embedding = record.Embedding
+		memory.Content = record.Content
+		for k, v := range record.Metadata {
+			memory.Metadata[k] = v
+		}
+	}
+
+	if doc, err := mm.storage.searchIndex.GetDocument(ctx, id); err == nil {
+		memory.inSearch = true
+		memory.Content = doc.Content
+		for k, v := range doc.Metadata {
+			if _, exists := memory.Metadata[k]; !exists {
+				memory.Metadata[k] = v
+			}
+		}
+	}
+
+	if !memory.inVector && !memory.inSearch {
+		return nil, fmt.Errorf("memory not found: %s", id)
+	}
+
+	delete(memory.Metadata, "content")
+	return memory, nil
+}
+
+// updateMemory sets metadata keys on a memory and writes them through storage, which
+// updates the vector and search views together under its write lock
+func (mm *MemoryManager) updateMemory(ctx context.Context, memory *managedMemory, changes map[string]interface{}) error {
+	for k, v := range changes {
+		memory.Metadata[k] = v
+	}
+	err := mm.storage.UpdateChunkMetadata(ctx, memory.ID, func(metadata map[string]interface{}) {
+		for k, v := range changes {
+			metadata[k] = v
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update metadata for %s: %w", memory.ID, err)
+	}
+	return nil
+}
+
+// pin marks memories so decay and forget leave them alone
+func (mm *MemoryManager) pin(ctx context.Context, memories []*managedMemory, options *ManageOptions, response *ManageResponse) {
+	for _, memory := range memories {
+		if options.DryRun {
+			response.AddPreviewItem(fmt.Sprintf("pin %s", memory.ID))
+			response.AffectedCount++
+			continue
+		}
+
+		err := mm.updateMemory(ctx, memory, map[string]interface{}{
+			"pinned":    true,
+			"pinned_at": time.Now().Format(time.RFC3339),
+		})
+		if err != nil {
+			response.AddError(err.Error())
+			continue
+		}
+		response.AffectedCount++
+	}
+}
+
+// forget removes memories from every view, skipping pinned ones unless forced
+func (mm *MemoryManager) forget(ctx context.Context, memories []*managedMemory, options *ManageOptions, response *ManageResponse) {
+	for _, memory := range memories {
+		if isPinned(memory.Metadata) && !options.Force {
+			response.AddError(fmt.Sprintf("memory %s is pinned; use force to forget it", memory.ID))
+			continue
+		}
+
+		if options.DryRun {
+			response.AddPreviewItem(fmt.Sprintf("forget %s", memory.ID))
+			response.AffectedCount++
+			continue
+		}
+
+		if err := mm.deleteMemory(ctx, memory); err != nil {
+			response.AddError(err.Error())
+			continue
+		}
+		response.AffectedCount++
+	}
+}
+
+// decay lowers confidence by the half-life elapsed since the last decay and forgets memories below the threshold
+func (mm *MemoryManager) decay(ctx context.Context, memories []*managedMemory, options *ManageOptions, response *ManageResponse) {
+	halfLife := options.TTL
+	if halfLife <= 0 {
+		halfLife = mm.config.DefaultHalfLife
+	}
+	now := time.Now()
+
+	for _, memory := range memories {
+		if isPinned(memory.Metadata) {
+			if options.DryRun {
+				response.AddPreviewItem(fmt.Sprintf("skip %s: pinned", memory.ID))
+			}
+			continue
+		}
+
+		current := memoryConfidence(memory.Metadata)
+		age := now.Sub(memoryDecayReference(memory.Metadata, now))
+		decayed := current * math.Pow(0.5, float64(age)/float64(halfLife))
+		forget := options.Confidence > 0 && decayed < options.Confidence
+
+		if options.DryRun {
+			if forget {
+				response.AddPreviewItem(fmt.Sprintf("decay %s: %.3f -> %.3f, forget (below %.3f)", memory.ID, current, decayed, options.Confidence))
+			} else {
+				response.AddPreviewItem(fmt.Sprintf("decay %s: %.3f -> %.3f", memory.ID, current, decayed))
+			}
+			response.AffectedCount++
+			continue
+		}
+
+		var err error
+		if forget {
+			err = mm.deleteMemory(ctx, memory)
+		} else {
+			err = mm.updateMemory(ctx, memory, map[string]interface{}{
+				"confidence":      decayed,
+				"last_decayed_at": now.Format(time.RFC3339),
+			})
+		}
+		if err != nil {
+			response.AddError(err.Error())
+			continue
+		}
+		response.AffectedCount++
+	}
+}
+
+// merge folds duplicate memories into one; explicit IDs form one group, query selections are grouped by similarity
+func (mm *MemoryManager) merge(ctx context.Context, memories []*managedMemory, options *ManageOptions, response *ManageResponse) {
+	var groups [][]*managedMemory
+	if len(options.MemoryIDs) > 0 {
+		if len(memories) < 2 {
+			response.AddError("merge requires at least two existing memories")
+			return
+		}
+		groups = [][]*managedMemory{memories}
+	} else {
+		groups = mm.groupDuplicates(memories)
+	}
+
+	for _, group := range groups {
+		canonical, duplicates := mm.chooseCanonical(group)
+
+		if options.DryRun {
+			for _, duplicate := range duplicates {
+				response.AddPreviewItem(fmt.Sprintf("merge %s into %s", duplicate.ID, canonical.ID))
+			}
+			response.AffectedCount += len(duplicates)
+			continue
+		}
+
+		merged, err := mm.mergeInto(ctx, canonical, duplicates)
+		if err != nil {
+			response.AddError(err.Error())
+		}
+		response.AffectedCount += merged
+	}
+}
+
+// groupDuplicates greedily clusters memories whose embeddings exceed the merge similarity
+func (mm *MemoryManager) groupDuplicates(memories []*managedMemory) [][]*managedMemory {
+	var groups [][]*managedMemory
+	assigned := make([]bool, len(memories))
+
+	for i, memory := range memories {
+		if assigned[i] || len(memory.Embedding) == 0 {
+			continue
+		}
+		group := []*managedMemory{memory}
+		for j := i + 1; j < len(memories); j++ {
+			if assigned[j] || len(memories[j].Embedding) == 0 {
+				continue
+			}
+			if mm.searcher.CosineSimilarity(memory.Embedding, memories[j].Embedding) >= mm.config.MergeSimilarity {
+				group = append(group, memories[j])
+				assigned[j] = true
+			}
+		}
+		if len(group) > 1 {
+			groups = append(groups, group)
+		}
+	}
+
+	return groups
+}
+
+// chooseCanonical prefers a pinned memory, then the most confident one, then the first listed
+func (mm *MemoryManager) chooseCanonical(group []*managedMemory) (*managedMemory, []*managedMemory) {
+	ordered := make([]*managedMemory, len(group))
+	copy(ordered, group)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		pi, pj := isPinned(ordered[i].Metadata), isPinned(ordered[j].Metadata)
+		if pi != pj {
+			return pi
+		}
+		return memoryConfidence(ordered[i].Metadata) > memoryConfidence(ordered[j].Metadata)
+	})
+	return ordered[0], ordered[1:]
+}
+
+// mergeInto combines duplicate metadata into the canonical memory and removes the duplicates
+func (mm *MemoryManager) mergeInto(ctx context.Context, canonical *managedMemory, duplicates []*managedMemory) (int, error) {
+	tags := metadataStrings(canonical.Metadata["tags"])
+	mergedFrom := metadataStrings(canonical.Metadata["merged_from"])
+	confidence := memoryConfidence(canonical.Metadata)
+	pinned := isPinned(canonical.Metadata)
+
+	for _, duplicate := range duplicates {
+		tags = appendUnique(tags, metadataStrings(duplicate.Metadata["tags"])...)
+		mergedFrom = appendUnique(mergedFrom, duplicate.ID)
+		if c := memoryConfidence(duplicate.Metadata); c > confidence {
+			confidence = c
+		}
+		if isPinned(duplicate.Metadata) {
+			pinned = true
+		}
+	}
+
+	changes := map[string]interface{}{
+		"tags":        tags,
+		"merged_from": mergedFrom,
+		"confidence":  confidence,
+	}
+	if pinned {
+		changes["pinned"] = true
+	}
+	if err := mm.updateMemory(ctx, canonical, changes); err != nil {
+		return 0, err
+	}
+
+	merged := 0
+	for _, duplicate := range duplicates {
+		if err := mm.repointGraphNodes(ctx, duplicate.ID, canonical.ID); err != nil {
+			return merged, err
+		}
+		if err := mm.deleteMemory(ctx, duplicate); err != nil {
+			return merged, err
+		}
+		merged++
+	}
+
+	return merged, nil
+}
+
+// repointGraphNodes moves entity and claim nodes, and their PART_OF edges, from one chunk to another,
+// ahead of the first chunk's deletion. Entities only gain a PART_OF edge, since they may still belong
+// to other chunks too
+func (mm *MemoryManager) repointGraphNodes(ctx context.Context, fromID, toID string) error {
+	entities, err := chunkEntities(ctx, mm.storage.graphStore, fromID)
+	if err != nil {
+		return fmt.Errorf("failed to find entities of %s: %w", fromID, err)
+	}
+	kept, err := chunkEntities(ctx, mm.storage.graphStore, toID)
+	if err != nil {
+		return fmt.Errorf("failed to find entities of %s: %w", toID, err)
+	}
+	if err := repointChunkEdges(ctx, mm.storage.graphStore, entities, toID); err != nil {
+		return fmt.Errorf("failed to repoint graph edges: %w", err)
+	}
+	if err := mm.addMovedCooccurrences(ctx, kept, entities, toID); err != nil {
+		return err
+	}
+
+	claims, err := mm.storage.graphStore.FindNodesByType(ctx, ClaimNode, map[string]interface{}{"chunk_id": fromID})
+	if err == nil {
+		for _, node := range claims {
+			updated := *node
+			updated.Properties = make(map[string]interface{}, len(node.Properties))
+			for k, v := range node.Properties {
+				updated.Properties[k] = v
+			}
+			updated.Properties["chunk_id"] = toID
+			updated.UpdatedAt = time.Now()
+			if err := mm.storage.graphStore.UpdateNode(ctx, &updated); err != nil {
+				return fmt.Errorf("failed to repoint graph node %s: %w", node.ID, err)
+			}
+		}
+		if err := repointChunkEdges(ctx, mm.storage.graphStore, claims, toID); err != nil {
+			return fmt.Errorf("failed to repoint graph edges: %w", err)
+		}
+	}
+	return nil
+}
+
+// addMovedCooccurrences counts the chunk a merge moved entities onto towards the co-occurrences
+// of each pair it newly holds, since deleting the merged duplicate takes its pairs' counts away
+func (mm *MemoryManager) addMovedCooccurrences(ctx context.Context, kept, moved []*Node, chunkID string) error {
+	if _, err := mm.storage.graphStore.GetNode(ctx, chunkID); err != nil {
+		return nil
+	}
+
+	held := make(map[string]bool, len(kept))
+	var ids []string
+	for _, node := range kept {
+		held[node.ID] = true
+		ids = append(ids, node.ID)
+	}
+	for _, node := range moved {
+		ids = append(ids, node.ID)
+	}
+	ids = uniqueSorted(ids)
+
+	for i := 0; i < len(ids); i++ {
+		for j := i + 1; j < len(ids); j++ {
+			if held[ids[i]] && held[ids[j]] {
+				continue
+			}
+			if err := addCooccurrence(ctx, mm.storage.graphStore, ids[i], ids[j]); err != nil {
+				return fmt.Errorf("failed to link co-occurring entities: %w", err)
+			}
+			if err := addCooccurrence(ctx, mm.storage.graphStore, ids[j], ids[i]); err != nil {
+				return fmt.Errorf("failed to link co-occurring entities: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// deleteMemory removes a memory from every view through storage, which keeps the entities
+// other memories still mention and forgets the memory's fingerprint and external ID
+func (mm *MemoryManager) deleteMemory(ctx context.Context, memory *managedMemory) error {
+	return mm.storage.DeleteChunk(ctx, memory.ID)
+}
+
+// summarize builds the human-readable result message
+func (mm *MemoryManager) summarize(options *ManageOptions, response *ManageResponse, selected int) string {
+	if selected == 0 && len(response.Errors) == 0 {
+		return fmt.Sprintf("No memories selected for %s", options.Operation)
+	}
+	prefix := ""
+	if options.DryRun {
+		prefix = "Dry run: would have "
+	}
+	message := fmt.Sprintf("%sapplied %s to %d of %d memories", prefix, options.Operation, response.AffectedCount, selected)
+	if len(response.Errors) > 0 {
+		message += fmt.Sprintf(" (%d errors)", len(response.Errors))
+	}
+	return message
+}
+
+// GetConfig returns the current configuration
+func (mm *MemoryManager) GetConfig() *MemoryManagerConfig {
+	return mm.config
+}
+
+// isPinned reports whether memory metadata marks it as pinned
+func isPinned(metadata map[string]interface{}) bool {
+	pinned, ok := metadata["pinned"].(bool)
+	return ok && pinned
+}
+
+// memoryConfidence returns the memory's current confidence, defaulting to full confidence
+func memoryConfidence(metadata map[string]interface{}) float64 {
+	for _, key := range []string{"confidence", "write_confidence"} {
+		switch v := metadata[key].(type) {
+		case float64:
+			return v
+		case float32:
+			return float64(v)
+		}
+	}
+	return 1.0
+}
+
+// memoryDecayReference returns when decay was last applied, falling back to the write time
+func memoryDecayReference(metadata map[string]interface{}, now time.Time) time.Time {
+	for _, key := range []string{"last_decayed_at", "timestamp"} {
+		if value, ok := metadata[key].(string); ok {
+			if t, err := time.Parse(time.RFC3339, value); err == nil {
+				return t
+			}
+		}
+	}
+	return now
+}
+
+// metadataStrings reads a string list from metadata that may have round-tripped through JSON
+func metadataStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return append([]string(nil), v...)
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// appendUnique appends values not already present
+func appendUnique(values []string, additions ...string) []string {
+	for _, addition := range additions {
+		found := false
+		for _, existing := range values {
+			if existing == addition {
+				found = true
+				break
+			}
+		}
+		if !found {
+			values = append(values, addition)
+		}
+	}
+	return values
+}
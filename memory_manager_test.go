@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// newTestMemoryManager wires a writer and manager over shared mock storage
+func newTestMemoryManager() (*MemoryManager, *MemoryWriter, *MultiViewStorage) {
+	storage := NewMultiViewStorage(NewMockVectorStore(), NewMockGraphStore(), NewMockSearchIndex(), &MultiViewStorageConfig{Timeout: 5 * time.Second})
+	embedder := NewHashEmbedder(128)
+
+	contentProcessor := NewContentProcessor()
+	contentProcessor.SetEmbedder(embedder)
+	writer := NewMemoryWriter(storage, contentProcessor, nil)
+	writer.SetEmbedder(embedder)
+
+	queryProcessor := NewQueryProcessor(nil)
+	queryProcessor.SetEmbedder(embedder)
+	queryProcessor.SetVectorSearcher(NewVectorSearcherWithStore(storage.vectorStore, nil))
+	queryProcessor.SetKeywordSearcher(NewKeywordSearcherWithIndex(storage.searchIndex, nil))
+	queryProcessor.SetGraphSearcher(NewGraphSearcherWithStore(storage.graphStore, storage.searchIndex, nil))
+	recallHandler := NewRecallHandler(queryProcessor, NewResultFuser())
+
+	return NewMemoryManager(storage, recallHandler), writer, storage
+}
+
+// writeTestMemory stores content under a unique source and returns its memory ID
+func writeTestMemory(writer *MemoryWriter, content, source string, timestamp time.Time) string {
+	metadata := NewWriteMetadata(source)
+	metadata.Timestamp = timestamp
+	metadata.Tags = []string{source}
+	result, err := writer.Write(context.Background(), content, *metadata)
+	So(err, ShouldBeNil)
+	return result.MemoryID
+}
+
+func TestMemoryManager(t *testing.T) {
+	Convey("Given a MemoryManager over stored memories", t, func() {
+		ctx := context.Background()
+		manager, writer, storage := newTestMemoryManager()
+		now := time.Now()
+
+		eiffel := writeTestMemory(writer, "The Eiffel Tower is located in Paris and was completed in 1889.", "landmarks", now)
+		plants := writeTestMemory(writer, "Photosynthesis converts sunlight into chemical energy in plants.", "biology", now.Add(-60*24*time.Hour))
+
+		Convey("When pinning a memory", func() {
+			options := NewManageOptions("pin")
+			options.MemoryIDs = []string{eiffel}
+			response, err := manager.Manage(ctx, options)
+			So(err, ShouldBeNil)
+
+			Convey("Then it should be marked pinned in the vector and search views", func() {
+				So(response.AffectedCount, ShouldEqual, 1)
+				So(response.Success, ShouldBeTrue)
+
+				record, err := storage.vectorStore.GetByID(ctx, eiffel)
+				So(err, ShouldBeNil)
+				So(record.Metadata["pinned"], ShouldEqual, true)
+				So(record.Metadata["content"], ShouldContainSubstring, "Eiffel")
+
+				doc, err := storage.searchIndex.GetDocument(ctx, eiffel)
+				So(err, ShouldBeNil)
+				So(doc.Metadata["pinned"], ShouldEqual, true)
+			})
+
+			Convey("Then forgetting it without force should be refused", func() {
+				forget := NewManageOptions("forget")
+				forget.MemoryIDs = []string{eiffel}
+				response, err := manager.Manage(ctx, forget)
+				So(err, ShouldBeNil)
+				So(response.AffectedCount, ShouldEqual, 0)
+				So(response.Success, ShouldBeFalse)
+				So(response.Errors[0], ShouldContainSubstring, "pinned")
+
+				forget.Force = true
+				response, err = manager.Manage(ctx, forget)
+				So(err, ShouldBeNil)
+				So(response.AffectedCount, ShouldEqual, 1)
+			})
+		})
+
+		Convey("When forgetting a memory", func() {
+			claimNodes, err := storage.graphStore.FindNodesByType(ctx, ClaimNode, map[string]interface{}{"chunk_id": eiffel})
+			So(err, ShouldBeNil)
+			So(len(claimNodes), ShouldBeGreaterThan, 0)
+
+			options := NewManageOptions("forget")
+			options.MemoryIDs = []string{eiffel}
+			response, err := manager.Manage(ctx, options)
+			So(err, ShouldBeNil)
+
+			Convey("Then it should be removed from every view", func() {
+				So(response.AffectedCount, ShouldEqual, 1)
+
+				_, err := storage.vectorStore.GetByID(ctx, eiffel)
+				So(err, ShouldNotBeNil)
+				exists, err := storage.searchIndex.DocumentExists(ctx, eiffel)
+				So(err, ShouldBeNil)
+				So(exists, ShouldBeFalse)
+				remaining, err := storage.graphStore.FindNodesByType(ctx, ClaimNode, map[string]interface{}{"chunk_id": eiffel})
+				So(err, ShouldBeNil)
+				So(len(remaining), ShouldEqual, 0)
+
+				_, err = storage.vectorStore.GetByID(ctx, plants)
+				So(err, ShouldBeNil)
+			})
+		})
+
+		Convey("When forgetting a memory that shares an entity with another", func() {
+			entity := Entity{ID: "team_email", Name: "team@example.com", Type: "EMAIL", Properties: map[string]interface{}{"embedding": []float32{0, 0, 1}}}
+			for _, id := range []string{"launch_chunk", "budget_chunk"} {
+				chunk := NewChunk(id, "Questions go to team@example.com.", "notes")
+				chunk.Embedding = []float32{1, 0, 0}
+				chunk.Entities = []Entity{entity}
+				So(storage.StoreChunk(ctx, chunk), ShouldBeNil)
+			}
+
+			options := NewManageOptions("forget")
+			options.MemoryIDs = []string{"budget_chunk"}
+			response, err := manager.Manage(ctx, options)
+			So(err, ShouldBeNil)
+
+			Convey("Then the entity should stay for the other memory", func() {
+				So(response.AffectedCount, ShouldEqual, 1)
+				_, err := storage.graphStore.GetNode(ctx, entity.ID)
+				So(err, ShouldBeNil)
+				_, err = storage.vectorStore.GetByID(ctx, entity.ID)
+				So(err, ShouldBeNil)
+				So(entityChunks(ctx, storage.graphStore, entity.ID, ""), ShouldResemble, []string{"launch_chunk"})
+			})
+		})
+
+		Convey("When decaying memories with a 30 day half-life", func() {
+			options := NewManageOptions("decay")
+			options.MemoryIDs = []string{eiffel, plants}
+			options.TTL = 30 * 24 * time.Hour
+			response, err := manager.Manage(ctx, options)
+			So(err, ShouldBeNil)
+
+			Convey("Then older memories should lose more confidence", func() {
+				So(response.AffectedCount, ShouldEqual, 2)
+
+				fresh, err := storage.vectorStore.GetByID(ctx, eiffel)
+				So(err, ShouldBeNil)
+				old, err := storage.vectorStore.GetByID(ctx, plants)
+				So(err, ShouldBeNil)
+				So(fresh.Metadata["confidence"], ShouldAlmostEqual, 1.0, 0.01)
+				So(old.Metadata["confidence"], ShouldAlmostEqual, 0.25, 0.01)
+			})
+		})
+
+		Convey("When decaying with a forget threshold", func() {
+			options := NewManageOptions("decay")
+			options.MemoryIDs = []string{eiffel, plants}
+			options.TTL = 30 * 24 * time.Hour
+			options.Confidence = 0.5
+			_, err := manager.Manage(ctx, options)
+			So(err, ShouldBeNil)
+
+			Convey("Then memories below the threshold should be forgotten", func() {
+				_, err := storage.vectorStore.GetByID(ctx, plants)
+				So(err, ShouldNotBeNil)
+				_, err = storage.vectorStore.GetByID(ctx, eiffel)
+				So(err, ShouldBeNil)
+			})
+		})
+
+		Convey("When running a dry-run forget", func() {
+			options := NewManageOptions("forget")
+			options.MemoryIDs = []string{eiffel, plants}
+			options.DryRun = true
+			response, err := manager.Manage(ctx, options)
+			So(err, ShouldBeNil)
+
+			Convey("Then it should preview without deleting", func() {
+				So(response.AffectedCount, ShouldEqual, 2)
+				So(response.Preview, ShouldContain, "forget "+eiffel)
+				So(response.Preview, ShouldContain, "forget "+plants)
+				So(response.Message, ShouldContainSubstring, "Dry run")
+
+				_, err := storage.vectorStore.GetByID(ctx, eiffel)
+				So(err, ShouldBeNil)
+			})
+		})
+
+		Convey("When selecting memories by query", func() {
+			options := NewManageOptions("pin")
+			options.Query = "photosynthesis sunlight"
+			options.DryRun = true
+			response, err := manager.Manage(ctx, options)
+			So(err, ShouldBeNil)
+
+			Convey("Then the matching memory should be selected first", func() {
+				So(len(response.Preview), ShouldBeGreaterThan, 0)
+				So(response.Preview[0], ShouldEqual, "pin "+plants)
+			})
+		})
+
+		Convey("When merging explicit duplicates", func() {
+			duplicate := writeTestMemory(writer, "The Eiffel Tower is located in Paris and was completed in 1889.", "landmarks-copy", now)
+			// Builds the fingerprint index the merge has to keep up to date
+			_, err := storage.FindNearDuplicates(ctx, &Chunk{ID: "probe", Content: "The Eiffel Tower"}, 0)
+			So(err, ShouldBeNil)
+
+			options := NewManageOptions("merge")
+			options.MemoryIDs = []string{eiffel, duplicate}
+			response, err := manager.Manage(ctx, options)
+			So(err, ShouldBeNil)
+
+			Convey("Then the duplicate should be folded into the first memory", func() {
+				So(response.AffectedCount, ShouldEqual, 1)
+
+				_, err := storage.vectorStore.GetByID(ctx, duplicate)
+				So(err, ShouldNotBeNil)
+
+				canonical, err := storage.searchIndex.GetDocument(ctx, eiffel)
+				So(err, ShouldBeNil)
+				So(canonical.Metadata["merged_from"], ShouldResemble, []string{duplicate})
+				So(canonical.Metadata["tags"], ShouldResemble, []string{"landmarks", "landmarks-copy"})
+
+				nodes, err := storage.graphStore.FindNodesByType(ctx, ClaimNode, map[string]interface{}{"chunk_id": duplicate})
+				So(err, ShouldBeNil)
+				So(len(nodes), ShouldEqual, 0)
+
+				_, indexed := storage.fingerprints.fingerprints[duplicate]
+				So(indexed, ShouldBeFalse)
+				_, indexed = storage.fingerprints.fingerprints[eiffel]
+				So(indexed, ShouldBeTrue)
+			})
+		})
+
+		Convey("When merging by query", func() {
+			duplicate := writeTestMemory(writer, "The Eiffel Tower is located in Paris and was completed in 1889.", "landmarks-copy", now)
+
+			options := NewManageOptions("merge")
+			options.Query = "Eiffel Tower Paris"
+			options.DryRun = true
+			response, err := manager.Manage(ctx, options)
+			So(err, ShouldBeNil)
+
+			Convey("Then only near-identical memories should be grouped", func() {
+				So(response.AffectedCount, ShouldEqual, 1)
+				So(len(response.Preview), ShouldEqual, 1)
+				So(response.Preview[0], ShouldContainSubstring, duplicate)
+				So(response.Preview[0], ShouldNotContainSubstring, plants)
+			})
+		})
+
+		Convey("When an operation is invalid", func() {
+			_, err := manager.Manage(ctx, NewManageOptions("archive"))
+
+			Convey("Then it should return an error", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "invalid operation")
+			})
+		})
+	})
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"context"
 	"fmt"
+	"sort"
+	"sync"
 	"time"
 )
 
@@ -12,6 +14,11 @@ type MemoryWriter struct {
 	contentProcessor  *ContentProcessor
 	entityResolver    *EntityResolver
 	provenanceTracker *ProvenanceTracker
+	embedder          Embedder
+	idempotency       *idempotencyCache
+	manifests         map[string]*sourceManifest // Per source, built from storage on first re-ingest
+	manifestMu        sync.Mutex                 // Serializes re-ingests so each diffs against the last
+	externalLocks     keyLocks                   // Serializes writes sharing an external ID from lookup to replace
 	config           *MemoryWriterConfig
 }
 
@@ -21,17 +28,26 @@ type MemoryWriterConfig struct {
 	MaxChunkSize      int
 	MinConfidence     float64
 	EnableDeduplication bool
+	DuplicatePolicy   DuplicatePolicy // What a write does with chunks nearly duplicating stored ones
+	DuplicateDistance int             // Fingerprint bits near-duplicates may differ in; 0 means the default
+	IdempotencyTTL    time.Duration   // How long retries with an idempotency key get the first result; 0 means the default
+}
+
+// DefaultMemoryWriterConfig returns the configuration used when none is given
+func DefaultMemoryWriterConfig() *MemoryWriterConfig {
+	return &MemoryWriterConfig{
+		RequireEvidence:    false,
+		MaxChunkSize:      1000,
+		MinConfidence:     0.5,
+		EnableDeduplication: true,
+		DuplicatePolicy:   DuplicatePolicyOff,
+	}
 }
 
 // NewMemoryWriter creates a new MemoryWriter instance
 func NewMemoryWriter(storage *MultiViewStorage, contentProcessor *ContentProcessor, config *MemoryWriterConfig) *MemoryWriter {
 	if config == nil {
-		config = &MemoryWriterConfig{
-			RequireEvidence:    false,
-			MaxChunkSize:      1000,
-			MinConfidence:     0.5,
-			EnableDeduplication: true,
-		}
+		config = DefaultMemoryWriterConfig()
 	}
 
 	return &MemoryWriter{
@@ -39,14 +55,46 @@ func NewMemoryWriter(storage *MultiViewStorage, contentProcessor *ContentProcess
 		contentProcessor:  contentProcessor,
 		entityResolver:    NewEntityResolver(storage),
 		provenanceTracker: NewProvenanceTracker(),
+		idempotency:       newIdempotencyCache(config.IdempotencyTTL),
+		manifests:         make(map[string]*sourceManifest),
 		config:           config,
 	}
 }
 
-// Write processes content and stores it as memory chunks
+// SetEmbedder sets the embedder used for chunks and entities that arrive without embeddings
+func (mw *MemoryWriter) SetEmbedder(embedder Embedder) {
+	mw.embedder = embedder
+	mw.entityResolver.SetEmbedder(embedder)
+}
+
+// Write processes content and stores it as memory chunks. A write with an external ID
+// replaces the chunks stored by earlier writes with the same ID; a re-ingest of a source
+// stores only the chunks its text did not have before and deletes the ones it has lost; a
+// write with an idempotency key already used returns the result of the first write with that key.
 func (mw *MemoryWriter) Write(ctx context.Context, content string, metadata WriteMetadata) (*WriteResult, error) {
+	if metadata.IdempotencyKey == "" {
+		return mw.write(ctx, content, metadata)
+	}
+	request, err := idempotencyRequest(content, metadata)
+	if err != nil {
+		return nil, err
+	}
+	return mw.idempotency.do(ctx, metadata.IdempotencyKey, request, func() (*WriteResult, error) {
+		return mw.write(ctx, content, metadata)
+	})
+}
+
+// write processes content and stores it as memory chunks
+func (mw *MemoryWriter) write(ctx context.Context, content string, metadata WriteMetadata) (*WriteResult, error) {
+	if metadata.Reingest && metadata.ExternalID != "" {
+		return nil, fmt.Errorf("a write cannot both replace an external ID and re-ingest a source")
+	}
+	if metadata.Reingest && metadata.Source == "" {
+		return nil, fmt.Errorf("re-ingesting requires a source")
+	}
+
 	// Process content to extract chunks, entities, and claims
-	processedContent, err := mw.contentProcessor.Process(content, metadata.Source)
+	processedContent, err := mw.contentProcessor.ProcessContext(ctx, content, metadata.Source)
 	if err != nil {
 		return nil, fmt.Errorf("failed to process content: %w", err)
 	}
@@ -57,12 +105,68 @@ func (mw *MemoryWriter) Write(ctx context.Context, content string, metadata Writ
 		return nil, fmt.Errorf("failed to create chunks: %w", err)
 	}
 
+	candidateCount := len(chunks)
+
+	// Find what this write replaces: the previous version of a document with an external ID,
+	// or the chunks of a re-ingested source its new text no longer contains
+	var previous *documentVersions
+	var diff *sourceDiff
+	switch {
+	case metadata.ExternalID != "":
+		// Held until the new version is stored, so concurrent writes of one document each
+		// replace the whole of the version before them
+		unlock := mw.externalLocks.lock(metadata.ExternalID)
+		defer unlock()
+		previous, err = mw.previousVersion(ctx, metadata.ExternalID)
+	case metadata.Reingest:
+		mw.manifestMu.Lock()
+		defer mw.manifestMu.Unlock()
+		previous, diff, err = mw.diffSource(ctx, metadata.Source, chunks)
+		if diff != nil {
+			chunks = diff.added
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	var settled []*Chunk // Chunks rejected as or merged into duplicates of other memories
+
 	var storedChunks []string
+	var provenanceIDs []string
 	var entitiesLinked []string
 	var conflictsFound []ConflictInfo
+	var replacements []*Chunk
+	var created []*Chunk // Chunks this write stored, new versions included
 
 	// Store each chunk
 	for _, chunk := range chunks {
+		// Check the chunk against stored content before resolving or storing anything
+		duplicate, err := mw.findDuplicate(ctx, chunk, previous)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for duplicate content: %w", err)
+		}
+		if duplicate != nil {
+			switch mw.config.DuplicatePolicy {
+			case DuplicatePolicyReject:
+				conflictsFound = append(conflictsFound, duplicateConflict(chunk, duplicate, DuplicatePolicyReject))
+				settled = append(settled, chunk)
+				continue
+			case DuplicatePolicyMerge:
+				provenanceID, err := mw.mergeDuplicate(ctx, duplicate.ChunkID, chunk, metadata)
+				if err != nil {
+					return nil, fmt.Errorf("failed to merge duplicate chunk: %w", err)
+				}
+				storedChunks = append(storedChunks, duplicate.ChunkID)
+				provenanceIDs = append(provenanceIDs, provenanceID)
+				conflictsFound = append(conflictsFound, duplicateConflict(chunk, duplicate, DuplicatePolicyMerge))
+				settled = append(settled, chunk)
+				continue
+			case DuplicatePolicyLink:
+				chunk.SetMetadata("duplicate_of", duplicate.ChunkID)
+				conflictsFound = append(conflictsFound, duplicateConflict(chunk, duplicate, DuplicatePolicyLink))
+			}
+		}
+
 		// Resolve entities if deduplication is enabled
 		if mw.config.EnableDeduplication {
 			resolvedEntities, err := mw.entityResolver.Resolve(ctx, chunk.Entities)
@@ -75,12 +179,26 @@ func (mw *MemoryWriter) Write(ctx context.Context, content string, metadata Writ
 			}
 		}
 
+		// Chunks of a new version are stored together once all of them are ready
+		if previous != nil {
+			replacements = append(replacements, chunk)
+			continue
+		}
+
 		// Store the chunk
 		chunkID, err := mw.StoreChunk(ctx, chunk)
 		if err != nil {
 			return nil, fmt.Errorf("failed to store chunk: %w", err)
 		}
 		storedChunks = append(storedChunks, chunkID)
+		created = append(created, chunk)
+
+		// Report the stored claims this chunk's claims contradict
+		claimConflicts, err := mw.storage.ClaimConflicts(ctx, chunk)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check claim conflicts: %w", err)
+		}
+		conflictsFound = append(conflictsFound, claimConflicts...)
 
 		// Track provenance
 		provenanceID, err := mw.provenanceTracker.Track(chunkID, metadata)
@@ -88,20 +206,279 @@ func (mw *MemoryWriter) Write(ctx context.Context, content string, metadata Writ
 			return nil, fmt.Errorf("failed to track provenance: %w", err)
 		}
 		chunk.Metadata["provenance_id"] = provenanceID
+		provenanceIDs = append(provenanceIDs, provenanceID)
 	}
 
-	// Create write result
+	if previous != nil {
+		ids, provenance, conflicts, err := mw.replaceVersion(ctx, previous, replacements, metadata)
+		if err != nil {
+			return nil, err
+		}
+		storedChunks = append(storedChunks, ids...)
+		provenanceIDs = append(provenanceIDs, provenance...)
+		conflictsFound = append(conflictsFound, conflicts...)
+		created = append(created, replacements...)
+	}
+
+	// Record what the re-ingest left in memory for the next one to diff against
+	if diff != nil {
+		for _, chunk := range settled {
+			diff.manifest.add(diff.hashes[chunk], "")
+		}
+		for _, chunk := range replacements {
+			diff.manifest.add(diff.hashes[chunk], chunk.ID)
+		}
+		mw.manifests[metadata.Source] = diff.manifest
+	} else if metadata.ExternalID == "" && len(storedChunks) > 0 {
+		// Chunks written to a source outside a re-ingest are missing from its manifest;
+		// list the source afresh on its next re-ingest
+		mw.manifestMu.Lock()
+		delete(mw.manifests, metadata.Source)
+		mw.manifestMu.Unlock()
+	}
+
+	// Create write result; a write whose every chunk was rejected stores nothing and
+	// reports only the conflicts
 	result := &WriteResult{
-		MemoryID:       storedChunks[0], // Primary chunk ID
-		CandidateCount: len(chunks),
+		CandidateCount: candidateCount,
 		ConflictsFound: conflictsFound,
 		EntitiesLinked: entitiesLinked,
-		ProvenanceID:   fmt.Sprintf("%v", chunks[0].Metadata["provenance_id"]),
+		ChunksCreated:  len(created),
+	}
+	for _, chunk := range created {
+		result.GraphUpdates.NodesCreated += len(chunk.Entities) + len(chunk.Claims)
+		result.GraphUpdates.EdgesCreated += len(chunk.Claims)
+	}
+	if len(storedChunks) > 0 {
+		result.MemoryID = storedChunks[0] // Primary chunk ID
+		result.ProvenanceID = provenanceIDs[0]
+	}
+	if previous != nil {
+		// A re-ingest that stores nothing new still reports the document's first chunk
+		if result.MemoryID == "" && len(previous.unchanged) > 0 {
+			result.MemoryID = previous.unchanged[0]
+		}
+		result.ChunksUnchanged = len(previous.unchanged)
+		for _, id := range storedChunks {
+			delete(previous.chunks, id)
+		}
+		result.ChunksRemoved = len(previous.chunks)
 	}
 
 	return result, nil
 }
 
+// findDuplicate returns the stored chunk the given chunk most nearly duplicates, or nil when
+// there is none or the duplicate policy is off. The chunks of the version a write replaces
+// are not duplicates of it.
+func (mw *MemoryWriter) findDuplicate(ctx context.Context, chunk *Chunk, previous *documentVersions) (*NearDuplicate, error) {
+	if mw.config.DuplicatePolicy == DuplicatePolicyOff {
+		return nil, nil
+	}
+
+	duplicates, err := mw.storage.FindNearDuplicates(ctx, chunk, mw.config.DuplicateDistance)
+	if err != nil {
+		return nil, err
+	}
+	for _, duplicate := range duplicates {
+		if previous == nil || !previous.chunks[duplicate.ChunkID] {
+			return &duplicate, nil
+		}
+	}
+	return nil, nil
+}
+
+// documentVersions describes the stored chunks of a document a write replaces
+type documentVersions struct {
+	chunks     map[string]bool // IDs of the stored chunks the write deletes
+	unchanged  []string        // IDs of the stored chunks a re-ingest leaves in place
+	version    int             // Version the stored chunks were written as, 0 when none are stored
+	provenance []string        // Provenance records of the stored chunks
+}
+
+// previousVersion finds the chunks stored under an external ID and the version they make up
+func (mw *MemoryWriter) previousVersion(ctx context.Context, externalID string) (*documentVersions, error) {
+	docs, err := mw.storage.ExternalChunks(ctx, externalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find previous version: %w", err)
+	}
+
+	previous := &documentVersions{chunks: make(map[string]bool, len(docs))}
+	var provenance []string
+	for _, doc := range docs {
+		previous.chunks[doc.ID] = true
+		if version := documentVersion(doc.Metadata); version > previous.version {
+			previous.version = version
+		}
+		if provenanceID, ok := doc.Metadata["provenance_id"].(string); ok {
+			provenance = append(provenance, provenanceID)
+		}
+	}
+	previous.provenance = uniqueSorted(provenance)
+	return previous, nil
+}
+
+// diffSource compares the chunks of a re-ingested document with the source's manifest. The
+// chunks it deletes are those whose text the document lost; the version is 0 because a
+// re-ingest adds new chunks rather than new versions of old ones (assumes manifestMu is held).
+func (mw *MemoryWriter) diffSource(ctx context.Context, source string, chunks []*Chunk) (*documentVersions, *sourceDiff, error) {
+	manifest, err := mw.sourceManifest(ctx, source)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	exists := func(chunkID string) (bool, error) {
+		return mw.storage.ChunkExists(ctx, chunkID)
+	}
+	diff, err := manifest.diff(source, chunks, exists)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to diff source %s: %w", source, err)
+	}
+
+	previous := &documentVersions{
+		chunks:    make(map[string]bool, len(diff.removed)),
+		unchanged: diff.unchanged,
+	}
+	for id := range diff.removed {
+		// Chunks already deleted some other way need no deleting
+		stored, err := exists(id)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to check chunk %s: %w", id, err)
+		}
+		if stored {
+			previous.chunks[id] = true
+		}
+	}
+	return previous, diff, nil
+}
+
+// sourceManifest returns a source's manifest, listing the source's stored chunks when it
+// has not been re-ingested since the writer started (assumes manifestMu is held)
+func (mw *MemoryWriter) sourceManifest(ctx context.Context, source string) (*sourceManifest, error) {
+	if manifest, ok := mw.manifests[source]; ok {
+		return manifest, nil
+	}
+
+	docs, err := mw.storage.SourceChunks(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chunks of source %s: %w", source, err)
+	}
+	manifest := newSourceManifest()
+	for _, doc := range docs {
+		manifest.add(chunkContentHash(doc.Content), doc.ID)
+	}
+	mw.manifests[source] = manifest
+	return manifest, nil
+}
+
+// replaceVersion stores the chunks of a new version of a document in place of the previous
+// version's chunks, entities and edges. Each chunk's provenance record is created first, as
+// the next version of the previous ones, so the chunk carries its ID into storage for the
+// version after it. It returns the stored chunk IDs, their provenance records and the claim
+// conflicts the new version raised.
+func (mw *MemoryWriter) replaceVersion(ctx context.Context, previous *documentVersions, chunks []*Chunk, metadata WriteMetadata) ([]string, []string, []ConflictInfo, error) {
+	version := previous.version + 1
+
+	var ids []string
+	var provenanceIDs []string
+	for _, chunk := range chunks {
+		if err := mw.embedChunk(ctx, chunk); err != nil {
+			return nil, nil, nil, err
+		}
+
+		provenanceID, err := mw.provenanceTracker.TrackVersion(chunk.ID, metadata, version, previous.provenance)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to track provenance: %w", err)
+		}
+		chunk.SetMetadata("version", version)
+		chunk.SetMetadata("provenance_id", provenanceID)
+		ids = append(ids, chunk.ID)
+		provenanceIDs = append(provenanceIDs, provenanceID)
+	}
+
+	previousIDs := make([]string, 0, len(previous.chunks))
+	for id := range previous.chunks {
+		previousIDs = append(previousIDs, id)
+	}
+	sort.Strings(previousIDs)
+
+	if err := mw.storage.ReplaceChunks(ctx, previousIDs, chunks); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to store chunk: %w", err)
+	}
+
+	var conflicts []ConflictInfo
+	for _, chunk := range chunks {
+		claimConflicts, err := mw.storage.ClaimConflicts(ctx, chunk)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to check claim conflicts: %w", err)
+		}
+		conflicts = append(conflicts, claimConflicts...)
+	}
+	return ids, provenanceIDs, conflicts, nil
+}
+
+// mergeDuplicate folds a write into the chunk it nearly duplicates instead of storing it:
+// the chunk's evidence count goes up by one and the write's provenance, source and tags are
+// added to it. It returns the provenance record created for the write.
+func (mw *MemoryWriter) mergeDuplicate(ctx context.Context, existingID string, chunk *Chunk, metadata WriteMetadata) (string, error) {
+	provenanceID, err := mw.provenanceTracker.Track(existingID, metadata)
+	if err != nil {
+		return "", fmt.Errorf("failed to track provenance: %w", err)
+	}
+
+	err = mw.storage.UpdateChunkMetadata(ctx, existingID, func(existing map[string]interface{}) {
+		existing["evidence_count"] = chunkEvidenceCount(existing) + 1
+		existing["provenance_ids"] = appendUnique(metadataStrings(existing["provenance_ids"]), provenanceID)
+
+		sources := metadataStrings(existing["sources"])
+		if source, ok := existing["source"].(string); ok && source != "" {
+			sources = appendUnique(sources, source)
+		}
+		if chunk.Source != "" {
+			sources = appendUnique(sources, chunk.Source)
+		}
+		existing["sources"] = sources
+
+		if len(metadata.Tags) > 0 {
+			existing["tags"] = appendUnique(metadataStrings(existing["tags"]), metadata.Tags...)
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return provenanceID, nil
+}
+
+// duplicateConflict reports a near-duplicate chunk and what the policy did with it
+func duplicateConflict(chunk *Chunk, duplicate *NearDuplicate, policy DuplicatePolicy) ConflictInfo {
+	conflictingIDs := []string{duplicate.ChunkID}
+	outcome := "rejected"
+	switch policy {
+	case DuplicatePolicyMerge:
+		outcome = "merged into the existing memory"
+	case DuplicatePolicyLink:
+		outcome = "stored and linked to the existing memory"
+		conflictingIDs = append(conflictingIDs, chunk.ID)
+	}
+
+	return ConflictInfo{
+		ID:             fmt.Sprintf("duplicate_content_%s", chunk.ID),
+		Type:           "duplicate_content",
+		Description:    fmt.Sprintf("Content nearly duplicates memory %s (similarity %.2f); %s", duplicate.ChunkID, duplicate.Similarity(), outcome),
+		ConflictingIDs: conflictingIDs,
+		Severity:       "low",
+	}
+}
+
+// chunkEvidenceCount reads how many writes have contributed to a chunk, 1 when never merged into
+func chunkEvidenceCount(metadata map[string]interface{}) int {
+	if count, ok := filterNumber(metadata["evidence_count"]); ok && count >= 1 {
+		return int(count)
+	}
+	return 1
+}
+
 // CreateChunk creates memory chunks from processed content
 func (mw *MemoryWriter) CreateChunk(processedContent *ProcessingResult, metadata WriteMetadata) ([]*Chunk, error) {
 	var chunks []*Chunk
@@ -112,6 +489,26 @@ func (mw *MemoryWriter) CreateChunk(processedContent *ProcessingResult, metadata
 		chunk.SetMetadata("user_id", metadata.UserID)
 		chunk.SetMetadata("tags", metadata.Tags)
 		chunk.SetMetadata("write_confidence", metadata.Confidence)
+		chunk.SetMetadata("source", chunk.Source)
+		if metadata.Language != "" {
+			// Picks the analyzer the search index tokenizes the chunk with
+			chunk.SetMetadata("language", metadata.Language)
+		}
+		if !metadata.Timestamp.IsZero() {
+			chunk.SetMetadata("timestamp", metadata.Timestamp.Format(time.RFC3339))
+		}
+		if metadata.ContentType != "" {
+			chunk.SetMetadata("content_type", metadata.ContentType)
+		}
+		if metadata.ExternalID != "" {
+			// Every version of the document stores its chunks under the same IDs
+			chunk.ID = externalChunkID(metadata.ExternalID, len(chunks))
+			chunk.SetMetadata("external_id", metadata.ExternalID)
+		}
+		if entityTypes := chunkEntityTypes(chunk); len(entityTypes) > 0 {
+			// Lets searches filter and facet on the kinds of entity a chunk mentions
+			chunk.SetMetadata("entity_types", entityTypes)
+		}
 		
 		// Override confidence if provided in metadata
 		if metadata.Confidence > 0 {
@@ -144,60 +541,41 @@ func (mw *MemoryWriter) CreateChunk(processedContent *ProcessingResult, metadata
 
 // StoreChunk stores a chunk in the multi-view storage system
 func (mw *MemoryWriter) StoreChunk(ctx context.Context, chunk *Chunk) (string, error) {
-	// Store in vector database
-	if err := mw.storage.vectorStore.Store(ctx, chunk.ID, chunk.Embedding, chunk.Metadata); err != nil {
-		return "", fmt.Errorf("failed to store in vector database: %w", err)
+	if err := mw.embedChunk(ctx, chunk); err != nil {
+		return "", err
 	}
 
-	// Store in search index
-	doc := IndexDocument{
-		ID:       chunk.ID,
-		Content:  chunk.Content,
-		Metadata: chunk.Metadata,
-	}
-	if err := mw.storage.searchIndex.Index(ctx, doc); err != nil {
-		return "", fmt.Errorf("failed to index in search: %w", err)
+	// Write every view in one transaction so a failure leaves no partial chunk behind
+	if err := mw.storage.StoreChunk(ctx, chunk); err != nil {
+		return "", err
 	}
 
-	// Store entities and claims in graph database
-	for _, entity := range chunk.Entities {
-		node := &Node{
-			ID:   entity.ID,
-			Type: EntityNode,
-			Properties: map[string]interface{}{
-				"name":        entity.Name,
-				"type":        entity.Type,
-				"confidence":  entity.Confidence,
-				"chunk_id":    chunk.ID,
-			},
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-		}
-		
-		if err := mw.storage.graphStore.CreateNode(ctx, node); err != nil {
-			return "", fmt.Errorf("failed to create entity node: %w", err)
+	return chunk.ID, nil
+}
+
+// embedChunk embeds a chunk if processing did not already do so
+func (mw *MemoryWriter) embedChunk(ctx context.Context, chunk *Chunk) error {
+	if len(chunk.Embedding) == 0 && mw.embedder != nil {
+		embedding, err := embedSingle(ctx, mw.embedder, chunk.Content)
+		if err != nil {
+			return fmt.Errorf("failed to embed chunk: %w", err)
 		}
+		chunk.Embedding = embedding
 	}
+	return nil
+}
 
-	for _, claim := range chunk.Claims {
-		node := &Node{
-			ID:   claim.ID,
-			Type: ClaimNode,
-			Properties: map[string]interface{}{
-				"subject":     claim.Subject,
-				"predicate":   claim.Predicate,
-				"object":      claim.Object,
-				"confidence":  claim.Confidence,
-				"chunk_id":    chunk.ID,
-			},
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-		}
-		
-		if err := mw.storage.graphStore.CreateNode(ctx, node); err != nil {
-			return "", fmt.Errorf("failed to create claim node: %w", err)
+// chunkEntityTypes returns the distinct, sorted types of the entities a chunk mentions
+func chunkEntityTypes(chunk *Chunk) []string {
+	seen := make(map[string]bool)
+	var types []string
+	for _, entity := range chunk.Entities {
+		if entity.Type == "" || seen[entity.Type] {
+			continue
 		}
+		seen[entity.Type] = true
+		types = append(types, entity.Type)
 	}
-
-	return chunk.ID, nil
-}
\ No newline at end of file
+	sort.Strings(types)
+	return types
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -273,9 +275,19 @@ func TestMemoryWriterStoreChunk(t *testing.T) {
 				So(len(indexed), ShouldEqual, 1)
 				So(indexed[chunk.ID], ShouldNotBeNil)
 				
-				// Verify graph store was called for entities and claims
+				// Verify graph store was called for the chunk, entities and claims
 				nodes := mockGraphStore.GetNodes()
-				So(len(nodes), ShouldEqual, 2) // 1 entity + 1 claim
+				So(len(nodes), ShouldEqual, 3) // 1 chunk + 1 entity + 1 claim
+				So(nodes[chunk.ID].Type, ShouldEqual, ChunkNode)
+
+				// Verify the entity and claim were linked to the chunk and to each other
+				edges := make(map[string]*Edge)
+				for _, edge := range mockGraphStore.GetEdges() {
+					edges[edge.ID] = edge
+				}
+				So(edges["part_of_entity_1_test_chunk_1"], ShouldNotBeNil)
+				So(edges["part_of_claim_1_test_chunk_1"], ShouldNotBeNil)
+				So(edges["related_to_claim_1_entity_1"].Properties["role"], ShouldEqual, "subject")
 			})
 		})
 
@@ -313,4 +325,460 @@ func (m *MockClaimExtractor) Extract(text string) ([]Claim, error) {
 
 func (m *MockClaimExtractor) ExtractClaims(text string) ([]Claim, error) {
 	return m.claims, nil
-}
\ No newline at end of file
+}
+func TestMemoryWriterDuplicates(t *testing.T) {
+	Convey("Given a MemoryWriter and a stored memory", t, func() {
+		ctx := context.Background()
+		storage := &MultiViewStorage{
+			vectorStore: NewMockVectorStore(),
+			graphStore:  NewMockGraphStore(),
+			searchIndex: NewMockSearchIndex(),
+		}
+		config := DefaultMemoryWriterConfig()
+		writer := NewMemoryWriter(storage, NewContentProcessor(), config)
+
+		original := "Machine learning systems learn statistical patterns from large datasets and use them to make predictions about new inputs."
+		first, err := writer.Write(ctx, original, WriteMetadata{Source: "notes", Timestamp: time.Now(), Tags: []string{"ml"}})
+		So(err, ShouldBeNil)
+		So(first.ConflictsFound, ShouldBeEmpty)
+		So(first.ChunksCreated, ShouldEqual, 1)
+
+		// Differs only in inflection and punctuation, which the analyzer folds away
+		duplicate := "Machine learning systems learned statistical patterns from large datasets, and use them to make predictions about new inputs!"
+		metadata := WriteMetadata{Source: "transcript", Timestamp: time.Now(), Tags: []string{"lecture"}}
+
+		Convey("When the policy is link", func() {
+			config.DuplicatePolicy = DuplicatePolicyLink
+			result, err := writer.Write(ctx, duplicate, metadata)
+
+			Convey("Then the chunk is stored and linked to the one it duplicates", func() {
+				So(err, ShouldBeNil)
+				So(result.MemoryID, ShouldNotEqual, first.MemoryID)
+				So(result.ConflictsFound, ShouldHaveLength, 1)
+				So(result.ConflictsFound[0].Type, ShouldEqual, "duplicate_content")
+				So(result.ConflictsFound[0].ConflictingIDs, ShouldResemble, []string{first.MemoryID, result.MemoryID})
+				So(result.ChunksCreated, ShouldEqual, 1)
+
+				edge, err := storage.graphStore.GetEdge(ctx, graphEdgeID(RelatedTo, result.MemoryID, first.MemoryID))
+				So(err, ShouldBeNil)
+				So(edge.Properties["relation"], ShouldEqual, "near_duplicate")
+
+				doc, err := storage.searchIndex.GetDocument(ctx, result.MemoryID)
+				So(err, ShouldBeNil)
+				So(doc.Metadata["duplicate_of"], ShouldEqual, first.MemoryID)
+			})
+		})
+
+		Convey("When the policy is reject", func() {
+			config.DuplicatePolicy = DuplicatePolicyReject
+			result, err := writer.Write(ctx, duplicate, metadata)
+
+			Convey("Then nothing is stored and the duplicate is reported", func() {
+				So(err, ShouldBeNil)
+				So(result.MemoryID, ShouldBeEmpty)
+				So(result.ConflictsFound, ShouldHaveLength, 1)
+				So(result.ConflictsFound[0].ConflictingIDs, ShouldResemble, []string{first.MemoryID})
+				So(result.ConflictsFound[0].Description, ShouldContainSubstring, "rejected")
+				So(result.ChunksCreated, ShouldEqual, 0)
+				So(result.GraphUpdates, ShouldResemble, GraphUpdates{})
+
+				count, _ := storage.searchIndex.DocumentCount(ctx)
+				So(count, ShouldEqual, 1)
+			})
+		})
+
+		Convey("When the policy is merge", func() {
+			config.DuplicatePolicy = DuplicatePolicyMerge
+			result, err := writer.Write(ctx, duplicate, metadata)
+			So(err, ShouldBeNil)
+			again, err := writer.Write(ctx, duplicate, WriteMetadata{Source: "slides", Timestamp: time.Now()})
+			So(err, ShouldBeNil)
+
+			Convey("Then the existing chunk gains the evidence and provenance", func() {
+				So(result.MemoryID, ShouldEqual, first.MemoryID)
+				So(again.MemoryID, ShouldEqual, first.MemoryID)
+				So(result.ConflictsFound, ShouldHaveLength, 1)
+				So(result.ConflictsFound[0].Description, ShouldContainSubstring, "merged")
+				So(result.ChunksCreated, ShouldEqual, 0)
+				So(again.ChunksCreated, ShouldEqual, 0)
+
+				count, _ := storage.searchIndex.DocumentCount(ctx)
+				So(count, ShouldEqual, 1)
+
+				doc, err := storage.searchIndex.GetDocument(ctx, first.MemoryID)
+				So(err, ShouldBeNil)
+				So(doc.Content, ShouldEqual, original)
+				So(doc.Metadata["evidence_count"], ShouldEqual, 3)
+				So(metadataStrings(doc.Metadata["provenance_ids"]), ShouldResemble, []string{result.ProvenanceID, again.ProvenanceID})
+				So(metadataStrings(doc.Metadata["sources"]), ShouldResemble, []string{"notes", "transcript", "slides"})
+				So(metadataStrings(doc.Metadata["tags"]), ShouldResemble, []string{"ml", "lecture"})
+
+				vector, err := storage.vectorStore.GetByID(ctx, first.MemoryID)
+				So(err, ShouldBeNil)
+				So(vector.Metadata["evidence_count"], ShouldEqual, 3)
+				So(vector.Metadata["content"], ShouldEqual, original)
+
+				lineage, err := writer.provenanceTracker.GetMemoryLineage(first.MemoryID)
+				So(err, ShouldBeNil)
+				So(len(lineage), ShouldEqual, 3)
+			})
+		})
+
+		Convey("When the content is unrelated", func() {
+			config.DuplicatePolicy = DuplicatePolicyReject
+			result, err := writer.Write(ctx, "Databases answer queries using indexes built over columns, with transactions keeping data consistent.", metadata)
+
+			Convey("Then it is stored without conflicts", func() {
+				So(err, ShouldBeNil)
+				So(result.MemoryID, ShouldNotBeEmpty)
+				So(result.ConflictsFound, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the policy is left at its default", func() {
+			So(DefaultMemoryWriterConfig().DuplicatePolicy, ShouldEqual, DuplicatePolicyOff)
+			result, err := writer.Write(ctx, duplicate, metadata)
+
+			Convey("Then the duplicate is stored without checking", func() {
+				So(err, ShouldBeNil)
+				So(result.MemoryID, ShouldNotBeEmpty)
+				So(result.ConflictsFound, ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+func TestMemoryWriterClaimConflicts(t *testing.T) {
+	Convey("Given a MemoryWriter that has stored a claim", t, func() {
+		ctx := context.Background()
+		storage := &MultiViewStorage{
+			vectorStore: NewMockVectorStore(),
+			graphStore:  NewMockGraphStore(),
+			searchIndex: NewMockSearchIndex(),
+		}
+		config := DefaultMemoryWriterConfig()
+		config.DuplicatePolicy = DuplicatePolicyOff
+		writer := NewMemoryWriter(storage, NewContentProcessor(), config)
+
+		_, err := writer.Write(ctx, "Paris is the capital of France.", WriteMetadata{Source: "atlas", Timestamp: time.Now()})
+		So(err, ShouldBeNil)
+
+		Convey("When a write negates the claim", func() {
+			result, err := writer.Write(ctx, "Paris is not the capital of France.", WriteMetadata{Source: "notes", Timestamp: time.Now()})
+
+			Convey("Then the contradiction is reported with both claim IDs", func() {
+				So(err, ShouldBeNil)
+				So(result.MemoryID, ShouldNotBeEmpty)
+
+				var contradictions []ConflictInfo
+				for _, conflict := range result.ConflictsFound {
+					if conflict.Type == "claim_contradiction" {
+						contradictions = append(contradictions, conflict)
+					}
+				}
+				So(contradictions, ShouldNotBeEmpty)
+				So(contradictions[0].Severity, ShouldEqual, "high")
+				So(contradictions[0].ConflictingIDs, ShouldHaveLength, 2)
+
+				edges, err := storage.graphStore.FindEdgesByType(ctx, Refutes, nil)
+				So(err, ShouldBeNil)
+				So(len(edges), ShouldEqual, len(contradictions))
+			})
+		})
+
+		Convey("When a write repeats the claim", func() {
+			result, err := writer.Write(ctx, "Paris is the capital of France.", WriteMetadata{Source: "notes", Timestamp: time.Now()})
+
+			Convey("Then it supports the stored claim without conflicts", func() {
+				So(err, ShouldBeNil)
+				So(result.ConflictsFound, ShouldBeEmpty)
+
+				edges, err := storage.graphStore.FindEdgesByType(ctx, Supports, nil)
+				So(err, ShouldBeNil)
+				So(edges, ShouldNotBeEmpty)
+			})
+		})
+	})
+}
+
+func TestMemoryWriterExternalIDs(t *testing.T) {
+	Convey("Given a MemoryWriter that has stored a document under an external ID", t, func() {
+		ctx := context.Background()
+		storage := &MultiViewStorage{
+			vectorStore: NewMockVectorStore(),
+			graphStore:  NewMockGraphStore(),
+			searchIndex: NewMockSearchIndex(),
+		}
+		config := DefaultMemoryWriterConfig()
+		config.DuplicatePolicy = DuplicatePolicyReject
+		writer := NewMemoryWriter(storage, NewContentProcessor(), config)
+
+		metadata := WriteMetadata{Source: "atlas", Timestamp: time.Now(), ExternalID: "doc-1"}
+		first, err := writer.Write(ctx, "Paris is the capital of France.", metadata)
+		So(err, ShouldBeNil)
+		So(first.MemoryID, ShouldEqual, externalChunkID("doc-1", 0))
+
+		Convey("When the document is written again", func() {
+			result, err := writer.Write(ctx, "Paris is not the capital of France.", metadata)
+
+			Convey("Then the new version replaces the old one's chunks, claims and edges", func() {
+				So(err, ShouldBeNil)
+				So(result.MemoryID, ShouldEqual, first.MemoryID)
+				// The new version neither duplicates nor contradicts the version it replaces
+				So(result.ConflictsFound, ShouldBeEmpty)
+
+				count, _ := storage.searchIndex.DocumentCount(ctx)
+				So(count, ShouldEqual, 1)
+				doc, err := storage.searchIndex.GetDocument(ctx, result.MemoryID)
+				So(err, ShouldBeNil)
+				So(doc.Content, ShouldEqual, "Paris is not the capital of France.")
+				So(doc.Metadata["version"], ShouldEqual, 2)
+
+				claims, err := storage.graphStore.FindNodesByType(ctx, ClaimNode, nil)
+				So(err, ShouldBeNil)
+				So(claims, ShouldHaveLength, 1)
+				So(claims[0].Properties["object"], ShouldEqual, "not the capital of France")
+				edges, err := storage.graphStore.FindEdgesByType(ctx, Refutes, nil)
+				So(err, ShouldBeNil)
+				So(edges, ShouldBeEmpty)
+			})
+
+			Convey("Then its provenance is the next version of the old one's", func() {
+				So(result.ProvenanceID, ShouldNotEqual, first.ProvenanceID)
+				record, err := writer.provenanceTracker.GetProvenance(result.ProvenanceID)
+				So(err, ShouldBeNil)
+				So(record.Version, ShouldEqual, 2)
+				So(record.ParentVersions, ShouldResemble, []string{first.ProvenanceID})
+
+				third, err := writer.Write(ctx, "Paris is the capital of France.", metadata)
+				So(err, ShouldBeNil)
+				record, err = writer.provenanceTracker.GetProvenance(third.ProvenanceID)
+				So(err, ShouldBeNil)
+				So(record.Version, ShouldEqual, 3)
+				So(record.ParentVersions, ShouldResemble, []string{result.ProvenanceID})
+			})
+		})
+
+		Convey("When another document is written", func() {
+			result, err := writer.Write(ctx, "Berlin is the capital of Germany.", WriteMetadata{Source: "atlas", Timestamp: time.Now(), ExternalID: "doc-2"})
+
+			Convey("Then the first document is left alone", func() {
+				So(err, ShouldBeNil)
+				So(result.MemoryID, ShouldNotEqual, first.MemoryID)
+
+				count, _ := storage.searchIndex.DocumentCount(ctx)
+				So(count, ShouldEqual, 2)
+			})
+		})
+	})
+}
+
+func TestMemoryWriterConcurrentExternalIDs(t *testing.T) {
+	Convey("Given versions of a document with different numbers of chunks", t, func() {
+		ctx := context.Background()
+		storage := NewMultiViewStorage(NewMockVectorStore(), NewMockGraphStore(), NewMockSearchIndex(), nil)
+		processor := NewContentProcessorWithConfig(&ContentProcessingConfig{
+			MaxChunkSize:        100,
+			ChunkStrategy:       "paragraph",
+			MinEntityConfidence: 0.5,
+			MinClaimConfidence:  0.6,
+		})
+		config := DefaultMemoryWriterConfig()
+		config.DuplicatePolicy = DuplicatePolicyOff
+		writer := NewMemoryWriter(storage, processor, config)
+
+		long := "Paris is the capital of France. Write to team@example.com.\n\nBerlin is the capital of Germany. Write to berlin@example.com.\n\nMadrid is the capital of Spain. Write to madrid@example.com."
+		short := "Rome is the capital of Italy. Write to rome@example.com today.\n\nLisbon is the capital of Portugal. Write to lisbon@example.com."
+
+		Convey("When both are written concurrently, again and again", func() {
+			Convey("Then every round should leave exactly one version stored", func() {
+				for round := 0; round < 20; round++ {
+					var wg sync.WaitGroup
+					errs := make([]error, 2)
+					for i, content := range []string{long, short} {
+						wg.Add(1)
+						go func(i int, content string) {
+							defer wg.Done()
+							_, errs[i] = writer.Write(ctx, content, WriteMetadata{Source: "notes", Timestamp: time.Now(), ExternalID: "doc"})
+						}(i, content)
+					}
+					wg.Wait()
+					So(errs, ShouldResemble, []error{nil, nil})
+
+					docs, err := storage.ExternalChunks(ctx, "doc")
+					So(err, ShouldBeNil)
+					var contents []string
+					for _, doc := range docs {
+						contents = append(contents, doc.Content)
+					}
+					So(strings.Join(contents, "\n\n"), ShouldBeIn, []string{long, short})
+				}
+			})
+		})
+	})
+}
+
+func TestMemoryWriterIdempotency(t *testing.T) {
+	Convey("Given a MemoryWriter", t, func() {
+		ctx := context.Background()
+		storage := &MultiViewStorage{
+			vectorStore: NewMockVectorStore(),
+			graphStore:  NewMockGraphStore(),
+			searchIndex: NewMockSearchIndex(),
+		}
+		config := DefaultMemoryWriterConfig()
+		config.DuplicatePolicy = DuplicatePolicyOff
+		writer := NewMemoryWriter(storage, NewContentProcessor(), config)
+
+		content := "Machine learning systems learn statistical patterns from large datasets."
+		metadata := WriteMetadata{Source: "notes", Timestamp: time.Now(), IdempotencyKey: "retry-1"}
+		first, err := writer.Write(ctx, content, metadata)
+		So(err, ShouldBeNil)
+
+		Convey("When the write is retried with the same idempotency key", func() {
+			metadata.Timestamp = time.Now().Add(time.Minute)
+			again, err := writer.Write(ctx, content, metadata)
+
+			Convey("Then it returns the original result without storing anything", func() {
+				So(err, ShouldBeNil)
+				So(again, ShouldResemble, first)
+				So(again, ShouldNotPointTo, first)
+
+				lineage, err := writer.provenanceTracker.GetMemoryLineage(first.MemoryID)
+				So(err, ShouldBeNil)
+				So(len(lineage), ShouldEqual, 1)
+			})
+		})
+
+		Convey("When the key is reused for different content", func() {
+			_, err := writer.Write(ctx, "Databases answer queries using indexes.", metadata)
+
+			Convey("Then the write is refused", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "already used")
+			})
+		})
+	})
+}
+
+func TestMemoryWriterReingest(t *testing.T) {
+	Convey("Given a MemoryWriter that has ingested a document", t, func() {
+		ctx := context.Background()
+		storage := &MultiViewStorage{
+			vectorStore: NewMockVectorStore(),
+			graphStore:  NewMockGraphStore(),
+			searchIndex: NewMockSearchIndex(),
+			config:      &MultiViewStorageConfig{Timeout: 5 * time.Second},
+		}
+		processor := NewContentProcessorWithConfig(&ContentProcessingConfig{
+			MaxChunkSize:        100,
+			ChunkStrategy:       "paragraph",
+			MinEntityConfidence: 0.5,
+			MinClaimConfidence:  0.6,
+		})
+		config := DefaultMemoryWriterConfig()
+		writer := NewMemoryWriter(storage, processor, config)
+
+		metadata := WriteMetadata{Source: "atlas.md", Timestamp: time.Now(), Reingest: true}
+		document := "Paris is the capital of France. Write to team@example.com.\n\nBerlin is the capital of Germany. Write to berlin@example.com.\n\nMadrid is the capital of Spain. Write to madrid@example.com or team@example.com."
+		first, err := writer.Write(ctx, document, metadata)
+		So(err, ShouldBeNil)
+		So(first.ChunksUnchanged, ShouldEqual, 0)
+		count, _ := storage.searchIndex.DocumentCount(ctx)
+		So(count, ShouldEqual, 3)
+
+		chunkIDs := func() []string {
+			docs, err := storage.searchIndex.ListDocuments(ctx)
+			So(err, ShouldBeNil)
+			var ids []string
+			for _, doc := range docs {
+				ids = append(ids, doc.ID)
+			}
+			return uniqueSorted(ids)
+		}
+		before := chunkIDs()
+
+		Convey("When the document is re-ingested with one paragraph changed, one removed and one added", func() {
+			edited := "Paris is the capital of France. Write to team@example.com.\n\nBerlin is not the capital of Germany. Write to berlin@example.com.\n\nRome is the capital of Italy. Write to rome@example.com today."
+			result, err := writer.Write(ctx, edited, metadata)
+
+			Convey("Then only the new chunks are stored and the vanished ones deleted", func() {
+				So(err, ShouldBeNil)
+				So(result.CandidateCount, ShouldEqual, 3)
+				So(result.ChunksUnchanged, ShouldEqual, 1)
+				So(result.ChunksRemoved, ShouldEqual, 2)
+				So(result.ConflictsFound, ShouldBeEmpty)
+
+				after := chunkIDs()
+				So(after, ShouldHaveLength, 3)
+				So(after, ShouldContain, first.MemoryID)
+				kept := 0
+				for _, id := range after {
+					for _, old := range before {
+						if id == old {
+							kept++
+						}
+					}
+				}
+				So(kept, ShouldEqual, 1)
+			})
+
+			Convey("Then the entities and claims only the vanished chunks mentioned are gone", func() {
+				So(err, ShouldBeNil)
+				nodes, err := storage.graphStore.FindNodesByType(ctx, EntityNode, map[string]interface{}{"name": "madrid@example.com"})
+				So(err, ShouldBeNil)
+				So(nodes, ShouldBeEmpty)
+				for _, name := range []string{"team@example.com", "berlin@example.com", "rome@example.com"} {
+					nodes, err := storage.graphStore.FindNodesByType(ctx, EntityNode, map[string]interface{}{"name": name})
+					So(err, ShouldBeNil)
+					So(nodes, ShouldHaveLength, 1)
+				}
+
+				claims, err := storage.graphStore.FindNodesByType(ctx, ClaimNode, nil)
+				So(err, ShouldBeNil)
+				So(claims, ShouldHaveLength, 3)
+				for _, claim := range claims {
+					So(claim.Properties["subject"], ShouldNotEqual, "Madrid")
+					So(chunkIDs(), ShouldContain, claim.Properties["chunk_id"])
+				}
+			})
+		})
+
+		Convey("When the same document is re-ingested", func() {
+			result, err := writer.Write(ctx, document, metadata)
+
+			Convey("Then nothing is stored or deleted and the chunk IDs are kept", func() {
+				So(err, ShouldBeNil)
+				So(result.MemoryID, ShouldEqual, first.MemoryID)
+				So(result.ChunksUnchanged, ShouldEqual, 3)
+				So(result.ChunksRemoved, ShouldEqual, 0)
+				So(result.ProvenanceID, ShouldBeEmpty)
+				So(chunkIDs(), ShouldResemble, before)
+			})
+		})
+
+		Convey("When a new writer re-ingests the document", func() {
+			restarted := NewMemoryWriter(storage, processor, config)
+			result, err := restarted.Write(ctx, "Paris is the capital of France. Write to team@example.com.\n\nMadrid is the capital of Spain. Write to madrid@example.com or team@example.com.", metadata)
+
+			Convey("Then the manifest is rebuilt from the stored chunks", func() {
+				So(err, ShouldBeNil)
+				So(result.ChunksUnchanged, ShouldEqual, 2)
+				So(result.ChunksRemoved, ShouldEqual, 1)
+				So(chunkIDs(), ShouldHaveLength, 2)
+			})
+		})
+
+		Convey("When a chunk is deleted outside the writer", func() {
+			So(storage.DeleteChunk(ctx, first.MemoryID), ShouldBeNil)
+			result, err := writer.Write(ctx, document, metadata)
+
+			Convey("Then re-ingesting stores it again under the same ID", func() {
+				So(err, ShouldBeNil)
+				So(result.MemoryID, ShouldEqual, first.MemoryID)
+				So(result.ChunksUnchanged, ShouldEqual, 2)
+				So(chunkIDs(), ShouldResemble, before)
+			})
+		})
+	})
+}
@@ -0,0 +1,519 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MetadataFilter is a parsed filter expression that every store evaluates against document
+// metadata, node properties or edge properties in the same way.
+//
+// Filters are written as JSON-style maps. A field mapped to a plain value is an equality test;
+// a field mapped to an object applies the operators it names, all of which must hold:
+//
+//	{"source": "blog"}
+//	{"source": {"in": ["blog", "wiki"]}, "author": {"exists": true}}
+//	{"tags": {"contains": ["go", "storage"]}}
+//	{"confidence": {"range": {"gte": 0.5}}}
+//	{"timestamp": {"range": {"gte": "2025-01-01T00:00:00Z", "lt": "2025-02-01T00:00:00Z"}}}
+//	{"or": [{"source": "blog"}, {"not": {"tags": {"contains": "draft"}}}]}
+//
+// The keys "and", "or" and "not" are reserved for boolean combinators at any level.
+type MetadataFilter interface {
+	Matches(metadata map[string]interface{}) bool
+}
+
+// Filter operator names accepted inside a field's operator object
+const (
+	FilterOpEq       = "eq"
+	FilterOpNe       = "ne"
+	FilterOpIn       = "in"
+	FilterOpRange    = "range"
+	FilterOpExists   = "exists"
+	FilterOpPrefix   = "prefix"
+	FilterOpContains = "contains"
+
+	FilterOpAnd = "and"
+	FilterOpOr  = "or"
+	FilterOpNot = "not"
+)
+
+// isLogicalFilterKey reports whether key is a boolean combinator rather than a field name
+func isLogicalFilterKey(key string) bool {
+	return key == FilterOpAnd || key == FilterOpOr || key == FilterOpNot
+}
+
+// ParseMetadataFilter parses a filter map into an expression; nil or empty filters match everything
+func ParseMetadataFilter(filters map[string]interface{}) (MetadataFilter, error) {
+	if len(filters) == 0 {
+		return andFilter{}, nil
+	}
+
+	keys := make([]string, 0, len(filters))
+	for key := range filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var clauses andFilter
+	for _, key := range keys {
+		clause, err := parseFilterClause(key, filters[key])
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, clause)
+	}
+	if len(clauses) == 1 {
+		return clauses[0], nil
+	}
+	return clauses, nil
+}
+
+// parseFilterClause parses one key of a filter map, either a combinator or a field test
+func parseFilterClause(key string, value interface{}) (MetadataFilter, error) {
+	switch key {
+	case FilterOpAnd, FilterOpOr:
+		items, ok := filterList(value)
+		if !ok || len(items) == 0 {
+			return nil, fmt.Errorf("filter %q needs a non-empty list of filters", key)
+		}
+		clauses := make([]MetadataFilter, 0, len(items))
+		for i, item := range items {
+			sub, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("filter %q item %d is not an object", key, i)
+			}
+			clause, err := ParseMetadataFilter(sub)
+			if err != nil {
+				return nil, err
+			}
+			clauses = append(clauses, clause)
+		}
+		if key == FilterOpAnd {
+			return andFilter(clauses), nil
+		}
+		return orFilter(clauses), nil
+	case FilterOpNot:
+		sub, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("filter %q needs an object", key)
+		}
+		clause, err := ParseMetadataFilter(sub)
+		if err != nil {
+			return nil, err
+		}
+		return notFilter{clause}, nil
+	}
+
+	if key == "" {
+		return nil, fmt.Errorf("filter field name cannot be empty")
+	}
+
+	switch v := value.(type) {
+	case *TimeRange:
+		if v == nil {
+			return nil, fmt.Errorf("filter %q has a nil time range", key)
+		}
+		return timeRangeFilter{field: key, timeRange: *v}, nil
+	case TimeRange:
+		return timeRangeFilter{field: key, timeRange: v}, nil
+	case map[string]interface{}:
+		return parseFieldOperators(key, v)
+	}
+	return eqFilter{field: key, value: value}, nil
+}
+
+// parseFieldOperators parses an operator object such as {"in": [...], "exists": true} for one field
+func parseFieldOperators(field string, operators map[string]interface{}) (MetadataFilter, error) {
+	if len(operators) == 0 {
+		return nil, fmt.Errorf("filter %q has no operators", field)
+	}
+
+	names := make([]string, 0, len(operators))
+	for name := range operators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var clauses andFilter
+	for _, name := range names {
+		operand := operators[name]
+		switch name {
+		case FilterOpEq:
+			clauses = append(clauses, eqFilter{field: field, value: operand})
+		case FilterOpNe:
+			clauses = append(clauses, notFilter{eqFilter{field: field, value: operand}})
+		case FilterOpIn:
+			values, ok := filterList(operand)
+			if !ok {
+				return nil, fmt.Errorf("filter %q operator %q needs a list", field, name)
+			}
+			clauses = append(clauses, inFilter{field: field, values: values})
+		case FilterOpRange:
+			clause, err := parseRangeFilter(field, operand)
+			if err != nil {
+				return nil, err
+			}
+			clauses = append(clauses, clause)
+		case FilterOpExists:
+			want, ok := operand.(bool)
+			if !ok {
+				return nil, fmt.Errorf("filter %q operator %q needs a boolean", field, name)
+			}
+			clauses = append(clauses, existsFilter{field: field, exists: want})
+		case FilterOpPrefix:
+			prefix, ok := operand.(string)
+			if !ok {
+				return nil, fmt.Errorf("filter %q operator %q needs a string", field, name)
+			}
+			clauses = append(clauses, prefixFilter{field: field, prefix: prefix})
+		case FilterOpContains:
+			values, ok := filterList(operand)
+			if !ok {
+				values = []interface{}{operand}
+			}
+			if len(values) == 0 {
+				return nil, fmt.Errorf("filter %q operator %q needs at least one value", field, name)
+			}
+			clauses = append(clauses, containsFilter{field: field, values: values})
+		default:
+			return nil, fmt.Errorf("filter %q uses unknown operator %q", field, name)
+		}
+	}
+	if len(clauses) == 1 {
+		return clauses[0], nil
+	}
+	return clauses, nil
+}
+
+// parseRangeFilter parses {"gt"|"gte"|"lt"|"lte": bound} into a numeric or time range; all bounds
+// must be of the same kind
+func parseRangeFilter(field string, operand interface{}) (MetadataFilter, error) {
+	bounds, ok := operand.(map[string]interface{})
+	if !ok || len(bounds) == 0 {
+		return nil, fmt.Errorf("filter %q range needs an object of bounds", field)
+	}
+
+	numeric := rangeFilter{field: field}
+	timeRange := timeBoundsFilter{field: field}
+	numericBounds, timeBounds := 0, 0
+
+	for name, bound := range bounds {
+		if name != "gt" && name != "gte" && name != "lt" && name != "lte" {
+			return nil, fmt.Errorf("filter %q range uses unknown bound %q", field, name)
+		}
+		if n, ok := filterNumber(bound); ok {
+			numeric.set(name, n)
+			numericBounds++
+			continue
+		}
+		if t, ok := filterTime(bound); ok {
+			timeRange.set(name, t)
+			timeBounds++
+			continue
+		}
+		return nil, fmt.Errorf("filter %q range bound %q must be a number or a timestamp", field, name)
+	}
+
+	switch {
+	case numericBounds > 0 && timeBounds > 0:
+		return nil, fmt.Errorf("filter %q range mixes numeric and time bounds", field)
+	case timeBounds > 0:
+		return timeRange, nil
+	}
+	return numeric, nil
+}
+
+// andFilter matches when every clause matches; an empty conjunction matches everything
+type andFilter []MetadataFilter
+
+func (f andFilter) Matches(metadata map[string]interface{}) bool {
+	for _, clause := range f {
+		if !clause.Matches(metadata) {
+			return false
+		}
+	}
+	return true
+}
+
+// orFilter matches when any clause matches
+type orFilter []MetadataFilter
+
+func (f orFilter) Matches(metadata map[string]interface{}) bool {
+	for _, clause := range f {
+		if clause.Matches(metadata) {
+			return true
+		}
+	}
+	return false
+}
+
+// notFilter inverts its clause, so "ne" also matches metadata without the field
+type notFilter struct {
+	clause MetadataFilter
+}
+
+func (f notFilter) Matches(metadata map[string]interface{}) bool {
+	return !f.clause.Matches(metadata)
+}
+
+// eqFilter matches a field equal to value, or a list field holding it
+type eqFilter struct {
+	field string
+	value interface{}
+}
+
+func (f eqFilter) Matches(metadata map[string]interface{}) bool {
+	actual, exists := metadata[f.field]
+	return exists && matchesFilterValue(actual, f.value)
+}
+
+// inFilter matches a field equal to any of values
+type inFilter struct {
+	field  string
+	values []interface{}
+}
+
+func (f inFilter) Matches(metadata map[string]interface{}) bool {
+	actual, exists := metadata[f.field]
+	if !exists {
+		return false
+	}
+	for _, value := range f.values {
+		if matchesFilterValue(actual, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// existsFilter matches on the presence of a non-nil field
+type existsFilter struct {
+	field  string
+	exists bool
+}
+
+func (f existsFilter) Matches(metadata map[string]interface{}) bool {
+	actual, exists := metadata[f.field]
+	return (exists && actual != nil) == f.exists
+}
+
+// prefixFilter matches a string field, or any item of a list field, starting with prefix
+type prefixFilter struct {
+	field  string
+	prefix string
+}
+
+func (f prefixFilter) Matches(metadata map[string]interface{}) bool {
+	actual, exists := metadata[f.field]
+	if !exists {
+		return false
+	}
+	if text, ok := actual.(string); ok {
+		return strings.HasPrefix(text, f.prefix)
+	}
+	items, ok := filterList(actual)
+	if !ok {
+		return false
+	}
+	for _, item := range items {
+		if text, ok := item.(string); ok && strings.HasPrefix(text, f.prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFilter matches a list field, such as tags, holding every one of values
+type containsFilter struct {
+	field  string
+	values []interface{}
+}
+
+func (f containsFilter) Matches(metadata map[string]interface{}) bool {
+	items, ok := filterList(metadata[f.field])
+	if !ok {
+		return false
+	}
+	for _, want := range f.values {
+		found := false
+		for _, item := range items {
+			if matchesFilterValue(item, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// rangeFilter matches a numeric field within optional bounds
+type rangeFilter struct {
+	field            string
+	min, max         *float64
+	minOpen, maxOpen bool
+}
+
+func (f *rangeFilter) set(bound string, value float64) {
+	switch bound {
+	case "gt", "gte":
+		f.min, f.minOpen = &value, bound == "gt"
+	case "lt", "lte":
+		f.max, f.maxOpen = &value, bound == "lt"
+	}
+}
+
+func (f rangeFilter) Matches(metadata map[string]interface{}) bool {
+	n, ok := filterNumber(metadata[f.field])
+	if !ok {
+		return false
+	}
+	if f.min != nil && (n < *f.min || (f.minOpen && n == *f.min)) {
+		return false
+	}
+	if f.max != nil && (n > *f.max || (f.maxOpen && n == *f.max)) {
+		return false
+	}
+	return true
+}
+
+// timeBoundsFilter matches a timestamp field within optional bounds
+type timeBoundsFilter struct {
+	field            string
+	min, max         *time.Time
+	minOpen, maxOpen bool
+}
+
+func (f *timeBoundsFilter) set(bound string, value time.Time) {
+	switch bound {
+	case "gt", "gte":
+		f.min, f.minOpen = &value, bound == "gt"
+	case "lt", "lte":
+		f.max, f.maxOpen = &value, bound == "lt"
+	}
+}
+
+func (f timeBoundsFilter) Matches(metadata map[string]interface{}) bool {
+	t, ok := filterTime(metadata[f.field])
+	if !ok {
+		return false
+	}
+	if f.min != nil && (t.Before(*f.min) || (f.minOpen && t.Equal(*f.min))) {
+		return false
+	}
+	if f.max != nil && (t.After(*f.max) || (f.maxOpen && t.Equal(*f.max))) {
+		return false
+	}
+	return true
+}
+
+// timeRangeFilter matches a timestamp field inside a TimeRange parsed from the query
+type timeRangeFilter struct {
+	field     string
+	timeRange TimeRange
+}
+
+func (f timeRangeFilter) Matches(metadata map[string]interface{}) bool {
+	t, ok := filterTime(metadata[f.field])
+	return ok && f.timeRange.Contains(t)
+}
+
+// matchesFilterValue compares one metadata value against a filter value. A *TimeRange matches
+// timestamps inside the range, a string matches equal text, list members and scalars printing
+// the same, numbers compare by value whatever their type, and any other value must be deeply equal.
+func matchesFilterValue(actual, expected interface{}) bool {
+	switch want := expected.(type) {
+	case *TimeRange:
+		t, ok := filterTime(actual)
+		return ok && want.Contains(t)
+	case TimeRange:
+		t, ok := filterTime(actual)
+		return ok && want.Contains(t)
+	case string:
+		switch have := actual.(type) {
+		case string:
+			return have == want
+		case []string:
+			for _, item := range have {
+				if item == want {
+					return true
+				}
+			}
+			return false
+		case []interface{}:
+			for _, item := range have {
+				if matchesFilterValue(item, want) {
+					return true
+				}
+			}
+			return false
+		case bool, int, int64, float32, float64:
+			return fmt.Sprint(have) == want
+		}
+		return false
+	}
+	if want, ok := filterNumber(expected); ok {
+		have, ok := filterNumber(actual)
+		return ok && have == want
+	}
+	return reflect.DeepEqual(actual, expected)
+}
+
+// filterList reads a metadata or operand value as a list
+func filterList(value interface{}) ([]interface{}, bool) {
+	switch v := value.(type) {
+	case []interface{}:
+		return v, true
+	case []string:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+		return items, true
+	}
+	return nil, false
+}
+
+// filterNumber reads a metadata or operand value as a number
+func filterNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case json.Number:
+		n, err := v.Float64()
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// filterTime reads a metadata value as a time, accepting time.Time, RFC3339 strings and dates
+func filterTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		text := strings.TrimSpace(v)
+		if t, err := time.Parse(time.RFC3339, text); err == nil {
+			return t, true
+		}
+		if t, err := time.Parse("2006-01-02", text); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
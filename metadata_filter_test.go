@@ -0,0 +1,125 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMetadataFilterMatches(t *testing.T) {
+	Convey("Given document metadata", t, func() {
+		metadata := map[string]interface{}{
+			"source":    "blog",
+			"tags":      []interface{}{"go", "storage"},
+			"pinned":    true,
+			"timestamp": "2025-03-10T12:00:00Z",
+		}
+
+		matches := func(metadata, filters map[string]interface{}) bool {
+			filter, err := ParseMetadataFilter(filters)
+			So(err, ShouldBeNil)
+			return filter.Matches(metadata)
+		}
+
+		Convey("Then equal strings, list members and scalar text should match", func() {
+			So(matches(metadata, map[string]interface{}{"source": "blog"}), ShouldBeTrue)
+			So(matches(metadata, map[string]interface{}{"tags": "storage"}), ShouldBeTrue)
+			So(matches(metadata, map[string]interface{}{"pinned": "true"}), ShouldBeTrue)
+			So(matches(metadata, map[string]interface{}{"pinned": true}), ShouldBeTrue)
+			So(matches(metadata, nil), ShouldBeTrue)
+		})
+
+		Convey("Then differing or missing values should not match", func() {
+			So(matches(metadata, map[string]interface{}{"source": "Blog"}), ShouldBeFalse)
+			So(matches(metadata, map[string]interface{}{"tags": "rust"}), ShouldBeFalse)
+			So(matches(metadata, map[string]interface{}{"author": "ann"}), ShouldBeFalse)
+		})
+
+		Convey("Then a time range should be checked against the timestamp", func() {
+			start := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+			end := time.Date(2025, time.March, 31, 0, 0, 0, 0, time.UTC)
+			So(matches(metadata, map[string]interface{}{"timestamp": &TimeRange{Start: &start, End: &end}}), ShouldBeTrue)
+
+			later := end.AddDate(0, 1, 0)
+			So(matches(metadata, map[string]interface{}{"timestamp": &TimeRange{Start: &end, End: &later}}), ShouldBeFalse)
+			So(matches(map[string]interface{}{"timestamp": "yesterday"}, map[string]interface{}{"timestamp": &TimeRange{Start: &start}}), ShouldBeFalse)
+		})
+	})
+}
+
+func TestParseMetadataFilter(t *testing.T) {
+	Convey("Given document metadata", t, func() {
+		metadata := map[string]interface{}{
+			"source":     "blog/engineering",
+			"tags":       []string{"go", "storage"},
+			"confidence": 0.8,
+			"count":      3,
+			"timestamp":  "2025-03-10T12:00:00Z",
+		}
+
+		matches := func(filters map[string]interface{}) bool {
+			filter, err := ParseMetadataFilter(filters)
+			So(err, ShouldBeNil)
+			return filter.Matches(metadata)
+		}
+
+		Convey("Then field operators should be evaluated", func() {
+			So(matches(map[string]interface{}{"source": map[string]interface{}{"eq": "blog/engineering"}}), ShouldBeTrue)
+			So(matches(map[string]interface{}{"source": map[string]interface{}{"ne": "wiki"}}), ShouldBeTrue)
+			So(matches(map[string]interface{}{"author": map[string]interface{}{"ne": "ann"}}), ShouldBeTrue)
+			So(matches(map[string]interface{}{"source": map[string]interface{}{"in": []interface{}{"wiki", "blog/engineering"}}}), ShouldBeTrue)
+			So(matches(map[string]interface{}{"source": map[string]interface{}{"prefix": "blog/"}}), ShouldBeTrue)
+			So(matches(map[string]interface{}{"source": map[string]interface{}{"prefix": "wiki/"}}), ShouldBeFalse)
+			So(matches(map[string]interface{}{"author": map[string]interface{}{"exists": false}}), ShouldBeTrue)
+			So(matches(map[string]interface{}{"source": map[string]interface{}{"exists": true, "prefix": "blog"}}), ShouldBeTrue)
+		})
+
+		Convey("Then tag arrays should support contains", func() {
+			So(matches(map[string]interface{}{"tags": map[string]interface{}{"contains": "go"}}), ShouldBeTrue)
+			So(matches(map[string]interface{}{"tags": map[string]interface{}{"contains": []interface{}{"go", "storage"}}}), ShouldBeTrue)
+			So(matches(map[string]interface{}{"tags": map[string]interface{}{"contains": []interface{}{"go", "rust"}}}), ShouldBeFalse)
+			So(matches(map[string]interface{}{"source": map[string]interface{}{"contains": "blog"}}), ShouldBeFalse)
+		})
+
+		Convey("Then numeric and time ranges should respect their bounds", func() {
+			So(matches(map[string]interface{}{"confidence": map[string]interface{}{"range": map[string]interface{}{"gte": 0.5, "lte": 0.8}}}), ShouldBeTrue)
+			So(matches(map[string]interface{}{"confidence": map[string]interface{}{"range": map[string]interface{}{"gt": 0.8}}}), ShouldBeFalse)
+			So(matches(map[string]interface{}{"count": map[string]interface{}{"range": map[string]interface{}{"lt": 4.0}}}), ShouldBeTrue)
+			So(matches(map[string]interface{}{"count": 3.0}), ShouldBeTrue)
+			So(matches(map[string]interface{}{"timestamp": map[string]interface{}{"range": map[string]interface{}{"gte": "2025-03-01", "lt": "2025-04-01T00:00:00Z"}}}), ShouldBeTrue)
+			So(matches(map[string]interface{}{"timestamp": map[string]interface{}{"range": map[string]interface{}{"gt": "2025-03-10T12:00:00Z"}}}), ShouldBeFalse)
+		})
+
+		Convey("Then boolean combinators should nest", func() {
+			So(matches(map[string]interface{}{
+				"or": []interface{}{
+					map[string]interface{}{"source": "wiki"},
+					map[string]interface{}{"tags": map[string]interface{}{"contains": "storage"}},
+				},
+			}), ShouldBeTrue)
+			So(matches(map[string]interface{}{
+				"and": []interface{}{
+					map[string]interface{}{"source": map[string]interface{}{"prefix": "blog"}},
+					map[string]interface{}{"not": map[string]interface{}{"tags": map[string]interface{}{"contains": "go"}}},
+				},
+			}), ShouldBeFalse)
+		})
+
+		Convey("Then malformed filters should be rejected", func() {
+			invalid := []map[string]interface{}{
+				{"source": map[string]interface{}{"like": "blog"}},
+				{"source": map[string]interface{}{"in": "blog"}},
+				{"tags": map[string]interface{}{"exists": "yes"}},
+				{"confidence": map[string]interface{}{"range": map[string]interface{}{"gte": 0.5, "lt": "2025-01-01"}}},
+				{"confidence": map[string]interface{}{"range": map[string]interface{}{"above": 0.5}}},
+				{"or": []interface{}{}},
+				{"not": "source"},
+			}
+			for _, filters := range invalid {
+				_, err := ParseMetadataFilter(filters)
+				So(err, ShouldNotBeNil)
+			}
+		})
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sort"
 	"sync"
 )
 
@@ -14,6 +15,7 @@ type MockGraphStore struct {
 	edges        map[string]*Edge
 	adjacencyOut map[string][]string // outgoing edges from node
 	adjacencyIn  map[string][]string // incoming edges to node
+	version      uint64              // bumped by every write
 	closed       bool
 	healthy      bool
 }
@@ -59,6 +61,7 @@ func (m *MockGraphStore) CreateNode(ctx context.Context, node *Node) error {
 	m.nodes[node.ID] = node
 	m.adjacencyOut[node.ID] = []string{}
 	m.adjacencyIn[node.ID] = []string{}
+	m.version++
 	
 	return nil
 }
@@ -93,6 +96,7 @@ func (m *MockGraphStore) UpdateNode(ctx context.Context, node *Node) error {
 	}
 	
 	m.nodes[node.ID] = node
+	m.version++
 	return nil
 }
 
@@ -109,11 +113,17 @@ func (m *MockGraphStore) DeleteNode(ctx context.Context, id string) error {
 		return fmt.Errorf("node with ID %s not found", id)
 	}
 	
-	// Remove all edges connected to this node
+	// Remove all edges connected to this node, including from the other ends' adjacency lists
 	for _, edgeID := range m.adjacencyOut[id] {
+		if edge, exists := m.edges[edgeID]; exists {
+			m.adjacencyIn[edge.To] = m.removeFromSlice(m.adjacencyIn[edge.To], edgeID)
+		}
 		delete(m.edges, edgeID)
 	}
 	for _, edgeID := range m.adjacencyIn[id] {
+		if edge, exists := m.edges[edgeID]; exists {
+			m.adjacencyOut[edge.From] = m.removeFromSlice(m.adjacencyOut[edge.From], edgeID)
+		}
 		delete(m.edges, edgeID)
 	}
 	
@@ -121,6 +131,7 @@ func (m *MockGraphStore) DeleteNode(ctx context.Context, id string) error {
 	delete(m.nodes, id)
 	delete(m.adjacencyOut, id)
 	delete(m.adjacencyIn, id)
+	m.version++
 	
 	return nil
 }
@@ -149,6 +160,7 @@ func (m *MockGraphStore) CreateEdge(ctx context.Context, edge *Edge) error {
 	m.edges[edge.ID] = edge
 	m.adjacencyOut[edge.From] = append(m.adjacencyOut[edge.From], edge.ID)
 	m.adjacencyIn[edge.To] = append(m.adjacencyIn[edge.To], edge.ID)
+	m.version++
 	
 	return nil
 }
@@ -183,6 +195,7 @@ func (m *MockGraphStore) UpdateEdge(ctx context.Context, edge *Edge) error {
 	}
 	
 	m.edges[edge.ID] = edge
+	m.version++
 	return nil
 }
 
@@ -201,10 +214,11 @@ func (m *MockGraphStore) DeleteEdge(ctx context.Context, id string) error {
 	}
 	
 	// Remove from adjacency lists
-	m.removeFromSlice(m.adjacencyOut[edge.From], id)
-	m.removeFromSlice(m.adjacencyIn[edge.To], id)
+	m.adjacencyOut[edge.From] = m.removeFromSlice(m.adjacencyOut[edge.From], id)
+	m.adjacencyIn[edge.To] = m.removeFromSlice(m.adjacencyIn[edge.To], id)
 	
 	delete(m.edges, id)
+	m.version++
 	return nil
 }
 
@@ -261,6 +275,57 @@ func (m *MockGraphStore) FindPaths(ctx context.Context, from, to string, options
 	return paths, nil
 }
 
+// Version returns a number that changes whenever a node or edge is written
+func (m *MockGraphStore) Version() uint64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	
+	return m.version
+}
+
+// GetNodeEdges returns the edges into and out of a node, ordered by ID
+func (m *MockGraphStore) GetNodeEdges(ctx context.Context, nodeID string) ([]*Edge, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	
+	if m.closed {
+		return nil, fmt.Errorf("graph store is closed")
+	}
+	
+	seen := make(map[string]bool)
+	var edges []*Edge
+	for _, edgeIDs := range [][]string{m.adjacencyOut[nodeID], m.adjacencyIn[nodeID]} {
+		for _, edgeID := range edgeIDs {
+			if edge, exists := m.edges[edgeID]; exists && !seen[edgeID] {
+				seen[edgeID] = true
+				edges = append(edges, edge)
+			}
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].ID < edges[j].ID })
+	return edges, nil
+}
+
+// FindEntitiesByName returns the entity nodes named name, ignoring case, ordered by ID
+func (m *MockGraphStore) FindEntitiesByName(ctx context.Context, name string) ([]*Node, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	
+	if m.closed {
+		return nil, fmt.Errorf("graph store is closed")
+	}
+	
+	key := entityNameKey(name)
+	var nodes []*Node
+	for _, node := range m.nodes {
+		if node.Type == EntityNode && key != "" && entityNameKey(nodeName(node)) == key {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes, nil
+}
+
 // GetNeighbors returns neighboring nodes
 func (m *MockGraphStore) GetNeighbors(ctx context.Context, nodeID string, options GraphTraversalOptions) ([]Node, error) {
 	m.mu.RLock()
@@ -298,15 +363,26 @@ func (m *MockGraphStore) PageRank(ctx context.Context, options PageRankOptions)
 	scores := make(map[string]float64)
 	newScores := make(map[string]float64)
 	
+	// Seeds personalize the walk: all teleport and dangling mass returns to them
+	teleport := pageRankTeleport(m.nodes, options.Seeds, float64(len(m.nodes)))
+	
 	// Initialize scores
 	for nodeID := range m.nodes {
-		scores[nodeID] = 1.0
+		if teleport != nil {
+			scores[nodeID] = teleport[nodeID]
+		} else {
+			scores[nodeID] = 1.0
+		}
 	}
 	
 	// Iterate PageRank algorithm
 	for iter := 0; iter < options.MaxIter; iter++ {
 		for nodeID := range m.nodes {
-			newScores[nodeID] = (1.0 - options.Alpha)
+			if teleport != nil {
+				newScores[nodeID] = (1.0 - options.Alpha) * teleport[nodeID]
+			} else {
+				newScores[nodeID] = (1.0 - options.Alpha)
+			}
 		}
 		
 		for nodeID := range m.nodes {
@@ -317,6 +393,10 @@ func (m *MockGraphStore) PageRank(ctx context.Context, options PageRankOptions)
 					edge := m.edges[edgeID]
 					newScores[edge.To] += contribution
 				}
+			} else if teleport != nil {
+				for seedID, share := range teleport {
+					newScores[seedID] += options.Alpha * scores[nodeID] * share / float64(len(m.nodes))
+				}
 			}
 		}
 		
@@ -339,8 +419,13 @@ func (m *MockGraphStore) PageRank(ctx context.Context, options PageRankOptions)
 	return scores, nil
 }
 
-// CommunityDetection implements a simple community detection algorithm
+// CommunityDetection performs community detection using Louvain algorithm
 func (m *MockGraphStore) CommunityDetection(ctx context.Context) ([]Community, error) {
+	return m.CommunityDetectionWithOptions(ctx, DefaultCommunityDetectionOptions())
+}
+
+// CommunityDetectionWithOptions performs Louvain community detection over weighted edges
+func (m *MockGraphStore) CommunityDetectionWithOptions(ctx context.Context, options CommunityDetectionOptions) ([]Community, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	
@@ -348,28 +433,17 @@ func (m *MockGraphStore) CommunityDetection(ctx context.Context) ([]Community, e
 		return nil, fmt.Errorf("graph store is closed")
 	}
 	
-	// Simple connected components as communities
-	visited := make(map[string]bool)
-	var communities []Community
-	communityID := 0
-	
+	nodeIDs := make([]string, 0, len(m.nodes))
 	for nodeID := range m.nodes {
-		if !visited[nodeID] {
-			var component []string
-			m.dfsComponent(nodeID, visited, &component)
-			
-			if len(component) > 0 {
-				communities = append(communities, Community{
-					ID:    fmt.Sprintf("community_%d", communityID),
-					Nodes: component,
-					Score: float64(len(component)),
-				})
-				communityID++
-			}
-		}
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	
+	edges := make([]*Edge, 0, len(m.edges))
+	for _, edge := range m.edges {
+		edges = append(edges, edge)
 	}
 	
-	return communities, nil
+	return DetectCommunitiesLouvain(nodeIDs, edges, options), nil
 }
 
 // ShortestPath finds the shortest path between two nodes
@@ -419,9 +493,14 @@ func (m *MockGraphStore) FindNodesByType(ctx context.Context, nodeType NodeType,
 		return nil, fmt.Errorf("graph store is closed")
 	}
 	
+	filter, err := ParseMetadataFilter(filters)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filters: %w", err)
+	}
+	
 	var result []*Node
 	for _, node := range m.nodes {
-		if node.Type == nodeType && m.matchesNodeFilters(node, filters) {
+		if node.Type == nodeType && filter.Matches(node.Properties) {
 			result = append(result, node)
 		}
 	}
@@ -438,9 +517,14 @@ func (m *MockGraphStore) FindEdgesByType(ctx context.Context, edgeType EdgeType,
 		return nil, fmt.Errorf("graph store is closed")
 	}
 	
+	filter, err := ParseMetadataFilter(filters)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filters: %w", err)
+	}
+	
 	var result []*Edge
 	for _, edge := range m.edges {
-		if edge.Type == edgeType && m.matchesEdgePropertiesFilters(edge, filters) {
+		if edge.Type == edgeType && filter.Matches(edge.Properties) {
 			result = append(result, edge)
 		}
 	}
@@ -536,50 +620,3 @@ func (m *MockGraphStore) matchesEdgeFilters(edge *Edge, edgeTypes []EdgeType) bo
 	
 	return false
 }
-
-func (m *MockGraphStore) matchesNodeFilters(node *Node, filters map[string]interface{}) bool {
-	if filters == nil {
-		return true
-	}
-	
-	for key, expectedValue := range filters {
-		if actualValue, exists := node.Properties[key]; !exists || actualValue != expectedValue {
-			return false
-		}
-	}
-	
-	return true
-}
-
-func (m *MockGraphStore) matchesEdgePropertiesFilters(edge *Edge, filters map[string]interface{}) bool {
-	if filters == nil {
-		return true
-	}
-	
-	for key, expectedValue := range filters {
-		if actualValue, exists := edge.Properties[key]; !exists || actualValue != expectedValue {
-			return false
-		}
-	}
-	
-	return true
-}
-
-func (m *MockGraphStore) dfsComponent(nodeID string, visited map[string]bool, component *[]string) {
-	visited[nodeID] = true
-	*component = append(*component, nodeID)
-	
-	for _, edgeID := range m.adjacencyOut[nodeID] {
-		edge := m.edges[edgeID]
-		if !visited[edge.To] {
-			m.dfsComponent(edge.To, visited, component)
-		}
-	}
-	
-	for _, edgeID := range m.adjacencyIn[nodeID] {
-		edge := m.edges[edgeID]
-		if !visited[edge.From] {
-			m.dfsComponent(edge.From, visited, component)
-		}
-	}
-}
\ No newline at end of file
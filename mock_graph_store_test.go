@@ -305,6 +305,26 @@ func TestMockGraphStore(t *testing.T) {
 				}
 			})
 			
+			Convey("Personalized PageRank", func() {
+				options := PageRankOptions{
+					Alpha:     0.85,
+					MaxIter:   100,
+					Tolerance: 0.0001,
+					Seeds:     []string{"1", "missing"},
+				}
+				
+				scores, err := store.PageRank(ctx, options)
+				So(err, ShouldBeNil)
+				So(len(scores), ShouldEqual, 4)
+				
+				// The seed should outrank the nodes it is not seeded from
+				for nodeID, score := range scores {
+					if nodeID != "1" {
+						So(scores["1"], ShouldBeGreaterThan, score)
+					}
+				}
+			})
+			
 			Convey("Community detection", func() {
 				communities, err := store.CommunityDetection(ctx)
 				So(err, ShouldBeNil)
@@ -312,9 +332,9 @@ func TestMockGraphStore(t *testing.T) {
 				
 				// All nodes should be in some community
 				allNodes := make(map[string]bool)
+				So(Modularity(communities), ShouldBeGreaterThanOrEqualTo, 0)
 				for _, community := range communities {
 					So(len(community.Nodes), ShouldBeGreaterThan, 0)
-					So(community.Score, ShouldBeGreaterThan, 0)
 					
 					for _, nodeID := range community.Nodes {
 						allNodes[nodeID] = true
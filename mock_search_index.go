@@ -46,14 +46,14 @@ func (m *MockSearchIndex) Index(ctx context.Context, doc IndexDocument) error {
 	
 	// Remove old document from index if it exists
 	if oldDoc, exists := m.documents[doc.ID]; exists {
-		m.removeFromIndex(doc.ID, oldDoc.Content)
+		m.removeFromIndex(doc.ID, documentText(oldDoc.Content, oldDoc.Metadata))
 	}
 	
 	// Store document
 	m.documents[doc.ID] = doc
 	
 	// Add to inverted index
-	m.addToIndex(doc.ID, doc.Content)
+	m.addToIndex(doc.ID, documentText(doc.Content, doc.Metadata))
 	
 	// Also store in test helper
 	m.indexed[doc.ID] = doc
@@ -82,7 +82,7 @@ func (m *MockSearchIndex) Update(ctx context.Context, id string, doc IndexDocume
 	
 	// Remove old document from index
 	if oldDoc, exists := m.documents[id]; exists {
-		m.removeFromIndex(id, oldDoc.Content)
+		m.removeFromIndex(id, documentText(oldDoc.Content, oldDoc.Metadata))
 	}
 	
 	// Update document ID to match parameter
@@ -90,7 +90,7 @@ func (m *MockSearchIndex) Update(ctx context.Context, id string, doc IndexDocume
 	m.documents[id] = doc
 	
 	// Add updated document to index
-	m.addToIndex(id, doc.Content)
+	m.addToIndex(id, documentText(doc.Content, doc.Metadata))
 	
 	return nil
 }
@@ -105,7 +105,7 @@ func (m *MockSearchIndex) Delete(ctx context.Context, id string) error {
 	}
 	
 	if doc, exists := m.documents[id]; exists {
-		m.removeFromIndex(id, doc.Content)
+		m.removeFromIndex(id, documentText(doc.Content, doc.Metadata))
 		delete(m.documents, id)
 		return nil
 	}
@@ -126,6 +126,64 @@ func (m *MockSearchIndex) Search(ctx context.Context, query string, options Sear
 		return nil, fmt.Errorf("search index is unhealthy")
 	}
 	
+	results, err := m.matchDocuments(query, options)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Apply pagination
+	start := options.Offset
+	end := start + options.Limit
+	
+	if start >= len(results) {
+		return []SearchResult{}, nil
+	}
+	
+	if end > len(results) {
+		end = len(results)
+	}
+	
+	if options.Limit > 0 {
+		results = results[start:end]
+	}
+	
+	return results, nil
+}
+
+// SearchWithFacets performs text search and counts the requested facets over every match
+func (m *MockSearchIndex) SearchWithFacets(ctx context.Context, query string, options SearchIndexOptions) (*SearchResponse, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	
+	if m.closed {
+		return nil, fmt.Errorf("search index is closed")
+	}
+	
+	if !m.healthy {
+		return nil, fmt.Errorf("search index is unhealthy")
+	}
+	
+	for _, request := range options.Facets {
+		if err := request.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid facets: %w", err)
+		}
+	}
+	
+	results, err := m.matchDocuments(query, options)
+	if err != nil {
+		return nil, err
+	}
+	
+	return newFacetedSearchResponse(results, options), nil
+}
+
+// matchDocuments scores, filters and sorts every document matching the query; callers hold the lock
+func (m *MockSearchIndex) matchDocuments(query string, options SearchIndexOptions) ([]SearchResult, error) {
+	filter, err := ParseMetadataFilter(options.Filters)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filters: %w", err)
+	}
+	
 	// Tokenize query
 	queryTerms := m.tokenize(strings.ToLower(query))
 	
@@ -146,7 +204,7 @@ func (m *MockSearchIndex) Search(ctx context.Context, query string, options Sear
 		doc := m.documents[docID]
 		
 		// Apply filters
-		if !m.matchesFilters(doc.Metadata, options.Filters) {
+		if !filter.Matches(doc.Metadata) {
 			continue
 		}
 		
@@ -170,22 +228,6 @@ func (m *MockSearchIndex) Search(ctx context.Context, query string, options Sear
 		return results[i].Score > results[j].Score
 	})
 	
-	// Apply pagination
-	start := options.Offset
-	end := start + options.Limit
-	
-	if start >= len(results) {
-		return []SearchResult{}, nil
-	}
-	
-	if end > len(results) {
-		end = len(results)
-	}
-	
-	if options.Limit > 0 {
-		results = results[start:end]
-	}
-	
 	return results, nil
 }
 
@@ -213,15 +255,8 @@ func (m *MockSearchIndex) Suggest(ctx context.Context, query string, field strin
 		return nil, fmt.Errorf("search index is closed")
 	}
 	
-	queryLower := strings.ToLower(query)
-	var suggestions []string
-	
-	// Find terms that start with the query
-	for term := range m.index {
-		if strings.HasPrefix(term, queryLower) {
-			suggestions = append(suggestions, term)
-		}
-	}
+	// Suggest whole words as written, not the stemmed terms in the index
+	suggestions := suggestWords(m.documents, query)
 	
 	// Sort and limit
 	sort.Strings(suggestions)
@@ -292,6 +327,26 @@ func (m *MockSearchIndex) DocumentCount(ctx context.Context) (int64, error) {
 	return int64(len(m.documents)), nil
 }
 
+// ListDocuments returns every indexed document ordered by ID
+func (m *MockSearchIndex) ListDocuments(ctx context.Context) ([]IndexDocument, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	
+	if m.closed {
+		return nil, fmt.Errorf("search index is closed")
+	}
+	
+	docs := make([]IndexDocument, 0, len(m.documents))
+	for _, doc := range m.documents {
+		docs = append(docs, doc)
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		return docs[i].ID < docs[j].ID
+	})
+	
+	return docs, nil
+}
+
 // IndexSize returns the index size (approximation)
 func (m *MockSearchIndex) IndexSize(ctx context.Context) (int64, error) {
 	m.mu.RLock()
@@ -343,23 +398,9 @@ func (m *MockSearchIndex) SetHealthy(healthy bool) {
 
 // Helper methods
 
-// tokenize splits text into terms
+// tokenize splits text into terms with the default analyzer
 func (m *MockSearchIndex) tokenize(text string) []string {
-	// Simple tokenization - split on whitespace and punctuation
-	text = strings.ToLower(text)
-	words := strings.FieldsFunc(text, func(c rune) bool {
-		return !((c >= 'a' && c <= 'z') || (c >= '0' && c <= '9'))
-	})
-	
-	// Remove empty strings
-	var result []string
-	for _, word := range words {
-		if len(word) > 0 {
-			result = append(result, word)
-		}
-	}
-	
-	return result
+	return AnalyzerForLanguage(defaultAnalyzerLanguage).Analyze(text)
 }
 
 // addToIndex adds a document to the inverted index
@@ -408,42 +449,12 @@ func (m *MockSearchIndex) removeFromIndex(docID, content string) {
 	}
 }
 
-// matchesFilters checks if document metadata matches filters
-func (m *MockSearchIndex) matchesFilters(metadata map[string]interface{}, filters map[string]interface{}) bool {
-	if filters == nil {
-		return true
-	}
-	
-	for key, expectedValue := range filters {
-		if actualValue, exists := metadata[key]; !exists || actualValue != expectedValue {
-			return false
-		}
-	}
-	
-	return true
-}
-
 // generateHighlights creates highlighted snippets
 func (m *MockSearchIndex) generateHighlights(content string, queryTerms []string) []string {
 	var highlights []string
-	contentLower := strings.ToLower(content)
 	
 	for _, term := range queryTerms {
-		if strings.Contains(contentLower, term) {
-			// Find the term and create a snippet around it
-			index := strings.Index(contentLower, term)
-			start := index - 20
-			if start < 0 {
-				start = 0
-			}
-			end := index + len(term) + 20
-			if end > len(content) {
-				end = len(content)
-			}
-			
-			snippet := content[start:end]
-			// Highlight the term (simple approach)
-			highlighted := strings.ReplaceAll(snippet, term, fmt.Sprintf("<em>%s</em>", term))
+		if highlighted, ok := highlightSnippet(content, term); ok {
 			highlights = append(highlights, highlighted)
 		}
 	}
@@ -329,6 +329,11 @@ func TestMockSearchIndex(t *testing.T) {
 			size, err := index.IndexSize(ctx)
 			So(err, ShouldBeNil)
 			So(size, ShouldBeGreaterThan, 0)
+			
+			docs, err := index.ListDocuments(ctx)
+			So(err, ShouldBeNil)
+			So(len(docs), ShouldEqual, count)
+			So(docs[0].ID < docs[len(docs)-1].ID, ShouldBeTrue)
 		})
 		
 		Convey("Index management operations", func() {
@@ -18,11 +18,18 @@ type MockVectorStore struct {
 	shouldFail    bool
 	searchResults []VectorResult
 	stored        map[string]VectorStoreItem
+	metric        VectorMetric
 }
 
 // NewMockVectorStore creates a new mock vector store
 func NewMockVectorStore() *MockVectorStore {
+	return NewMockVectorStoreWithMetric(VectorMetricCosine)
+}
+
+// NewMockVectorStoreWithMetric creates a mock vector store that scores with the given metric
+func NewMockVectorStoreWithMetric(metric VectorMetric) *MockVectorStore {
 	return &MockVectorStore{
+		metric:        metric,
 		vectors:       make(map[string]VectorStoreItem),
 		metadata:      make(map[string]map[string]interface{}),
 		healthy:       true,
@@ -76,16 +83,21 @@ func (m *MockVectorStore) Search(ctx context.Context, query []float32, k int, fi
 		return m.searchResults, nil
 	}
 	
+	filter, err := ParseMetadataFilter(filters)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filters: %w", err)
+	}
+	
 	var results []VectorResult
 	
 	for id, vector := range m.vectors {
 		// Apply filters
-		if !m.matchesFilters(vector.Metadata, filters) {
+		if !filter.Matches(vector.Metadata) {
 			continue
 		}
 		
-		// Calculate cosine similarity
-		score := m.cosineSimilarity(query, vector.Embedding)
+		// Score with the configured metric
+		score := m.metric.Similarity(query, vector.Embedding)
 		
 		result := VectorResult{
 			ID:        id,
@@ -265,6 +277,11 @@ func (m *MockVectorStore) GetStored() map[string]VectorStoreItem {
 	return m.stored
 }
 
+// Metric returns the metric search results are scored with
+func (m *MockVectorStore) Metric() VectorMetric {
+	return m.metric
+}
+
 // cosineSimilarity calculates cosine similarity between two vectors
 func (m *MockVectorStore) cosineSimilarity(a, b []float32) float64 {
 	if len(a) != len(b) {
@@ -285,18 +302,3 @@ func (m *MockVectorStore) cosineSimilarity(a, b []float32) float64 {
 	
 	return dotProduct / (math.Sqrt(normA) * math.Sqrt(normB))
 }
-
-// matchesFilters checks if metadata matches the given filters
-func (m *MockVectorStore) matchesFilters(metadata map[string]interface{}, filters map[string]interface{}) bool {
-	if filters == nil {
-		return true
-	}
-	
-	for key, expectedValue := range filters {
-		if actualValue, exists := metadata[key]; !exists || actualValue != expectedValue {
-			return false
-		}
-	}
-	
-	return true
-}
\ No newline at end of file
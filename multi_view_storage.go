@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -11,11 +12,14 @@ import (
 
 // MultiViewStorage coordinates operations across vector, graph, and search storage backends
 type MultiViewStorage struct {
-	vectorStore VectorStore
-	graphStore  GraphStore
-	searchIndex SearchIndex
-	config      *MultiViewStorageConfig
-	mu          sync.RWMutex
+	vectorStore  VectorStore
+	graphStore   GraphStore
+	searchIndex  SearchIndex
+	config       *MultiViewStorageConfig
+	writes       *writeCoordinator
+	fingerprints *fingerprintIndex
+	externalIDs  *externalIDIndex
+	mu           sync.RWMutex
 }
 
 // MultiViewStorageConfig holds configuration for the multi-view storage system
@@ -23,6 +27,7 @@ type MultiViewStorageConfig struct {
 	VectorStore VectorStoreConfig `json:"vector_store"`
 	GraphStore  GraphStoreConfig  `json:"graph_store"`
 	SearchIndex SearchIndexConfig `json:"search_index"`
+	DataDir     string            `json:"data_dir"`
 	Timeout     time.Duration     `json:"timeout"`
 	RetryCount  int               `json:"retry_count"`
 }
@@ -44,88 +49,475 @@ func NewMultiViewStorage(vectorStore VectorStore, graphStore GraphStore, searchI
 		graphStore:  graphStore,
 		searchIndex: searchIndex,
 		config:      config,
+		writes:      newWriteCoordinator(""),
 	}
 }
 
-// StoreChunk stores a chunk across all storage backends
+// StoreChunk stores a chunk across all storage backends as one transaction: if any view
+// fails to take the write, the changes already made to the others are rolled back, so the
+// chunk is visible in every view or in none
 func (mvs *MultiViewStorage) StoreChunk(ctx context.Context, chunk *Chunk) error {
 	mvs.mu.Lock()
 	defer mvs.mu.Unlock()
 
 	// Create timeout context
-	timeoutCtx, cancel := context.WithTimeout(ctx, mvs.config.Timeout)
+	timeoutCtx, cancel := mvs.timeoutContext(ctx)
 	defer cancel()
 
-	var errors []error
+	return mvs.storeChunk(timeoutCtx, chunk)
+}
+
+// storeChunk writes a chunk to every view in a transaction (assumes lock is held)
+func (mvs *MultiViewStorage) storeChunk(ctx context.Context, chunk *Chunk) error {
+	if mvs.writes == nil {
+		mvs.writes = newWriteCoordinator("")
+	}
+
+	tx, err := mvs.writes.begin(chunk, mvs.vectorStore, mvs.graphStore, mvs.searchIndex)
+	if err != nil {
+		return fmt.Errorf("failed to log write intent: %w", err)
+	}
+
+	if err := writeChunkViews(ctx, tx.Vectors(), tx.Search(), tx.Graph(), chunk); err != nil {
+		// Roll back even when the write failed because its deadline passed
+		if rollbackErr := tx.rollback(context.WithoutCancel(ctx)); rollbackErr != nil {
+			return fmt.Errorf("failed to store chunk %s: %w; %v", chunk.ID, err, rollbackErr)
+		}
+		return fmt.Errorf("failed to store chunk %s, rolled back all views: %w", chunk.ID, err)
+	}
+
+	if err := tx.commit(); err != nil {
+		return fmt.Errorf("failed to commit chunk %s: %w", chunk.ID, err)
+	}
+	mvs.indexFingerprint(chunk.ID, chunk.Content, chunk.Metadata)
+	if mvs.externalIDs != nil {
+		mvs.externalIDs.add(chunk.ID, chunk.Metadata)
+	}
+	return nil
+}
+
+// ReplaceChunks deletes the chunks of a previous version of a document and stores the new
+// version's chunks in one transaction, so readers see the old version or the new one and
+// never a mix; if any view fails, every change is rolled back and the old version remains.
+// previousIDs must still be current, so callers serialize the replacements of one document.
+func (mvs *MultiViewStorage) ReplaceChunks(ctx context.Context, previousIDs []string, chunks []*Chunk) error {
+	mvs.mu.Lock()
+	defer mvs.mu.Unlock()
+
+	timeoutCtx, cancel := mvs.timeoutContext(ctx)
+	defer cancel()
+
+	if mvs.writes == nil {
+		mvs.writes = newWriteCoordinator("")
+	}
+	// The new chunks are not logged: an interrupted replace is rolled back to the old version
+	tx, err := mvs.writes.begin(nil, mvs.vectorStore, mvs.graphStore, mvs.searchIndex)
+	if err != nil {
+		return fmt.Errorf("failed to log write intent: %w", err)
+	}
+
+	err = func() error {
+		for _, id := range previousIDs {
+			if errs := deleteChunkViews(timeoutCtx, tx.Vectors(), tx.Search(), tx.Graph(), id); len(errs) > 0 {
+				return fmt.Errorf("failed to delete chunk %s: %v", id, errs)
+			}
+		}
+		for _, chunk := range chunks {
+			if err := writeChunkViews(timeoutCtx, tx.Vectors(), tx.Search(), tx.Graph(), chunk); err != nil {
+				return fmt.Errorf("failed to store chunk %s: %w", chunk.ID, err)
+			}
+		}
+		return nil
+	}()
+	if err != nil {
+		if rollbackErr := tx.rollback(context.WithoutCancel(timeoutCtx)); rollbackErr != nil {
+			return fmt.Errorf("failed to replace chunks: %w; %v", err, rollbackErr)
+		}
+		return fmt.Errorf("failed to replace chunks, rolled back all views: %w", err)
+	}
+
+	if err := tx.commit(); err != nil {
+		return fmt.Errorf("failed to commit replaced chunks: %w", err)
+	}
+
+	for _, id := range previousIDs {
+		if mvs.fingerprints != nil {
+			mvs.fingerprints.remove(id)
+		}
+		if mvs.externalIDs != nil {
+			mvs.externalIDs.remove(id)
+		}
+	}
+	for _, chunk := range chunks {
+		mvs.indexFingerprint(chunk.ID, chunk.Content, chunk.Metadata)
+		if mvs.externalIDs != nil {
+			mvs.externalIDs.add(chunk.ID, chunk.Metadata)
+		}
+	}
+	return nil
+}
+
+// SourceChunks returns the stored chunks written from a source, ordered by ID. Chunks written
+// under an external ID belong to that document rather than their source and are left out.
+func (mvs *MultiViewStorage) SourceChunks(ctx context.Context, source string) ([]IndexDocument, error) {
+	mvs.mu.RLock()
+	defer mvs.mu.RUnlock()
+
+	timeoutCtx, cancel := mvs.timeoutContext(ctx)
+	defer cancel()
+
+	docs, err := mvs.searchIndex.ListDocuments(timeoutCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents for source %s: %w", source, err)
+	}
+
+	var chunks []IndexDocument
+	for _, doc := range docs {
+		if doc.Metadata["source"] != source {
+			continue
+		}
+		if externalID, _ := doc.Metadata["external_id"].(string); externalID != "" {
+			continue
+		}
+		chunks = append(chunks, doc)
+	}
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].ID < chunks[j].ID })
+	return chunks, nil
+}
+
+// ChunkExists reports whether a chunk is stored
+func (mvs *MultiViewStorage) ChunkExists(ctx context.Context, chunkID string) (bool, error) {
+	mvs.mu.RLock()
+	defer mvs.mu.RUnlock()
+
+	timeoutCtx, cancel := mvs.timeoutContext(ctx)
+	defer cancel()
+
+	return mvs.searchIndex.DocumentExists(timeoutCtx, chunkID)
+}
+
+// ExternalChunks returns the stored chunks written under an external ID, ordered by ID. The
+// index from external IDs to chunks is built from the search index the first time it is
+// needed and kept up to date as chunks are stored, replaced and deleted.
+func (mvs *MultiViewStorage) ExternalChunks(ctx context.Context, externalID string) ([]IndexDocument, error) {
+	timeoutCtx, cancel := mvs.timeoutContext(ctx)
+	defer cancel()
+
+	index, err := mvs.externalIDIndex(timeoutCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	mvs.mu.RLock()
+	var docs []IndexDocument
+	var stale []string
+	for _, id := range index.lookup(externalID) {
+		// Chunks removed without going through DeleteChunk drop out here
+		exists, err := mvs.searchIndex.DocumentExists(timeoutCtx, id)
+		if err != nil {
+			mvs.mu.RUnlock()
+			return nil, fmt.Errorf("failed to check chunk %s: %w", id, err)
+		}
+		if !exists {
+			stale = append(stale, id)
+			continue
+		}
+		doc, err := mvs.searchIndex.GetDocument(timeoutCtx, id)
+		if err != nil {
+			mvs.mu.RUnlock()
+			return nil, fmt.Errorf("failed to get chunk %s: %w", id, err)
+		}
+		docs = append(docs, *doc)
+	}
+	mvs.mu.RUnlock()
+
+	if len(stale) > 0 {
+		mvs.mu.Lock()
+		for _, id := range stale {
+			index.remove(id)
+		}
+		mvs.mu.Unlock()
+	}
+	return docs, nil
+}
+
+// externalIDIndex returns the external ID index, building it from the search index if it does
+// not exist yet; only building it takes the write lock
+func (mvs *MultiViewStorage) externalIDIndex(ctx context.Context) (*externalIDIndex, error) {
+	mvs.mu.RLock()
+	index := mvs.externalIDs
+	mvs.mu.RUnlock()
+	if index != nil {
+		return index, nil
+	}
+
+	mvs.mu.Lock()
+	defer mvs.mu.Unlock()
+	if mvs.externalIDs != nil {
+		return mvs.externalIDs, nil
+	}
+	docs, err := mvs.searchIndex.ListDocuments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents for external IDs: %w", err)
+	}
+	mvs.externalIDs = newExternalIDIndex()
+	for _, doc := range docs {
+		mvs.externalIDs.add(doc.ID, doc.Metadata)
+	}
+	return mvs.externalIDs, nil
+}
+
+// UpdateChunkMetadata applies update to a copy of a stored chunk's metadata and writes the
+// result to the vector store and search index in one transaction
+func (mvs *MultiViewStorage) UpdateChunkMetadata(ctx context.Context, chunkID string, update func(metadata map[string]interface{})) error {
+	mvs.mu.Lock()
+	defer mvs.mu.Unlock()
+
+	timeoutCtx, cancel := mvs.timeoutContext(ctx)
+	defer cancel()
+
+	doc, err := mvs.searchIndex.GetDocument(timeoutCtx, chunkID)
+	if err != nil {
+		return fmt.Errorf("failed to get chunk %s: %w", chunkID, err)
+	}
+	metadata := make(map[string]interface{}, len(doc.Metadata))
+	for k, v := range doc.Metadata {
+		metadata[k] = v
+	}
+	update(metadata)
+
+	if mvs.writes == nil {
+		mvs.writes = newWriteCoordinator("")
+	}
+	tx, err := mvs.writes.begin(nil, mvs.vectorStore, mvs.graphStore, mvs.searchIndex)
+	if err != nil {
+		return fmt.Errorf("failed to log write intent: %w", err)
+	}
+
+	if err := updateChunkViews(timeoutCtx, tx.Vectors(), tx.Search(), chunkID, doc.Content, metadata); err != nil {
+		if rollbackErr := tx.rollback(context.WithoutCancel(timeoutCtx)); rollbackErr != nil {
+			return fmt.Errorf("failed to update chunk %s: %w; %v", chunkID, err, rollbackErr)
+		}
+		return fmt.Errorf("failed to update chunk %s, rolled back all views: %w", chunkID, err)
+	}
+
+	if err := tx.commit(); err != nil {
+		return fmt.Errorf("failed to commit chunk %s: %w", chunkID, err)
+	}
+	return nil
+}
+
+// ClaimConflicts reports the stored claims a chunk's claims were found to negate or
+// contradict when the chunk was stored
+func (mvs *MultiViewStorage) ClaimConflicts(ctx context.Context, chunk *Chunk) ([]ConflictInfo, error) {
+	mvs.mu.RLock()
+	defer mvs.mu.RUnlock()
+
+	timeoutCtx, cancel := mvs.timeoutContext(ctx)
+	defer cancel()
 
-	// Store in vector database
-	if err := mvs.vectorStore.Store(timeoutCtx, chunk.ID, chunk.Embedding, chunk.Metadata); err != nil {
-		errors = append(errors, fmt.Errorf("vector store error: %w", err))
+	return findClaimConflicts(timeoutCtx, mvs.graphStore, chunk)
+}
+
+// FindNearDuplicates returns the stored chunks whose content fingerprints lie within distance
+// bits of the chunk's, closest first; a distance of 0 uses the default. The fingerprint index
+// is built from the search index the first time it is needed and kept up to date as chunks
+// are stored and deleted.
+func (mvs *MultiViewStorage) FindNearDuplicates(ctx context.Context, chunk *Chunk, distance int) ([]NearDuplicate, error) {
+	timeoutCtx, cancel := mvs.timeoutContext(ctx)
+	defer cancel()
+
+	fingerprint, ok := simHash(AnalyzerForLanguage(documentLanguage(chunk.Metadata)), chunk.Content)
+	if !ok {
+		return nil, nil
+	}
+
+	index, err := mvs.nearDuplicateIndex(timeoutCtx, distance)
+	if err != nil {
+		return nil, err
+	}
+
+	mvs.mu.RLock()
+	var duplicates []NearDuplicate
+	var stale []string
+	for _, match := range index.matches(fingerprint) {
+		if match.ChunkID == chunk.ID {
+			continue
+		}
+		// Chunks removed without going through DeleteChunk drop out here
+		exists, err := mvs.searchIndex.DocumentExists(timeoutCtx, match.ChunkID)
+		if err != nil {
+			mvs.mu.RUnlock()
+			return nil, fmt.Errorf("failed to check chunk %s: %w", match.ChunkID, err)
+		}
+		if !exists {
+			stale = append(stale, match.ChunkID)
+			continue
+		}
+		duplicates = append(duplicates, match)
+	}
+	mvs.mu.RUnlock()
+
+	if len(stale) > 0 {
+		mvs.mu.Lock()
+		for _, id := range stale {
+			index.remove(id)
+		}
+		mvs.mu.Unlock()
+	}
+	return duplicates, nil
+}
+
+// nearDuplicateIndex returns the fingerprint index, fingerprinting every indexed document
+// when there is none yet or it was built for another distance; only building it takes the
+// write lock
+func (mvs *MultiViewStorage) nearDuplicateIndex(ctx context.Context, distance int) (*fingerprintIndex, error) {
+	distance = duplicateDistance(distance)
+	mvs.mu.RLock()
+	index := mvs.fingerprints
+	mvs.mu.RUnlock()
+	if index != nil && index.distance == distance {
+		return index, nil
+	}
+
+	mvs.mu.Lock()
+	defer mvs.mu.Unlock()
+	if mvs.fingerprints != nil && mvs.fingerprints.distance == distance {
+		return mvs.fingerprints, nil
+	}
+
+	docs, err := mvs.searchIndex.ListDocuments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents for fingerprinting: %w", err)
+	}
+
+	mvs.fingerprints = newFingerprintIndex(distance)
+	for _, doc := range docs {
+		mvs.indexFingerprint(doc.ID, doc.Content, doc.Metadata)
+	}
+	return mvs.fingerprints, nil
+}
+
+// indexFingerprint adds a chunk to the fingerprint index once it has been built (assumes lock is held)
+func (mvs *MultiViewStorage) indexFingerprint(id, content string, metadata map[string]interface{}) {
+	if mvs.fingerprints == nil {
+		return
+	}
+	if fingerprint, ok := simHash(AnalyzerForLanguage(documentLanguage(metadata)), content); ok {
+		mvs.fingerprints.add(id, fingerprint)
+	} else {
+		mvs.fingerprints.remove(id)
+	}
+}
+
+// RecoverWrites opens the write intent log at path and resolves the writes a crash left
+// interrupted: each is rolled back and then written again from the logged chunk, leaving the
+// chunk in every view or, if the write fails again, in none. Later writes log their intents
+// to the same file.
+func (mvs *MultiViewStorage) RecoverWrites(ctx context.Context, path string) error {
+	mvs.mu.Lock()
+	defer mvs.mu.Unlock()
+
+	mvs.writes = newWriteCoordinator(path)
+	// Rolling back interrupted writes changes the stored chunks; index them afresh when next needed
+	mvs.fingerprints = nil
+	mvs.externalIDs = nil
+	pending, err := mvs.writes.interrupted()
+	if err != nil {
+		return fmt.Errorf("failed to read write intent log: %w", err)
+	}
+
+	for _, write := range pending {
+		tx := mvs.writes.resume(write, mvs.vectorStore, mvs.graphStore, mvs.searchIndex)
+		if err := tx.rollback(ctx); err != nil {
+			return fmt.Errorf("failed to roll back interrupted write %s: %w", write.id, err)
+		}
+		if write.chunk == nil {
+			continue
+		}
+		if err := mvs.storeChunk(ctx, write.chunk); err != nil {
+			log.Printf("Interrupted write of chunk %s was rolled back: %v", write.chunk.ID, err)
+		}
+	}
+
+	return mvs.writes.compact(true)
+}
+
+// timeoutContext applies the configured timeout, if any, to a context
+func (mvs *MultiViewStorage) timeoutContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if mvs.config == nil {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, mvs.config.Timeout)
+}
+
+// writeChunkViews writes a chunk to the vector store, search index and graph, then stores
+// the embeddings of its entities so later writes can resolve against them
+func writeChunkViews(ctx context.Context, vectorStore VectorStore, searchIndex SearchIndex, graphStore GraphStore, chunk *Chunk) error {
+	// Store in vector database, keeping the content alongside for vector-only hits
+	vectorMetadata := make(map[string]interface{}, len(chunk.Metadata)+1)
+	for k, v := range chunk.Metadata {
+		vectorMetadata[k] = v
+	}
+	vectorMetadata["content"] = chunk.Content
+	if err := vectorStore.Store(ctx, chunk.ID, chunk.Embedding, vectorMetadata); err != nil {
+		return fmt.Errorf("failed to store in vector database: %w", err)
 	}
 
 	// Store in search index
-	searchDoc := IndexDocument{
+	doc := IndexDocument{
 		ID:       chunk.ID,
 		Content:  chunk.Content,
 		Metadata: chunk.Metadata,
 	}
-	if err := mvs.searchIndex.Index(timeoutCtx, searchDoc); err != nil {
-		errors = append(errors, fmt.Errorf("search index error: %w", err))
+	if err := searchIndex.Index(ctx, doc); err != nil {
+		return fmt.Errorf("failed to index in search: %w", err)
 	}
 
-	// Store entities and claims in graph
+	// Store the chunk, its source, entities and claims in the graph with their relationships
+	if err := persistChunkGraph(ctx, graphStore, chunk); err != nil {
+		return err
+	}
+
+	// Store entity embeddings so later writes can resolve against them
 	for _, entity := range chunk.Entities {
-		node := &Node{
-			ID:   entity.ID,
-			Type: EntityNode,
-			Properties: map[string]interface{}{
-				"name":       entity.Name,
-				"type":       entity.Type,
-				"confidence": entity.Confidence,
-				"chunk_id":   chunk.ID,
-			},
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-		}
-
-		if err := mvs.graphStore.CreateNode(timeoutCtx, node); err != nil {
-			// Node might already exist, try to update
-			if updateErr := mvs.graphStore.UpdateNode(timeoutCtx, node); updateErr != nil {
-				// If both create and update fail, it's likely a duplicate. Log as a warning.
-				log.Printf("warning: failed to create or update node %s: %v", node.ID, updateErr)
+		if embedding, ok := extractEmbedding(entity.Properties["embedding"]); ok {
+			entityMetadata := map[string]interface{}{
+				"type":        "entity",
+				"name":        entity.Name,
+				"entity_type": entity.Type,
+			}
+			if err := vectorStore.Store(ctx, entity.ID, embedding, entityMetadata); err != nil {
+				return fmt.Errorf("failed to store entity embedding: %w", err)
 			}
 		}
 	}
 
-	// Store claims in graph
-	for _, claim := range chunk.Claims {
-		node := &Node{
-			ID:   claim.ID,
-			Type: ClaimNode,
-			Properties: map[string]interface{}{
-				"subject":    claim.Subject,
-				"predicate":  claim.Predicate,
-				"object":     claim.Object,
-				"confidence": claim.Confidence,
-				"chunk_id":   chunk.ID,
-			},
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-		}
+	return nil
+}
 
-		if err := mvs.graphStore.CreateNode(timeoutCtx, node); err != nil {
-			if updateErr := mvs.graphStore.UpdateNode(timeoutCtx, node); updateErr != nil {
-				// If both create and update fail, it's likely a duplicate. Log as a warning.
-				log.Printf("warning: failed to create or update claim node %s: %v", node.ID, updateErr)
-			}
+// updateChunkViews rewrites the metadata a chunk has in the vector store, if it has a vector,
+// and in the search index
+func updateChunkViews(ctx context.Context, vectorStore VectorStore, searchIndex SearchIndex, chunkID, content string, metadata map[string]interface{}) error {
+	if _, err := vectorStore.GetByID(ctx, chunkID); err == nil {
+		vectorMetadata := make(map[string]interface{}, len(metadata)+1)
+		for k, v := range metadata {
+			vectorMetadata[k] = v
+		}
+		vectorMetadata["content"] = content
+		if err := vectorStore.Update(ctx, chunkID, vectorMetadata); err != nil {
+			return fmt.Errorf("failed to update vector metadata: %w", err)
 		}
 	}
 
-	// If we have errors but some operations succeeded, log them but don't fail
-	if len(errors) > 0 {
-		return fmt.Errorf("partial storage failure: %v", errors)
+	doc := IndexDocument{
+		ID:       chunkID,
+		Content:  content,
+		Metadata: metadata,
+	}
+	if err := searchIndex.Update(ctx, chunkID, doc); err != nil {
+		return fmt.Errorf("failed to update search document: %w", err)
 	}
-
 	return nil
 }
 
@@ -201,54 +593,160 @@ func (mvs *MultiViewStorage) RetrieveMultiView(ctx context.Context, query string
 	return results, nil
 }
 
-// DeleteChunk removes a chunk from all storage backends
+// DeleteChunk removes a chunk from all storage backends in one transaction; if any view
+// fails, every change is rolled back and the chunk remains
 func (mvs *MultiViewStorage) DeleteChunk(ctx context.Context, chunkID string) error {
 	mvs.mu.Lock()
 	defer mvs.mu.Unlock()
 
-	timeoutCtx, cancel := context.WithTimeout(ctx, mvs.config.Timeout)
+	timeoutCtx, cancel := mvs.timeoutContext(ctx)
 	defer cancel()
 
+	if mvs.writes == nil {
+		mvs.writes = newWriteCoordinator("")
+	}
+	tx, err := mvs.writes.begin(nil, mvs.vectorStore, mvs.graphStore, mvs.searchIndex)
+	if err != nil {
+		return fmt.Errorf("failed to log write intent: %w", err)
+	}
+
+	if errors := deleteChunkViews(timeoutCtx, tx.Vectors(), tx.Search(), tx.Graph(), chunkID); len(errors) > 0 {
+		if rollbackErr := tx.rollback(context.WithoutCancel(timeoutCtx)); rollbackErr != nil {
+			return fmt.Errorf("failed to delete chunk %s: %v; %v", chunkID, errors, rollbackErr)
+		}
+		return fmt.Errorf("failed to delete chunk %s, rolled back all views: %v", chunkID, errors)
+	}
+
+	if err := tx.commit(); err != nil {
+		return fmt.Errorf("failed to commit deleted chunk %s: %w", chunkID, err)
+	}
+	if mvs.fingerprints != nil {
+		mvs.fingerprints.remove(chunkID)
+	}
+	if mvs.externalIDs != nil {
+		mvs.externalIDs.remove(chunkID)
+	}
+	return nil
+}
+
+// deleteChunkViews removes a chunk from the vector store and search index and deletes its
+// entity, claim and chunk nodes from the graph, carrying on past failures and returning them.
+// A view the chunk is missing from is left alone.
+func deleteChunkViews(ctx context.Context, vectorStore VectorStore, searchIndex SearchIndex, graphStore GraphStore, chunkID string) []error {
 	var errors []error
 
 	// Delete from vector store
-	if err := mvs.vectorStore.Delete(timeoutCtx, chunkID); err != nil {
-		errors = append(errors, fmt.Errorf("vector store delete error: %w", err))
+	if _, err := vectorStore.GetByID(ctx, chunkID); err == nil {
+		if err := vectorStore.Delete(ctx, chunkID); err != nil {
+			errors = append(errors, fmt.Errorf("vector store delete error: %w", err))
+		}
 	}
 
 	// Delete from search index
-	if err := mvs.searchIndex.Delete(timeoutCtx, chunkID); err != nil {
-		errors = append(errors, fmt.Errorf("search index delete error: %w", err))
+	if exists, err := searchIndex.DocumentExists(ctx, chunkID); err != nil {
+		errors = append(errors, fmt.Errorf("search index lookup error: %w", err))
+	} else if exists {
+		if err := searchIndex.Delete(ctx, chunkID); err != nil {
+			errors = append(errors, fmt.Errorf("search index delete error: %w", err))
+		}
 	}
 
-	// Delete related nodes from graph (nodes with chunk_id property)
-	nodes, err := mvs.graphStore.FindNodesByType(timeoutCtx, EntityNode, map[string]interface{}{
-		"chunk_id": chunkID,
-	})
-	if err == nil {
+	// Delete the entities no other chunk mentions, with their embeddings; an entity another
+	// chunk still mentions is kept and loses only its PART_OF edge with the chunk node
+	nodes, err := chunkEntities(ctx, graphStore, chunkID)
+	if err != nil {
+		errors = append(errors, fmt.Errorf("graph entity lookup error: %w", err))
+	} else {
+		// The chunk no longer counts towards the co-occurrences of its entities
+		for i := 0; i < len(nodes); i++ {
+			for j := i + 1; j < len(nodes); j++ {
+				if err := removeCooccurrence(ctx, graphStore, nodes[i].ID, nodes[j].ID); err != nil {
+					errors = append(errors, fmt.Errorf("graph co-occurrence update error: %w", err))
+				}
+				if err := removeCooccurrence(ctx, graphStore, nodes[j].ID, nodes[i].ID); err != nil {
+					errors = append(errors, fmt.Errorf("graph co-occurrence update error: %w", err))
+				}
+			}
+		}
+
 		for _, node := range nodes {
-			if err := mvs.graphStore.DeleteNode(timeoutCtx, node.ID); err != nil {
+			if remaining := entityChunks(ctx, graphStore, node.ID, chunkID); len(remaining) > 0 {
+				continue
+			}
+
+			if err := graphStore.DeleteNode(ctx, node.ID); err != nil {
 				errors = append(errors, fmt.Errorf("graph node delete error: %w", err))
 			}
+			if _, err := vectorStore.GetByID(ctx, node.ID); err == nil {
+				if err := vectorStore.Delete(ctx, node.ID); err != nil {
+					errors = append(errors, fmt.Errorf("entity vector delete error: %w", err))
+				}
+			}
 		}
 	}
 
-	claims, err := mvs.graphStore.FindNodesByType(timeoutCtx, ClaimNode, map[string]interface{}{
+	claims, err := graphStore.FindNodesByType(ctx, ClaimNode, map[string]interface{}{
 		"chunk_id": chunkID,
 	})
 	if err == nil {
 		for _, claim := range claims {
-			if err := mvs.graphStore.DeleteNode(timeoutCtx, claim.ID); err != nil {
+			if err := graphStore.DeleteNode(ctx, claim.ID); err != nil {
 				errors = append(errors, fmt.Errorf("graph claim delete error: %w", err))
 			}
 		}
 	}
 
-	if len(errors) > 0 {
-		return fmt.Errorf("partial delete failure: %v", errors)
+	// Delete the chunk node along with its PART_OF edges
+	if _, err := graphStore.GetNode(ctx, chunkID); err == nil {
+		if err := graphStore.DeleteNode(ctx, chunkID); err != nil {
+			errors = append(errors, fmt.Errorf("graph chunk delete error: %w", err))
+		}
 	}
 
-	return nil
+	return errors
+}
+
+// chunkEntities returns the entity nodes PART_OF a chunk, read from the chunk node's edges
+func chunkEntities(ctx context.Context, graphStore GraphStore, chunkID string) ([]*Node, error) {
+	if _, err := graphStore.GetNode(ctx, chunkID); err != nil {
+		return nil, nil
+	}
+	edges, err := graphStore.GetNodeEdges(ctx, chunkID)
+	if err != nil {
+		return nil, err
+	}
+
+	var entities []*Node
+	for _, edge := range edges {
+		if edge.Type != PartOf || edge.To != chunkID {
+			continue
+		}
+		node, err := graphStore.GetNode(ctx, edge.From)
+		if err == nil && node.Type == EntityNode {
+			entities = append(entities, node)
+		}
+	}
+	return entities, nil
+}
+
+// entityChunks returns the chunks other than excludeID that an entity is PART_OF, sorted by ID
+func entityChunks(ctx context.Context, graphStore GraphStore, entityID, excludeID string) []string {
+	neighbors, err := graphStore.GetNeighbors(ctx, entityID, GraphTraversalOptions{
+		MaxDepth:  1,
+		EdgeTypes: []EdgeType{PartOf},
+		NodeTypes: []NodeType{ChunkNode},
+	})
+	if err != nil {
+		return nil
+	}
+
+	var chunkIDs []string
+	for _, neighbor := range neighbors {
+		if neighbor.Type == ChunkNode && neighbor.ID != excludeID {
+			chunkIDs = append(chunkIDs, neighbor.ID)
+		}
+	}
+	return uniqueSorted(chunkIDs)
 }
 
 // GetStats returns statistics about the storage system
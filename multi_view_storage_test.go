@@ -86,7 +86,7 @@ func TestMultiViewStorage(t *testing.T) {
 					So(err, ShouldBeNil)
 					So(node.Type, ShouldEqual, EntityNode)
 					So(node.Properties["name"], ShouldEqual, entity.Name)
-					So(node.Properties["chunk_id"], ShouldEqual, chunk.ID)
+					So(entityChunks(ctx, graphStore, entity.ID, ""), ShouldResemble, []string{chunk.ID})
 				}
 				
 				for _, claim := range chunk.Claims {
@@ -98,18 +98,43 @@ func TestMultiViewStorage(t *testing.T) {
 				}
 			})
 			
-			Convey("Should handle partial storage failures gracefully", func() {
+			Convey("Should leave no view written when the first fails", func() {
 				// Make vector store unhealthy to simulate failure
 				vectorStore.SetHealthy(false)
 				
 				err := mvs.StoreChunk(ctx, chunk)
-				So(err, ShouldNotBeNil) // Should report partial failure
-				So(err.Error(), ShouldContainSubstring, "partial storage failure")
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "rolled back all views")
 				
-				// But other backends should still work
-				searchDoc, err := searchIndex.GetDocument(ctx, chunk.ID)
+				// No other backend should have taken the write
+				_, err = searchIndex.GetDocument(ctx, chunk.ID)
+				So(err, ShouldNotBeNil)
+				So(graphStore.GetNodes(), ShouldBeEmpty)
+			})
+			
+			Convey("Should roll back the views already written when a later one fails", func() {
+				existing := NewNode("entity-1", EntityNode)
+				existing.SetProperty("name", "ML")
+				So(graphStore.CreateNode(ctx, existing), ShouldBeNil)
+				
+				failing := NewMultiViewStorage(vectorStore, &failingGraphStore{GraphStore: graphStore, failOn: "claim-1"}, searchIndex, config)
+				err := failing.StoreChunk(ctx, chunk)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "rolled back all views")
+				
+				_, err = vectorStore.GetByID(ctx, chunk.ID)
+				So(err, ShouldNotBeNil)
+				_, err = searchIndex.GetDocument(ctx, chunk.ID)
+				So(err, ShouldNotBeNil)
+				_, err = graphStore.GetNode(ctx, chunk.ID)
+				So(err, ShouldNotBeNil)
+				So(graphStore.GetEdges(), ShouldBeEmpty)
+				
+				// The entity that existed beforehand should be restored rather than deleted
+				restored, err := graphStore.GetNode(ctx, "entity-1")
 				So(err, ShouldBeNil)
-				So(searchDoc.Content, ShouldEqual, chunk.Content)
+				So(restored.Properties["name"], ShouldEqual, "ML")
+				So(len(graphStore.GetNodes()), ShouldEqual, 1)
 			})
 		})
 		
@@ -248,6 +273,69 @@ func TestMultiViewStorage(t *testing.T) {
 				_, err = graphStore.GetNode(ctx, "delete-claim")
 				So(err, ShouldNotBeNil)
 			})
+			
+			Convey("Should keep entities another chunk still mentions", func() {
+				other := &Chunk{
+					ID:        "delete-test-other",
+					Content:   "This chunk mentions the same entity",
+					Embedding: []float32{0.3, 0.2, 0.1},
+					Entities:  []Entity{{ID: "delete-entity", Name: "test entity", Type: "test"}},
+				}
+				So(mvs.StoreChunk(ctx, other), ShouldBeNil)
+				
+				err := mvs.DeleteChunk(ctx, chunk.ID)
+				So(err, ShouldBeNil)
+				
+				_, err = graphStore.GetNode(ctx, "delete-entity")
+				So(err, ShouldBeNil)
+				So(entityChunks(ctx, graphStore, "delete-entity", ""), ShouldResemble, []string{other.ID})
+				
+				So(mvs.DeleteChunk(ctx, other.ID), ShouldBeNil)
+				_, err = graphStore.GetNode(ctx, "delete-entity")
+				So(err, ShouldNotBeNil)
+			})
+			
+			Convey("Should count only stored chunks towards co-occurrence weights", func() {
+				pair := func(id string) *Chunk {
+					return &Chunk{
+						ID:        id,
+						Content:   "Go and Rust are compiled languages",
+						Embedding: []float32{0.2, 0.2, 0.2},
+						Entities: []Entity{
+							{ID: "cooc-go", Name: "Go", Type: "Language"},
+							{ID: "cooc-rust", Name: "Rust", Type: "Language"},
+						},
+					}
+				}
+				weight := func(from, to string) float64 {
+					edge, err := graphStore.GetEdge(ctx, graphEdgeID(RelatedTo, from, to))
+					if err != nil {
+						return 0
+					}
+					So(edge.Properties["cooccurrences"], ShouldEqual, int(edge.Weight))
+					return edge.Weight
+				}
+				
+				So(mvs.StoreChunk(ctx, pair("cooc-a")), ShouldBeNil)
+				So(mvs.StoreChunk(ctx, pair("cooc-b")), ShouldBeNil)
+				So(weight("cooc-go", "cooc-rust"), ShouldEqual, 2.0)
+				So(weight("cooc-rust", "cooc-go"), ShouldEqual, 2.0)
+				
+				// A new version of a chunk takes the place of the old one's co-occurrence
+				So(mvs.ReplaceChunks(ctx, []string{"cooc-a"}, []*Chunk{pair("cooc-a-v2")}), ShouldBeNil)
+				So(weight("cooc-go", "cooc-rust"), ShouldEqual, 2.0)
+				So(weight("cooc-rust", "cooc-go"), ShouldEqual, 2.0)
+				
+				So(mvs.DeleteChunk(ctx, "cooc-b"), ShouldBeNil)
+				So(weight("cooc-go", "cooc-rust"), ShouldEqual, 1.0)
+				So(weight("cooc-rust", "cooc-go"), ShouldEqual, 1.0)
+				
+				So(mvs.DeleteChunk(ctx, "cooc-a-v2"), ShouldBeNil)
+				_, err := graphStore.GetEdge(ctx, graphEdgeID(RelatedTo, "cooc-go", "cooc-rust"))
+				So(err, ShouldNotBeNil)
+				_, err = graphStore.GetEdge(ctx, graphEdgeID(RelatedTo, "cooc-rust", "cooc-go"))
+				So(err, ShouldNotBeNil)
+			})
 		})
 		
 		Convey("Statistics collection", func() {
@@ -481,4 +569,23 @@ func BenchmarkMultiViewStorage(b *testing.B) {
 			mvs.GetStats(ctx)
 		}
 	})
-}
\ No newline at end of file
+}
+// failingGraphStore fails to create one node, to interrupt a write part way through
+type failingGraphStore struct {
+	GraphStore
+	failOn string
+}
+
+func (f *failingGraphStore) CreateNode(ctx context.Context, node *Node) error {
+	if node.ID == f.failOn {
+		return fmt.Errorf("injected failure creating %s", node.ID)
+	}
+	return f.GraphStore.CreateNode(ctx, node)
+}
+
+func (f *failingGraphStore) UpdateNode(ctx context.Context, node *Node) error {
+	if node.ID == f.failOn {
+		return fmt.Errorf("injected failure updating %s", node.ID)
+	}
+	return f.GraphStore.UpdateNode(ctx, node)
+}
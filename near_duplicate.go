@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"sort"
+	"strings"
+)
+
+// DuplicatePolicy decides what a write does with a chunk whose content nearly duplicates a
+// chunk already in memory
+type DuplicatePolicy string
+
+// Supported duplicate policies
+const (
+	DuplicatePolicyOff    DuplicatePolicy = ""       // Store every chunk without looking for duplicates
+	DuplicatePolicyReject DuplicatePolicy = "reject" // Drop the chunk and report the one it duplicates
+	DuplicatePolicyMerge  DuplicatePolicy = "merge"  // Add the write's evidence and provenance to the existing chunk
+	DuplicatePolicyLink   DuplicatePolicy = "link"   // Store the chunk and link it to the one it duplicates
+)
+
+// ParseDuplicatePolicy reads a configured duplicate policy; empty or "off" disables detection
+func ParseDuplicatePolicy(name string) (DuplicatePolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "off", "none":
+		return DuplicatePolicyOff, nil
+	case "reject":
+		return DuplicatePolicyReject, nil
+	case "merge":
+		return DuplicatePolicyMerge, nil
+	case "link", "store_and_link":
+		return DuplicatePolicyLink, nil
+	}
+	return "", fmt.Errorf("unsupported duplicate policy: %s", name)
+}
+
+// defaultDuplicateDistance is how many of the 64 fingerprint bits two chunks may differ in
+// and still count as near-duplicates
+const defaultDuplicateDistance = 6
+
+// maxDuplicateDistance bounds the configurable distance; beyond it unrelated text starts to match
+const maxDuplicateDistance = 16
+
+// NearDuplicate is a stored chunk whose fingerprint is within the configured distance of new content
+type NearDuplicate struct {
+	ChunkID  string // The stored chunk
+	Distance int    // Differing fingerprint bits, 0 for identical analyzed content
+}
+
+// Similarity maps the fingerprint distance onto [0, 1], 1 meaning identical fingerprints
+func (d NearDuplicate) Similarity() float64 {
+	return 1 - float64(d.Distance)/64
+}
+
+// simHash fingerprints text as a 64-bit SimHash over its analyzed terms and adjacent term
+// pairs, so texts that share most of their wording get fingerprints differing in few bits.
+// Text with no terms has no fingerprint.
+func simHash(analyzer Analyzer, text string) (uint64, bool) {
+	terms := analyzer.Analyze(text)
+	if len(terms) == 0 {
+		return 0, false
+	}
+
+	var weights [64]int
+	add := func(feature string) {
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<uint(bit)) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+	for i, term := range terms {
+		add(term)
+		if i > 0 {
+			add(terms[i-1] + " " + term)
+		}
+	}
+
+	var fingerprint uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			fingerprint |= 1 << uint(bit)
+		}
+	}
+	return fingerprint, true
+}
+
+// fingerprintIndex finds stored chunks whose SimHash fingerprints lie within a Hamming
+// distance of a query fingerprint. Fingerprints are split into distance+1 bands and every
+// chunk is bucketed by each band's bits: two fingerprints within the distance differ in at
+// most distance bits, so at least one band matches exactly and only the chunks sharing a
+// bucket with the query need comparing.
+type fingerprintIndex struct {
+	distance     int
+	fingerprints map[string]uint64
+	bands        []map[uint64][]string
+}
+
+// duplicateDistance applies the default to an unset distance and caps it at the maximum
+func duplicateDistance(distance int) int {
+	if distance <= 0 {
+		return defaultDuplicateDistance
+	}
+	if distance > maxDuplicateDistance {
+		return maxDuplicateDistance
+	}
+	return distance
+}
+
+// newFingerprintIndex creates an index matching fingerprints within distance bits
+func newFingerprintIndex(distance int) *fingerprintIndex {
+	distance = duplicateDistance(distance)
+	bands := make([]map[uint64][]string, distance+1)
+	for i := range bands {
+		bands[i] = make(map[uint64][]string)
+	}
+	return &fingerprintIndex{
+		distance:     distance,
+		fingerprints: make(map[string]uint64),
+		bands:        bands,
+	}
+}
+
+// band returns the bits of a fingerprint that fall in band i
+func (fi *fingerprintIndex) band(fingerprint uint64, i int) uint64 {
+	start := i * 64 / len(fi.bands)
+	end := (i + 1) * 64 / len(fi.bands)
+	return (fingerprint >> uint(start)) & (1<<uint(end-start) - 1)
+}
+
+// add indexes a chunk's fingerprint, replacing any it had before
+func (fi *fingerprintIndex) add(id string, fingerprint uint64) {
+	fi.remove(id)
+	fi.fingerprints[id] = fingerprint
+	for i, buckets := range fi.bands {
+		key := fi.band(fingerprint, i)
+		buckets[key] = append(buckets[key], id)
+	}
+}
+
+// remove drops a chunk from the index
+func (fi *fingerprintIndex) remove(id string) {
+	fingerprint, exists := fi.fingerprints[id]
+	if !exists {
+		return
+	}
+	delete(fi.fingerprints, id)
+
+	for i, buckets := range fi.bands {
+		key := fi.band(fingerprint, i)
+		ids := buckets[key]
+		for j, candidate := range ids {
+			if candidate == id {
+				ids = append(ids[:j], ids[j+1:]...)
+				break
+			}
+		}
+		if len(ids) == 0 {
+			delete(buckets, key)
+		} else {
+			buckets[key] = ids
+		}
+	}
+}
+
+// matches returns the chunks within the distance of a fingerprint, closest first and then by ID
+func (fi *fingerprintIndex) matches(fingerprint uint64) []NearDuplicate {
+	seen := make(map[string]bool)
+	var matches []NearDuplicate
+	for i, buckets := range fi.bands {
+		for _, id := range buckets[fi.band(fingerprint, i)] {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			if distance := bits.OnesCount64(fi.fingerprints[id] ^ fingerprint); distance <= fi.distance {
+				matches = append(matches, NearDuplicate{ChunkID: id, Distance: distance})
+			}
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Distance != matches[j].Distance {
+			return matches[i].Distance < matches[j].Distance
+		}
+		return matches[i].ChunkID < matches[j].ChunkID
+	})
+	return matches
+}
+
+// size returns how many chunks are indexed
+func (fi *fingerprintIndex) size() int {
+	return len(fi.fingerprints)
+}
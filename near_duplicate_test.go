@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseDuplicatePolicy(t *testing.T) {
+	Convey("Given configured duplicate policies", t, func() {
+		Convey("Then known names and aliases should parse", func() {
+			for name, want := range map[string]DuplicatePolicy{
+				"":               DuplicatePolicyOff,
+				"off":            DuplicatePolicyOff,
+				"Reject":         DuplicatePolicyReject,
+				"merge":          DuplicatePolicyMerge,
+				"link":           DuplicatePolicyLink,
+				"store_and_link": DuplicatePolicyLink,
+			} {
+				policy, err := ParseDuplicatePolicy(name)
+				So(err, ShouldBeNil)
+				So(policy, ShouldEqual, want)
+			}
+		})
+
+		Convey("Then unknown names should be rejected", func() {
+			_, err := ParseDuplicatePolicy("overwrite")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestSimHash(t *testing.T) {
+	Convey("Given the English analyzer", t, func() {
+		analyzer := AnalyzerForLanguage("en")
+		base := "The quick brown fox jumps over the lazy dog near the river bank on a sunny afternoon in July."
+
+		Convey("Then wording the analyzer folds away should not change the fingerprint", func() {
+			a, ok := simHash(analyzer, base)
+			So(ok, ShouldBeTrue)
+			b, _ := simHash(analyzer, "The QUICK brown fox jumped over the lazy dog, near the river bank on a sunny afternoon in July!")
+			So(a, ShouldEqual, b)
+		})
+
+		Convey("Then unrelated text should land far away", func() {
+			a, _ := simHash(analyzer, base)
+			b, _ := simHash(analyzer, "Databases answer queries using indexes built over columns, with transactions keeping data consistent.")
+			index := newFingerprintIndex(0)
+			index.add("base", a)
+			So(index.matches(b), ShouldBeEmpty)
+		})
+
+		Convey("Then text without terms should have no fingerprint", func() {
+			_, ok := simHash(analyzer, "the and of")
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
+
+func TestFingerprintIndex(t *testing.T) {
+	Convey("Given a fingerprint index matching within 3 bits", t, func() {
+		index := newFingerprintIndex(3)
+		So(len(index.bands), ShouldEqual, 4)
+
+		fingerprint := uint64(0x0123456789abcdef)
+		index.add("exact", fingerprint)
+		// Differing bits spread over several bands still leave one band intact
+		index.add("near", fingerprint^(1|1<<20|1<<40))
+		index.add("far", fingerprint^(1|1<<17|1<<33|1<<49))
+
+		Convey("Then fingerprints within the distance should match, closest first", func() {
+			matches := index.matches(fingerprint)
+			So(matches, ShouldResemble, []NearDuplicate{
+				{ChunkID: "exact", Distance: 0},
+				{ChunkID: "near", Distance: 3},
+			})
+			So(matches[1].Similarity(), ShouldAlmostEqual, 1-3.0/64)
+		})
+
+		Convey("Then re-adding a chunk should replace its fingerprint", func() {
+			index.add("exact", ^fingerprint)
+			So(index.size(), ShouldEqual, 3)
+			So(index.matches(fingerprint), ShouldHaveLength, 1)
+		})
+
+		Convey("Then removed chunks should no longer match", func() {
+			index.remove("exact")
+			index.remove("near")
+			index.remove("missing")
+			So(index.size(), ShouldEqual, 1)
+			So(index.matches(fingerprint), ShouldBeEmpty)
+			for _, buckets := range index.bands {
+				So(len(buckets), ShouldEqual, 1)
+			}
+		})
+	})
+
+	Convey("Given out of range distances", t, func() {
+		So(newFingerprintIndex(0).distance, ShouldEqual, defaultDuplicateDistance)
+		So(newFingerprintIndex(100).distance, ShouldEqual, maxDuplicateDistance)
+	})
+}
+
+func TestMultiViewStorageNearDuplicates(t *testing.T) {
+	Convey("Given storage holding documents written before the fingerprint index existed", t, func() {
+		ctx := context.Background()
+		searchIndex := NewMockSearchIndex()
+		storage := NewMultiViewStorage(NewMockVectorStore(), NewMockGraphStore(), searchIndex, nil)
+
+		content := "Machine learning systems learn statistical patterns from large datasets and use them to make predictions."
+		So(searchIndex.Index(ctx, IndexDocument{ID: "old", Content: content, Metadata: map[string]interface{}{}}), ShouldBeNil)
+
+		chunk := NewChunk("new", content, "test")
+
+		Convey("Then the index should be built from the search index", func() {
+			duplicates, err := storage.FindNearDuplicates(ctx, chunk, 0)
+			So(err, ShouldBeNil)
+			So(duplicates, ShouldResemble, []NearDuplicate{{ChunkID: "old", Distance: 0}})
+		})
+
+		Convey("Then a chunk should not duplicate itself", func() {
+			duplicates, err := storage.FindNearDuplicates(ctx, NewChunk("old", content, "test"), 0)
+			So(err, ShouldBeNil)
+			So(duplicates, ShouldBeEmpty)
+		})
+
+		Convey("Then stored chunks should be indexed as they are written", func() {
+			_, err := storage.FindNearDuplicates(ctx, chunk, 0)
+			So(err, ShouldBeNil)
+			So(storage.StoreChunk(ctx, chunk), ShouldBeNil)
+
+			duplicates, err := storage.FindNearDuplicates(ctx, NewChunk("newer", content, "test"), 0)
+			So(err, ShouldBeNil)
+			So(duplicates, ShouldHaveLength, 2)
+		})
+
+		Convey("Then chunks deleted outside the storage should drop out", func() {
+			_, err := storage.FindNearDuplicates(ctx, chunk, 0)
+			So(err, ShouldBeNil)
+			So(searchIndex.Delete(ctx, "old"), ShouldBeNil)
+
+			duplicates, err := storage.FindNearDuplicates(ctx, chunk, 0)
+			So(err, ShouldBeNil)
+			So(duplicates, ShouldBeEmpty)
+			So(storage.fingerprints.size(), ShouldEqual, 0)
+		})
+	})
+}
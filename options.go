@@ -16,6 +16,8 @@ type RecallOptions struct {
 	SortOrder     string                 `json:"sort_order"`     // "asc", "desc"
 	ExpandQuery   bool                   `json:"expand_query"`   // Whether to expand query with synonyms
 	UseCache      bool                   `json:"use_cache"`      // Whether to use cached results
+	Language      string                 `json:"language,omitempty"` // Query language for keyword analysis
+	Facets        []FacetRequest         `json:"facets,omitempty"`   // Counted over the fused candidates
 }
 
 // WriteMetadata represents metadata for memory write operations
@@ -29,7 +31,9 @@ type WriteMetadata struct {
 	Language        string                 `json:"language,omitempty"`
 	ContentType     string                 `json:"content_type,omitempty"`
 	Version         string                 `json:"version,omitempty"`
-	ExternalID      string                 `json:"external_id,omitempty"`
+	ExternalID      string                 `json:"external_id,omitempty"`     // Writes sharing it replace each other's chunks
+	IdempotencyKey  string                 `json:"idempotency_key,omitempty"` // Retries sharing it return the first write's result
+	Reingest        bool                   `json:"reingest,omitempty"`        // The content is the full new text of its source
 	Metadata        map[string]interface{} `json:"metadata,omitempty"`
 }
 
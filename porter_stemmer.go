@@ -0,0 +1,224 @@
+package main
+
+// porterStem reduces a lowercase English word to its stem with the Porter (1980) algorithm.
+// Words of two letters or fewer and words with characters outside a-z are returned unchanged.
+func porterStem(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+
+	w := []byte(word)
+	w = porterStep1a(w)
+	w = porterStep1b(w)
+	w = porterStep1c(w)
+	w = porterStep2(w)
+	w = porterStep3(w)
+	w = porterStep4(w)
+	w = porterStep5(w)
+	return string(w)
+}
+
+// porterRule maps a suffix to the text that replaces it
+type porterRule struct {
+	suffix      string
+	replacement string
+}
+
+var (
+	porterStep2Rules = []porterRule{
+		{"ational", "ate"}, {"tional", "tion"}, {"enci", "ence"}, {"anci", "ance"},
+		{"izer", "ize"}, {"abli", "able"}, {"alli", "al"}, {"entli", "ent"},
+		{"eli", "e"}, {"ousli", "ous"}, {"ization", "ize"}, {"ation", "ate"},
+		{"ator", "ate"}, {"alism", "al"}, {"iveness", "ive"}, {"fulness", "ful"},
+		{"ousness", "ous"}, {"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"},
+	}
+	porterStep3Rules = []porterRule{
+		{"icate", "ic"}, {"ative", ""}, {"alize", "al"}, {"iciti", "ic"},
+		{"ical", "ic"}, {"ful", ""}, {"ness", ""},
+	}
+	// Longer suffixes come first so only the longest match is considered
+	porterStep4Suffixes = []string{
+		"ement", "ment", "ent", "ance", "ence", "able", "ible", "ant", "ion",
+		"al", "er", "ic", "ou", "ism", "ate", "iti", "ous", "ive", "ize",
+	}
+)
+
+// porterIsConsonant reports whether w[i] is a consonant; y is a consonant after a vowel or at the start
+func porterIsConsonant(w []byte, i int) bool {
+	switch w[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !porterIsConsonant(w, i-1)
+	}
+	return true
+}
+
+// porterMeasure counts the vowel-consonant sequences in w, the m of [C](VC)^m[V]
+func porterMeasure(w []byte) int {
+	m := 0
+	i := 0
+	for i < len(w) && porterIsConsonant(w, i) {
+		i++
+	}
+	for i < len(w) {
+		for i < len(w) && !porterIsConsonant(w, i) {
+			i++
+		}
+		if i >= len(w) {
+			break
+		}
+		for i < len(w) && porterIsConsonant(w, i) {
+			i++
+		}
+		m++
+	}
+	return m
+}
+
+// porterHasVowel reports whether w contains a vowel
+func porterHasVowel(w []byte) bool {
+	for i := range w {
+		if !porterIsConsonant(w, i) {
+			return true
+		}
+	}
+	return false
+}
+
+// porterEndsDoubleConsonant reports whether w ends in two identical consonants
+func porterEndsDoubleConsonant(w []byte) bool {
+	n := len(w)
+	return n >= 2 && w[n-1] == w[n-2] && porterIsConsonant(w, n-1)
+}
+
+// porterEndsCVC reports whether w ends consonant-vowel-consonant with the last not w, x or y
+func porterEndsCVC(w []byte) bool {
+	n := len(w)
+	if n < 3 || !porterIsConsonant(w, n-3) || porterIsConsonant(w, n-2) || !porterIsConsonant(w, n-1) {
+		return false
+	}
+	last := w[n-1]
+	return last != 'w' && last != 'x' && last != 'y'
+}
+
+// porterHasSuffix reports whether w ends with suffix
+func porterHasSuffix(w []byte, suffix string) bool {
+	return len(w) >= len(suffix) && string(w[len(w)-len(suffix):]) == suffix
+}
+
+// porterApplyRules replaces the first matching suffix when its stem measure exceeds minMeasure
+func porterApplyRules(w []byte, rules []porterRule, minMeasure int) []byte {
+	for _, rule := range rules {
+		if porterHasSuffix(w, rule.suffix) {
+			stem := w[:len(w)-len(rule.suffix)]
+			if porterMeasure(stem) > minMeasure {
+				return append(stem, rule.replacement...)
+			}
+			return w
+		}
+	}
+	return w
+}
+
+// porterStep1a removes plurals: sses -> ss, ies -> i, s -> ""
+func porterStep1a(w []byte) []byte {
+	switch {
+	case porterHasSuffix(w, "sses"), porterHasSuffix(w, "ies"):
+		return w[:len(w)-2]
+	case porterHasSuffix(w, "ss"):
+		return w
+	case porterHasSuffix(w, "s"):
+		return w[:len(w)-1]
+	}
+	return w
+}
+
+// porterStep1b removes -eed, -ed and -ing and tidies the stem left behind
+func porterStep1b(w []byte) []byte {
+	if porterHasSuffix(w, "eed") {
+		if porterMeasure(w[:len(w)-3]) > 0 {
+			return w[:len(w)-1]
+		}
+		return w
+	}
+
+	var stem []byte
+	switch {
+	case porterHasSuffix(w, "ed") && porterHasVowel(w[:len(w)-2]):
+		stem = w[:len(w)-2]
+	case porterHasSuffix(w, "ing") && porterHasVowel(w[:len(w)-3]):
+		stem = w[:len(w)-3]
+	default:
+		return w
+	}
+
+	switch {
+	case porterHasSuffix(stem, "at"), porterHasSuffix(stem, "bl"), porterHasSuffix(stem, "iz"):
+		return append(stem, 'e')
+	case porterEndsDoubleConsonant(stem):
+		last := stem[len(stem)-1]
+		if last != 'l' && last != 's' && last != 'z' {
+			return stem[:len(stem)-1]
+		}
+	case porterMeasure(stem) == 1 && porterEndsCVC(stem):
+		return append(stem, 'e')
+	}
+	return stem
+}
+
+// porterStep1c turns a final y into i when the stem has a vowel
+func porterStep1c(w []byte) []byte {
+	if porterHasSuffix(w, "y") && porterHasVowel(w[:len(w)-1]) {
+		w[len(w)-1] = 'i'
+	}
+	return w
+}
+
+// porterStep2 maps double suffixes such as -ization to single ones
+func porterStep2(w []byte) []byte {
+	return porterApplyRules(w, porterStep2Rules, 0)
+}
+
+// porterStep3 handles -ic-, -full, -ness and similar
+func porterStep3(w []byte) []byte {
+	return porterApplyRules(w, porterStep3Rules, 0)
+}
+
+// porterStep4 removes the remaining suffixes from stems with a measure above one
+func porterStep4(w []byte) []byte {
+	for _, suffix := range porterStep4Suffixes {
+		if !porterHasSuffix(w, suffix) {
+			continue
+		}
+		stem := w[:len(w)-len(suffix)]
+		if porterMeasure(stem) <= 1 {
+			return w
+		}
+		if suffix == "ion" && !porterHasSuffix(stem, "s") && !porterHasSuffix(stem, "t") {
+			return w
+		}
+		return stem
+	}
+	return w
+}
+
+// porterStep5 removes a final -e and reduces a final -ll
+func porterStep5(w []byte) []byte {
+	if porterHasSuffix(w, "e") {
+		stem := w[:len(w)-1]
+		m := porterMeasure(stem)
+		if m > 1 || (m == 1 && !porterEndsCVC(stem)) {
+			w = stem
+		}
+	}
+	if porterMeasure(w) > 1 && porterEndsDoubleConsonant(w) && porterHasSuffix(w, "l") {
+		w = w[:len(w)-1]
+	}
+	return w
+}
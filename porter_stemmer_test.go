@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPorterStem(t *testing.T) {
+	Convey("Given the Porter stemmer", t, func() {
+		Convey("Then it should reduce words from the reference vocabulary", func() {
+			cases := map[string]string{
+				"caresses":       "caress",
+				"ponies":         "poni",
+				"cats":           "cat",
+				"feed":           "feed",
+				"agreed":         "agre",
+				"plastered":      "plaster",
+				"motoring":       "motor",
+				"sing":           "sing",
+				"conflated":      "conflat",
+				"hopping":        "hop",
+				"filing":         "file",
+				"happy":          "happi",
+				"relational":     "relat",
+				"generalization": "gener",
+				"hopefulness":    "hope",
+				"adjustment":     "adjust",
+				"controlling":    "control",
+				"probate":        "probat",
+				"rate":           "rate",
+			}
+			for word, stem := range cases {
+				So(porterStem(word), ShouldEqual, stem)
+			}
+		})
+
+		Convey("Then short or non-ASCII words should be left alone", func() {
+			So(porterStem("is"), ShouldEqual, "is")
+			So(porterStem("naïve"), ShouldEqual, "naïve")
+			So(porterStem("v2"), ShouldEqual, "v2")
+		})
+	})
+}
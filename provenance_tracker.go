@@ -82,10 +82,30 @@ func NewProvenanceTracker() *ProvenanceTracker {
 
 // Track creates a new provenance record for a memory
 func (pt *ProvenanceTracker) Track(memoryID string, metadata WriteMetadata) (string, error) {
+	return pt.TrackVersion(memoryID, metadata, 1, nil)
+}
+
+// TrackVersion creates the provenance record for a version of a memory written to replace
+// the versions whose provenance records are given as parents
+func (pt *ProvenanceTracker) TrackVersion(memoryID string, metadata WriteMetadata, version int, parentVersions []string) (string, error) {
 	pt.mutex.Lock()
 	defer pt.mutex.Unlock()
 
+	if version < 1 {
+		version = 1
+	}
 	provenanceID := pt.generateProvenanceID(memoryID, metadata)
+	if version > 1 {
+		// A new version can reuse its memory ID within the same second; keep its record apart
+		hash := sha256.Sum256([]byte(fmt.Sprintf("%s_v%d", provenanceID, version)))
+		provenanceID = fmt.Sprintf("prov_%x", hash[:8])
+	}
+
+	// Limit version history
+	parents := append([]string{}, parentVersions...)
+	if pt.config.MaxVersionHistory > 0 && len(parents) > pt.config.MaxVersionHistory {
+		parents = parents[len(parents)-pt.config.MaxVersionHistory:]
+	}
 	
 	record := &ProvenanceRecord{
 		ID:             provenanceID,
@@ -95,8 +115,8 @@ func (pt *ProvenanceTracker) Track(memoryID string, metadata WriteMetadata) (str
 		CreatedBy:      metadata.UserID,
 		LastModified:   metadata.Timestamp,
 		ModifiedBy:     metadata.UserID,
-		Version:        1,
-		ParentVersions: []string{},
+		Version:        version,
+		ParentVersions: parents,
 		Transformations: []Transformation{},
 		Metadata: map[string]interface{}{
 			"tags":       metadata.Tags,
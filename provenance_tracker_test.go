@@ -47,6 +47,29 @@ func TestProvenanceTracker(t *testing.T) {
 			})
 		})
 
+		Convey("When tracking a new version of a memory", func() {
+			memoryID := "memory_123"
+			metadata := WriteMetadata{Source: "test_source", Timestamp: time.Now()}
+
+			first, err := tracker.Track(memoryID, metadata)
+			So(err, ShouldBeNil)
+			second, err := tracker.TrackVersion(memoryID, metadata, 2, []string{first})
+
+			Convey("Then it should record the version and its parents under a new ID", func() {
+				So(err, ShouldBeNil)
+				So(second, ShouldNotEqual, first)
+
+				record, err := tracker.GetProvenance(second)
+				So(err, ShouldBeNil)
+				So(record.Version, ShouldEqual, 2)
+				So(record.ParentVersions, ShouldResemble, []string{first})
+
+				lineage, err := tracker.GetMemoryLineage(memoryID)
+				So(err, ShouldBeNil)
+				So(len(lineage), ShouldEqual, 2)
+			})
+		})
+
 		Convey("When getting non-existent provenance", func() {
 			record, err := tracker.GetProvenance("non_existent")
 
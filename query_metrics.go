@@ -0,0 +1,63 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// QueryMetrics records recall latency and error counts since startup
+type QueryMetrics struct {
+	mu        sync.RWMutex
+	queries   int64
+	errors    int64
+	totalTime time.Duration
+	startTime time.Time
+}
+
+// QueryMetricsSnapshot is a point-in-time copy of the recorded metrics
+type QueryMetricsSnapshot struct {
+	Queries        int64         `json:"queries"`
+	Errors         int64         `json:"errors"`
+	AvgQueryTime   time.Duration `json:"avg_query_time"`
+	RequestsPerSec float64       `json:"requests_per_second"`
+	ErrorRate      float64       `json:"error_rate"`
+}
+
+// NewQueryMetrics creates an empty QueryMetrics starting now
+func NewQueryMetrics() *QueryMetrics {
+	return &QueryMetrics{
+		startTime: time.Now(),
+	}
+}
+
+// Record adds one query with its duration and outcome
+func (qm *QueryMetrics) Record(duration time.Duration, err error) {
+	qm.mu.Lock()
+	defer qm.mu.Unlock()
+
+	qm.queries++
+	qm.totalTime += duration
+	if err != nil {
+		qm.errors++
+	}
+}
+
+// Snapshot returns the current averages and rates
+func (qm *QueryMetrics) Snapshot() QueryMetricsSnapshot {
+	qm.mu.RLock()
+	defer qm.mu.RUnlock()
+
+	snapshot := QueryMetricsSnapshot{
+		Queries: qm.queries,
+		Errors:  qm.errors,
+	}
+	if qm.queries > 0 {
+		snapshot.AvgQueryTime = qm.totalTime / time.Duration(qm.queries)
+		snapshot.ErrorRate = float64(qm.errors) / float64(qm.queries)
+	}
+	if elapsed := time.Since(qm.startTime).Seconds(); elapsed > 0 {
+		snapshot.RequestsPerSec = float64(qm.queries) / elapsed
+	}
+
+	return snapshot
+}
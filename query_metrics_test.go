@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestQueryMetrics(t *testing.T) {
+	Convey("Given QueryMetrics with recorded queries", t, func() {
+		metrics := NewQueryMetrics()
+		metrics.Record(10*time.Millisecond, nil)
+		metrics.Record(20*time.Millisecond, nil)
+		metrics.Record(30*time.Millisecond, context.DeadlineExceeded)
+
+		snapshot := metrics.Snapshot()
+
+		Convey("Then averages and error rate should be computed", func() {
+			So(snapshot.Queries, ShouldEqual, 3)
+			So(snapshot.Errors, ShouldEqual, 1)
+			So(snapshot.AvgQueryTime, ShouldEqual, 20*time.Millisecond)
+			So(snapshot.ErrorRate, ShouldAlmostEqual, 1.0/3.0, 0.001)
+			So(snapshot.RequestsPerSec, ShouldBeGreaterThan, 0)
+		})
+	})
+}
@@ -5,13 +5,16 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode"
 )
 
 // QueryProcessor handles query parsing, expansion, and processing
 type QueryProcessor struct {
 	vectorSearcher  *VectorSearcher
 	keywordSearcher *KeywordSearcher
+	graphSearcher   *GraphSearcher
 	queryExpander   *QueryExpander
+	embedder        Embedder
 	config          *QueryProcessorConfig
 }
 
@@ -21,6 +24,7 @@ type QueryProcessorConfig struct {
 	DefaultTimeout  time.Duration `json:"default_timeout"`
 	EnableExpansion bool          `json:"enable_expansion"`
 	MinQueryLength  int           `json:"min_query_length"`
+	FilterFields    []string      `json:"filter_fields,omitempty"` // Keys a key:value query token may filter on; defaults to defaultFilterFields
 }
 
 // ProcessedQuery represents a parsed and expanded query
@@ -30,6 +34,7 @@ type ProcessedQuery struct {
 	Expanded    []string          `json:"expanded"`
 	Entities    []string          `json:"entities"`
 	Keywords    []string          `json:"keywords"`
+	Filters     map[string]interface{} `json:"filters,omitempty"` // Constraints every retrieval view applies
 	Metadata    map[string]interface{} `json:"metadata"`
 }
 
@@ -39,6 +44,7 @@ type ParsedQuery struct {
 	Phrases     []string          `json:"phrases"`
 	Filters     map[string]string `json:"filters"`
 	TimeRange   *TimeRange        `json:"time_range,omitempty"`
+	TimeBoost   *TimeRange        `json:"time_boost,omitempty"` // Period named by a bare year: preferred, not filtered on
 	QueryType   QueryType         `json:"query_type"`
 }
 
@@ -48,6 +54,17 @@ type TimeRange struct {
 	End   *time.Time `json:"end,omitempty"`
 }
 
+// Contains reports whether t falls inside the range; a nil bound is open
+func (tr TimeRange) Contains(t time.Time) bool {
+	if tr.Start != nil && t.Before(*tr.Start) {
+		return false
+	}
+	if tr.End != nil && t.After(*tr.End) {
+		return false
+	}
+	return true
+}
+
 // QueryType indicates the type of query
 type QueryType string
 
@@ -103,6 +120,7 @@ func (qp *QueryProcessor) Process(ctx context.Context, query string, options *Re
 
 	// Extract entities and keywords
 	entities := qp.extractEntities(parsed)
+	entities = qp.deduplicateStrings(append(entities, qp.extractCapitalizedTerms(query)...))
 	keywords := qp.extractKeywords(parsed)
 
 	processedQuery := &ProcessedQuery{
@@ -111,6 +129,7 @@ func (qp *QueryProcessor) Process(ctx context.Context, query string, options *Re
 		Expanded: expanded,
 		Entities: entities,
 		Keywords: keywords,
+		Filters:  qp.buildFilters(parsed, options),
 		Metadata: make(map[string]interface{}),
 	}
 
@@ -122,6 +141,69 @@ func (qp *QueryProcessor) Process(ctx context.Context, query string, options *Re
 	return processedQuery, nil
 }
 
+// timeBoost returns the period the query prefers results from without filtering on it
+func (pq *ProcessedQuery) timeBoost() *TimeRange {
+	if pq.Parsed == nil {
+		return nil
+	}
+	return pq.Parsed.TimeBoost
+}
+
+// buildFilters merges filters written in the query and its time range with the caller's filters,
+// which take precedence; the time range constrains the "timestamp" metadata key
+func (qp *QueryProcessor) buildFilters(parsed *ParsedQuery, options *RecallOptions) map[string]interface{} {
+	filters := make(map[string]interface{})
+	for key, value := range parsed.Filters {
+		filters[key] = value
+	}
+	if parsed.TimeRange != nil {
+		filters["timestamp"] = parsed.TimeRange
+	}
+	if options != nil {
+		for key, value := range options.Filters {
+			filters[key] = value
+		}
+	}
+	if len(filters) == 0 {
+		return nil
+	}
+	return filters
+}
+
+// SetEmbedder sets the embedder used to embed queries for vector search
+func (qp *QueryProcessor) SetEmbedder(embedder Embedder) {
+	qp.embedder = embedder
+}
+
+// SetVectorSearcher replaces the vector searcher used for the vector view
+func (qp *QueryProcessor) SetVectorSearcher(searcher *VectorSearcher) {
+	qp.vectorSearcher = searcher
+}
+
+// SetKeywordSearcher replaces the keyword searcher used for the keyword view
+func (qp *QueryProcessor) SetKeywordSearcher(searcher *KeywordSearcher) {
+	qp.keywordSearcher = searcher
+}
+
+// SetGraphSearcher sets the graph searcher used for the graph view
+func (qp *QueryProcessor) SetGraphSearcher(searcher *GraphSearcher) {
+	qp.graphSearcher = searcher
+}
+
+// EmbedQuery converts a query string into an embedding for vector search
+func (qp *QueryProcessor) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
+	if qp.embedder == nil {
+		return nil, fmt.Errorf("no embedder configured")
+	}
+
+	embedding, err := embedSingle(ctx, qp.embedder, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	return embedding, nil
+}
+
 // Parse extracts structured components from a query string
 func (qp *QueryProcessor) Parse(query string) (*ParsedQuery, error) {
 	if query == "" {
@@ -149,17 +231,21 @@ func (qp *QueryProcessor) Parse(query string) (*ParsedQuery, error) {
 	// Remove phrases from query to extract individual terms
 	queryWithoutPhrases := qp.removePhrases(query)
 
-	// Extract individual terms
-	terms := strings.Fields(queryWithoutPhrases)
-	parsed.Terms = qp.filterTerms(terms)
-
-	// Extract filters (key:value pairs)
-	filters := qp.extractFilters(query)
+	// Extract filters (key:value pairs), keeping the case of their values
+	filters := qp.extractFilters(originalQuery)
 	parsed.Filters = filters
 
+	// Extract individual terms, leaving out filter tokens
+	var terms []string
+	for _, term := range strings.Fields(queryWithoutPhrases) {
+		if _, _, isFilter := qp.filterToken(term); !isFilter {
+			terms = append(terms, term)
+		}
+	}
+	parsed.Terms = qp.filterTerms(terms)
+
 	// Extract time range if present
-	timeRange := qp.extractTimeRange(query)
-	parsed.TimeRange = timeRange
+	parsed.TimeRange, parsed.TimeBoost = qp.extractTimeRange(query)
 
 	// Determine query type using original query
 	parsed.QueryType = qp.determineQueryType(originalQuery, parsed)
@@ -240,27 +326,65 @@ func (qp *QueryProcessor) removePhrases(query string) string {
 	return result.String()
 }
 
-// extractFilters finds key:value filter pairs
+// extractFilters finds key:value filter pairs on known metadata fields; other key:value
+// tokens such as localhost:8080 or std::vector stay search terms
 func (qp *QueryProcessor) extractFilters(query string) map[string]string {
 	filters := make(map[string]string)
 	
 	// Simple regex-like extraction for key:value pairs
 	words := strings.Fields(query)
 	for _, word := range words {
-		if strings.Contains(word, ":") {
-			parts := strings.SplitN(word, ":", 2)
-			if len(parts) == 2 && parts[0] != "" && parts[1] != "" {
-				filters[parts[0]] = parts[1]
-			}
+		if key, value, ok := qp.filterToken(word); ok {
+			filters[key] = value
 		}
 	}
 
 	return filters
 }
 
-// extractTimeRange attempts to extract time-based filters
-func (qp *QueryProcessor) extractTimeRange(query string) *TimeRange {
-	// Simple implementation - could be enhanced with more sophisticated parsing
+// filterToken splits a key:value token whose key is one of the fields queries may filter on
+func (qp *QueryProcessor) filterToken(word string) (string, string, bool) {
+	key, value, ok := splitFilterToken(word)
+	if !ok {
+		return "", "", false
+	}
+	fields := qp.config.FilterFields
+	if len(fields) == 0 {
+		fields = defaultFilterFields
+	}
+	for _, field := range fields {
+		if key == field {
+			return key, value, true
+		}
+	}
+	return "", "", false
+}
+
+// splitFilterToken splits a key:value token; keys must start with a letter so times
+// such as 10:30 and URLs such as https://example.com are not taken as filters
+func splitFilterToken(word string) (string, string, bool) {
+	parts := strings.SplitN(word, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" || strings.HasPrefix(parts[1], "//") {
+		return "", "", false
+	}
+	for i, r := range parts[0] {
+		if !(unicode.IsLetter(r) || r == '_' || (i > 0 && unicode.IsDigit(r))) {
+			return "", "", false
+		}
+	}
+	return strings.ToLower(parts[0]), parts[1], true
+}
+
+// extractTimeRange attempts to extract time-based filters. A period named by a bare year is
+// returned as a boost instead, since the year may be an ordinary number.
+func (qp *QueryProcessor) extractTimeRange(query string) (*TimeRange, *TimeRange) {
+	// Absolute dates are more specific than relative keywords
+	timeRange, yearOnly := parseAbsoluteTimeRange(query, time.Local)
+	if timeRange != nil && !yearOnly {
+		return timeRange, nil
+	}
+	boost := timeRange
+
 	timeKeywords := []string{"today", "yesterday", "last week", "last month", "recent"}
 	
 	for _, keyword := range timeKeywords {
@@ -270,26 +394,26 @@ func (qp *QueryProcessor) extractTimeRange(query string) *TimeRange {
 			switch keyword {
 			case "today":
 				start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-				return &TimeRange{Start: &start, End: &now}
+				return &TimeRange{Start: &start, End: &now}, boost
 			case "yesterday":
 				yesterday := now.AddDate(0, 0, -1)
 				start := time.Date(yesterday.Year(), yesterday.Month(), yesterday.Day(), 0, 0, 0, 0, yesterday.Location())
 				end := time.Date(yesterday.Year(), yesterday.Month(), yesterday.Day(), 23, 59, 59, 0, yesterday.Location())
-				return &TimeRange{Start: &start, End: &end}
+				return &TimeRange{Start: &start, End: &end}, boost
 			case "last week":
 				weekAgo := now.AddDate(0, 0, -7)
-				return &TimeRange{Start: &weekAgo, End: &now}
+				return &TimeRange{Start: &weekAgo, End: &now}, boost
 			case "last month":
 				monthAgo := now.AddDate(0, -1, 0)
-				return &TimeRange{Start: &monthAgo, End: &now}
+				return &TimeRange{Start: &monthAgo, End: &now}, boost
 			case "recent":
 				recent := now.AddDate(0, 0, -3) // Last 3 days
-				return &TimeRange{Start: &recent, End: &now}
+				return &TimeRange{Start: &recent, End: &now}, boost
 			}
 		}
 	}
 
-	return nil
+	return nil, boost
 }
 
 // filterTerms removes stop words and short terms
@@ -362,6 +486,27 @@ func (qp *QueryProcessor) extractEntities(parsed *ParsedQuery) []string {
 	return qp.deduplicateStrings(entities)
 }
 
+// extractCapitalizedTerms finds capitalized words in the original query, since parsed terms are lowercased
+func (qp *QueryProcessor) extractCapitalizedTerms(query string) []string {
+	var terms []string
+	for _, word := range strings.Fields(query) {
+		word = strings.TrimFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if len(word) < 2 {
+			continue
+		}
+		// Month names belong to date expressions, not entities
+		if _, isMonth := monthNames[strings.ToLower(word)]; isMonth {
+			continue
+		}
+		if first := []rune(word)[0]; unicode.IsUpper(first) {
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
 // extractKeywords gets important keywords from the parsed query
 func (qp *QueryProcessor) extractKeywords(parsed *ParsedQuery) []string {
 	keywords := make([]string, 0)
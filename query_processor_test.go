@@ -43,6 +43,23 @@ func TestQueryProcessor(t *testing.T) {
 			})
 		})
 
+		Convey("When processing a query with filters and a time range", func() {
+			ctx := context.Background()
+			options := &RecallOptions{MaxResults: 10, Filters: map[string]interface{}{"source": "wiki"}}
+
+			result, err := qp.Process(ctx, "deploy notes since 2024-01-01 source:blog type:howto", options)
+
+			Convey("Then the filters should merge with the caller's taking precedence", func() {
+				So(err, ShouldBeNil)
+				So(result.Filters["source"], ShouldEqual, "wiki")
+				So(result.Filters["type"], ShouldEqual, "howto")
+				timeRange, ok := result.Filters["timestamp"].(*TimeRange)
+				So(ok, ShouldBeTrue)
+				So(timeRange.Start.Year(), ShouldEqual, 2024)
+				So(timeRange.End, ShouldBeNil)
+			})
+		})
+
 		Convey("When processing an empty query", func() {
 			ctx := context.Background()
 			query := ""
@@ -128,6 +145,51 @@ func TestQueryProcessorParse(t *testing.T) {
 			})
 		})
 
+		Convey("When parsing a query with an absolute date", func() {
+			result, err := qp.Parse("release notes in March 2025 source:changelog")
+
+			Convey("Then it should extract the month as the time range and keep filters out of the terms", func() {
+				So(err, ShouldBeNil)
+				So(result.TimeRange, ShouldNotBeNil)
+				So(result.TimeRange.Start.Month(), ShouldEqual, time.March)
+				So(result.TimeRange.End.Month(), ShouldEqual, time.March)
+				So(result.Filters["source"], ShouldEqual, "changelog")
+				So(result.Terms, ShouldNotContain, "source:changelog")
+				So(qp.extractCapitalizedTerms("release notes in March 2025"), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When parsing a query naming only a year", func() {
+			result, err := qp.Parse("release notes during 2023")
+
+			Convey("Then the year should boost results rather than filter them", func() {
+				So(err, ShouldBeNil)
+				So(result.TimeRange, ShouldBeNil)
+				So(result.TimeBoost, ShouldNotBeNil)
+				So(result.TimeBoost.Start.Year(), ShouldEqual, 2023)
+				So(result.Filters, ShouldNotContainKey, "timestamp")
+			})
+		})
+
+		Convey("When parsing a query with a four-digit count", func() {
+			result, err := qp.Parse("top 10 of 1000 records")
+
+			Convey("Then it should not be read as a date", func() {
+				So(err, ShouldBeNil)
+				So(result.TimeRange, ShouldBeNil)
+				So(result.TimeBoost, ShouldBeNil)
+			})
+		})
+
+		Convey("When parsing a query containing a URL or a clock time", func() {
+			result, err := qp.Parse("notes from https://example.com at 10:30")
+
+			Convey("Then neither should be read as a filter", func() {
+				So(err, ShouldBeNil)
+				So(result.Filters, ShouldBeEmpty)
+			})
+		})
+
 		Convey("When parsing an entity-like query", func() {
 			query := "OpenAI GPT"
 			result, err := qp.Parse(query)
@@ -238,17 +300,28 @@ func TestQueryProcessorHelperMethods(t *testing.T) {
 		})
 
 		Convey("When extracting filters", func() {
-			query := "search query type:document author:john date:2023"
+			query := "search query type:document user_id:john date:2023"
 			filters := qp.extractFilters(query)
 
 			Convey("Then it should extract all key:value pairs", func() {
 				So(len(filters), ShouldEqual, 3)
 				So(filters["type"], ShouldEqual, "document")
-				So(filters["author"], ShouldEqual, "john")
+				So(filters["user_id"], ShouldEqual, "john")
 				So(filters["date"], ShouldEqual, "2023")
 			})
 		})
 
+		Convey("When the query holds key:value tokens on unknown fields", func() {
+			parsed, err := qp.Parse("connect to localhost:8080 with std::vector note:draft type:howto")
+
+			Convey("Then only known metadata fields should become filters and the rest stay terms", func() {
+				So(err, ShouldBeNil)
+				So(parsed.Filters, ShouldResemble, map[string]string{"type": "howto"})
+				So(parsed.Terms, ShouldContain, "localhost:8080")
+				So(parsed.Terms, ShouldContain, "note:draft")
+			})
+		})
+
 		Convey("When filtering terms", func() {
 			terms := []string{"the", "machine", "learning", "a", "algorithm", "is"}
 			filtered := qp.filterTerms(terms)
@@ -278,6 +351,14 @@ func TestQueryProcessorHelperMethods(t *testing.T) {
 			})
 		})
 
+		Convey("When extracting capitalized terms from the original query", func() {
+			terms := qp.extractCapitalizedTerms("where is the Eiffel Tower, in Paris?")
+
+			Convey("Then it should keep their case and drop punctuation", func() {
+				So(terms, ShouldResemble, []string{"Eiffel", "Tower", "Paris"})
+			})
+		})
+
 		Convey("When deduplicating queries", func() {
 			queries := []string{"query1", "query2", "query1", "query3", "query2"}
 			deduplicated := qp.deduplicateQueries(queries)
@@ -96,6 +96,7 @@ func (f *RecallResponseFormatter) Format(response *RecallResponse, args RecallAr
 		Conflicts:      formattedConflicts,
 		Stats:          formattedStats,
 		SelfCritique:   formattedCritique,
+		Facets:         response.Facets,
 	}
 
 	return result, nil
@@ -477,6 +478,9 @@ func (f *RecallResponseFormatter) FormatForDisplay(result RecallResult) string {
 	// Format evidence
 	for i, evidence := range result.Evidence {
 		builder.WriteString(fmt.Sprintf("Evidence %d (Confidence: %.2f):\n", i+1, evidence.Confidence))
+		if evidence.Breadcrumb != "" {
+			builder.WriteString(fmt.Sprintf("  Section: %s\n", evidence.Breadcrumb))
+		}
 		builder.WriteString(fmt.Sprintf("  Content: %s\n", evidence.Content))
 		builder.WriteString(fmt.Sprintf("  Source: %s\n", evidence.Source))
 		builder.WriteString(fmt.Sprintf("  Why Selected: %s\n", evidence.WhySelected))
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -14,8 +16,12 @@ import (
 type RecallHandler struct {
 	queryProcessor *QueryProcessor
 	resultFuser    *ResultFuser
+	ranker         *ResultRanker
+	assembler      *EvidenceAssembler
 	validator      *RecallArgsValidator
 	formatter      *RecallResponseFormatter
+	metrics        *QueryMetrics
+	summarizer     *CommunitySummarizer
 	config         *RecallHandlerConfig
 }
 
@@ -26,6 +32,14 @@ type RecallHandlerConfig struct {
 	MaxTimeBudget        time.Duration `json:"max_time_budget"`
 	EnableSelfCritique   bool          `json:"enable_self_critique"`
 	EnableQueryExpansion bool          `json:"enable_query_expansion"`
+	MaxCommunityCards    int           `json:"max_community_cards"`
+	EnableRanking        bool          `json:"enable_ranking"`
+
+	// Stage configuration for fusion -> ranking -> assembly -> formatting; nil keeps each stage's defaults
+	Fusion     *ResultFuserConfig       `json:"fusion,omitempty"`
+	Ranking    *ResultRankerConfig      `json:"ranking,omitempty"`
+	Assembly   *EvidenceAssemblerConfig `json:"assembly,omitempty"`
+	Formatting *RecallFormatterConfig   `json:"formatting,omitempty"`
 }
 
 // NewRecallHandler creates a new RecallHandler instance
@@ -36,15 +50,11 @@ func NewRecallHandler(queryProcessor *QueryProcessor, resultFuser *ResultFuser)
 		MaxTimeBudget:        30 * time.Second,
 		EnableSelfCritique:   true,
 		EnableQueryExpansion: true,
+		MaxCommunityCards:    3,
+		EnableRanking:        true,
 	}
 
-	return &RecallHandler{
-		queryProcessor: queryProcessor,
-		resultFuser:    resultFuser,
-		validator:      NewRecallArgsValidator(),
-		formatter:      NewRecallResponseFormatter(),
-		config:         config,
-	}
+	return NewRecallHandlerWithConfig(queryProcessor, resultFuser, config)
 }
 
 // NewRecallHandlerWithConfig creates a RecallHandler with custom configuration
@@ -53,18 +63,27 @@ func NewRecallHandlerWithConfig(queryProcessor *QueryProcessor, resultFuser *Res
 		return NewRecallHandler(queryProcessor, resultFuser)
 	}
 
-	return &RecallHandler{
+	rh := &RecallHandler{
 		queryProcessor: queryProcessor,
 		resultFuser:    resultFuser,
 		validator:      NewRecallArgsValidator(),
-		formatter:      NewRecallResponseFormatter(),
-		config:         config,
+		metrics:        NewQueryMetrics(),
 	}
+	rh.UpdateConfig(config)
+
+	return rh
 }
 
-// HandleRecall processes a memory recall request
+// HandleRecall processes a memory recall request and records its latency
 func (rh *RecallHandler) HandleRecall(ctx context.Context, req *mcp.CallToolRequest, args RecallArgs) (*mcp.CallToolResult, RecallResult, error) {
 	startTime := time.Now()
+	mcpResult, result, err := rh.handleRecall(ctx, req, args, startTime)
+	rh.metrics.Record(time.Since(startTime), err)
+	return mcpResult, result, err
+}
+
+// handleRecall runs validation, retrieval and formatting for a recall request
+func (rh *RecallHandler) handleRecall(ctx context.Context, req *mcp.CallToolRequest, args RecallArgs, startTime time.Time) (*mcp.CallToolResult, RecallResult, error) {
 
 	log.Printf("Handling recall request: query=%s, maxResults=%d", args.Query, args.MaxResults)
 
@@ -129,6 +148,16 @@ func (rh *RecallHandler) HandleRecall(ctx context.Context, req *mcp.CallToolRequ
 	return mcpResult, result, nil
 }
 
+// SetCommunitySummarizer enables community cards for the communities touched by recalled evidence
+func (rh *RecallHandler) SetCommunitySummarizer(summarizer *CommunitySummarizer) {
+	rh.summarizer = summarizer
+}
+
+// GetMetrics returns the recall latency and error metrics
+func (rh *RecallHandler) GetMetrics() *QueryMetrics {
+	return rh.metrics
+}
+
 // processQuery handles the core query processing logic
 func (rh *RecallHandler) processQuery(ctx context.Context, query string, options *RecallOptions) (*RecallResponse, error) {
 	// Process the query through the query processor
@@ -147,6 +176,18 @@ func (rh *RecallHandler) processQuery(ctx context.Context, query string, options
 		return nil, fmt.Errorf("multi-view retrieval failed: %w", err)
 	}
 
+	// Record how many candidates each view contributed
+	for _, input := range fusionInputs {
+		switch input.Method {
+		case "vector":
+			response.RetrievalStats.VectorResults = len(input.Results)
+		case "keyword":
+			response.RetrievalStats.SearchResults = len(input.Results)
+		case "graph":
+			response.RetrievalStats.GraphResults = len(input.Results)
+		}
+	}
+
 	// Fuse results if we have multiple inputs
 	if len(fusionInputs) > 0 {
 		fusionResponse, err := rh.resultFuser.Fuse(ctx, fusionInputs)
@@ -154,10 +195,24 @@ func (rh *RecallHandler) processQuery(ctx context.Context, query string, options
 			return nil, fmt.Errorf("result fusion failed: %w", err)
 		}
 
-		// Convert fused results to evidence
-		evidence, err := rh.convertFusedResultsToEvidence(fusionResponse.Results)
+		// Re-rank the fused candidates on freshness, authority, quality, diversity and personalization
+		boostTimeRange(fusionResponse.Results, processedQuery.timeBoost())
+		ranked, err := rh.rankResults(ctx, query, fusionResponse.Results)
+		if err != nil {
+			return nil, fmt.Errorf("result ranking failed: %w", err)
+		}
+
+		// Assemble evidence with deduplication, provenance, relation maps and graph paths
+		evidence, err := rh.assembleEvidence(ctx, ranked, fusionResponse.Results, processedQuery, options)
 		if err != nil {
-			return nil, fmt.Errorf("evidence conversion failed: %w", err)
+			return nil, fmt.Errorf("evidence assembly failed: %w", err)
+		}
+
+		// Graph paths are only returned when the caller asked for graph relationships
+		if !options.IncludeGraph {
+			for i := range evidence {
+				evidence[i].GraphPath = nil
+			}
 		}
 
 		response.Evidence = evidence
@@ -166,6 +221,29 @@ func (rh *RecallHandler) processQuery(ctx context.Context, query string, options
 		// Update retrieval stats
 		response.RetrievalStats.FusionScore = rh.calculateAverageFusionScore(fusionResponse.Results)
 		response.RetrievalStats.TotalCandidates = fusionResponse.TotalResults
+
+		// Count the requested facets over every fused candidate, not just the evidence returned
+		if len(options.Facets) > 0 {
+			metadata := make([]map[string]interface{}, len(fusionResponse.Results))
+			for i, result := range fusionResponse.Results {
+				metadata[i] = result.Metadata
+			}
+			response.Facets = computeFacets(metadata, options.Facets)
+		}
+
+		// Attach the global view: cards for every community the returned evidence belongs to
+		if rh.summarizer != nil && len(evidence) > 0 {
+			chunkIDs := make([]string, 0, len(evidence))
+			for _, item := range evidence {
+				chunkIDs = append(chunkIDs, item.MemoryID)
+			}
+			cards, err := rh.summarizer.CardsForChunks(ctx, chunkIDs, rh.config.MaxCommunityCards)
+			if err != nil {
+				log.Printf("Community card lookup failed: %v", err)
+			} else {
+				response.CommunityCards = cards
+			}
+		}
 	}
 
 	// Add self-critique if enabled
@@ -211,23 +289,53 @@ func (rh *RecallHandler) performMultiViewRetrieval(ctx context.Context, processe
 		}
 	}
 
-	// Graph search (placeholder - would be implemented when graph components are available)
-	// This would be implemented in later tasks when graph algorithms are available
+	// Graph search anchored on the entities mentioned in the query (if available)
+	if rh.queryProcessor.graphSearcher != nil {
+		graphResults, err := rh.performGraphSearch(ctx, processedQuery, options)
+		if err != nil {
+			log.Printf("Graph search failed: %v", err)
+		} else if len(graphResults) > 0 {
+			fusionInputs = append(fusionInputs, rh.convertGraphResultsToFusionInput(graphResults))
+		}
+	}
 
 	return fusionInputs, nil
 }
 
 // performVectorSearch executes vector-based similarity search
-func (rh *RecallHandler) performVectorSearch(_ context.Context, _ *ProcessedQuery, _ *RecallOptions) ([]VectorSearchResult, error) {
-	// For now, return empty results since we don't have embeddings
-	// This would be implemented when we have actual embedding generation
-	return []VectorSearchResult{}, nil
+func (rh *RecallHandler) performVectorSearch(ctx context.Context, processedQuery *ProcessedQuery, options *RecallOptions) ([]VectorSearchResult, error) {
+	// Without an embedder or a backing store there is nothing to search
+	if rh.queryProcessor.embedder == nil || rh.queryProcessor.vectorSearcher.vectorStore == nil {
+		return []VectorSearchResult{}, nil
+	}
+
+	queryEmbedding, err := rh.queryProcessor.EmbedQuery(ctx, processedQuery.Original)
+	if err != nil {
+		return []VectorSearchResult{}, err
+	}
+
+	response, err := rh.queryProcessor.vectorSearcher.Search(ctx, queryEmbedding, options.MaxResults, chunkVectorFilters(processedQuery.Filters))
+	if err != nil {
+		return []VectorSearchResult{}, err
+	}
+
+	return response.Results, nil
+}
+
+// chunkVectorFilters adds a clause to the query's filters that leaves out the entity
+// embeddings sharing the vector store with chunks
+func chunkVectorFilters(filters map[string]interface{}) map[string]interface{} {
+	notEntity := map[string]interface{}{"type": map[string]interface{}{FilterOpNe: "entity"}}
+	if len(filters) == 0 {
+		return notEntity
+	}
+	return map[string]interface{}{FilterOpAnd: []interface{}{filters, notEntity}}
 }
 
 // performKeywordSearch executes keyword-based text search
 func (rh *RecallHandler) performKeywordSearch(ctx context.Context, processedQuery *ProcessedQuery, options *RecallOptions) ([]KeywordSearchResult, error) {
 	// Use the original query for keyword search
-	response, err := rh.queryProcessor.keywordSearcher.Search(ctx, processedQuery.Original, options.MaxResults, options.Filters)
+	response, err := rh.queryProcessor.keywordSearcher.SearchInLanguage(ctx, processedQuery.Original, options.Language, options.MaxResults, processedQuery.Filters)
 	if err != nil {
 		return []KeywordSearchResult{}, err
 	}
@@ -235,6 +343,20 @@ func (rh *RecallHandler) performKeywordSearch(ctx context.Context, processedQuer
 	return response.Results, nil
 }
 
+// performGraphSearch executes entity-anchored graph retrieval
+func (rh *RecallHandler) performGraphSearch(ctx context.Context, processedQuery *ProcessedQuery, options *RecallOptions) ([]GraphRetrievalResult, error) {
+	if len(processedQuery.Entities) == 0 || rh.queryProcessor.graphSearcher.graphStore == nil {
+		return []GraphRetrievalResult{}, nil
+	}
+
+	response, err := rh.queryProcessor.graphSearcher.Search(ctx, processedQuery.Entities, options.MaxResults, processedQuery.Filters)
+	if err != nil {
+		return []GraphRetrievalResult{}, err
+	}
+
+	return response.Results, nil
+}
+
 // convertVectorResultsToFusionInput converts vector search results to fusion input
 func (rh *RecallHandler) convertVectorResultsToFusionInput(results []VectorSearchResult) FusionInput {
 	fusionItems := make([]FusionItem, len(results))
@@ -283,66 +405,158 @@ func (rh *RecallHandler) convertKeywordResultsToFusionInput(results []KeywordSea
 	}
 }
 
-// convertFusedResultsToEvidence converts fused results to evidence format
-func (rh *RecallHandler) convertFusedResultsToEvidence(fusedResults []FusedResult) ([]Evidence, error) {
-	evidence := make([]Evidence, len(fusedResults))
+// convertGraphResultsToFusionInput converts graph retrieval results to fusion input
+func (rh *RecallHandler) convertGraphResultsToFusionInput(results []GraphRetrievalResult) FusionInput {
+	fusionItems := make([]FusionItem, len(results))
+	for i, result := range results {
+		fusionItems[i] = FusionItem{
+			ID:       result.ID,
+			Content:  result.Content,
+			Score:    result.Score,
+			Rank:     i + 1,
+			Metadata: result.Metadata,
+		}
+	}
 
-	for i, result := range fusedResults {
-		// Extract provenance information from metadata
-		provenance := ProvenanceInfo{
-			Source:    "unknown",
-			Timestamp: time.Now().Format(time.RFC3339),
-			Version:   "1.0.0",
+	// Leave the weight unset so the fuser applies its configured GraphWeight
+	return FusionInput{
+		Method:  "graph",
+		Results: fusionItems,
+		Metadata: map[string]interface{}{
+			"search_type":  "personalized_pagerank",
+			"result_count": len(results),
+		},
+	}
+}
+
+// timeBoostFactor is how much more a result dated in a period the query named only by a bare
+// year scores than one outside it
+const timeBoostFactor = 1.25
+
+// boostTimeRange raises the fused score of results whose timestamp falls in period and re-sorts
+// them, so a bare year in the query favours that year without filtering the rest out
+func boostTimeRange(results []FusedResult, period *TimeRange) {
+	if period == nil {
+		return
+	}
+	for i := range results {
+		if timestamp, ok := metadataTime(results[i].Metadata, "timestamp"); ok && period.Contains(timestamp) {
+			results[i].FinalScore *= timeBoostFactor
 		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].FinalScore > results[j].FinalScore
+	})
+}
 
-		if source, ok := result.Metadata["source"].(string); ok {
-			provenance.Source = source
+// rankResults orders fused results with the ResultRanker, or keeps fusion order when ranking is disabled.
+// Fused scores are normalized against the best one so relevance stays comparable to the other factors.
+func (rh *RecallHandler) rankResults(ctx context.Context, query string, fusedResults []FusedResult) ([]RankableResult, error) {
+	maxScore := 0.0
+	for _, result := range fusedResults {
+		if result.FinalScore > maxScore {
+			maxScore = result.FinalScore
 		}
-		if timestamp, ok := result.Metadata["timestamp"].(string); ok {
-			provenance.Timestamp = timestamp
+	}
+
+	rankable := make([]RankableResult, len(fusedResults))
+	for i, result := range fusedResults {
+		baseScore := 0.0
+		if maxScore > 0 {
+			baseScore = result.FinalScore / maxScore
 		}
-		if version, ok := result.Metadata["version"].(string); ok {
-			provenance.Version = version
+
+		timestamp, _ := metadataTime(result.Metadata, "timestamp")
+		rankable[i] = RankableResult{
+			ID:         result.ID,
+			Content:    result.Content,
+			BaseScore:  baseScore,
+			FinalScore: baseScore,
+			Rank:       i + 1,
+			Metadata:   result.Metadata,
+			Timestamp:  timestamp,
 		}
-		if userID, ok := result.Metadata["user_id"].(string); ok {
-			provenance.UserID = userID
+		if source, ok := result.Metadata["source"].(string); ok {
+			rankable[i].Source = source
 		}
+	}
 
-		// Generate why_selected explanation
-		whySelected := rh.generateWhySelectedExplanation(result)
+	if !rh.config.EnableRanking {
+		return rankable, nil
+	}
 
-		// Create relation map from source methods
-		relationMap := make(map[string]string)
-		for _, method := range result.SourceMethods {
-			relationMap[method] = fmt.Sprintf("%.3f", result.MethodScores[method])
-		}
+	response, err := rh.ranker.Rank(ctx, rankable, &RankingContext{
+		Query:       query,
+		TimeContext: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response.Results, nil
+}
 
-		evidence[i] = Evidence{
-			Content:     result.Content,
-			Source:      provenance.Source,
-			Confidence:  result.FinalScore,
-			WhySelected: whySelected,
-			RelationMap: relationMap,
-			Provenance:  provenance,
-		}
+// assembleEvidence turns ranked results into evidence through the EvidenceAssembler
+func (rh *RecallHandler) assembleEvidence(ctx context.Context, ranked []RankableResult, fusedResults []FusedResult, processedQuery *ProcessedQuery, options *RecallOptions) ([]Evidence, error) {
+	fusedByID := make(map[string]FusedResult, len(fusedResults))
+	for _, result := range fusedResults {
+		fusedByID[result.ID] = result
 	}
 
-	return evidence, nil
-}
+	var queryTerms []string
+	if processedQuery.Parsed != nil {
+		queryTerms = processedQuery.Parsed.Terms
+	}
+
+	inputs := make([]AssemblyInput, len(ranked))
+	for i, result := range ranked {
+		fused := fusedByID[result.ID]
 
-// generateWhySelectedExplanation creates an explanation for why evidence was selected
-func (rh *RecallHandler) generateWhySelectedExplanation(result FusedResult) string {
-	methods := result.SourceMethods
-	if len(methods) == 0 {
-		return "Selected based on relevance score"
+		inputs[i] = AssemblyInput{
+			ID:           result.ID,
+			Content:      result.Content,
+			Score:        result.FinalScore,
+			Source:       result.Source,
+			Timestamp:    result.Timestamp,
+			Metadata:     result.Metadata,
+			MatchedTerms: matchedQueryTerms(result.Content, queryTerms),
+			MethodScores: fused.MethodScores,
+		}
+		if inputs[i].Source == "" {
+			inputs[i].Source = "unknown"
+		}
+		if graphPath, ok := result.Metadata["graph_path"].([]string); ok {
+			inputs[i].GraphPath = graphPath
+		}
+	}
+
+	response, err := rh.assembler.Assemble(ctx, inputs, &AssemblyContext{
+		Query:           processedQuery.Original,
+		QueryTerms:      queryTerms,
+		RequestTime:     time.Now(),
+		GraphContext:    &GraphContext{QueryEntities: processedQuery.Entities},
+		RetrievalMethod: "fused",
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if len(methods) == 1 {
-		return fmt.Sprintf("Selected via %s search (score: %.3f)", methods[0], result.FinalScore)
+	evidence := response.Evidence
+	if options.MaxResults > 0 && len(evidence) > options.MaxResults {
+		evidence = evidence[:options.MaxResults]
 	}
+	return evidence, nil
+}
 
-	return fmt.Sprintf("Selected via multi-view fusion of %v (combined score: %.3f)",
-		methods, result.FinalScore)
+// matchedQueryTerms returns the query terms that appear in content
+func matchedQueryTerms(content string, terms []string) []string {
+	contentLower := strings.ToLower(content)
+	var matched []string
+	for _, term := range terms {
+		if term != "" && strings.Contains(contentLower, strings.ToLower(term)) {
+			matched = append(matched, term)
+		}
+	}
+	return matched
 }
 
 // calculateAverageFusionScore calculates the average fusion score
@@ -443,19 +657,82 @@ func (rh *RecallHandler) convertArgsToOptions(args RecallArgs) *RecallOptions {
 
 	options.IncludeGraph = args.IncludeGraph
 	options.Filters = args.Filters
+	options.Language = args.Language
+	for _, spec := range args.Facets {
+		// Specs were checked by the validator, so anything unparseable is dropped
+		if request, err := ParseFacetRequest(spec); err == nil {
+			options.Facets = append(options.Facets, request)
+		}
+	}
 	options.ExpandQuery = rh.config.EnableQueryExpansion
 
 	return options
 }
 
+// SelectMemoryIDs runs multi-view retrieval for a query and returns the fused memory IDs in rank order
+func (rh *RecallHandler) SelectMemoryIDs(ctx context.Context, query string, maxResults int) ([]string, error) {
+	options := rh.convertArgsToOptions(RecallArgs{Query: query, MaxResults: maxResults})
+
+	processedQuery, err := rh.queryProcessor.Process(ctx, query, options)
+	if err != nil {
+		return nil, fmt.Errorf("query processing failed: %w", err)
+	}
+
+	fusionInputs, err := rh.performMultiViewRetrieval(ctx, processedQuery, options)
+	if err != nil {
+		return nil, fmt.Errorf("multi-view retrieval failed: %w", err)
+	}
+	if len(fusionInputs) == 0 {
+		return []string{}, nil
+	}
+
+	fusionResponse, err := rh.resultFuser.Fuse(ctx, fusionInputs)
+	if err != nil {
+		return nil, fmt.Errorf("result fusion failed: %w", err)
+	}
+
+	boostTimeRange(fusionResponse.Results, processedQuery.timeBoost())
+	ranked, err := rh.rankResults(ctx, query, fusionResponse.Results)
+	if err != nil {
+		return nil, fmt.Errorf("result ranking failed: %w", err)
+	}
+
+	ids := make([]string, 0, len(ranked))
+	for _, result := range ranked {
+		ids = append(ids, result.ID)
+		if len(ids) >= options.MaxResults {
+			break
+		}
+	}
+
+	return ids, nil
+}
+
 // GetConfig returns the current configuration
 func (rh *RecallHandler) GetConfig() *RecallHandlerConfig {
 	return rh.config
 }
 
-// UpdateConfig updates the configuration
+// UpdateConfig updates the configuration and rebuilds the pipeline stages from it
 func (rh *RecallHandler) UpdateConfig(config *RecallHandlerConfig) {
-	if config != nil {
-		rh.config = config
+	if config == nil {
+		return
+	}
+
+	if config.Fusion != nil && rh.resultFuser != nil {
+		rh.resultFuser.UpdateConfig(config.Fusion)
+	}
+	rh.ranker = NewResultRankerWithConfig(config.Ranking)
+	rh.assembler = NewEvidenceAssemblerWithConfig(config.Assembly)
+	rh.formatter = NewRecallResponseFormatterWithConfig(config.Formatting)
+
+	// Expose the effective stage configuration, defaults included
+	if rh.resultFuser != nil {
+		config.Fusion = rh.resultFuser.GetConfig()
 	}
+	config.Ranking = rh.ranker.GetConfig()
+	config.Assembly = rh.assembler.GetConfig()
+	config.Formatting = rh.formatter.config
+
+	rh.config = config
 }
@@ -234,6 +234,22 @@ func TestRecallHandlerConvertArgsToOptions(t *testing.T) {
 			})
 		})
 
+		Convey("When converting args with facet specs", func() {
+			args := RecallArgs{
+				Query:  "test query",
+				Facets: []string{"source", "timestamp:month"},
+			}
+
+			options := handler.convertArgsToOptions(args)
+
+			Convey("Then they should become facet requests", func() {
+				So(options.Facets, ShouldResemble, []FacetRequest{
+					{Field: "source"},
+					{Field: "timestamp", Interval: FacetIntervalMonth},
+				})
+			})
+		})
+
 		Convey("When converting args with excessive time budget", func() {
 			args := RecallArgs{
 				Query:      "test query",
@@ -251,55 +267,124 @@ func TestRecallHandlerConvertArgsToOptions(t *testing.T) {
 
 func TestRecallHandlerEvidenceConversion(t *testing.T) {
 	Convey("Given a RecallHandler", t, func() {
+		ctx := context.Background()
 		queryProcessor := NewQueryProcessor(nil)
 		resultFuser := NewResultFuser()
 		handler := NewRecallHandler(queryProcessor, resultFuser)
 
-		Convey("When converting fused results to evidence", func() {
-			fusedResults := []FusedResult{
-				{
-					ID:            "result1",
-					Content:       "Test content 1",
-					FinalScore:    0.8,
-					SourceMethods: []string{"vector", "keyword"},
-					MethodScores:  map[string]float64{"vector": 0.7, "keyword": 0.9},
-					Metadata: map[string]interface{}{
-						"source":    "test_source",
-						"timestamp": "2024-01-01T00:00:00Z",
-						"version":   "1.0",
-					},
+		fusedResults := []FusedResult{
+			{
+				ID:            "result1",
+				Content:       "Test content 1",
+				FinalScore:    0.032,
+				SourceMethods: []string{"vector", "keyword"},
+				MethodScores:  map[string]float64{"vector": 0.7, "keyword": 0.9},
+				Metadata: map[string]interface{}{
+					"source":    "test_source",
+					"timestamp": "2024-01-01T00:00:00Z",
+					"version":   "1.0",
+					"user_id":   "user_1",
 				},
-				{
-					ID:            "result2",
-					Content:       "Test content 2",
-					FinalScore:    0.6,
-					SourceMethods: []string{"vector"},
-					MethodScores:  map[string]float64{"vector": 0.6},
-					Metadata: map[string]interface{}{
-						"source": "another_source",
-					},
+			},
+			{
+				ID:            "result2",
+				Content:       "Another snippet",
+				FinalScore:    0.016,
+				SourceMethods: []string{"vector"},
+				MethodScores:  map[string]float64{"vector": 0.6},
+				Metadata: map[string]interface{}{
+					"source": "another_source",
 				},
-			}
+			},
+		}
+		processedQuery := &ProcessedQuery{
+			Original: "test content",
+			Parsed:   &ParsedQuery{Terms: []string{"test", "content"}},
+		}
+		options := NewRecallOptions()
+		options.MaxResults = 10
+
+		Convey("When ranking is disabled", func() {
+			handler.config.EnableRanking = false
+			ranked, err := handler.rankResults(ctx, "test content", fusedResults)
+			So(err, ShouldBeNil)
+
+			Convey("Then fusion order should be kept with scores normalized to the best result", func() {
+				So(ranked, ShouldHaveLength, 2)
+				So(ranked[0].ID, ShouldEqual, "result1")
+				So(ranked[0].FinalScore, ShouldEqual, 1.0)
+				So(ranked[1].FinalScore, ShouldEqual, 0.5)
+				So(ranked[0].Source, ShouldEqual, "test_source")
+				So(ranked[0].Timestamp.Year(), ShouldEqual, 2024)
+			})
+		})
+
+		Convey("When ranking is enabled", func() {
+			ranked, err := handler.rankResults(ctx, "test content", fusedResults)
+			So(err, ShouldBeNil)
+
+			Convey("Then results should carry the ranker's factor scores", func() {
+				So(ranked, ShouldHaveLength, 2)
+				So(ranked[0].Rank, ShouldEqual, 1)
+				for _, result := range ranked {
+					So(result.FinalScore, ShouldBeBetweenOrEqual, 0, 1)
+					So(result.QualityScore, ShouldBeGreaterThan, 0)
+				}
+			})
+		})
 
-			evidence, err := handler.convertFusedResultsToEvidence(fusedResults)
+		Convey("When assembling evidence from ranked results", func() {
+			handler.config.EnableRanking = false
+			ranked, err := handler.rankResults(ctx, "test content", fusedResults)
+			So(err, ShouldBeNil)
+			evidence, err := handler.assembleEvidence(ctx, ranked, fusedResults, processedQuery, options)
 
-			Convey("Then evidence should be properly converted", func() {
+			Convey("Then evidence should carry provenance, explanations and per-view scores", func() {
 				So(err, ShouldBeNil)
 				So(evidence, ShouldHaveLength, 2)
 
 				So(evidence[0].Content, ShouldEqual, "Test content 1")
-				So(evidence[0].Confidence, ShouldEqual, 0.8)
+				So(evidence[0].Confidence, ShouldEqual, 1.0)
 				So(evidence[0].Source, ShouldEqual, "test_source")
 				So(evidence[0].Provenance.Source, ShouldEqual, "test_source")
 				So(evidence[0].Provenance.Timestamp, ShouldEqual, "2024-01-01T00:00:00Z")
+				So(evidence[0].Provenance.UserID, ShouldEqual, "user_1")
 				So(evidence[0].WhySelected, ShouldContainSubstring, "multi-view fusion")
+				So(evidence[0].WhySelected, ShouldContainSubstring, "matches terms: test, content")
+				So(evidence[0].RelationMap["keyword"], ShouldEqual, "0.900")
 
-				So(evidence[1].Content, ShouldEqual, "Test content 2")
-				So(evidence[1].Confidence, ShouldEqual, 0.6)
+				So(evidence[1].Content, ShouldEqual, "Another snippet")
+				So(evidence[1].Confidence, ShouldEqual, 0.5)
 				So(evidence[1].Source, ShouldEqual, "another_source")
+				So(evidence[1].Provenance.Timestamp, ShouldNotBeEmpty)
 				So(evidence[1].WhySelected, ShouldContainSubstring, "vector search")
 			})
 		})
+
+		Convey("When the assembly stage is configured to drop low-confidence evidence", func() {
+			config := &RecallHandlerConfig{
+				DefaultMaxResults: 10,
+				Assembly:          &EvidenceAssemblerConfig{MinConfidence: 0.75},
+			}
+			strict := NewRecallHandlerWithConfig(queryProcessor, resultFuser, config)
+			ranked, err := strict.rankResults(ctx, "test content", fusedResults)
+			So(err, ShouldBeNil)
+			evidence, err := strict.assembleEvidence(ctx, ranked, fusedResults, processedQuery, options)
+
+			Convey("Then only evidence above the threshold should remain", func() {
+				So(err, ShouldBeNil)
+				So(evidence, ShouldHaveLength, 1)
+				So(evidence[0].Content, ShouldEqual, "Test content 1")
+			})
+
+			Convey("Then the effective stage configuration should be exposed", func() {
+				So(strict.GetConfig().Assembly.MinConfidence, ShouldEqual, 0.75)
+				So(strict.GetConfig().Assembly.MaxEvidenceItems, ShouldEqual, 50)
+				So(strict.GetConfig().Ranking, ShouldNotBeNil)
+				So(strict.GetConfig().Fusion, ShouldEqual, resultFuser.GetConfig())
+				So(strict.GetConfig().Formatting, ShouldNotBeNil)
+			})
+		})
 	})
 }
 
@@ -470,3 +555,213 @@ func TestRecallHandlerConfiguration(t *testing.T) {
 		})
 	})
 }
+
+func TestRecallHandlerVectorSearch(t *testing.T) {
+	Convey("Given a RecallHandler sharing an embedder and vector store with a MemoryWriter", t, func() {
+		ctx := context.Background()
+		embedder := NewHashEmbedder(128)
+		vectorStore := NewMockVectorStore()
+		storage := &MultiViewStorage{
+			vectorStore: vectorStore,
+			graphStore:  NewMockGraphStore(),
+			searchIndex: NewMockSearchIndex(),
+		}
+
+		contentProcessor := NewContentProcessor()
+		contentProcessor.SetEmbedder(embedder)
+		writer := NewMemoryWriter(storage, contentProcessor, nil)
+		writer.SetEmbedder(embedder)
+
+		queryProcessor := NewQueryProcessor(nil)
+		queryProcessor.SetEmbedder(embedder)
+		queryProcessor.SetVectorSearcher(NewVectorSearcherWithStore(vectorStore, nil))
+		handler := NewRecallHandler(queryProcessor, NewResultFuser())
+
+		documents := map[string]string{
+			"biology": "Photosynthesis converts sunlight into chemical energy in plants.",
+			"finance": "The stock market closed higher after the interest rate decision.",
+		}
+		for source, content := range documents {
+			_, err := writer.Write(ctx, content, WriteMetadata{Source: source, Confidence: 0.9})
+			So(err, ShouldBeNil)
+		}
+
+		Convey("When performing vector search", func() {
+			processedQuery := &ProcessedQuery{Original: "how do plants use sunlight"}
+			options := &RecallOptions{MaxResults: 5, TimeBudget: 5 * time.Second}
+
+			results, err := handler.performVectorSearch(ctx, processedQuery, options)
+
+			Convey("Then the most similar chunk should rank first", func() {
+				So(err, ShouldBeNil)
+				So(len(results), ShouldBeGreaterThan, 0)
+				So(results[0].Content, ShouldContainSubstring, "Photosynthesis")
+				for _, result := range results {
+					So(result.Metadata["type"], ShouldNotEqual, "entity")
+				}
+			})
+		})
+
+		Convey("When the page is no larger than the number of chunks", func() {
+			processedQuery := &ProcessedQuery{Original: "plants sunlight stock market", Filters: map[string]interface{}{}}
+			results, err := handler.performVectorSearch(ctx, processedQuery, &RecallOptions{MaxResults: 2})
+
+			Convey("Then the store should fill it with chunks rather than entities", func() {
+				So(err, ShouldBeNil)
+				So(len(results), ShouldEqual, 2)
+				for _, result := range results {
+					So(result.Metadata["type"], ShouldNotEqual, "entity")
+				}
+			})
+		})
+
+		Convey("When the query has its own filters", func() {
+			processedQuery := &ProcessedQuery{Original: "plants", Filters: map[string]interface{}{"source": "finance"}}
+			results, err := handler.performVectorSearch(ctx, processedQuery, &RecallOptions{MaxResults: 5})
+
+			Convey("Then the entity filter should apply alongside them", func() {
+				So(err, ShouldBeNil)
+				So(len(results), ShouldEqual, 1)
+				So(results[0].Content, ShouldContainSubstring, "stock market")
+			})
+		})
+
+		Convey("When the query processor has no embedder", func() {
+			queryProcessor.SetEmbedder(nil)
+			results, err := handler.performVectorSearch(ctx, &ProcessedQuery{Original: "plants"}, &RecallOptions{MaxResults: 5})
+
+			Convey("Then it should return no results without error", func() {
+				So(err, ShouldBeNil)
+				So(len(results), ShouldEqual, 0)
+			})
+		})
+	})
+}
+
+func TestRecallHandlerGraphView(t *testing.T) {
+	Convey("Given a RecallHandler with a graph searcher", t, func() {
+		ctx := context.Background()
+		req := &mcp.CallToolRequest{}
+		graphStore := NewMockGraphStore()
+		searchIndex := NewMockSearchIndex()
+
+		So(searchIndex.Index(ctx, IndexDocument{ID: "chunk_paris", Content: "Paris, in France, hosts the Louvre.", Metadata: map[string]interface{}{"source": "travel"}}), ShouldBeNil)
+		So(searchIndex.Index(ctx, IndexDocument{ID: "chunk_france", Content: "France borders Spain.", Metadata: map[string]interface{}{"source": "travel"}}), ShouldBeNil)
+		So(createTestEntity(ctx, graphStore, "paris", "Paris", "chunk_paris"), ShouldBeNil)
+		So(createTestEntity(ctx, graphStore, "france", "France", "chunk_france", "chunk_paris"), ShouldBeNil)
+		So(graphStore.CreateEdge(ctx, NewEdge("paris_france", "paris", "france", PartOf, 1.0)), ShouldBeNil)
+		So(addCooccurrence(ctx, graphStore, "paris", "france"), ShouldBeNil)
+		So(addCooccurrence(ctx, graphStore, "france", "paris"), ShouldBeNil)
+
+		queryProcessor := NewQueryProcessor(nil)
+		queryProcessor.SetKeywordSearcher(NewKeywordSearcherWithIndex(searchIndex, nil))
+		queryProcessor.SetGraphSearcher(NewGraphSearcherWithStore(graphStore, searchIndex, nil))
+		handler := NewRecallHandler(queryProcessor, NewResultFuser())
+
+		Convey("When recalling with graph relationships requested", func() {
+			_, result, err := handler.HandleRecall(ctx, req, RecallArgs{Query: "Paris", MaxResults: 5, IncludeGraph: true})
+			So(err, ShouldBeNil)
+
+			Convey("Then graph hits should be fused with their paths", func() {
+				So(result.Stats.GraphResults, ShouldEqual, 2)
+
+				paths := make(map[string][]string)
+				for _, evidence := range result.Evidence {
+					paths[evidence.Content] = evidence.GraphPath
+				}
+				So(paths["France borders Spain."], ShouldResemble, []string{"paris", "france", "chunk_france"})
+				So(paths["Paris, in France, hosts the Louvre."], ShouldResemble, []string{"paris", "chunk_paris"})
+			})
+		})
+
+		Convey("When recalling without graph relationships", func() {
+			_, result, err := handler.HandleRecall(ctx, req, RecallArgs{Query: "Paris", MaxResults: 5})
+			So(err, ShouldBeNil)
+
+			Convey("Then the graph view should still contribute but paths should be omitted", func() {
+				So(result.Stats.GraphResults, ShouldEqual, 2)
+				So(len(result.Evidence), ShouldEqual, 2)
+				for _, evidence := range result.Evidence {
+					So(evidence.GraphPath, ShouldBeEmpty)
+				}
+			})
+
+			Convey("Then no community cards should be attached without a summarizer", func() {
+				So(result.CommunityCards, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When a community summarizer is configured", func() {
+			handler.SetCommunitySummarizer(NewCommunitySummarizer(graphStore, searchIndex))
+			_, result, err := handler.HandleRecall(ctx, req, RecallArgs{Query: "Paris", MaxResults: 5})
+			So(err, ShouldBeNil)
+
+			Convey("Then the community touched by the evidence should be attached as a card", func() {
+				So(len(result.CommunityCards), ShouldEqual, 1)
+				So(result.CommunityCards[0].Title, ShouldEqual, "France and Paris")
+				So(result.CommunityCards[0].Entities, ShouldResemble, []string{"France", "Paris"})
+			})
+		})
+
+		Convey("When the recalled evidence is cut short of another community", func() {
+			So(searchIndex.Index(ctx, IndexDocument{ID: "chunk_lyon", Content: "Paris to Lyon.", Metadata: map[string]interface{}{"source": "travel"}}), ShouldBeNil)
+			So(createTestEntity(ctx, graphStore, "lyon", "Lyon", "chunk_lyon"), ShouldBeNil)
+			So(createTestEntity(ctx, graphStore, "rhone", "Rhone", "chunk_lyon"), ShouldBeNil)
+			So(graphStore.CreateEdge(ctx, NewEdge("lyon_rhone", "lyon", "rhone", RelatedTo, 1.0)), ShouldBeNil)
+
+			handler.SetCommunitySummarizer(NewCommunitySummarizer(graphStore, searchIndex))
+			_, result, err := handler.HandleRecall(ctx, req, RecallArgs{Query: "Paris", MaxResults: 1})
+			So(err, ShouldBeNil)
+
+			Convey("Then only the communities of the returned evidence should be attached", func() {
+				titles := map[string]string{
+					"chunk_paris":  "France and Paris",
+					"chunk_france": "France and Paris",
+					"chunk_lyon":   "Lyon and Rhone",
+				}
+				expected := make(map[string]bool)
+				for _, evidence := range result.Evidence {
+					expected[titles[evidence.MemoryID]] = true
+				}
+				attached := make(map[string]bool)
+				for _, card := range result.CommunityCards {
+					attached[card.Title] = true
+				}
+				So(len(result.Evidence), ShouldEqual, 1)
+				So(result.Stats.TotalCandidates, ShouldEqual, 2)
+				So(attached, ShouldResemble, expected)
+			})
+		})
+	})
+}
+
+func TestBoostTimeRange(t *testing.T) {
+	Convey("Given fused results dated in different years", t, func() {
+		results := []FusedResult{
+			{ID: "old", FinalScore: 1.0, Metadata: map[string]interface{}{"timestamp": "2021-06-01T00:00:00Z"}},
+			{ID: "match", FinalScore: 0.9, Metadata: map[string]interface{}{"timestamp": "2023-06-01T00:00:00Z"}},
+			{ID: "undated", FinalScore: 0.5},
+		}
+		start := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+		end := start.AddDate(1, 0, 0).Add(-time.Nanosecond)
+
+		Convey("When the query prefers 2023", func() {
+			boostTimeRange(results, &TimeRange{Start: &start, End: &end})
+
+			Convey("Then the 2023 result should move up without dropping the others", func() {
+				So(results, ShouldHaveLength, 3)
+				So(results[0].ID, ShouldEqual, "match")
+				So(results[0].FinalScore, ShouldAlmostEqual, 0.9*timeBoostFactor)
+				So(results[1].ID, ShouldEqual, "old")
+			})
+		})
+
+		Convey("When the query names no year", func() {
+			boostTimeRange(results, nil)
+
+			Convey("Then the order should be unchanged", func() {
+				So(results[0].ID, ShouldEqual, "old")
+			})
+		})
+	})
+}
@@ -44,6 +44,10 @@ type ValidationResult struct {
 	Sanitized RecallArgs        `json:"sanitized"`
 }
 
+// defaultFilterFields are the metadata fields recall filters may constrain, whether passed as
+// filters or written into the query as key:value
+var defaultFilterFields = []string{"source", "type", "confidence", "date", "tag", "tags", "timestamp", "user_id"}
+
 // NewRecallArgsValidator creates a new validator with default configuration
 func NewRecallArgsValidator() *RecallArgsValidator {
 	config := &RecallValidatorConfig{
@@ -51,7 +55,7 @@ func NewRecallArgsValidator() *RecallArgsValidator {
 		MinQueryLength:  1,
 		MaxResults:      100,
 		MaxTimeBudget:   30 * time.Second,
-		AllowedFilters:  []string{"source", "type", "confidence", "date", "tag", "user_id"},
+		AllowedFilters:  append([]string(nil), defaultFilterFields...),
 		BlockedPatterns: []string{`<script`, `javascript:`, `data:`, `vbscript:`},
 		SanitizeHTML:    true,
 		ValidateUTF8:    true,
@@ -106,6 +110,9 @@ func (v *RecallArgsValidator) ValidateDetailed(args RecallArgs) *ValidationResul
 	// Validate filters
 	v.validateFilters(args.Filters, result)
 
+	// Validate facets
+	v.validateFacets(args.Facets, result)
+
 	// Check for blocked patterns
 	v.checkBlockedPatterns(args.Query, result)
 
@@ -273,25 +280,86 @@ func (v *RecallArgsValidator) validateFilters(filters map[string]interface{}, re
 		return
 	}
 
-	sanitizedFilters := make(map[string]interface{})
+	sanitizedFilters := v.sanitizeFilterExpression(filters, &result.Warnings)
+
+	// The stores evaluate the same expression, so reject anything they cannot parse
+	if _, err := ParseMetadataFilter(sanitizedFilters); err != nil {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "filters",
+			Message: err.Error(),
+			Value:   filters,
+		})
+	}
+
+	result.Sanitized.Filters = sanitizedFilters
+}
+
+// validateFacets checks every facet spec names a field and a known histogram interval
+func (v *RecallArgsValidator) validateFacets(facets []string, result *ValidationResult) {
+	for _, spec := range facets {
+		if _, err := ParseFacetRequest(spec); err != nil {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "facets",
+				Message: err.Error(),
+				Value:   spec,
+			})
+		}
+	}
+}
+
+// sanitizeFilterExpression drops fields outside the allowed list and sanitizes values throughout
+// a filter expression, descending into "and", "or" and "not"; warnings may be nil
+func (v *RecallArgsValidator) sanitizeFilterExpression(filters map[string]interface{}, warnings *[]string) map[string]interface{} {
+	warn := func(format string, args ...interface{}) {
+		if warnings != nil {
+			*warnings = append(*warnings, fmt.Sprintf(format, args...))
+		}
+	}
+
+	sanitized := make(map[string]interface{})
 
 	for key, value := range filters {
+		if isLogicalFilterKey(key) {
+			sanitized[key] = v.sanitizeLogicalFilter(value, warnings)
+			continue
+		}
+
 		// Check if filter key is allowed
 		if !v.isAllowedFilter(key) {
-			result.Warnings = append(result.Warnings, fmt.Sprintf("filter key '%s' is not in allowed list", key))
+			warn("filter key '%s' is not in allowed list", key)
 			continue
 		}
 
 		// Sanitize filter value
 		sanitizedValue := v.sanitizeFilterValue(value)
 		if sanitizedValue != nil {
-			sanitizedFilters[key] = sanitizedValue
+			sanitized[key] = sanitizedValue
 		} else {
-			result.Warnings = append(result.Warnings, fmt.Sprintf("filter value for key '%s' could not be sanitized", key))
+			warn("filter value for key '%s' could not be sanitized", key)
 		}
 	}
 
-	result.Sanitized.Filters = sanitizedFilters
+	return sanitized
+}
+
+// sanitizeLogicalFilter sanitizes the operand of a combinator: a filter object for "not" or a
+// list of filter objects for "and" and "or". Malformed operands are left for the parser to reject.
+func (v *RecallArgsValidator) sanitizeLogicalFilter(value interface{}, warnings *[]string) interface{} {
+	switch operand := value.(type) {
+	case map[string]interface{}:
+		return v.sanitizeFilterExpression(operand, warnings)
+	case []interface{}:
+		items := make([]interface{}, 0, len(operand))
+		for _, item := range operand {
+			if sub, ok := item.(map[string]interface{}); ok {
+				items = append(items, v.sanitizeFilterExpression(sub, warnings))
+			} else {
+				items = append(items, item)
+			}
+		}
+		return items
+	}
+	return value
 }
 
 // checkBlockedPatterns checks for blocked patterns in the query
@@ -334,18 +402,7 @@ func (v *RecallArgsValidator) sanitizeFilters(filters map[string]interface{}) ma
 		return nil
 	}
 
-	sanitized := make(map[string]interface{})
-
-	for key, value := range filters {
-		if v.isAllowedFilter(key) {
-			sanitizedValue := v.sanitizeFilterValue(value)
-			if sanitizedValue != nil {
-				sanitized[key] = sanitizedValue
-			}
-		}
-	}
-
-	return sanitized
+	return v.sanitizeFilterExpression(filters, nil)
 }
 
 // sanitizeFilterValue sanitizes a filter value
@@ -358,8 +415,23 @@ func (v *RecallArgsValidator) sanitizeFilterValue(value interface{}) interface{}
 		}
 		sanitized = v.removeControlCharacters(sanitized)
 		return sanitized
-	case int, int32, int64, float32, float64, bool:
+	case int, int32, int64, float32, float64, bool, time.Time, TimeRange, *TimeRange:
 		return val
+	case map[string]interface{}:
+		// An operator object such as {"in": [...]} or {"range": {"gte": 0.5}}
+		sanitized := make(map[string]interface{}, len(val))
+		for op, operand := range val {
+			if sanitizedOperand := v.sanitizeFilterValue(operand); sanitizedOperand != nil {
+				sanitized[op] = sanitizedOperand
+			}
+		}
+		return sanitized
+	case []string:
+		sanitizedSlice := make([]interface{}, 0, len(val))
+		for _, item := range val {
+			sanitizedSlice = append(sanitizedSlice, v.sanitizeFilterValue(item))
+		}
+		return sanitizedSlice
 	case []interface{}:
 		var sanitizedSlice []interface{}
 		for _, item := range val {
@@ -193,6 +193,17 @@ func TestRecallArgsValidatorValidateDetailed(t *testing.T) {
 			})
 		})
 
+		Convey("When validating facet specs", func() {
+			valid := validator.ValidateDetailed(RecallArgs{Query: "test query", Facets: []string{"source", "timestamp:week"}})
+			invalid := validator.ValidateDetailed(RecallArgs{Query: "test query", Facets: []string{"timestamp:year"}})
+
+			Convey("Then only known intervals should be accepted", func() {
+				So(valid.Valid, ShouldBeTrue)
+				So(invalid.Valid, ShouldBeFalse)
+				So(invalid.Errors[0].Field, ShouldEqual, "facets")
+			})
+		})
+
 		Convey("When validating args with blocked patterns", func() {
 			args := RecallArgs{
 				Query:      "test <script>alert('xss')</script> query",
@@ -235,6 +246,47 @@ func TestRecallArgsValidatorValidateDetailed(t *testing.T) {
 				So(result.Sanitized.Filters, ShouldNotContainKey, "malicious_key")
 			})
 		})
+
+		Convey("When validating args with a nested filter expression", func() {
+			args := RecallArgs{
+				Query:      "test query",
+				MaxResults: 10,
+				Filters: map[string]interface{}{
+					"or": []interface{}{
+						map[string]interface{}{"tags": map[string]interface{}{"contains": []interface{}{"go"}}},
+						map[string]interface{}{"malicious_key": "bad_value", "confidence": map[string]interface{}{"range": map[string]interface{}{"gte": 0.5}}},
+					},
+				},
+			}
+
+			result := validator.ValidateDetailed(args)
+
+			Convey("Then it should keep the expression and drop disallowed keys inside it", func() {
+				So(result.Valid, ShouldBeTrue)
+				branches := result.Sanitized.Filters["or"].([]interface{})
+				So(len(branches), ShouldEqual, 2)
+				So(branches[1], ShouldNotContainKey, "malicious_key")
+				So(branches[1], ShouldContainKey, "confidence")
+
+				_, err := ParseMetadataFilter(result.Sanitized.Filters)
+				So(err, ShouldBeNil)
+			})
+		})
+
+		Convey("When validating args with a malformed filter expression", func() {
+			args := RecallArgs{
+				Query:      "test query",
+				MaxResults: 10,
+				Filters:    map[string]interface{}{"source": map[string]interface{}{"like": "blog"}},
+			}
+
+			result := validator.ValidateDetailed(args)
+
+			Convey("Then it should report the filters as invalid", func() {
+				So(result.Valid, ShouldBeFalse)
+				So(result.Errors[0].Field, ShouldEqual, "filters")
+			})
+		})
 	})
 }
 
@@ -16,19 +16,22 @@ type RecallResponse struct {
 	TotalResults    int                `json:"total_results"`
 	HasMore         bool               `json:"has_more"`
 	NextOffset      int                `json:"next_offset,omitempty"`
+	Facets          map[string][]Facet `json:"facets,omitempty"`
 }
 
 // WriteResponse represents the response from a memory write operation
 type WriteResponse struct {
-	MemoryID       string         `json:"memory_id"`
-	CandidateCount int            `json:"candidate_count"`
-	ConflictsFound []ConflictInfo `json:"conflicts_found,omitempty"`
-	EntitiesLinked []string       `json:"entities_linked"`
-	ProvenanceID   string         `json:"provenance_id"`
-	ChunksCreated  int            `json:"chunks_created"`
-	GraphUpdates   GraphUpdates   `json:"graph_updates"`
-	ProcessingTime time.Duration  `json:"processing_time"`
-	Warnings       []string       `json:"warnings,omitempty"`
+	MemoryID        string         `json:"memory_id"`
+	CandidateCount  int            `json:"candidate_count"`
+	ConflictsFound  []ConflictInfo `json:"conflicts_found,omitempty"`
+	EntitiesLinked  []string       `json:"entities_linked"`
+	ProvenanceID    string         `json:"provenance_id"`
+	ChunksCreated   int            `json:"chunks_created"`
+	ChunksUnchanged int            `json:"chunks_unchanged,omitempty"` // Chunks a re-ingest found already stored
+	ChunksRemoved   int            `json:"chunks_removed,omitempty"`   // Chunks deleted because the new text lost them
+	GraphUpdates    GraphUpdates   `json:"graph_updates"`
+	ProcessingTime  time.Duration  `json:"processing_time"`
+	Warnings        []string       `json:"warnings,omitempty"`
 }
 
 // ManageResponse represents the response from a memory management operation
@@ -418,9 +418,10 @@ func (rr *ResultRanker) calculateContentSimilarity(content1, content2 string) fl
 		return 0.0
 	}
 
-	// Simple Jaccard similarity based on words
-	words1 := rr.tokenizeContent(content1)
-	words2 := rr.tokenizeContent(content2)
+	// Jaccard similarity over analyzed terms
+	analyzer := AnalyzerForLanguage(defaultAnalyzerLanguage)
+	words1 := analyzer.Analyze(content1)
+	words2 := analyzer.Analyze(content2)
 
 	if len(words1) == 0 || len(words2) == 0 {
 		return 0.0
@@ -456,22 +457,6 @@ func (rr *ResultRanker) calculateContentSimilarity(content1, content2 string) fl
 	return float64(intersection) / float64(union)
 }
 
-// tokenizeContent tokenizes content into words
-func (rr *ResultRanker) tokenizeContent(content string) []string {
-	// Simple tokenization - split on whitespace and convert to lowercase
-	words := strings.Fields(strings.ToLower(content))
-
-	// Filter out very short words
-	var filtered []string
-	for _, word := range words {
-		if len(word) >= 3 {
-			filtered = append(filtered, word)
-		}
-	}
-
-	return filtered
-}
-
 // calculateFinalScore combines all individual scores into a final score
 func (rr *ResultRanker) calculateFinalScore(result *RankableResult) {
 	finalScore := 0.0
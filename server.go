@@ -2,11 +2,14 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
 	"sync"
-	"time"
+	"syscall"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -17,9 +20,14 @@ type AgenticMemoryServer struct {
 	config        *ServerConfig
 	recallHandler *RecallHandler
 	writeHandler  *WriteHandler
+	manageHandler *ManageHandler
+	statsHandler  *StatsHandler
+	storage       *MultiViewStorage
 	mu            sync.RWMutex
 	isRunning     bool
 	shutdownChan  chan struct{}
+	closeOnce     sync.Once
+	closeErr      error
 }
 
 // NewAgenticMemoryServer creates a new MCP server with memory capabilities
@@ -43,36 +51,62 @@ func NewAgenticMemoryServer(config *ServerConfig) (*AgenticMemoryServer, error)
 		shutdownChan: make(chan struct{}),
 	}
 
-	// Initialize handlers
+	// Check the rest of the config before opening storage, which would otherwise have to be closed again
+	duplicatePolicy, err := ParseDuplicatePolicy(config.Processing.DuplicatePolicy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid processing config: %w", err)
+	}
+
+	// Initialize the embedding provider shared by the write and recall paths
+	embedder, err := NewEmbedder(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedder: %w", err)
+	}
+
+	// Initialize storage components from the configured providers
+	storage, err := NewStorageFromConfig(&config.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage: %w", err)
+	}
+	ams.storage = storage
+
+	// Initialize handlers; recall searches the same stores the write path fills
 	queryProcessor := NewQueryProcessor(nil)
+	queryProcessor.SetEmbedder(embedder)
+	queryProcessor.SetVectorSearcher(NewVectorSearcherWithStore(storage.vectorStore, nil))
+	queryProcessor.SetKeywordSearcher(NewKeywordSearcherWithIndex(storage.searchIndex, nil))
+	queryProcessor.SetGraphSearcher(NewGraphSearcherWithStore(storage.graphStore, storage.searchIndex, nil))
 	resultFuser := NewResultFuser()
 	ams.recallHandler = NewRecallHandler(queryProcessor, resultFuser)
+	ams.recallHandler.SetCommunitySummarizer(NewCommunitySummarizer(storage.graphStore, storage.searchIndex))
 
 	// Initialize write handler
 	contentProcessor := NewContentProcessor()
-	// Initialize storage components
-	vectorStore := NewMockVectorStore()
-	graphStore := NewMockGraphStore()
-	searchIndex := NewMockSearchIndex()
-	storageConfig := &MultiViewStorageConfig{
-		VectorStore: VectorStoreConfig{
-			Provider:   "mock",
-			Dimensions: 1536,
-		},
-		GraphStore: GraphStoreConfig{
-			Provider: "mock",
-		},
-		SearchIndex: SearchIndexConfig{
-			Provider: "mock",
-		},
-		Timeout: 10 * time.Second,
-	}
-	storage := NewMultiViewStorage(vectorStore, graphStore, searchIndex, storageConfig)
-	memoryWriter := NewMemoryWriter(storage, contentProcessor, nil)
+	contentProcessor.SetEmbedder(embedder)
+	writerConfig := DefaultMemoryWriterConfig()
+	writerConfig.DuplicatePolicy = duplicatePolicy
+	writerConfig.DuplicateDistance = config.Processing.DuplicateDistance
+	writerConfig.IdempotencyTTL = config.Processing.IdempotencyTTL
+	memoryWriter := NewMemoryWriter(storage, contentProcessor, writerConfig)
+	memoryWriter.SetEmbedder(embedder)
 	ams.writeHandler = NewWriteHandler(memoryWriter, contentProcessor)
 
+	// Initialize manage handler over the same storage
+	ams.manageHandler = NewManageHandler(NewMemoryManager(storage, ams.recallHandler))
+
+	// Initialize stats handler from live storage and recall metrics
+	statsCollector := NewStatsCollector(storage)
+	statsCollector.SetQueryMetrics(ams.recallHandler.GetMetrics())
+	if cachedEmbedder, ok := embedder.(*CachedEmbedder); ok {
+		statsCollector.SetEmbeddingCache(cachedEmbedder.GetCache())
+	}
+	ams.statsHandler = NewStatsHandler(statsCollector, config.Storage.VectorStore.Dimensions)
+
 	// Register MCP tools
 	if err := ams.registerTools(); err != nil {
+		if closeErr := storage.Close(); closeErr != nil {
+			log.Printf("Error closing storage: %v", closeErr)
+		}
 		return nil, fmt.Errorf("failed to register tools: %w", err)
 	}
 
@@ -137,9 +171,12 @@ func (ams *AgenticMemoryServer) Run(ctx context.Context, transport mcp.Transport
 	return ams.server.Run(ctx, transport)
 }
 
-// RunHTTP starts the server as an HTTP handler
-func (ams *AgenticMemoryServer) RunHTTP(addr string) error {
-	ctx := context.Background()
+// RunHTTP serves the MCP server over HTTP until ctx is done or the process is interrupted or
+// terminated, then shuts the listener down and closes storage so its logs are compacted
+func (ams *AgenticMemoryServer) RunHTTP(ctx context.Context, addr string) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	if err := ams.Start(ctx); err != nil {
 		return err
 	}
@@ -147,9 +184,37 @@ func (ams *AgenticMemoryServer) RunHTTP(addr string) error {
 	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
 		return ams.server
 	}, nil)
+	httpServer := &http.Server{Addr: addr, Handler: handler}
 
+	served := make(chan error, 1)
+	go func() {
+		served <- httpServer.ListenAndServe()
+	}()
 	log.Printf("Starting HTTP server on %s", addr)
-	return http.ListenAndServe(addr, handler)
+
+	var err error
+	select {
+	case err = <-served:
+	case <-ctx.Done():
+		log.Printf("Shutting down HTTP server")
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), ams.config.Server.Timeout)
+		defer cancel()
+		err = httpServer.Shutdown(shutdownCtx)
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+	if err != nil {
+		err = fmt.Errorf("http server failed: %w", err)
+	}
+
+	if stopErr := ams.Stop(ctx); stopErr != nil {
+		log.Printf("Error stopping server: %v", stopErr)
+	}
+	if closeErr := ams.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	return err
 }
 
 // GetServer returns the underlying MCP server for testing
@@ -161,3 +226,17 @@ func (ams *AgenticMemoryServer) GetServer() *mcp.Server {
 func (ams *AgenticMemoryServer) GetConfig() *ServerConfig {
 	return ams.config
 }
+
+// GetStorage returns the multi-view storage shared by the write and recall paths
+func (ams *AgenticMemoryServer) GetStorage() *MultiViewStorage {
+	return ams.storage
+}
+
+// Close releases the storage backends; the server cannot be restarted afterwards.
+// Calls after the first return the first call's result
+func (ams *AgenticMemoryServer) Close() error {
+	ams.closeOnce.Do(func() {
+		ams.closeErr = ams.storage.Close()
+	})
+	return ams.closeErr
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"os"
 	"testing"
 	"time"
 
@@ -155,6 +156,159 @@ func TestAgenticMemoryServerConcurrency(t *testing.T) {
 	})
 }
 
+func TestAgenticMemoryServerStorage(t *testing.T) {
+	Convey("Given a server backed by file storage", t, func() {
+		config := DefaultServerConfig()
+		config.Storage.DataDir = t.TempDir()
+		config.Storage.VectorStore.Provider = "file"
+		config.Storage.GraphStore.Provider = "file"
+		config.Storage.SearchIndex.Provider = "file"
+		config.Storage.VectorStore.Dimensions = 128
+
+		server, err := NewAgenticMemoryServer(config)
+		So(err, ShouldBeNil)
+
+		ctx := context.Background()
+		_, _, err = server.writeHandler.HandleWrite(ctx, nil, WriteArgs{
+			Content: "Photosynthesis converts sunlight into chemical energy in plants.",
+			Source:  "biology-notes",
+		})
+		So(err, ShouldBeNil)
+
+		Convey("When recalling through the same server", func() {
+			_, result, err := server.recallHandler.HandleRecall(ctx, nil, RecallArgs{Query: "photosynthesis sunlight"})
+
+			Convey("Then the written memory should be returned", func() {
+				So(err, ShouldBeNil)
+				So(len(result.Evidence), ShouldBeGreaterThan, 0)
+				So(result.Evidence[0].Content, ShouldContainSubstring, "Photosynthesis")
+			})
+		})
+
+		Convey("When the server is restarted on the same data directory", func() {
+			So(server.Close(), ShouldBeNil)
+
+			restarted, err := NewAgenticMemoryServer(config)
+			So(err, ShouldBeNil)
+			defer restarted.Close()
+
+			_, result, err := restarted.recallHandler.HandleRecall(ctx, nil, RecallArgs{Query: "photosynthesis sunlight"})
+
+			Convey("Then the memory should still be recallable", func() {
+				So(err, ShouldBeNil)
+				So(len(result.Evidence), ShouldBeGreaterThan, 0)
+				So(result.Evidence[0].Content, ShouldContainSubstring, "Photosynthesis")
+			})
+		})
+	})
+
+	Convey("Given a server backed by file storage serving HTTP", t, func() {
+		config := DefaultServerConfig()
+		config.Storage.DataDir = t.TempDir()
+		config.Storage.VectorStore.Provider = "file"
+		config.Storage.GraphStore.Provider = "file"
+		config.Storage.SearchIndex.Provider = "file"
+		config.Storage.VectorStore.Dimensions = 128
+
+		server, err := NewAgenticMemoryServer(config)
+		So(err, ShouldBeNil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		_, _, err = server.writeHandler.HandleWrite(ctx, nil, WriteArgs{
+			Content: "Photosynthesis converts sunlight into chemical energy in plants.",
+			Source:  "biology-notes",
+		})
+		So(err, ShouldBeNil)
+
+		served := make(chan error, 1)
+		go func() {
+			served <- server.RunHTTP(ctx, "127.0.0.1:0")
+		}()
+
+		Convey("When its context is cancelled", func() {
+			time.Sleep(50 * time.Millisecond)
+			cancel()
+
+			var runErr error
+			select {
+			case runErr = <-served:
+			case <-time.After(5 * time.Second):
+				runErr = context.DeadlineExceeded
+			}
+
+			Convey("Then it should shut down cleanly and close storage", func() {
+				So(runErr, ShouldBeNil)
+				So(server.IsRunning(), ShouldBeFalse)
+				So(server.GetStorage().graphStore.Health(context.Background()), ShouldNotBeNil)
+				So(server.Close(), ShouldBeNil)
+
+				restarted, err := NewAgenticMemoryServer(config)
+				So(err, ShouldBeNil)
+				defer restarted.Close()
+				_, result, err := restarted.recallHandler.HandleRecall(context.Background(), nil, RecallArgs{Query: "photosynthesis sunlight"})
+				So(err, ShouldBeNil)
+				So(len(result.Evidence), ShouldBeGreaterThan, 0)
+			})
+		})
+	})
+
+	Convey("Given a server asked to listen on an unusable address", t, func() {
+		server, err := NewAgenticMemoryServer(DefaultServerConfig())
+		So(err, ShouldBeNil)
+
+		Convey("When serving HTTP", func() {
+			err := server.RunHTTP(context.Background(), "127.0.0.1:-1")
+
+			Convey("Then it should return the listen error and still close storage", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "http server failed")
+				So(server.IsRunning(), ShouldBeFalse)
+				So(server.GetStorage().graphStore.Health(context.Background()), ShouldNotBeNil)
+			})
+		})
+	})
+
+	Convey("Given a file-backed config with an unknown duplicate policy", t, func() {
+		config := DefaultServerConfig()
+		config.Storage.DataDir = t.TempDir()
+		config.Storage.VectorStore.Provider = "file"
+		config.Storage.GraphStore.Provider = "file"
+		config.Storage.SearchIndex.Provider = "file"
+		config.Storage.VectorStore.Dimensions = 128
+		config.Processing.DuplicatePolicy = "unknown"
+
+		Convey("When creating the server", func() {
+			server, err := NewAgenticMemoryServer(config)
+
+			Convey("Then it should fail before storage is opened", func() {
+				So(err, ShouldNotBeNil)
+				So(server, ShouldBeNil)
+				So(err.Error(), ShouldContainSubstring, "unsupported duplicate policy")
+
+				entries, err := os.ReadDir(config.Storage.DataDir)
+				So(err, ShouldBeNil)
+				So(entries, ShouldBeEmpty)
+			})
+		})
+	})
+
+	Convey("Given a config with an unknown storage provider", t, func() {
+		config := DefaultServerConfig()
+		config.Storage.VectorStore.Provider = "unknown"
+
+		Convey("When creating the server", func() {
+			server, err := NewAgenticMemoryServer(config)
+
+			Convey("Then it should return a storage error", func() {
+				So(err, ShouldNotBeNil)
+				So(server, ShouldBeNil)
+				So(err.Error(), ShouldContainSubstring, "failed to create storage")
+			})
+		})
+	})
+}
+
 // Benchmark tests
 func BenchmarkNewAgenticMemoryServer(b *testing.B) {
 	config := DefaultServerConfig()
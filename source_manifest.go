@@ -0,0 +1,103 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+)
+
+// chunkContentHash addresses a chunk by its exact content
+func chunkContentHash(content string) string {
+	hash := sha256.Sum256([]byte(content))
+	return fmt.Sprintf("%x", hash)
+}
+
+// contentChunkID derives the ID of a re-ingested chunk from its source and content hash, so
+// the same text from the same source always gets the same ID. The occurrence tells apart
+// chunks of one document that repeat the same text.
+func contentChunkID(source, hash string, occurrence int) string {
+	if occurrence == 0 {
+		return fmt.Sprintf("%s_chunk_%s", source, hash[:16])
+	}
+	return fmt.Sprintf("%s_chunk_%s_%d", source, hash[:16], occurrence)
+}
+
+// sourceManifest lists the chunks a source's last re-ingest left in memory by content hash.
+// Text a re-ingest merged into or rejected as a duplicate of another memory is listed with
+// an empty chunk ID: it is unchanged if it appears again, and nothing is deleted if it vanishes.
+type sourceManifest struct {
+	chunks map[string][]string // Content hash -> chunk IDs, one per occurrence in the document
+}
+
+// newSourceManifest creates an empty manifest
+func newSourceManifest() *sourceManifest {
+	return &sourceManifest{chunks: make(map[string][]string)}
+}
+
+// add lists a chunk's content under its ID
+func (m *sourceManifest) add(hash, chunkID string) {
+	m.chunks[hash] = append(m.chunks[hash], chunkID)
+}
+
+// sourceDiff is how a re-ingested document's chunks compare with the source's manifest
+type sourceDiff struct {
+	added     []*Chunk        // Chunks with text the manifest does not list, to be stored
+	unchanged []string        // IDs of stored chunks whose text the document still contains
+	removed   map[string]bool // IDs of stored chunks whose text the document no longer contains
+	manifest  *sourceManifest // The manifest once the added chunks are stored
+	hashes    map[*Chunk]string
+}
+
+// diff matches a document's chunks against the manifest by content hash. Unchanged chunks
+// keep the IDs they were stored under and added chunks get content-addressed IDs. exists
+// reports whether a listed chunk is still stored, so chunks deleted since the manifest was
+// made are stored again rather than assumed present.
+func (m *sourceManifest) diff(source string, chunks []*Chunk, exists func(chunkID string) (bool, error)) (*sourceDiff, error) {
+	diff := &sourceDiff{
+		removed:  make(map[string]bool),
+		manifest: newSourceManifest(),
+		hashes:   make(map[*Chunk]string, len(chunks)),
+	}
+
+	occurrences := make(map[string]int)
+	for _, chunk := range chunks {
+		hash := chunkContentHash(chunk.Content)
+		occurrence := occurrences[hash]
+		occurrences[hash]++
+
+		if listed := m.chunks[hash]; occurrence < len(listed) {
+			id := listed[occurrence]
+			stored := id == ""
+			if !stored {
+				var err error
+				if stored, err = exists(id); err != nil {
+					return nil, fmt.Errorf("failed to check chunk %s: %w", id, err)
+				}
+			}
+			if stored {
+				diff.manifest.add(hash, id)
+				if id != "" {
+					diff.unchanged = append(diff.unchanged, id)
+				}
+				continue
+			}
+		}
+
+		chunk.ID = contentChunkID(source, hash, occurrence)
+		diff.hashes[chunk] = hash
+		diff.added = append(diff.added, chunk)
+	}
+
+	// Whatever the document no longer contains goes, unless an added chunk takes over its ID
+	for hash, ids := range m.chunks {
+		for i, id := range ids {
+			if id != "" && i >= occurrences[hash] {
+				diff.removed[id] = true
+			}
+		}
+	}
+	for _, chunk := range diff.added {
+		delete(diff.removed, chunk.ID)
+	}
+
+	return diff, nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSourceManifestDiff(t *testing.T) {
+	Convey("Given a manifest of a stored document", t, func() {
+		kept, dropped, merged := chunkContentHash("kept"), chunkContentHash("dropped"), chunkContentHash("merged")
+		manifest := newSourceManifest()
+		manifest.add(kept, "doc_kept")
+		manifest.add(dropped, "doc_dropped")
+		manifest.add(merged, "")
+
+		stored := map[string]bool{"doc_kept": true, "doc_dropped": true}
+		exists := func(id string) (bool, error) { return stored[id], nil }
+
+		chunks := func(contents ...string) []*Chunk {
+			var chunks []*Chunk
+			for i, content := range contents {
+				chunks = append(chunks, NewChunk(contentChunkID("tmp", chunkContentHash(content), i), content, "doc"))
+			}
+			return chunks
+		}
+
+		Convey("When the document changes", func() {
+			diff, err := manifest.diff("doc", chunks("kept", "merged", "new"), exists)
+
+			Convey("Then unchanged text keeps its ID, new text is added and vanished text removed", func() {
+				So(err, ShouldBeNil)
+				So(diff.unchanged, ShouldResemble, []string{"doc_kept"})
+				So(diff.added, ShouldHaveLength, 1)
+				So(diff.added[0].ID, ShouldEqual, contentChunkID("doc", chunkContentHash("new"), 0))
+				So(diff.hashes[diff.added[0]], ShouldEqual, chunkContentHash("new"))
+				So(diff.removed, ShouldResemble, map[string]bool{"doc_dropped": true})
+			})
+
+			Convey("Then the new manifest lists only what is left", func() {
+				So(diff.manifest.chunks, ShouldResemble, map[string][]string{kept: {"doc_kept"}, merged: {""}})
+			})
+		})
+
+		Convey("When the document repeats a paragraph", func() {
+			diff, err := manifest.diff("doc", chunks("kept", "kept"), exists)
+
+			Convey("Then the repeat is added under its own ID", func() {
+				So(err, ShouldBeNil)
+				So(diff.unchanged, ShouldResemble, []string{"doc_kept"})
+				So(diff.added, ShouldHaveLength, 1)
+				So(diff.added[0].ID, ShouldEqual, contentChunkID("doc", kept, 1))
+				So(diff.added[0].ID, ShouldNotEqual, contentChunkID("doc", kept, 0))
+			})
+		})
+
+		Convey("When a listed chunk was deleted since", func() {
+			delete(stored, "doc_kept")
+			manifest.chunks[kept] = []string{contentChunkID("doc", kept, 0)}
+			diff, err := manifest.diff("doc", chunks("kept", "dropped"), exists)
+
+			Convey("Then it is stored again rather than assumed present, and not removed", func() {
+				So(err, ShouldBeNil)
+				So(diff.unchanged, ShouldResemble, []string{"doc_dropped"})
+				So(diff.added, ShouldHaveLength, 1)
+				So(diff.added[0].ID, ShouldEqual, contentChunkID("doc", kept, 0))
+				So(diff.removed, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When checking a chunk fails", func() {
+			_, err := manifest.diff("doc", chunks("kept"), func(string) (bool, error) {
+				return false, errors.New("unavailable")
+			})
+
+			Convey("Then the diff should fail", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "doc_kept")
+			})
+		})
+	})
+}
@@ -0,0 +1,397 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// StatsCollector gathers memory statistics from live storage and runtime metrics
+type StatsCollector struct {
+	storage        *MultiViewStorage
+	queryMetrics   *QueryMetrics
+	embeddingCache *EmbeddingCache
+	startTime      time.Time
+	config         *StatsCollectorConfig
+}
+
+// StatsCollectorConfig holds configuration for stats collection
+type StatsCollectorConfig struct {
+	TopEntities   int `json:"top_entities"`
+	RecentUpdates int `json:"recent_updates"`
+}
+
+// fileBackedStore is implemented by stores that persist to a snapshot file and its log
+type fileBackedStore interface {
+	FilePath() string
+}
+
+// NewStatsCollector creates a new StatsCollector with default configuration
+func NewStatsCollector(storage *MultiViewStorage) *StatsCollector {
+	return NewStatsCollectorWithConfig(storage, nil)
+}
+
+// NewStatsCollectorWithConfig creates a new StatsCollector with custom configuration
+func NewStatsCollectorWithConfig(storage *MultiViewStorage, config *StatsCollectorConfig) *StatsCollector {
+	cfg := StatsCollectorConfig{}
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.TopEntities <= 0 {
+		cfg.TopEntities = 10
+	}
+	if cfg.RecentUpdates <= 0 {
+		cfg.RecentUpdates = 10
+	}
+
+	return &StatsCollector{
+		storage:   storage,
+		startTime: time.Now(),
+		config:    &cfg,
+	}
+}
+
+// SetQueryMetrics sets the recall metrics reported as performance stats
+func (sc *StatsCollector) SetQueryMetrics(metrics *QueryMetrics) {
+	sc.queryMetrics = metrics
+}
+
+// SetEmbeddingCache sets the embedding cache whose hit rate is reported
+func (sc *StatsCollector) SetEmbeddingCache(cache *EmbeddingCache) {
+	sc.embeddingCache = cache
+}
+
+// Collect builds a StatsResponse from the current state of every store
+func (sc *StatsCollector) Collect(ctx context.Context) (*StatsResponse, error) {
+	if sc.storage == nil {
+		return nil, fmt.Errorf("storage is not configured")
+	}
+
+	response := NewStatsResponse()
+
+	storageStats, err := sc.storage.GetStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage stats: %w", err)
+	}
+	response.TotalMemories = int(storageStats.DocumentCount)
+	response.GraphNodes = int(storageStats.NodeCount)
+	response.GraphEdges = int(storageStats.EdgeCount)
+
+	docs, err := sc.storage.searchIndex.ListDocuments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+
+	response.StorageUsage = sc.collectStorageUsage(ctx, storageStats)
+	response.PerformanceStats = sc.collectPerformance()
+	response.MemoryStats = sc.collectMemoryStats(docs, time.Now())
+	response.MemoryStats.TopEntities = sc.collectTopEntities(ctx)
+	response.SystemHealth = sc.collectHealth(ctx)
+
+	return response, nil
+}
+
+// collectStorageUsage reports per-store counts and on-disk sizes for file backends
+func (sc *StatsCollector) collectStorageUsage(ctx context.Context, stats *StorageStats) StorageUsageStats {
+	usage := StorageUsageStats{
+		VectorStore: map[string]interface{}{"count": stats.VectorCount},
+		GraphStore:  map[string]interface{}{"nodes": stats.NodeCount, "edges": stats.EdgeCount},
+		SearchIndex: map[string]interface{}{"documents": stats.DocumentCount},
+	}
+
+	if nodesByType := sc.countNodesByType(ctx); len(nodesByType) > 0 {
+		usage.GraphStore["nodes_by_type"] = nodesByType
+	}
+	if size, err := sc.storage.searchIndex.IndexSize(ctx); err == nil {
+		usage.SearchIndex["content_bytes"] = size
+	}
+
+	usage.TotalSize += addFileUsage(usage.VectorStore, sc.storage.vectorStore)
+	usage.TotalSize += addFileUsage(usage.GraphStore, sc.storage.graphStore)
+	usage.TotalSize += addFileUsage(usage.SearchIndex, sc.storage.searchIndex)
+
+	return usage
+}
+
+// addFileUsage records the file path of a file-backed store and the size of its snapshot and
+// log, returning the size
+func addFileUsage(usage map[string]interface{}, store interface{}) int64 {
+	fileStore, ok := store.(fileBackedStore)
+	if !ok {
+		usage["backend"] = "memory"
+		return 0
+	}
+
+	usage["backend"] = "file"
+	usage["file"] = fileStore.FilePath()
+
+	var size int64
+	for _, path := range storeLogFiles(fileStore.FilePath()) {
+		if info, err := os.Stat(path); err == nil {
+			size += info.Size()
+		}
+	}
+	usage["size_bytes"] = size
+
+	return size
+}
+
+// countNodesByType counts graph nodes of each supported type
+func (sc *StatsCollector) countNodesByType(ctx context.Context) map[string]int {
+	counts := make(map[string]int)
+	for _, nodeType := range allNodeTypes {
+		nodes, err := sc.storage.graphStore.FindNodesByType(ctx, nodeType, nil)
+		if err != nil || len(nodes) == 0 {
+			continue
+		}
+		counts[string(nodeType)] = len(nodes)
+	}
+	return counts
+}
+
+// collectPerformance reports recall latency and embedding cache effectiveness
+func (sc *StatsCollector) collectPerformance() PerformanceStats {
+	var stats PerformanceStats
+
+	if sc.queryMetrics != nil {
+		snapshot := sc.queryMetrics.Snapshot()
+		stats.AvgQueryTime = snapshot.AvgQueryTime
+		stats.RequestsPerSec = snapshot.RequestsPerSec
+		stats.ErrorRate = snapshot.ErrorRate
+	}
+
+	if sc.embeddingCache != nil {
+		cacheStats := sc.embeddingCache.Stats()
+		hits, _ := cacheStats["hits"].(int64)
+		misses, _ := cacheStats["misses"].(int64)
+		if hits+misses > 0 {
+			stats.CacheHitRate = float64(hits) / float64(hits+misses)
+		}
+	}
+
+	return stats
+}
+
+// collectMemoryStats breaks stored memories down by type, source, confidence and age
+func (sc *StatsCollector) collectMemoryStats(docs []IndexDocument, now time.Time) MemoryStats {
+	stats := MemoryStats{
+		ByType:        make(map[string]int),
+		BySource:      make(map[string]int),
+		ByConfidence:  make(map[string]int),
+		ByAge:         make(map[string]int),
+		TopEntities:   []EntityStat{},
+		RecentUpdates: []RecentUpdate{},
+	}
+
+	for _, doc := range docs {
+		memoryType := "chunk"
+		if value, ok := doc.Metadata["type"].(string); ok && value != "" {
+			memoryType = value
+		}
+		stats.ByType[memoryType]++
+
+		source := "unknown"
+		if value, ok := doc.Metadata["source"].(string); ok && value != "" {
+			source = value
+		}
+		stats.BySource[source]++
+
+		stats.ByConfidence[confidenceBucket(memoryConfidence(doc.Metadata))]++
+
+		timestamp, hasTimestamp := metadataTime(doc.Metadata, "timestamp")
+		stats.ByAge[ageBucket(timestamp, hasTimestamp, now)]++
+
+		if update, ok := latestUpdate(doc); ok {
+			update.Source = source
+			stats.RecentUpdates = append(stats.RecentUpdates, update)
+		}
+	}
+
+	sort.Slice(stats.RecentUpdates, func(i, j int) bool {
+		if stats.RecentUpdates[i].Timestamp.Equal(stats.RecentUpdates[j].Timestamp) {
+			return stats.RecentUpdates[i].MemoryID < stats.RecentUpdates[j].MemoryID
+		}
+		return stats.RecentUpdates[i].Timestamp.After(stats.RecentUpdates[j].Timestamp)
+	})
+	if len(stats.RecentUpdates) > sc.config.RecentUpdates {
+		stats.RecentUpdates = stats.RecentUpdates[:sc.config.RecentUpdates]
+	}
+
+	return stats
+}
+
+// collectTopEntities ranks entity nodes by the number of edges touching them
+func (sc *StatsCollector) collectTopEntities(ctx context.Context) []EntityStat {
+	entities, err := sc.storage.graphStore.FindNodesByType(ctx, EntityNode, nil)
+	if err != nil || len(entities) == 0 {
+		return []EntityStat{}
+	}
+
+	degree := make(map[string]int)
+	for _, edgeType := range allEdgeTypes {
+		edges, err := sc.storage.graphStore.FindEdgesByType(ctx, edgeType, nil)
+		if err != nil {
+			continue
+		}
+		for _, edge := range edges {
+			degree[edge.From]++
+			degree[edge.To]++
+		}
+	}
+
+	top := make([]EntityStat, 0, len(entities))
+	for _, entity := range entities {
+		name, _ := entity.Properties["name"].(string)
+		if name == "" {
+			name = entity.ID
+		}
+		entityType, _ := entity.Properties["type"].(string)
+		top = append(top, EntityStat{
+			Name:  name,
+			Count: degree[entity.ID],
+			Type:  entityType,
+		})
+	}
+
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count == top[j].Count {
+			return top[i].Name < top[j].Name
+		}
+		return top[i].Count > top[j].Count
+	})
+	if len(top) > sc.config.TopEntities {
+		top = top[:sc.config.TopEntities]
+	}
+
+	return top
+}
+
+// collectHealth checks each store and derives an overall status
+func (sc *StatsCollector) collectHealth(ctx context.Context) SystemHealthStats {
+	now := time.Now()
+	health := SystemHealthStats{
+		Uptime:          now.Sub(sc.startTime),
+		LastHealthCheck: now,
+		ComponentHealth: make(map[string]string),
+	}
+
+	components := []struct {
+		name  string
+		check func(context.Context) error
+	}{
+		{"vector_store", sc.storage.vectorStore.Health},
+		{"graph_store", sc.storage.graphStore.Health},
+		{"search_index", sc.storage.searchIndex.Health},
+	}
+
+	unhealthy := 0
+	for _, component := range components {
+		if err := component.check(ctx); err != nil {
+			unhealthy++
+			health.ComponentHealth[component.name] = "unhealthy"
+			health.Alerts = append(health.Alerts, Alert{
+				Level:     "error",
+				Message:   err.Error(),
+				Component: component.name,
+				Timestamp: now,
+			})
+			continue
+		}
+		health.ComponentHealth[component.name] = "healthy"
+	}
+
+	switch {
+	case unhealthy == 0:
+		health.Status = "healthy"
+	case unhealthy < len(components):
+		health.Status = "degraded"
+	default:
+		health.Status = "unhealthy"
+	}
+
+	return health
+}
+
+// GetStartTime returns when the collector began measuring uptime
+func (sc *StatsCollector) GetStartTime() time.Time {
+	return sc.startTime
+}
+
+// GetConfig returns the current configuration
+func (sc *StatsCollector) GetConfig() *StatsCollectorConfig {
+	return sc.config
+}
+
+// confidenceBucket maps a confidence score to a coarse label
+func confidenceBucket(confidence float64) string {
+	switch {
+	case confidence >= 0.8:
+		return "high"
+	case confidence >= 0.5:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// ageBucket maps a memory timestamp to a coarse age label
+func ageBucket(timestamp time.Time, ok bool, now time.Time) string {
+	if !ok {
+		return "unknown"
+	}
+
+	age := now.Sub(timestamp)
+	switch {
+	case age < 24*time.Hour:
+		return "last_24h"
+	case age < 7*24*time.Hour:
+		return "last_7d"
+	case age < 30*24*time.Hour:
+		return "last_30d"
+	default:
+		return "older"
+	}
+}
+
+// metadataTime parses an RFC3339 timestamp stored under key
+func metadataTime(metadata map[string]interface{}, key string) (time.Time, bool) {
+	value, ok := metadata[key].(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// latestUpdate returns the most recent write, pin or decay recorded on a memory; later operations win ties
+func latestUpdate(doc IndexDocument) (RecentUpdate, bool) {
+	var update RecentUpdate
+	found := false
+
+	for _, candidate := range []struct {
+		operation string
+		key       string
+	}{
+		{"write", "timestamp"},
+		{"pin", "pinned_at"},
+		{"decay", "last_decayed_at"},
+	} {
+		t, ok := metadataTime(doc.Metadata, candidate.key)
+		if !ok || (found && t.Before(update.Timestamp)) {
+			continue
+		}
+		update = RecentUpdate{
+			MemoryID:  doc.ID,
+			Operation: candidate.operation,
+			Timestamp: t,
+		}
+		found = true
+	}
+
+	return update, found
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestStatsCollector(t *testing.T) {
+	Convey("Given a StatsCollector over stored memories", t, func() {
+		ctx := context.Background()
+		manager, writer, storage := newTestMemoryManager()
+		collector := NewStatsCollector(storage)
+		now := time.Now()
+
+		eiffel := writeTestMemory(writer, "The Eiffel Tower is located in Paris and was completed in 1889.", "landmarks", now)
+		writeTestMemory(writer, "Photosynthesis converts sunlight into chemical energy in plants.", "biology", now.Add(-60*24*time.Hour))
+
+		Convey("When collecting stats", func() {
+			response, err := collector.Collect(ctx)
+			So(err, ShouldBeNil)
+
+			Convey("Then counts should reflect live storage", func() {
+				So(response.TotalMemories, ShouldEqual, 2)
+				So(response.GraphNodes, ShouldBeGreaterThan, 0)
+				So(response.StorageUsage.SearchIndex["documents"], ShouldEqual, int64(2))
+				So(response.StorageUsage.VectorStore["backend"], ShouldEqual, "memory")
+				So(response.StorageUsage.TotalSize, ShouldEqual, 0)
+			})
+
+			Convey("Then memories should be broken down by source, type, confidence and age", func() {
+				stats := response.MemoryStats
+				So(stats.BySource, ShouldResemble, map[string]int{"landmarks": 1, "biology": 1})
+				So(stats.ByType["chunk"], ShouldEqual, 2)
+				So(stats.ByAge, ShouldResemble, map[string]int{"last_24h": 1, "older": 1})
+
+				total := 0
+				for _, count := range stats.ByConfidence {
+					total += count
+				}
+				So(total, ShouldEqual, 2)
+			})
+
+			Convey("Then recent updates should be newest first", func() {
+				So(len(response.MemoryStats.RecentUpdates), ShouldEqual, 2)
+				So(response.MemoryStats.RecentUpdates[0].MemoryID, ShouldEqual, eiffel)
+				So(response.MemoryStats.RecentUpdates[0].Operation, ShouldEqual, "write")
+				So(response.MemoryStats.RecentUpdates[0].Source, ShouldEqual, "landmarks")
+			})
+
+			Convey("Then every component should be healthy", func() {
+				So(response.SystemHealth.Status, ShouldEqual, "healthy")
+				So(response.SystemHealth.ComponentHealth["graph_store"], ShouldEqual, "healthy")
+				So(response.SystemHealth.Alerts, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When a memory has been pinned", func() {
+			options := NewManageOptions("pin")
+			options.MemoryIDs = []string{eiffel}
+			_, err := manager.Manage(ctx, options)
+			So(err, ShouldBeNil)
+
+			response, err := collector.Collect(ctx)
+			So(err, ShouldBeNil)
+
+			Convey("Then the pin should be its latest update", func() {
+				So(response.MemoryStats.RecentUpdates[0].MemoryID, ShouldEqual, eiffel)
+				So(response.MemoryStats.RecentUpdates[0].Operation, ShouldEqual, "pin")
+			})
+		})
+
+		Convey("When entities are linked in the graph", func() {
+			for _, node := range []*Node{NewNode("entity_paris", EntityNode), NewNode("entity_france", EntityNode), NewNode("entity_seine", EntityNode)} {
+				node.Properties["name"] = node.ID[len("entity_"):]
+				node.Properties["type"] = "location"
+				So(storage.graphStore.CreateNode(ctx, node), ShouldBeNil)
+			}
+			So(storage.graphStore.CreateEdge(ctx, NewEdge("e1", "entity_paris", "entity_france", PartOf, 1.0)), ShouldBeNil)
+			So(storage.graphStore.CreateEdge(ctx, NewEdge("e2", "entity_seine", "entity_paris", RelatedTo, 1.0)), ShouldBeNil)
+
+			response, err := collector.Collect(ctx)
+			So(err, ShouldBeNil)
+
+			Convey("Then top entities should be ranked by degree", func() {
+				top := response.MemoryStats.TopEntities
+				So(len(top), ShouldBeGreaterThanOrEqualTo, 3)
+				So(top[0], ShouldResemble, EntityStat{Name: "paris", Count: 2, Type: "location"})
+				So(top[1].Count, ShouldEqual, 1)
+				So(response.StorageUsage.GraphStore["nodes_by_type"].(map[string]int)["Entity"], ShouldBeGreaterThanOrEqualTo, 3)
+			})
+		})
+
+		Convey("When a store is unhealthy", func() {
+			storage.graphStore.(*MockGraphStore).SetHealthy(false)
+			response, err := collector.Collect(ctx)
+			So(err, ShouldBeNil)
+
+			Convey("Then the system should be reported as degraded", func() {
+				So(response.SystemHealth.Status, ShouldEqual, "degraded")
+				So(response.SystemHealth.ComponentHealth["graph_store"], ShouldEqual, "unhealthy")
+				So(response.SystemHealth.ComponentHealth["vector_store"], ShouldEqual, "healthy")
+				So(len(response.SystemHealth.Alerts), ShouldEqual, 1)
+				So(response.SystemHealth.Alerts[0].Component, ShouldEqual, "graph_store")
+			})
+		})
+
+		Convey("When query metrics and an embedding cache are attached", func() {
+			metrics := NewQueryMetrics()
+			metrics.Record(10*time.Millisecond, nil)
+			metrics.Record(30*time.Millisecond, nil)
+			collector.SetQueryMetrics(metrics)
+
+			cache, err := NewEmbeddingCache(t.TempDir(), 10)
+			So(err, ShouldBeNil)
+			So(cache.Put("key", []float32{1}), ShouldBeNil)
+			cache.Get("key")
+			cache.Get("missing")
+			collector.SetEmbeddingCache(cache)
+
+			response, err := collector.Collect(ctx)
+			So(err, ShouldBeNil)
+
+			Convey("Then performance stats should reflect them", func() {
+				So(response.PerformanceStats.AvgQueryTime, ShouldEqual, 20*time.Millisecond)
+				So(response.PerformanceStats.CacheHitRate, ShouldAlmostEqual, 0.5, 0.001)
+			})
+		})
+	})
+
+	Convey("Given a StatsCollector over file-backed storage", t, func() {
+		ctx := context.Background()
+		config := DefaultServerConfig().Storage
+		config.DataDir = t.TempDir()
+		config.VectorStore.Provider = "file"
+		config.GraphStore.Provider = "file"
+		config.SearchIndex.Provider = "file"
+		storage, err := NewStorageFromConfig(&config)
+		So(err, ShouldBeNil)
+		defer storage.Close()
+
+		chunk := NewChunk("stats_chunk", "Durable memories have a size on disk", "stats-test")
+		chunk.Embedding = []float32{0.1, 0.2, 0.3}
+		chunk.SetMetadata("source", chunk.Source)
+		So(storage.StoreChunk(ctx, chunk), ShouldBeNil)
+
+		response, err := NewStatsCollector(storage).Collect(ctx)
+		So(err, ShouldBeNil)
+
+		Convey("Then on-disk sizes should be reported per store", func() {
+			So(response.StorageUsage.VectorStore["backend"], ShouldEqual, "file")
+			So(response.StorageUsage.VectorStore["size_bytes"], ShouldBeGreaterThan, 0)
+			So(response.StorageUsage.SearchIndex["size_bytes"], ShouldBeGreaterThan, 0)
+			So(response.StorageUsage.TotalSize, ShouldBeGreaterThan, 0)
+			So(response.MemoryStats.BySource["stats-test"], ShouldEqual, 1)
+		})
+	})
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// StatsHandler handles memory statistics requests
+type StatsHandler struct {
+	collector *StatsCollector
+	config    *StatsHandlerConfig
+}
+
+// StatsHandlerConfig holds configuration for the stats handler
+type StatsHandlerConfig struct {
+	VectorDimensions  int           `json:"vector_dimensions"`
+	ProcessingTimeout time.Duration `json:"processing_timeout"`
+}
+
+// NewStatsHandler creates a new StatsHandler instance
+func NewStatsHandler(collector *StatsCollector, vectorDimensions int) *StatsHandler {
+	config := &StatsHandlerConfig{
+		VectorDimensions:  vectorDimensions,
+		ProcessingTimeout: 10 * time.Second,
+	}
+
+	return &StatsHandler{
+		collector: collector,
+		config:    config,
+	}
+}
+
+// HandleStats collects live statistics and converts them to a StatsResult
+func (sh *StatsHandler) HandleStats(ctx context.Context, req *mcp.CallToolRequest, args StatsArgs) (*mcp.CallToolResult, StatsResult, error) {
+	log.Printf("Handling stats request: includePerformance=%t, includeStorage=%t", args.IncludePerformance, args.IncludeStorage)
+
+	statsCtx, cancel := context.WithTimeout(ctx, sh.config.ProcessingTimeout)
+	defer cancel()
+
+	response, err := sh.collector.Collect(statsCtx)
+	if err != nil {
+		return nil, StatsResult{}, fmt.Errorf("stats collection failed: %w", err)
+	}
+
+	result := sh.convertResponse(response, args)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: fmt.Sprintf("Memory system contains %d memories, %d graph nodes, %d graph edges (%s)",
+					result.TotalMemories, result.GraphNodes, result.GraphEdges, result.SystemHealth.Status),
+			},
+		},
+	}, result, nil
+}
+
+// convertResponse flattens a StatsResponse, detailing storage and performance only when requested
+func (sh *StatsHandler) convertResponse(response *StatsResponse, args StatsArgs) StatsResult {
+	result := StatsResult{
+		TotalMemories:    response.TotalMemories,
+		GraphNodes:       response.GraphNodes,
+		GraphEdges:       response.GraphEdges,
+		VectorDimensions: sh.config.VectorDimensions,
+		StorageUsage: map[string]interface{}{
+			"vector_store": response.StorageUsage.VectorStore,
+			"graph_store":  response.StorageUsage.GraphStore,
+			"search_index": response.StorageUsage.SearchIndex,
+		},
+		PerformanceStats: map[string]interface{}{},
+		MemoryStats:      response.MemoryStats,
+		SystemHealth:     response.SystemHealth,
+	}
+
+	if args.IncludeStorage {
+		result.StorageUsage["total_size_bytes"] = response.StorageUsage.TotalSize
+	}
+
+	if args.IncludePerformance {
+		performance := response.PerformanceStats
+		result.PerformanceStats = map[string]interface{}{
+			"avg_query_time":      performance.AvgQueryTime.String(),
+			"cache_hit_rate":      performance.CacheHitRate,
+			"requests_per_second": performance.RequestsPerSec,
+			"error_rate":          performance.ErrorRate,
+			"uptime":              response.SystemHealth.Uptime.String(),
+		}
+	}
+
+	return result
+}
+
+// GetConfig returns the current configuration
+func (sh *StatsHandler) GetConfig() *StatsHandlerConfig {
+	return sh.config
+}
@@ -55,6 +55,37 @@ type PageRankOptions struct {
 	Seeds       []string  `json:"seeds,omitempty"`
 }
 
+// CommunityDetectionOptions configures Louvain community detection
+type CommunityDetectionOptions struct {
+	Resolution    float64 `json:"resolution"`     // >1 favors smaller communities, <1 larger ones
+	Seed          int64   `json:"seed"`           // seeds the node visiting order for reproducible results
+	MaxIterations int     `json:"max_iterations"` // passes over the nodes per level
+	MaxLevels     int     `json:"max_levels"`     // aggregation levels
+	MinGain       float64 `json:"min_gain"`       // smallest modularity gain that moves a node
+}
+
+// DefaultCommunityDetectionOptions returns the options used by CommunityDetection
+func DefaultCommunityDetectionOptions() CommunityDetectionOptions {
+	return CommunityDetectionOptions{}.withDefaults()
+}
+
+// withDefaults fills unset options
+func (o CommunityDetectionOptions) withDefaults() CommunityDetectionOptions {
+	if o.Resolution <= 0 {
+		o.Resolution = 1.0
+	}
+	if o.MaxIterations <= 0 {
+		o.MaxIterations = 100
+	}
+	if o.MaxLevels <= 0 {
+		o.MaxLevels = 10
+	}
+	if o.MinGain <= 0 {
+		o.MinGain = 1e-9
+	}
+	return o
+}
+
 // VectorStore interface defines operations for vector similarity search
 type VectorStore interface {
 	// Store stores a vector with associated metadata
@@ -120,9 +151,22 @@ type GraphStore interface {
 	// CommunityDetection performs community detection using Louvain algorithm
 	CommunityDetection(ctx context.Context) ([]Community, error)
 	
+	// CommunityDetectionWithOptions performs Louvain community detection with explicit options
+	CommunityDetectionWithOptions(ctx context.Context, options CommunityDetectionOptions) ([]Community, error)
+	
 	// GetNeighbors returns neighboring nodes of a given node
 	GetNeighbors(ctx context.Context, nodeID string, options GraphTraversalOptions) ([]Node, error)
 	
+	// Version returns a number that changes whenever a node or edge is written, so caches
+	// built from the graph can tell when they are stale
+	Version() uint64
+	
+	// GetNodeEdges returns the edges into and out of a node, ordered by ID
+	GetNodeEdges(ctx context.Context, nodeID string) ([]*Edge, error)
+	
+	// FindEntitiesByName returns the entity nodes named name, ignoring case, ordered by ID
+	FindEntitiesByName(ctx context.Context, name string) ([]*Node, error)
+	
 	// FindEdgesByType finds edges by type with optional filters
 	FindEdgesByType(ctx context.Context, edgeType EdgeType, filters map[string]interface{}) ([]*Edge, error)
 	
@@ -157,11 +201,14 @@ type SearchIndexOptions struct {
 	SortBy     string                 `json:"sort_by,omitempty"`
 	SortOrder  string                 `json:"sort_order,omitempty"`
 	Highlight  bool                   `json:"highlight"`
+	Language   string                 `json:"language,omitempty"` // Picks the query analyzer; empty means English
+	Facets     []FacetRequest         `json:"facets,omitempty"`   // Counted over all matches by SearchWithFacets
 }
 
 // VectorStoreConfig holds vector store specific configuration
 type VectorStoreConfig struct {
 	Provider    string            `json:"provider"`
+	URI         string            `json:"uri"`
 	Dimensions  int               `json:"dimensions"`
 	IndexType   string            `json:"index_type"`
 	Metric      string            `json:"metric"`
@@ -199,6 +246,9 @@ type SearchIndex interface {
 	// Search performs text search with options
 	Search(ctx context.Context, query string, options SearchIndexOptions) ([]SearchResult, error)
 	
+	// SearchWithFacets performs text search and counts the requested facets over every match
+	SearchWithFacets(ctx context.Context, query string, options SearchIndexOptions) (*SearchResponse, error)
+	
 	// Delete removes a document from the index
 	Delete(ctx context.Context, id string) error
 	
@@ -217,6 +267,9 @@ type SearchIndex interface {
 	// DocumentCount returns the total number of documents indexed
 	DocumentCount(ctx context.Context) (int64, error)
 	
+	// ListDocuments returns every indexed document ordered by ID
+	ListDocuments(ctx context.Context) ([]IndexDocument, error)
+	
 	// IndexSize returns the index size
 	IndexSize(ctx context.Context) (int64, error)
 	
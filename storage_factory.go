@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Default file names used by file-backed stores inside the data directory. Snapshots are now
+// binary but keep the names the JSON files had, so existing data directories migrate in place.
+const (
+	vectorStoreFileName = "vectors.json"
+	graphStoreFileName  = "graph.json"
+	searchIndexFileName = "search_index.json"
+)
+
+// NewStorageFromConfig builds a MultiViewStorage with the backends selected by the storage configuration
+func NewStorageFromConfig(config *MultiViewStorageConfig) (*MultiViewStorage, error) {
+	if config == nil {
+		return nil, fmt.Errorf("storage config cannot be nil")
+	}
+
+	vectorStore, err := NewVectorStoreFromConfig(config.VectorStore, config.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vector store: %w", err)
+	}
+
+	graphStore, err := NewGraphStoreFromConfig(config.GraphStore, config.DataDir)
+	if err != nil {
+		vectorStore.Close()
+		return nil, fmt.Errorf("failed to create graph store: %w", err)
+	}
+
+	searchIndex, err := NewSearchIndexFromConfig(config.SearchIndex, config.DataDir)
+	if err != nil {
+		vectorStore.Close()
+		graphStore.Close()
+		return nil, fmt.Errorf("failed to create search index: %w", err)
+	}
+
+	cfg := *config
+	storage := NewMultiViewStorage(vectorStore, graphStore, searchIndex, &cfg)
+
+	// Durable stores get a durable intent log, so writes a crash interrupted are resolved now
+	if cfg.DataDir != "" && anyFileBacked(vectorStore, graphStore, searchIndex) {
+		if err := storage.RecoverWrites(context.Background(), filepath.Join(cfg.DataDir, writeIntentLogName)); err != nil {
+			storage.Close()
+			return nil, fmt.Errorf("failed to recover interrupted writes: %w", err)
+		}
+	}
+
+	return storage, nil
+}
+
+// anyFileBacked reports whether any of the stores persists to disk
+func anyFileBacked(stores ...interface{}) bool {
+	for _, store := range stores {
+		if _, ok := store.(fileBackedStore); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// NewVectorStoreFromConfig creates the vector store for the configured provider
+func NewVectorStoreFromConfig(config VectorStoreConfig, dataDir string) (VectorStore, error) {
+	switch strings.ToLower(config.Provider) {
+	case "mock", "memory":
+		metric, err := ParseVectorMetric(config.Metric)
+		if err != nil {
+			return nil, err
+		}
+		return NewMockVectorStoreWithMetric(metric), nil
+	case "file":
+		path, err := storageFilePath(config.URI, dataDir, vectorStoreFileName)
+		if err != nil {
+			return nil, err
+		}
+		storeConfig, err := fileVectorStoreConfig(config.Metric, config.IndexType, config.Parameters)
+		if err != nil {
+			return nil, err
+		}
+		store := NewFileVectorStoreWithConfig(path, storeConfig)
+		if err := store.Load(); err != nil {
+			return nil, fmt.Errorf("failed to load vector store from %s: %w", path, err)
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unsupported vector store provider: %s", config.Provider)
+	}
+}
+
+// NewGraphStoreFromConfig creates the graph store for the configured provider
+func NewGraphStoreFromConfig(config GraphStoreConfig, dataDir string) (GraphStore, error) {
+	switch strings.ToLower(config.Provider) {
+	case "mock", "memory":
+		return NewMockGraphStore(), nil
+	case "file":
+		path, err := storageFilePath(config.URI, dataDir, graphStoreFileName)
+		if err != nil {
+			return nil, err
+		}
+		store := NewFileGraphStore(path)
+		if err := store.Load(); err != nil {
+			return nil, fmt.Errorf("failed to load graph store from %s: %w", path, err)
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unsupported graph store provider: %s", config.Provider)
+	}
+}
+
+// NewSearchIndexFromConfig creates the search index for the configured provider
+func NewSearchIndexFromConfig(config SearchIndexConfig, dataDir string) (SearchIndex, error) {
+	switch strings.ToLower(config.Provider) {
+	case "mock", "memory":
+		return NewMockSearchIndex(), nil
+	case "file":
+		path, err := storageFilePath(config.URI, dataDir, searchIndexFileName)
+		if err != nil {
+			return nil, err
+		}
+		index := NewFileSearchIndexWithConfig(path, fileSearchIndexConfig(config.Parameters))
+		if err := index.Load(); err != nil {
+			return nil, fmt.Errorf("failed to load search index from %s: %w", path, err)
+		}
+		return index, nil
+	default:
+		return nil, fmt.Errorf("unsupported search index provider: %s", config.Provider)
+	}
+}
+
+// fileSearchIndexConfig reads the optional "bm25_k1" and "bm25_b" parameters, keeping the
+// defaults for anything missing or out of range
+func fileSearchIndexConfig(parameters map[string]interface{}) *FileSearchIndexConfig {
+	config := DefaultFileSearchIndexConfig()
+	if k1, ok := filterNumber(parameters["bm25_k1"]); ok && k1 >= 0 {
+		config.BM25K1 = k1
+	}
+	if b, ok := filterNumber(parameters["bm25_b"]); ok && b >= 0 && b <= 1 {
+		config.BM25B = b
+	}
+	return config
+}
+
+// fileVectorStoreConfig reads the metric, the index type and the optional HNSW parameters
+// "M", "efConstruction" and "efSearch" (or their snake_case forms), keeping the defaults
+// for anything missing or not positive
+func fileVectorStoreConfig(metric, indexType string, parameters map[string]interface{}) (*FileVectorStoreConfig, error) {
+	config := DefaultFileVectorStoreConfig()
+	parsedMetric, err := ParseVectorMetric(metric)
+	if err != nil {
+		return nil, err
+	}
+	config.Metric = parsedMetric
+
+	switch strings.ToLower(indexType) {
+	case "", VectorIndexFlat:
+		config.IndexType = VectorIndexFlat
+	case VectorIndexHNSW:
+		config.IndexType = VectorIndexHNSW
+	default:
+		return nil, fmt.Errorf("unsupported vector index type: %s", indexType)
+	}
+
+	intParameter := func(names ...string) (int, bool) {
+		for _, name := range names {
+			if value, ok := filterNumber(parameters[name]); ok && value >= 1 {
+				return int(value), true
+			}
+		}
+		return 0, false
+	}
+	if m, ok := intParameter("M", "m"); ok {
+		config.M = m
+	}
+	if ef, ok := intParameter("efConstruction", "ef_construction"); ok {
+		config.EfConstruction = ef
+	}
+	if ef, ok := intParameter("efSearch", "ef_search"); ok {
+		config.EfSearch = ef
+	}
+	return config, nil
+}
+
+// storageFilePath resolves a file backend path: an explicit URI wins, otherwise the file lives in dataDir
+func storageFilePath(uri, dataDir, fileName string) (string, error) {
+	if uri != "" {
+		return strings.TrimPrefix(uri, "file://"), nil
+	}
+	if dataDir == "" {
+		return "", fmt.Errorf("file provider requires a data directory or URI")
+	}
+	return filepath.Join(dataDir, fileName), nil
+}
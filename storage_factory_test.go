@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNewStorageFromConfig(t *testing.T) {
+	Convey("Given a storage configuration", t, func() {
+		config := DefaultServerConfig().Storage
+		config.DataDir = t.TempDir()
+
+		Convey("When the providers are in-memory", func() {
+			storage, err := NewStorageFromConfig(&config)
+
+			Convey("Then it should use the mock backends", func() {
+				So(err, ShouldBeNil)
+				_, isMockVector := storage.vectorStore.(*MockVectorStore)
+				_, isMockGraph := storage.graphStore.(*MockGraphStore)
+				_, isMockIndex := storage.searchIndex.(*MockSearchIndex)
+				So(isMockVector, ShouldBeTrue)
+				So(isMockGraph, ShouldBeTrue)
+				So(isMockIndex, ShouldBeTrue)
+			})
+		})
+
+		Convey("When the providers are file-backed", func() {
+			config.VectorStore.Provider = "file"
+			config.GraphStore.Provider = "file"
+			config.SearchIndex.Provider = "file"
+			storage, err := NewStorageFromConfig(&config)
+			So(err, ShouldBeNil)
+
+			chunk := NewChunk("persisted_chunk", "Durable memories survive restarts", "factory-test")
+			chunk.Embedding = []float32{0.1, 0.2, 0.3}
+			So(storage.StoreChunk(context.Background(), chunk), ShouldBeNil)
+			So(storage.Close(), ShouldBeNil)
+
+			Convey("Then a new storage on the same data directory should see the data", func() {
+				reopened, err := NewStorageFromConfig(&config)
+				So(err, ShouldBeNil)
+				defer reopened.Close()
+
+				stats, err := reopened.GetStats(context.Background())
+				So(err, ShouldBeNil)
+				So(stats.VectorCount, ShouldEqual, 1)
+				So(stats.DocumentCount, ShouldEqual, 1)
+				So(stats.NodeCount, ShouldBeGreaterThanOrEqualTo, 0)
+			})
+		})
+
+		Convey("When a file provider has an explicit URI", func() {
+			path := filepath.Join(t.TempDir(), "custom_vectors.json")
+			config.VectorStore.Provider = "file"
+			config.VectorStore.URI = path
+			store, err := NewVectorStoreFromConfig(config.VectorStore, "")
+
+			Convey("Then it should use the URI as the file path", func() {
+				So(err, ShouldBeNil)
+				fileStore, ok := store.(*FileVectorStore)
+				So(ok, ShouldBeTrue)
+				So(fileStore.filePath, ShouldEqual, path)
+			})
+		})
+
+		Convey("When the file vector store asks for an HNSW index", func() {
+			config.VectorStore.Provider = "file"
+			config.VectorStore.IndexType = "HNSW"
+			config.VectorStore.Parameters = map[string]interface{}{"M": 8, "efConstruction": 100.0, "ef_search": 32}
+			store, err := NewVectorStoreFromConfig(config.VectorStore, config.DataDir)
+
+			Convey("Then the store should use the configured graph parameters", func() {
+				So(err, ShouldBeNil)
+				fileStore, ok := store.(*FileVectorStore)
+				So(ok, ShouldBeTrue)
+				So(fileStore.IndexType(), ShouldEqual, VectorIndexHNSW)
+				So(fileStore.config.M, ShouldEqual, 8)
+				So(fileStore.config.EfConstruction, ShouldEqual, 100)
+				So(fileStore.config.EfSearch, ShouldEqual, 32)
+			})
+		})
+
+		Convey("When the vector index type is unknown", func() {
+			config.VectorStore.Provider = "file"
+			config.VectorStore.IndexType = "ivf"
+			_, err := NewVectorStoreFromConfig(config.VectorStore, config.DataDir)
+
+			Convey("Then it should return an error", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "unsupported vector index type")
+			})
+		})
+
+		Convey("When a file provider has neither a URI nor a data directory", func() {
+			config.GraphStore.Provider = "file"
+			_, err := NewGraphStoreFromConfig(config.GraphStore, "")
+
+			Convey("Then it should return an error", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "data directory")
+			})
+		})
+
+		Convey("When a provider is unknown", func() {
+			config.SearchIndex.Provider = "elasticsearch"
+			storage, err := NewStorageFromConfig(&config)
+
+			Convey("Then it should return an error", func() {
+				So(err, ShouldNotBeNil)
+				So(storage, ShouldBeNil)
+				So(err.Error(), ShouldContainSubstring, "unsupported search index provider")
+			})
+		})
+	})
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// storeLogMagic opens every snapshot and log file, telling them apart from the JSON files
+// the file-backed stores used to write
+var storeLogMagic = []byte("AMSLOG01")
+
+// storeLogHeaderSize is the magic followed by the snapshot generation as a uint64
+const storeLogHeaderSize = 16
+
+// storeLogCompactAfter is the fewest log records worth compacting. A log is folded into a new
+// snapshot once it holds at least this many records and at least one per live entry, so each
+// write pays amortized constant time for compaction.
+const storeLogCompactAfter = 1024
+
+// storeRecord is one change to a file-backed store: an operation on a key with an encoded value
+type storeRecord struct {
+	op    byte
+	key   string
+	value []byte
+}
+
+// storeLog persists a file-backed store as a binary snapshot plus an append-only log of the
+// changes made since. Snapshots are written to a temporary file, synced and renamed into
+// place, so a crash leaves either the old snapshot or the new one. Each record carries a
+// CRC32, and a record torn by a crash mid-append is cut off, with anything after it, when
+// the log is replayed. The log names the generation of the snapshot it extends, so a log
+// left behind by a crash just after a snapshot replaced it is discarded rather than replayed.
+//
+// Records are framed as a uint32 length and a uint32 checksum followed by the operation
+// byte, the key as a uvarint length and bytes, and the value.
+type storeLog struct {
+	path       string // Snapshot path; the log lives beside it with a .log suffix
+	generation uint64 // Generation of the current snapshot, bumped by each snapshot
+	pending    int    // Records appended since the last snapshot
+	loaded     bool   // Set once the store has been loaded from or written to disk
+	legacy     bool   // The snapshot on disk is JSON and should be rewritten
+}
+
+// newStoreLog creates the log for a store persisted at path
+func newStoreLog(path string) *storeLog {
+	return &storeLog{path: path}
+}
+
+// logPath is where records are appended between snapshots
+func (l *storeLog) logPath() string {
+	return storeLogFiles(l.path)[1]
+}
+
+// storeLogFiles returns the snapshot and log files of a store persisted at path
+func storeLogFiles(path string) []string {
+	return []string{path, path + ".log"}
+}
+
+// loadSnapshot reads the snapshot, passing each record to apply, or the whole file to
+// legacy when it was written as JSON. A missing or empty snapshot loads nothing.
+func (l *storeLog) loadSnapshot(legacy func([]byte) error, apply func(storeRecord) error) error {
+	l.generation, l.pending, l.loaded, l.legacy = 0, 0, true, false
+
+	data, err := os.ReadFile(l.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	if !bytes.HasPrefix(data, storeLogMagic) {
+		l.legacy = true
+		return legacy(data)
+	}
+	if len(data) < storeLogHeaderSize {
+		return fmt.Errorf("snapshot %s is truncated", l.path)
+	}
+
+	l.generation = binary.LittleEndian.Uint64(data[len(storeLogMagic):storeLogHeaderSize])
+	read, err := readStoreRecords(data[storeLogHeaderSize:], apply)
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot %s: %w", l.path, err)
+	}
+	if storeLogHeaderSize+read != len(data) {
+		return fmt.Errorf("snapshot %s is corrupt at offset %d", l.path, storeLogHeaderSize+read)
+	}
+	return nil
+}
+
+// replay applies the records logged since the snapshot. A torn or corrupt tail is truncated
+// so later appends follow the last good record; a log from an older snapshot is removed.
+func (l *storeLog) replay(apply func(storeRecord) error) error {
+	data, err := os.ReadFile(l.logPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read log: %w", err)
+	}
+
+	if len(data) < storeLogHeaderSize || !bytes.HasPrefix(data, storeLogMagic) ||
+		binary.LittleEndian.Uint64(data[len(storeLogMagic):storeLogHeaderSize]) != l.generation {
+		if err := os.Remove(l.logPath()); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove stale log: %w", err)
+		}
+		return nil
+	}
+
+	read, err := readStoreRecords(data[storeLogHeaderSize:], func(record storeRecord) error {
+		l.pending++
+		return apply(record)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to replay log: %w", err)
+	}
+	if storeLogHeaderSize+read < len(data) {
+		if err := os.Truncate(l.logPath(), int64(storeLogHeaderSize+read)); err != nil {
+			return fmt.Errorf("failed to truncate torn log: %w", err)
+		}
+	}
+	return nil
+}
+
+// needsSnapshot reports whether the next change should be persisted by writing a snapshot
+// rather than appending: the store was never loaded, so the disk does not hold its state; the
+// snapshot is still JSON; or the log has grown as large as the live data it describes.
+func (l *storeLog) needsSnapshot(live int) bool {
+	return !l.loaded || l.legacy || (l.pending >= storeLogCompactAfter && l.pending >= live)
+}
+
+// append writes records to the end of the log in a single write. A failed write is truncated
+// away so it cannot hide the records appended after it.
+func (l *storeLog) append(records ...storeRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	file, err := os.OpenFile(l.logPath(), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat log: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if info.Size() == 0 {
+		writeStoreLogHeader(&buf, l.generation)
+	}
+	for _, record := range records {
+		writeStoreRecord(&buf, record)
+	}
+
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		file.Truncate(info.Size())
+		return fmt.Errorf("failed to append to log: %w", err)
+	}
+
+	l.pending += len(records)
+	return nil
+}
+
+// snapshot writes the records emitted by write as the next generation's snapshot, renames it
+// over the current one and discards the log it replaces
+func (l *storeLog) snapshot(write func(emit func(storeRecord)) error) error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	generation := l.generation + 1
+	if err := writeFileAtomic(l.path, func(w io.Writer) error {
+		buffered := bufio.NewWriter(w)
+		writeStoreLogHeader(buffered, generation)
+		if err := write(func(record storeRecord) {
+			writeStoreRecord(buffered, record)
+		}); err != nil {
+			return err
+		}
+		return buffered.Flush()
+	}); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	l.generation, l.pending, l.loaded, l.legacy = generation, 0, true, false
+	if err := os.Remove(l.logPath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove compacted log: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes a file through a synced temporary file renamed into place, so
+// readers and crashes see either the old contents or the new, never a partial write
+func writeFileAtomic(path string, write func(io.Writer) error) error {
+	tmpPath := path + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	if err := write(file); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// writeStoreLogHeader writes the magic and the snapshot generation
+func writeStoreLogHeader(w io.Writer, generation uint64) {
+	var header [storeLogHeaderSize]byte
+	copy(header[:], storeLogMagic)
+	binary.LittleEndian.PutUint64(header[len(storeLogMagic):], generation)
+	w.Write(header[:])
+}
+
+// writeStoreRecord writes one framed record
+func writeStoreRecord(w io.Writer, record storeRecord) {
+	body := make([]byte, 0, 1+binary.MaxVarintLen64+len(record.key)+len(record.value))
+	body = append(body, record.op)
+	body = binary.AppendUvarint(body, uint64(len(record.key)))
+	body = append(body, record.key...)
+	body = append(body, record.value...)
+
+	var frame [8]byte
+	binary.LittleEndian.PutUint32(frame[0:4], uint32(len(body)))
+	binary.LittleEndian.PutUint32(frame[4:8], crc32.ChecksumIEEE(body))
+	w.Write(frame[:])
+	w.Write(body)
+}
+
+// readStoreRecords passes each framed record in data to apply and returns how many bytes of
+// complete, intact records it read; it stops at the first truncated or corrupt record
+func readStoreRecords(data []byte, apply func(storeRecord) error) (int, error) {
+	offset := 0
+	for len(data)-offset >= 8 {
+		length := int(binary.LittleEndian.Uint32(data[offset : offset+4]))
+		checksum := binary.LittleEndian.Uint32(data[offset+4 : offset+8])
+		if length < 1 || length > len(data)-offset-8 {
+			break
+		}
+
+		body := data[offset+8 : offset+8+length]
+		if crc32.ChecksumIEEE(body) != checksum {
+			break
+		}
+
+		keyLength, n := binary.Uvarint(body[1:])
+		if n <= 0 || keyLength > uint64(len(body)-1-n) {
+			break
+		}
+		keyEnd := 1 + n + int(keyLength)
+		record := storeRecord{
+			op:    body[0],
+			key:   string(body[1+n : keyEnd]),
+			value: body[keyEnd:],
+		}
+		if err := apply(record); err != nil {
+			return offset, err
+		}
+		offset += 8 + length
+	}
+	return offset, nil
+}
+
+// encodeStoreValue packs an embedding as a uvarint length and little-endian float32s,
+// followed by the JSON encoding of rest unless rest is nil
+func encodeStoreValue(embedding []float32, rest interface{}) ([]byte, error) {
+	value := make([]byte, 0, binary.MaxVarintLen64+4*len(embedding))
+	value = binary.AppendUvarint(value, uint64(len(embedding)))
+	for _, x := range embedding {
+		value = binary.LittleEndian.AppendUint32(value, math.Float32bits(x))
+	}
+
+	if rest == nil {
+		return value, nil
+	}
+	data, err := json.Marshal(rest)
+	if err != nil {
+		return nil, err
+	}
+	return append(value, data...), nil
+}
+
+// decodeStoreValue unpacks a value written by encodeStoreValue, unmarshaling the JSON part
+// into rest when rest is not nil. An empty embedding decodes as nil.
+func decodeStoreValue(value []byte, rest interface{}) ([]float32, error) {
+	dimension, n := binary.Uvarint(value)
+	if n <= 0 || dimension > uint64(len(value)-n)/4 {
+		return nil, fmt.Errorf("malformed embedding")
+	}
+
+	var embedding []float32
+	if dimension > 0 {
+		embedding = make([]float32, dimension)
+		for i := range embedding {
+			embedding[i] = math.Float32frombits(binary.LittleEndian.Uint32(value[n+4*i:]))
+		}
+	}
+
+	data := value[n+4*int(dimension):]
+	if rest != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, rest); err != nil {
+			return nil, err
+		}
+	}
+	return embedding, nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestStoreLog(t *testing.T) {
+	Convey("Given a store log", t, func() {
+		path := filepath.Join(t.TempDir(), "store.json")
+		log := newStoreLog(path)
+		So(log.loadSnapshot(nil, nil), ShouldBeNil)
+
+		collect := func() ([]storeRecord, []storeRecord) {
+			reader := newStoreLog(path)
+			var snapshot, logged []storeRecord
+			So(reader.loadSnapshot(func([]byte) error { return nil }, func(record storeRecord) error {
+				snapshot = append(snapshot, record)
+				return nil
+			}), ShouldBeNil)
+			So(reader.replay(func(record storeRecord) error {
+				logged = append(logged, record)
+				return nil
+			}), ShouldBeNil)
+			return snapshot, logged
+		}
+
+		Convey("When records are appended", func() {
+			So(log.append(storeRecord{op: 1, key: "a", value: []byte("one")}), ShouldBeNil)
+			So(log.append(storeRecord{op: 2, key: "b"}, storeRecord{op: 1, key: "c", value: []byte("three")}), ShouldBeNil)
+
+			Convey("Then they should replay in order", func() {
+				snapshot, logged := collect()
+				So(snapshot, ShouldBeEmpty)
+				So(len(logged), ShouldEqual, 3)
+				So(logged[0].key, ShouldEqual, "a")
+				So(string(logged[0].value), ShouldEqual, "one")
+				So(logged[1].op, ShouldEqual, 2)
+				So(logged[2].key, ShouldEqual, "c")
+				So(log.pending, ShouldEqual, 3)
+			})
+
+			Convey("And a snapshot is taken", func() {
+				So(log.snapshot(func(emit func(storeRecord)) error {
+					emit(storeRecord{op: 1, key: "a", value: []byte("one")})
+					return nil
+				}), ShouldBeNil)
+
+				Convey("Then the snapshot should hold its records and the log should be gone", func() {
+					snapshot, logged := collect()
+					So(len(snapshot), ShouldEqual, 1)
+					So(logged, ShouldBeEmpty)
+					So(log.pending, ShouldEqual, 0)
+					_, err := os.Stat(log.logPath())
+					So(os.IsNotExist(err), ShouldBeTrue)
+					_, err = os.Stat(path + ".tmp")
+					So(os.IsNotExist(err), ShouldBeTrue)
+				})
+			})
+
+			Convey("And the log ends in a torn record", func() {
+				info, err := os.Stat(log.logPath())
+				So(err, ShouldBeNil)
+				file, err := os.OpenFile(log.logPath(), os.O_WRONLY|os.O_APPEND, 0644)
+				So(err, ShouldBeNil)
+				_, err = file.Write([]byte{42, 0, 0, 0, 1, 2})
+				So(err, ShouldBeNil)
+				So(file.Close(), ShouldBeNil)
+
+				Convey("Then the intact records should replay and the tail should be cut off", func() {
+					_, logged := collect()
+					So(len(logged), ShouldEqual, 3)
+					truncated, err := os.Stat(log.logPath())
+					So(err, ShouldBeNil)
+					So(truncated.Size(), ShouldEqual, info.Size())
+				})
+			})
+
+			Convey("And a record's checksum does not match", func() {
+				data, err := os.ReadFile(log.logPath())
+				So(err, ShouldBeNil)
+				data[len(data)-1] ^= 0xff
+				So(os.WriteFile(log.logPath(), data, 0644), ShouldBeNil)
+
+				Convey("Then replay should stop before it", func() {
+					_, logged := collect()
+					So(len(logged), ShouldEqual, 2)
+				})
+			})
+		})
+
+		Convey("When a log from an older snapshot is left behind", func() {
+			So(log.append(storeRecord{op: 1, key: "stale"}), ShouldBeNil)
+			stale, err := os.ReadFile(log.logPath())
+			So(err, ShouldBeNil)
+			So(log.snapshot(func(emit func(storeRecord)) error { return nil }), ShouldBeNil)
+			So(os.WriteFile(log.logPath(), stale, 0644), ShouldBeNil)
+
+			Convey("Then it should be discarded rather than replayed", func() {
+				_, logged := collect()
+				So(logged, ShouldBeEmpty)
+				_, err := os.Stat(log.logPath())
+				So(os.IsNotExist(err), ShouldBeTrue)
+			})
+		})
+
+		Convey("When the snapshot is a JSON file", func() {
+			So(os.WriteFile(path, []byte(`{"a": 1}`), 0644), ShouldBeNil)
+			reader := newStoreLog(path)
+			var legacy string
+			err := reader.loadSnapshot(func(data []byte) error {
+				legacy = string(data)
+				return nil
+			}, nil)
+
+			Convey("Then it should be handed to the legacy loader and marked for rewriting", func() {
+				So(err, ShouldBeNil)
+				So(legacy, ShouldEqual, `{"a": 1}`)
+				So(reader.needsSnapshot(0), ShouldBeTrue)
+			})
+		})
+
+		Convey("When deciding whether to compact", func() {
+			Convey("Then a short log should be appended to", func() {
+				So(log.needsSnapshot(10), ShouldBeFalse)
+			})
+
+			Convey("Then a log as large as the live data should be compacted", func() {
+				log.pending = storeLogCompactAfter
+				So(log.needsSnapshot(10), ShouldBeTrue)
+				So(log.needsSnapshot(storeLogCompactAfter*2), ShouldBeFalse)
+			})
+
+			Convey("Then a store that was never loaded should write a snapshot", func() {
+				So(newStoreLog(path).needsSnapshot(0), ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func TestStoreValueEncoding(t *testing.T) {
+	Convey("Given an embedding and metadata", t, func() {
+		embedding := []float32{0.25, -1.5, 3}
+		metadata := map[string]interface{}{"source": "test"}
+
+		value, err := encodeStoreValue(embedding, metadata)
+		So(err, ShouldBeNil)
+
+		Convey("Then they should decode unchanged", func() {
+			var decoded map[string]interface{}
+			decodedEmbedding, err := decodeStoreValue(value, &decoded)
+			So(err, ShouldBeNil)
+			So(decodedEmbedding, ShouldResemble, embedding)
+			So(decoded, ShouldResemble, metadata)
+		})
+
+		Convey("Then the embedding should take four bytes per dimension", func() {
+			withoutMetadata, err := encodeStoreValue(embedding, nil)
+			So(err, ShouldBeNil)
+			So(len(withoutMetadata), ShouldEqual, 1+4*len(embedding))
+		})
+
+		Convey("Then a truncated embedding should be rejected", func() {
+			_, err := decodeStoreValue(value[:5], nil)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
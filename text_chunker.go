@@ -21,14 +21,16 @@ const (
 	ChunkBySize ChunkStrategy = iota
 	ChunkBySentence
 	ChunkByParagraph
+	ChunkByMarkdown
 )
 
 // ChunkResult represents the result of chunking text
 type ChunkResult struct {
-	Text     string `json:"text"`
-	Start    int    `json:"start"`
-	End      int    `json:"end"`
-	Strategy string `json:"strategy"`
+	Text     string   `json:"text"`
+	Start    int      `json:"start"`
+	End      int      `json:"end"`
+	Strategy string   `json:"strategy"`
+	Headings []string `json:"headings,omitempty"` // Enclosing Markdown headings, outermost first
 }
 
 // NewTextChunker creates a new TextChunker with default settings
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// monthNames maps full and abbreviated month names to months
+var monthNames = map[string]time.Month{
+	"january": time.January, "jan": time.January,
+	"february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March,
+	"april": time.April, "apr": time.April,
+	"may":  time.May,
+	"june": time.June, "jun": time.June,
+	"july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August,
+	"september": time.September, "sept": time.September, "sep": time.September,
+	"october": time.October, "oct": time.October,
+	"november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+}
+
+const (
+	monthPattern = `(?:january|february|march|april|may|june|july|august|september|october|november|december|jan|feb|mar|apr|jun|jul|aug|sept|sep|oct|nov|dec)`
+	dayPattern   = `\d{1,2}(?:st|nd|rd|th)?`
+
+	// calendarDatePattern matches dates that are unambiguous on their own: ISO days and months,
+	// "march 5, 2025", "5 march 2025" and "march 2025"
+	calendarDatePattern = `(?:\d{4}-\d{2}-\d{2}|\d{4}-\d{2}|` +
+		monthPattern + `\s+` + dayPattern + `,?\s+\d{4}|` +
+		dayPattern + `\s+` + monthPattern + `,?\s+\d{4}|` +
+		monthPattern + `,?\s+\d{4})`
+
+	// datePattern also accepts a bare year, which is only trusted after a preposition
+	datePattern = `(?:` + calendarDatePattern + `|\d{4})`
+
+	// Years outside this span are taken for ordinary numbers, as in "top 10 of 1000 records"
+	minPlausibleYear = 1900
+	maxPlausibleYear = 2100
+)
+
+var (
+	betweenPattern = regexp.MustCompile(`\bbetween\s+(` + datePattern + `)\s+and\s+(` + datePattern + `)\b`)
+	fromToPattern  = regexp.MustCompile(`\bfrom\s+(` + datePattern + `)\s+(?:to|until|through)\s+(` + datePattern + `)\b`)
+	sincePattern   = regexp.MustCompile(`\b(since|after|from)\s+(` + datePattern + `)\b`)
+	beforePattern  = regexp.MustCompile(`\b(before|until|till)\s+(` + datePattern + `)\b`)
+	duringPattern  = regexp.MustCompile(`\b(?:in|during|on|of)\s+(` + datePattern + `)\b`)
+	calendarDate   = regexp.MustCompile(`\b(` + calendarDatePattern + `)\b`)
+)
+
+// parseAbsoluteTimeRange finds an absolute date expression such as "in march 2025",
+// "since 2024-01-01" or "between 2024-01-01 and 2024-06-30" in a lowercased query. yearOnly
+// reports that the expression names nothing finer than a bare year, which may just as well be
+// a number, so callers should prefer results in the range rather than filter on it.
+func parseAbsoluteTimeRange(query string, loc *time.Location) (timeRange *TimeRange, yearOnly bool) {
+	if m := betweenPattern.FindStringSubmatch(query); m != nil {
+		if tr := spanRange(m[1], m[2], loc); tr != nil {
+			return tr, isBareYear(m[1]) || isBareYear(m[2])
+		}
+	}
+	if m := fromToPattern.FindStringSubmatch(query); m != nil {
+		if tr := spanRange(m[1], m[2], loc); tr != nil {
+			return tr, isBareYear(m[1]) || isBareYear(m[2])
+		}
+	}
+
+	if m := sincePattern.FindStringSubmatch(query); m != nil {
+		if start, end, ok := parseDatePeriod(m[2], loc); ok {
+			if m[1] == "after" {
+				start = end.Add(time.Nanosecond)
+			}
+			return &TimeRange{Start: &start}, isBareYear(m[2])
+		}
+	}
+	if m := beforePattern.FindStringSubmatch(query); m != nil {
+		if start, end, ok := parseDatePeriod(m[2], loc); ok {
+			if m[1] == "before" {
+				end = start.Add(-time.Nanosecond)
+			}
+			return &TimeRange{End: &end}, isBareYear(m[2])
+		}
+	}
+
+	for _, pattern := range []*regexp.Regexp{duringPattern, calendarDate} {
+		if m := pattern.FindStringSubmatch(query); m != nil {
+			if start, end, ok := parseDatePeriod(m[1], loc); ok {
+				return &TimeRange{Start: &start, End: &end}, isBareYear(m[1])
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// isBareYear reports whether a date expression is a year alone
+func isBareYear(text string) bool {
+	if len(text) != 4 {
+		return false
+	}
+	_, err := strconv.Atoi(text)
+	return err == nil
+}
+
+// spanRange builds a range from the start of one date expression to the end of another
+func spanRange(from, to string, loc *time.Location) *TimeRange {
+	start, _, ok := parseDatePeriod(from, loc)
+	if !ok {
+		return nil
+	}
+	_, end, ok := parseDatePeriod(to, loc)
+	if !ok || end.Before(start) {
+		return nil
+	}
+	return &TimeRange{Start: &start, End: &end}
+}
+
+// parseDatePeriod returns the first and last instant of the day, month or year a date expression names
+func parseDatePeriod(text string, loc *time.Location) (time.Time, time.Time, bool) {
+	fields := strings.Fields(strings.ReplaceAll(text, ",", " "))
+	for i, field := range fields {
+		fields[i] = strings.TrimRight(field, "stndrh")
+		if _, isMonth := monthNames[field]; isMonth {
+			fields[i] = field
+		}
+	}
+
+	var year, day int
+	var month time.Month
+	var err error
+
+	switch len(fields) {
+	case 1:
+		parts := strings.Split(fields[0], "-")
+		if year, err = strconv.Atoi(parts[0]); err != nil {
+			return time.Time{}, time.Time{}, false
+		}
+		if len(parts) > 1 {
+			m, err := strconv.Atoi(parts[1])
+			if err != nil || m < 1 || m > 12 {
+				return time.Time{}, time.Time{}, false
+			}
+			month = time.Month(m)
+		}
+		if len(parts) > 2 {
+			if day, err = strconv.Atoi(parts[2]); err != nil {
+				return time.Time{}, time.Time{}, false
+			}
+		}
+	case 2:
+		m, ok := monthNames[fields[0]]
+		if !ok {
+			return time.Time{}, time.Time{}, false
+		}
+		month = m
+		if year, err = strconv.Atoi(fields[1]); err != nil {
+			return time.Time{}, time.Time{}, false
+		}
+	case 3:
+		monthField, dayField := fields[0], fields[1]
+		if _, ok := monthNames[monthField]; !ok {
+			monthField, dayField = fields[1], fields[0]
+		}
+		m, ok := monthNames[monthField]
+		if !ok {
+			return time.Time{}, time.Time{}, false
+		}
+		month = m
+		if day, err = strconv.Atoi(dayField); err != nil {
+			return time.Time{}, time.Time{}, false
+		}
+		if year, err = strconv.Atoi(fields[2]); err != nil {
+			return time.Time{}, time.Time{}, false
+		}
+	default:
+		return time.Time{}, time.Time{}, false
+	}
+
+	if year < minPlausibleYear || year > maxPlausibleYear {
+		return time.Time{}, time.Time{}, false
+	}
+
+	switch {
+	case month == 0:
+		start := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+		return start, start.AddDate(1, 0, 0).Add(-time.Nanosecond), true
+	case day == 0:
+		start := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+		return start, start.AddDate(0, 1, 0).Add(-time.Nanosecond), true
+	}
+
+	start := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	if start.Day() != day || start.Month() != month {
+		// Reject dates such as february 30 that time.Date would roll over
+		return time.Time{}, time.Time{}, false
+	}
+	return start, start.AddDate(0, 0, 1).Add(-time.Nanosecond), true
+}
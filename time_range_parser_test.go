@@ -0,0 +1,97 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseAbsoluteTimeRange(t *testing.T) {
+	Convey("Given absolute date expressions", t, func() {
+		loc := time.UTC
+		date := func(year int, month time.Month, day int) time.Time {
+			return time.Date(year, month, day, 0, 0, 0, 0, loc)
+		}
+
+		Convey("When a month is named", func() {
+			tr, yearOnly := parseAbsoluteTimeRange("notes in march 2025", loc)
+
+			Convey("Then the range should cover the whole month", func() {
+				So(tr, ShouldNotBeNil)
+				So(yearOnly, ShouldBeFalse)
+				So(*tr.Start, ShouldEqual, date(2025, time.March, 1))
+				So(*tr.End, ShouldEqual, date(2025, time.April, 1).Add(-time.Nanosecond))
+			})
+		})
+
+		Convey("When a start date is given with since", func() {
+			tr, yearOnly := parseAbsoluteTimeRange("changes since 2024-01-01", loc)
+
+			Convey("Then the range should be open-ended", func() {
+				So(tr, ShouldNotBeNil)
+				So(yearOnly, ShouldBeFalse)
+				So(*tr.Start, ShouldEqual, date(2024, time.January, 1))
+				So(tr.End, ShouldBeNil)
+			})
+		})
+
+		Convey("When two dates are given with between", func() {
+			tr, yearOnly := parseAbsoluteTimeRange("between jan 5th, 2024 and 2024-02", loc)
+
+			Convey("Then the range should run from the first day to the end of the last period", func() {
+				So(tr, ShouldNotBeNil)
+				So(yearOnly, ShouldBeFalse)
+				So(*tr.Start, ShouldEqual, date(2024, time.January, 5))
+				So(*tr.End, ShouldEqual, date(2024, time.March, 1).Add(-time.Nanosecond))
+			})
+		})
+
+		Convey("When before and after are used", func() {
+			before, beforeYearOnly := parseAbsoluteTimeRange("meetings before 2024", loc)
+			after, afterYearOnly := parseAbsoluteTimeRange("meetings after 3 may 2024", loc)
+
+			Convey("Then the named period itself should be excluded", func() {
+				So(beforeYearOnly, ShouldBeTrue)
+				So(afterYearOnly, ShouldBeFalse)
+				So(before.Start, ShouldBeNil)
+				So(*before.End, ShouldEqual, date(2024, time.January, 1).Add(-time.Nanosecond))
+				So(*after.Start, ShouldEqual, date(2024, time.May, 4))
+				So(after.End, ShouldBeNil)
+			})
+		})
+
+		Convey("When a year is only preceded by a preposition", func() {
+			tr, yearOnly := parseAbsoluteTimeRange("what happened during 2023", loc)
+			So(tr, ShouldNotBeNil)
+			So(yearOnly, ShouldBeTrue)
+			tr, _ = parseAbsoluteTimeRange("top 2023 ideas", loc)
+			So(tr, ShouldBeNil)
+		})
+
+		Convey("When a four-digit number is not a plausible year", func() {
+			tr, _ := parseAbsoluteTimeRange("top 10 of 1000 records", loc)
+			So(tr, ShouldBeNil)
+			tr, _ = parseAbsoluteTimeRange("everything since 9999", loc)
+			So(tr, ShouldBeNil)
+		})
+
+		Convey("When the date does not exist", func() {
+			tr, _ := parseAbsoluteTimeRange("on 2024-02-30", loc)
+			So(tr, ShouldBeNil)
+		})
+	})
+}
+
+func TestTimeRangeContains(t *testing.T) {
+	Convey("Given a time range with an open end", t, func() {
+		start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+		tr := TimeRange{Start: &start}
+
+		Convey("Then it should contain later times but not earlier ones", func() {
+			So(tr.Contains(start), ShouldBeTrue)
+			So(tr.Contains(start.AddDate(5, 0, 0)), ShouldBeTrue)
+			So(tr.Contains(start.Add(-time.Second)), ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// VectorMetric is the measure embeddings are compared with. Similarities are always
+// "higher is closer" so results sort the same way whichever metric is configured.
+type VectorMetric string
+
+// Supported vector metrics
+const (
+	VectorMetricCosine VectorMetric = "cosine" // Angle only; vectors are normalized to unit length
+	VectorMetricDot    VectorMetric = "dot"    // Inner product; magnitude matters, for models trained on it
+	VectorMetricL2     VectorMetric = "l2"     // Euclidean distance; magnitude matters
+)
+
+// ParseVectorMetric reads a configured metric name; empty means cosine
+func ParseVectorMetric(name string) (VectorMetric, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "cosine":
+		return VectorMetricCosine, nil
+	case "dot", "dot_product", "inner_product", "ip":
+		return VectorMetricDot, nil
+	case "l2", "euclidean":
+		return VectorMetricL2, nil
+	}
+	return "", fmt.Errorf("unsupported vector metric: %s", name)
+}
+
+// metricVectorStore is implemented by vector stores that score with a configurable metric,
+// so searchers and resolvers can compare embeddings the same way the store does
+type metricVectorStore interface {
+	Metric() VectorMetric
+}
+
+// vectorStoreMetric returns the metric a store scores with, cosine when it does not say
+func vectorStoreMetric(store VectorStore) VectorMetric {
+	if metricStore, ok := store.(metricVectorStore); ok {
+		return metricStore.Metric()
+	}
+	return VectorMetricCosine
+}
+
+// Similarity scores two vectors: cosine similarity in [-1, 1], the raw inner product for
+// dot, and 1/(1+d) in (0, 1] for Euclidean distance d. Mismatched lengths score 0.
+func (m VectorMetric) Similarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	switch m {
+	case VectorMetricDot:
+		var dot float64
+		for i := range a {
+			dot += float64(a[i]) * float64(b[i])
+		}
+		return dot
+	case VectorMetricL2:
+		return 1 / (1 + math.Sqrt(squaredEuclidean(a, b)))
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	// Clamp to [-1, 1] to absorb floating point error
+	return math.Max(-1, math.Min(1, dot/(math.Sqrt(normA)*math.Sqrt(normB))))
+}
+
+// Confidence maps a similarity onto [0, 1]: cosine clamps negatives to 0, dot products go
+// through a logistic curve since they are unbounded, and the L2 similarity is already in range
+func (m VectorMetric) Confidence(similarity float64) float64 {
+	if m == VectorMetricDot {
+		return 1 / (1 + math.Exp(-similarity))
+	}
+	return math.Max(0, math.Min(1, similarity))
+}
+
+// Normalizes reports the metric's normalization policy: only cosine ignores magnitude,
+// so only cosine may scale query and stored vectors to unit length
+func (m VectorMetric) Normalizes() bool {
+	return m == VectorMetricCosine
+}
+
+// Prepare applies the normalization policy, returning a unit-length copy for cosine and
+// the vector unchanged otherwise
+func (m VectorMetric) Prepare(v []float32) []float32 {
+	if !m.Normalizes() {
+		return v
+	}
+	return normalizeVector(v)
+}
+
+// squaredEuclidean returns the squared Euclidean distance between equal-length vectors
+func squaredEuclidean(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		d := float64(a[i]) - float64(b[i])
+		sum += d * d
+	}
+	return sum
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseVectorMetric(t *testing.T) {
+	Convey("Given configured metric names", t, func() {
+		Convey("Then known names and aliases should parse", func() {
+			for name, want := range map[string]VectorMetric{
+				"":            VectorMetricCosine,
+				"Cosine":      VectorMetricCosine,
+				"dot":         VectorMetricDot,
+				"dot_product": VectorMetricDot,
+				"ip":          VectorMetricDot,
+				"l2":          VectorMetricL2,
+				"euclidean":   VectorMetricL2,
+			} {
+				metric, err := ParseVectorMetric(name)
+				So(err, ShouldBeNil)
+				So(metric, ShouldEqual, want)
+			}
+		})
+
+		Convey("Then unknown names should be rejected", func() {
+			_, err := ParseVectorMetric("manhattan")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestVectorMetricScoring(t *testing.T) {
+	Convey("Given two vectors pointing the same way with different lengths", t, func() {
+		a := []float32{1, 0}
+		b := []float32{3, 4}
+
+		Convey("Then cosine should ignore magnitude", func() {
+			So(VectorMetricCosine.Similarity(a, b), ShouldAlmostEqual, 0.6, 1e-9)
+			So(VectorMetricCosine.Similarity(b, []float32{6, 8}), ShouldAlmostEqual, 1.0, 1e-9)
+			So(VectorMetricCosine.Confidence(-0.5), ShouldEqual, 0)
+		})
+
+		Convey("Then dot should use the raw inner product with a logistic confidence", func() {
+			So(VectorMetricDot.Similarity(a, b), ShouldAlmostEqual, 3.0, 1e-9)
+			So(VectorMetricDot.Confidence(0), ShouldAlmostEqual, 0.5, 1e-9)
+			So(VectorMetricDot.Confidence(3), ShouldAlmostEqual, 1/(1+math.Exp(-3)), 1e-9)
+		})
+
+		Convey("Then l2 should map the Euclidean distance into (0, 1]", func() {
+			So(VectorMetricL2.Similarity(a, b), ShouldAlmostEqual, 1/(1+math.Sqrt(20)), 1e-9)
+			So(VectorMetricL2.Similarity(b, b), ShouldEqual, 1)
+			So(VectorMetricL2.Confidence(VectorMetricL2.Similarity(a, b)), ShouldAlmostEqual, 1/(1+math.Sqrt(20)), 1e-9)
+		})
+
+		Convey("Then only cosine should normalize vectors", func() {
+			So(VectorMetricCosine.Prepare(b), ShouldResemble, []float32{0.6, 0.8})
+			So(VectorMetricDot.Prepare(b), ShouldResemble, b)
+			So(VectorMetricL2.Prepare(b), ShouldResemble, b)
+		})
+
+		Convey("Then mismatched lengths should score zero", func() {
+			for _, metric := range []VectorMetric{VectorMetricCosine, VectorMetricDot, VectorMetricL2} {
+				So(metric.Similarity(a, []float32{1, 2, 3}), ShouldEqual, 0)
+			}
+		})
+	})
+}
@@ -19,6 +19,7 @@ type VectorSearchConfig struct {
 	MinSimilarity   float64 `json:"min_similarity"`
 	MaxResults      int     `json:"max_results"`
 	NormalizeScores bool    `json:"normalize_scores"`
+	Metric          string  `json:"metric,omitempty"` // "cosine", "dot" or "l2"; empty follows the store
 }
 
 // VectorSearchResult represents a single vector search result
@@ -27,6 +28,7 @@ type VectorSearchResult struct {
 	Content    string                 `json:"content"`
 	Score      float64                `json:"score"`
 	Similarity float64                `json:"similarity"`
+	Confidence float64                `json:"confidence"` // Similarity mapped onto [0, 1] for the metric
 	Metadata   map[string]interface{} `json:"metadata"`
 	Embedding  []float32              `json:"embedding,omitempty"`
 }
@@ -88,11 +90,15 @@ func (vs *VectorSearcher) Search(ctx context.Context, queryEmbedding []float32,
 		k = vs.config.MaxResults
 	}
 
-	// Normalize query embedding
-	normalizedQuery := vs.normalizeVector(queryEmbedding)
+	// Only cosine ignores magnitude, so only cosine queries are normalized
+	metric := vs.Metric()
+	query := queryEmbedding
+	if metric.Normalizes() {
+		query = vs.normalizeVector(queryEmbedding)
+	}
 
 	// Perform the search
-	vectorResults, err := vs.vectorStore.Search(ctx, normalizedQuery, k, filters)
+	vectorResults, err := vs.vectorStore.Search(ctx, query, k, filters)
 	if err != nil {
 		return nil, fmt.Errorf("vector store search failed: %w", err)
 	}
@@ -100,11 +106,11 @@ func (vs *VectorSearcher) Search(ctx context.Context, queryEmbedding []float32,
 	// Convert to VectorSearchResult format
 	results := make([]VectorSearchResult, 0, len(vectorResults))
 	for _, vr := range vectorResults {
-		// Calculate cosine similarity
-		similarity := vs.CosineSimilarity(normalizedQuery, vr.Embedding)
+		// Score with the configured metric
+		similarity := metric.Similarity(query, vr.Embedding)
 		
-		// Skip results below minimum similarity threshold
-		if similarity < vs.config.MinSimilarity {
+		// Skip results whose confidence falls below the minimum similarity threshold
+		if metric.Confidence(similarity) < vs.config.MinSimilarity {
 			continue
 		}
 
@@ -132,10 +138,24 @@ func (vs *VectorSearcher) Search(ctx context.Context, queryEmbedding []float32,
 	response.Metadata["query_embedding_dim"] = len(queryEmbedding)
 	response.Metadata["min_similarity"] = vs.config.MinSimilarity
 	response.Metadata["normalized_scores"] = vs.config.NormalizeScores
+	response.Metadata["metric"] = string(metric)
 
 	return response, nil
 }
 
+// Metric returns the metric results are scored with: the configured one, else the store's, else cosine
+func (vs *VectorSearcher) Metric() VectorMetric {
+	if vs.config.Metric != "" {
+		if metric, err := ParseVectorMetric(vs.config.Metric); err == nil {
+			return metric
+		}
+	}
+	if vs.vectorStore != nil {
+		return vectorStoreMetric(vs.vectorStore)
+	}
+	return VectorMetricCosine
+}
+
 // CosineSimilarity calculates cosine similarity between two vectors
 func (vs *VectorSearcher) CosineSimilarity(a, b []float32) float64 {
 	if len(a) != len(b) {
@@ -170,12 +190,18 @@ func (vs *VectorSearcher) CosineSimilarity(a, b []float32) float64 {
 	return similarity
 }
 
-// RankResults sorts results by similarity score in descending order
+// RankResults sorts results by similarity score in descending order and maps each
+// similarity to a confidence for the metric
 func (vs *VectorSearcher) RankResults(results []VectorSearchResult) []VectorSearchResult {
 	// Create a copy to avoid modifying the original slice
 	ranked := make([]VectorSearchResult, len(results))
 	copy(ranked, results)
 
+	metric := vs.Metric()
+	for i := range ranked {
+		ranked[i].Confidence = metric.Confidence(ranked[i].Similarity)
+	}
+
 	// Sort by similarity (descending) and then by score (descending) as tiebreaker
 	sort.Slice(ranked, func(i, j int) bool {
 		if math.Abs(ranked[i].Similarity-ranked[j].Similarity) < 1e-9 {
@@ -291,18 +317,19 @@ func (vs *VectorSearcher) SearchMultiple(ctx context.Context, queryEmbeddings []
 	return response, nil
 }
 
-// GetSimilarityMatrix calculates similarity matrix between multiple vectors
+// GetSimilarityMatrix calculates similarity matrix between multiple vectors under the searcher's metric
 func (vs *VectorSearcher) GetSimilarityMatrix(vectors [][]float32) [][]float64 {
 	n := len(vectors)
 	matrix := make([][]float64, n)
+	metric := vs.Metric()
 	
 	for i := 0; i < n; i++ {
 		matrix[i] = make([]float64, n)
 		for j := 0; j < n; j++ {
-			if i == j {
+			if i == j && metric == VectorMetricCosine {
 				matrix[i][j] = 1.0
 			} else {
-				matrix[i][j] = vs.CosineSimilarity(vectors[i], vectors[j])
+				matrix[i][j] = metric.Similarity(vectors[i], vectors[j])
 			}
 		}
 	}
@@ -317,9 +344,10 @@ func (vs *VectorSearcher) FindNearestNeighbors(ctx context.Context, targetEmbedd
 	}
 
 	// Calculate similarities
+	metric := vs.Metric()
 	results := make([]VectorSearchResult, len(candidates))
 	for i, candidate := range candidates {
-		similarity := vs.CosineSimilarity(targetEmbedding, candidate.Embedding)
+		similarity := metric.Similarity(targetEmbedding, candidate.Embedding)
 		results[i] = VectorSearchResult{
 			ID:         candidate.ID,
 			Content:    candidate.Content,
@@ -403,4 +403,70 @@ func BenchmarkVectorSearcherNormalizeVector(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		vs.normalizeVector(vector)
 	}
-}
\ No newline at end of file
+}
+func TestVectorSearcherMetrics(t *testing.T) {
+	Convey("Given vectors whose magnitude differs from their direction", t, func() {
+		ctx := context.Background()
+		store := func(metric VectorMetric) *MockVectorStore {
+			s := NewMockVectorStoreWithMetric(metric)
+			s.Store(ctx, "aligned_short", []float32{1, 0}, map[string]interface{}{"content": "short"})
+			s.Store(ctx, "skewed_long", []float32{4, 3}, map[string]interface{}{"content": "long"})
+			s.Store(ctx, "aligned_far", []float32{10, 0}, map[string]interface{}{"content": "far"})
+			return s
+		}
+		query := []float32{2, 0}
+
+		Convey("When the store uses cosine", func() {
+			vs := NewVectorSearcherWithStore(store(VectorMetricCosine), nil)
+			response, err := vs.Search(ctx, query, 3, nil)
+
+			Convey("Then aligned vectors should tie regardless of length", func() {
+				So(err, ShouldBeNil)
+				So(response.Metadata["metric"], ShouldEqual, "cosine")
+				So(response.Results[0].Similarity, ShouldAlmostEqual, 1.0, 1e-9)
+				So(response.Results[1].Similarity, ShouldAlmostEqual, 1.0, 1e-9)
+				So(response.Results[2].ID, ShouldEqual, "skewed_long")
+				So(response.Results[2].Confidence, ShouldAlmostEqual, 0.8, 1e-9)
+			})
+		})
+
+		Convey("When the store uses dot product", func() {
+			vs := NewVectorSearcherWithStore(store(VectorMetricDot), nil)
+			response, err := vs.Search(ctx, query, 3, nil)
+
+			Convey("Then the query should not be normalized and longer vectors should win", func() {
+				So(err, ShouldBeNil)
+				So(response.Metadata["metric"], ShouldEqual, "dot")
+				So(response.Results[0].ID, ShouldEqual, "aligned_far")
+				So(response.Results[0].Similarity, ShouldAlmostEqual, 20.0, 1e-9)
+				So(response.Results[1].ID, ShouldEqual, "skewed_long")
+				So(response.Results[2].ID, ShouldEqual, "aligned_short")
+				So(response.Results[0].Confidence, ShouldBeGreaterThan, response.Results[2].Confidence)
+			})
+		})
+
+		Convey("When the store uses Euclidean distance", func() {
+			vs := NewVectorSearcherWithStore(store(VectorMetricL2), nil)
+			response, err := vs.Search(ctx, query, 3, nil)
+
+			Convey("Then the nearest point should win", func() {
+				So(err, ShouldBeNil)
+				So(response.Results[0].ID, ShouldEqual, "aligned_short")
+				So(response.Results[0].Similarity, ShouldAlmostEqual, 0.5, 1e-9)
+				So(response.Results[2].ID, ShouldEqual, "aligned_far")
+			})
+		})
+
+		Convey("When the searcher config overrides the store's metric", func() {
+			vs := NewVectorSearcherWithStore(store(VectorMetricCosine), &VectorSearchConfig{
+				DefaultK:   10,
+				MaxResults: 100,
+				Metric:     "dot",
+			})
+
+			Convey("Then the configured metric should be used", func() {
+				So(vs.Metric(), ShouldEqual, VectorMetricDot)
+			})
+		})
+	})
+}
@@ -0,0 +1,476 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// writeIntentLogName is the intent log's file name inside the data directory
+const writeIntentLogName = "write_intents"
+
+// Operations recorded in the write intent log, each keyed by transaction ID
+const (
+	intentOpBegin  byte = iota + 1 // The chunk being written, so an interrupted write can be finished
+	intentOpUndo                   // A before-image, logged ahead of the change it undoes
+	intentOpCommit                 // Every view was written
+	intentOpAbort                  // Every change was rolled back
+)
+
+// Views a before-image belongs to
+const (
+	writeViewVector   = "vector"
+	writeViewDocument = "document"
+	writeViewNode     = "node"
+	writeViewEdge     = "edge"
+)
+
+// writeUndo is the before-image of one key a transaction changes. Exists is false when the
+// key was absent, in which case undoing the change deletes it.
+type writeUndo struct {
+	View     string             `json:"view"`
+	ID       string             `json:"id"`
+	Exists   bool               `json:"exists"`
+	Vector   *VectorStoreRecord `json:"vector,omitempty"`
+	Document *IndexDocument     `json:"document,omitempty"`
+	Node     *Node              `json:"node,omitempty"`
+	Edge     *Edge              `json:"edge,omitempty"`
+}
+
+// writeCoordinator makes writes that span the vector store, search index and graph atomic.
+// Each write runs as a transaction whose stores record a before-image of every key ahead of
+// changing it; a failed write is rolled back by restoring the images in reverse, which
+// deletes the keys it created. With an intent log the images reach disk before the changes
+// they undo, so a write interrupted by a crash can be rolled back when storage is next opened.
+type writeCoordinator struct {
+	log        *storeLog // nil keeps before-images in memory only
+	sequence   uint64
+	unresolved int // Transactions whose rollback failed; the log is not compacted past them
+}
+
+// writeTransaction is one in-flight write across the views
+type writeTransaction struct {
+	id          string
+	coordinator *writeCoordinator
+	vectorStore VectorStore
+	graphStore  GraphStore
+	searchIndex SearchIndex
+	captured    map[string]bool
+	undo        []writeUndo
+}
+
+// interruptedWrite is a transaction the intent log shows neither committed nor rolled back
+type interruptedWrite struct {
+	id    string
+	chunk *Chunk
+	undo  []writeUndo
+}
+
+// newWriteCoordinator creates a coordinator logging intents to the given path, or keeping
+// them in memory when the path is empty
+func newWriteCoordinator(path string) *writeCoordinator {
+	coordinator := &writeCoordinator{}
+	if path != "" {
+		coordinator.log = newStoreLog(path)
+	}
+	return coordinator
+}
+
+// begin starts a transaction writing chunk through the given stores, logging the chunk
+// first so an interrupted write can be finished. A transaction that only changes existing
+// chunks passes a nil chunk; if interrupted it is rolled back and not redone.
+func (c *writeCoordinator) begin(chunk *Chunk, vectorStore VectorStore, graphStore GraphStore, searchIndex SearchIndex) (*writeTransaction, error) {
+	c.sequence++
+	tx := &writeTransaction{
+		id:          fmt.Sprintf("tx_%d_%d", time.Now().UnixNano(), c.sequence),
+		coordinator: c,
+		vectorStore: vectorStore,
+		graphStore:  graphStore,
+		searchIndex: searchIndex,
+		captured:    make(map[string]bool),
+	}
+
+	if c.log != nil {
+		value, err := json.Marshal(chunk)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal chunk %s: %w", chunk.ID, err)
+		}
+		if err := c.log.append(storeRecord{op: intentOpBegin, key: tx.id, value: value}); err != nil {
+			return nil, err
+		}
+	}
+	return tx, nil
+}
+
+// interrupted reads the intent log and returns the transactions it shows were neither
+// committed nor rolled back, in the order they began
+func (c *writeCoordinator) interrupted() ([]interruptedWrite, error) {
+	if c.log == nil {
+		return nil, nil
+	}
+
+	var order []string
+	writes := make(map[string]*interruptedWrite)
+	apply := func(record storeRecord) error {
+		switch record.op {
+		case intentOpBegin:
+			chunk := &Chunk{}
+			if err := json.Unmarshal(record.value, chunk); err != nil || chunk.ID == "" {
+				chunk = nil
+			}
+			writes[record.key] = &interruptedWrite{id: record.key, chunk: chunk}
+			order = append(order, record.key)
+		case intentOpUndo:
+			write, exists := writes[record.key]
+			if !exists {
+				return nil
+			}
+			var undo writeUndo
+			if err := json.Unmarshal(record.value, &undo); err != nil {
+				return fmt.Errorf("failed to decode before-image in transaction %s: %w", record.key, err)
+			}
+			write.undo = append(write.undo, undo)
+		case intentOpCommit, intentOpAbort:
+			delete(writes, record.key)
+		}
+		return nil
+	}
+
+	if err := c.log.loadSnapshot(func([]byte) error {
+		return fmt.Errorf("write intent log is not in the log format")
+	}, apply); err != nil {
+		return nil, err
+	}
+	if err := c.log.replay(apply); err != nil {
+		return nil, err
+	}
+
+	var pending []interruptedWrite
+	for _, id := range order {
+		if write, exists := writes[id]; exists {
+			pending = append(pending, *write)
+		}
+	}
+	return pending, nil
+}
+
+// resume reopens an interrupted write as a transaction so it can be rolled back
+func (c *writeCoordinator) resume(write interruptedWrite, vectorStore VectorStore, graphStore GraphStore, searchIndex SearchIndex) *writeTransaction {
+	return &writeTransaction{
+		id:          write.id,
+		coordinator: c,
+		vectorStore: vectorStore,
+		graphStore:  graphStore,
+		searchIndex: searchIndex,
+		captured:    make(map[string]bool),
+		undo:        write.undo,
+	}
+}
+
+// compact empties the intent log once it has grown and no transaction is left unresolved
+func (c *writeCoordinator) compact(force bool) error {
+	if c.log == nil || c.unresolved > 0 {
+		return nil
+	}
+	if !force && c.log.pending < storeLogCompactAfter {
+		return nil
+	}
+	return c.log.snapshot(func(emit func(storeRecord)) error { return nil })
+}
+
+// Vectors returns the vector store as seen by the transaction
+func (tx *writeTransaction) Vectors() VectorStore {
+	return &txVectorStore{VectorStore: tx.vectorStore, tx: tx}
+}
+
+// Graph returns the graph store as seen by the transaction
+func (tx *writeTransaction) Graph() GraphStore {
+	return &txGraphStore{GraphStore: tx.graphStore, tx: tx}
+}
+
+// Search returns the search index as seen by the transaction
+func (tx *writeTransaction) Search() SearchIndex {
+	return &txSearchIndex{SearchIndex: tx.searchIndex, tx: tx}
+}
+
+// capture records the before-image of a key the first time the transaction changes it,
+// logging it ahead of the change
+func (tx *writeTransaction) capture(ctx context.Context, view, id string) error {
+	key := view + "\x00" + id
+	if tx.captured[key] {
+		return nil
+	}
+
+	undo := writeUndo{View: view, ID: id}
+	switch view {
+	case writeViewVector:
+		if result, err := tx.vectorStore.GetByID(ctx, id); err == nil {
+			undo.Exists = true
+			undo.Vector = &VectorStoreRecord{ID: id, Embedding: result.Embedding, Metadata: result.Metadata}
+		}
+	case writeViewDocument:
+		if doc, err := tx.searchIndex.GetDocument(ctx, id); err == nil {
+			undo.Exists = true
+			undo.Document = doc
+		}
+	case writeViewNode:
+		if node, err := tx.graphStore.GetNode(ctx, id); err == nil {
+			undo.Exists = true
+			undo.Node = node
+		}
+	case writeViewEdge:
+		if edge, err := tx.graphStore.GetEdge(ctx, id); err == nil {
+			undo.Exists = true
+			undo.Edge = edge
+		}
+	}
+
+	// Keep a copy decoded from the logged form, so later changes to shared maps cannot
+	// alter the image and a rollback restores exactly what recovery would
+	value, err := json.Marshal(undo)
+	if err != nil {
+		return fmt.Errorf("failed to marshal before-image of %s %s: %w", view, id, err)
+	}
+	if tx.coordinator.log != nil {
+		if err := tx.coordinator.log.append(storeRecord{op: intentOpUndo, key: tx.id, value: value}); err != nil {
+			return err
+		}
+	}
+	var image writeUndo
+	if err := json.Unmarshal(value, &image); err != nil {
+		return fmt.Errorf("failed to copy before-image of %s %s: %w", view, id, err)
+	}
+
+	tx.captured[key] = true
+	tx.undo = append(tx.undo, image)
+	return nil
+}
+
+// commit records that every view was written
+func (tx *writeTransaction) commit() error {
+	coordinator := tx.coordinator
+	if coordinator.log == nil {
+		return nil
+	}
+	if err := coordinator.log.append(storeRecord{op: intentOpCommit, key: tx.id}); err != nil {
+		return err
+	}
+	return coordinator.compact(false)
+}
+
+// rollback restores every before-image, newest first, and records the abort once all of
+// them are restored. Restoring is idempotent, so a rollback cut short can be run again.
+func (tx *writeTransaction) rollback(ctx context.Context) error {
+	var errors []error
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		if err := tx.restore(ctx, tx.undo[i]); err != nil {
+			errors = append(errors, fmt.Errorf("failed to restore %s %s: %w", tx.undo[i].View, tx.undo[i].ID, err))
+		}
+	}
+
+	coordinator := tx.coordinator
+	if len(errors) > 0 {
+		coordinator.unresolved++
+		return fmt.Errorf("rollback failed: %v", errors)
+	}
+	if coordinator.log == nil {
+		return nil
+	}
+	if err := coordinator.log.append(storeRecord{op: intentOpAbort, key: tx.id}); err != nil {
+		return err
+	}
+	return coordinator.compact(false)
+}
+
+// restore puts one key back the way its before-image describes, recreating or updating it
+// when it existed and deleting it, if present, when it did not
+func (tx *writeTransaction) restore(ctx context.Context, undo writeUndo) error {
+	switch undo.View {
+	case writeViewVector:
+		if undo.Exists && undo.Vector != nil {
+			return tx.vectorStore.Store(ctx, undo.ID, undo.Vector.Embedding, undo.Vector.Metadata)
+		}
+		if _, err := tx.vectorStore.GetByID(ctx, undo.ID); err != nil {
+			return nil
+		}
+		return tx.vectorStore.Delete(ctx, undo.ID)
+	case writeViewDocument:
+		if undo.Exists && undo.Document != nil {
+			return tx.searchIndex.Index(ctx, *undo.Document)
+		}
+		if exists, err := tx.searchIndex.DocumentExists(ctx, undo.ID); err != nil || !exists {
+			return nil
+		}
+		return tx.searchIndex.Delete(ctx, undo.ID)
+	case writeViewNode:
+		_, err := tx.graphStore.GetNode(ctx, undo.ID)
+		present := err == nil
+		switch {
+		case undo.Exists && undo.Node != nil && present:
+			return tx.graphStore.UpdateNode(ctx, undo.Node)
+		case undo.Exists && undo.Node != nil:
+			return tx.graphStore.CreateNode(ctx, undo.Node)
+		case present:
+			return tx.graphStore.DeleteNode(ctx, undo.ID)
+		}
+	case writeViewEdge:
+		_, err := tx.graphStore.GetEdge(ctx, undo.ID)
+		present := err == nil
+		switch {
+		case undo.Exists && undo.Edge != nil && present:
+			return tx.graphStore.UpdateEdge(ctx, undo.Edge)
+		case undo.Exists && undo.Edge != nil:
+			return tx.graphStore.CreateEdge(ctx, undo.Edge)
+		case present:
+			return tx.graphStore.DeleteEdge(ctx, undo.ID)
+		}
+	default:
+		return fmt.Errorf("unknown view %q", undo.View)
+	}
+	return nil
+}
+
+// txVectorStore records a before-image of each vector ahead of changing it
+type txVectorStore struct {
+	VectorStore
+	tx *writeTransaction
+}
+
+// Store captures the vector, then stores it
+func (s *txVectorStore) Store(ctx context.Context, id string, embedding []float32, metadata map[string]interface{}) error {
+	if err := s.tx.capture(ctx, writeViewVector, id); err != nil {
+		return err
+	}
+	return s.VectorStore.Store(ctx, id, embedding, metadata)
+}
+
+// Delete captures the vector, then deletes it
+func (s *txVectorStore) Delete(ctx context.Context, id string) error {
+	if err := s.tx.capture(ctx, writeViewVector, id); err != nil {
+		return err
+	}
+	return s.VectorStore.Delete(ctx, id)
+}
+
+// BatchStore captures every vector, then stores them
+func (s *txVectorStore) BatchStore(ctx context.Context, items []VectorStoreItem) error {
+	for _, item := range items {
+		if err := s.tx.capture(ctx, writeViewVector, item.ID); err != nil {
+			return err
+		}
+	}
+	return s.VectorStore.BatchStore(ctx, items)
+}
+
+// Update captures the vector, then updates its metadata
+func (s *txVectorStore) Update(ctx context.Context, id string, metadata map[string]interface{}) error {
+	if err := s.tx.capture(ctx, writeViewVector, id); err != nil {
+		return err
+	}
+	return s.VectorStore.Update(ctx, id, metadata)
+}
+
+// txSearchIndex records a before-image of each document ahead of changing it
+type txSearchIndex struct {
+	SearchIndex
+	tx *writeTransaction
+}
+
+// Index captures the document, then indexes it
+func (s *txSearchIndex) Index(ctx context.Context, doc IndexDocument) error {
+	if err := s.tx.capture(ctx, writeViewDocument, doc.ID); err != nil {
+		return err
+	}
+	return s.SearchIndex.Index(ctx, doc)
+}
+
+// Delete captures the document, then deletes it
+func (s *txSearchIndex) Delete(ctx context.Context, id string) error {
+	if err := s.tx.capture(ctx, writeViewDocument, id); err != nil {
+		return err
+	}
+	return s.SearchIndex.Delete(ctx, id)
+}
+
+// BatchIndex captures every document, then indexes them
+func (s *txSearchIndex) BatchIndex(ctx context.Context, docs []IndexDocument) error {
+	for _, doc := range docs {
+		if err := s.tx.capture(ctx, writeViewDocument, doc.ID); err != nil {
+			return err
+		}
+	}
+	return s.SearchIndex.BatchIndex(ctx, docs)
+}
+
+// Update captures the document, then replaces it
+func (s *txSearchIndex) Update(ctx context.Context, id string, doc IndexDocument) error {
+	if err := s.tx.capture(ctx, writeViewDocument, id); err != nil {
+		return err
+	}
+	return s.SearchIndex.Update(ctx, id, doc)
+}
+
+// txGraphStore records a before-image of each node and edge ahead of changing it
+type txGraphStore struct {
+	GraphStore
+	tx *writeTransaction
+}
+
+// CreateNode captures the node, then creates it
+func (s *txGraphStore) CreateNode(ctx context.Context, node *Node) error {
+	if err := s.tx.capture(ctx, writeViewNode, node.ID); err != nil {
+		return err
+	}
+	return s.GraphStore.CreateNode(ctx, node)
+}
+
+// UpdateNode captures the node, then updates it
+func (s *txGraphStore) UpdateNode(ctx context.Context, node *Node) error {
+	if err := s.tx.capture(ctx, writeViewNode, node.ID); err != nil {
+		return err
+	}
+	return s.GraphStore.UpdateNode(ctx, node)
+}
+
+// DeleteNode captures the node's edges and then the node, so a rollback recreates the node
+// before its edges, then deletes it
+func (s *txGraphStore) DeleteNode(ctx context.Context, id string) error {
+	edges, err := s.GraphStore.GetNodeEdges(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to list edges of node %s: %w", id, err)
+	}
+	for _, edge := range edges {
+		if err := s.tx.capture(ctx, writeViewEdge, edge.ID); err != nil {
+			return err
+		}
+	}
+	if err := s.tx.capture(ctx, writeViewNode, id); err != nil {
+		return err
+	}
+	return s.GraphStore.DeleteNode(ctx, id)
+}
+
+// CreateEdge captures the edge, then creates it
+func (s *txGraphStore) CreateEdge(ctx context.Context, edge *Edge) error {
+	if err := s.tx.capture(ctx, writeViewEdge, edge.ID); err != nil {
+		return err
+	}
+	return s.GraphStore.CreateEdge(ctx, edge)
+}
+
+// UpdateEdge captures the edge, then updates it
+func (s *txGraphStore) UpdateEdge(ctx context.Context, edge *Edge) error {
+	if err := s.tx.capture(ctx, writeViewEdge, edge.ID); err != nil {
+		return err
+	}
+	return s.GraphStore.UpdateEdge(ctx, edge)
+}
+
+// DeleteEdge captures the edge, then deletes it
+func (s *txGraphStore) DeleteEdge(ctx context.Context, id string) error {
+	if err := s.tx.capture(ctx, writeViewEdge, id); err != nil {
+		return err
+	}
+	return s.GraphStore.DeleteEdge(ctx, id)
+}
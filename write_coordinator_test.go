@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWriteCoordinatorRecovery(t *testing.T) {
+	Convey("Given file-backed storage with a write intent log", t, func() {
+		ctx := context.Background()
+		config := DefaultServerConfig().Storage
+		config.DataDir = t.TempDir()
+		config.VectorStore.Provider = "file"
+		config.GraphStore.Provider = "file"
+		config.SearchIndex.Provider = "file"
+
+		storage, err := NewStorageFromConfig(&config)
+		So(err, ShouldBeNil)
+		So(storage.writes.log, ShouldNotBeNil)
+
+		chunk := NewChunk("recovered_chunk", "Alice met Bob at the lab", "recovery-test")
+		chunk.Embedding = []float32{0.3, 0.1, 0.2}
+		chunk.Entities = []Entity{
+			{ID: "entity_alice", Name: "Alice", Type: "PERSON"},
+			{ID: "entity_bob", Name: "Bob", Type: "PERSON"},
+		}
+
+		Convey("When a write is interrupted after only some views were written", func() {
+			tx, err := storage.writes.begin(chunk, storage.vectorStore, storage.graphStore, storage.searchIndex)
+			So(err, ShouldBeNil)
+			So(tx.Vectors().Store(ctx, chunk.ID, chunk.Embedding, chunk.Metadata), ShouldBeNil)
+			So(tx.Search().Index(ctx, IndexDocument{ID: chunk.ID, Content: chunk.Content}), ShouldBeNil)
+
+			reopened, err := NewStorageFromConfig(&config)
+			So(err, ShouldBeNil)
+			defer reopened.Close()
+
+			Convey("Then reopening should finish it in every view", func() {
+				_, err := reopened.vectorStore.GetByID(ctx, chunk.ID)
+				So(err, ShouldBeNil)
+				_, err = reopened.searchIndex.GetDocument(ctx, chunk.ID)
+				So(err, ShouldBeNil)
+				node, err := reopened.graphStore.GetNode(ctx, chunk.ID)
+				So(err, ShouldBeNil)
+				So(node.Type, ShouldEqual, ChunkNode)
+				_, err = reopened.graphStore.GetNode(ctx, "entity_alice")
+				So(err, ShouldBeNil)
+			})
+
+			Convey("Then the resolved intents should be compacted away", func() {
+				_, err := os.Stat(filepath.Join(config.DataDir, writeIntentLogName+".log"))
+				So(os.IsNotExist(err), ShouldBeTrue)
+				pending, err := newWriteCoordinator(filepath.Join(config.DataDir, writeIntentLogName)).interrupted()
+				So(err, ShouldBeNil)
+				So(pending, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When a write reached every view but was never committed", func() {
+			tx, err := storage.writes.begin(chunk, storage.vectorStore, storage.graphStore, storage.searchIndex)
+			So(err, ShouldBeNil)
+			So(writeChunkViews(ctx, tx.Vectors(), tx.Search(), tx.Graph(), chunk), ShouldBeNil)
+
+			reopened, err := NewStorageFromConfig(&config)
+			So(err, ShouldBeNil)
+			defer reopened.Close()
+
+			Convey("Then replaying it should not apply its changes twice", func() {
+				edge, err := reopened.graphStore.GetEdge(ctx, graphEdgeID(RelatedTo, "entity_alice", "entity_bob"))
+				So(err, ShouldBeNil)
+				So(edge.Weight, ShouldEqual, 1.0)
+			})
+		})
+
+		Convey("When an interrupted write overwrote an existing chunk", func() {
+			So(storage.StoreChunk(ctx, chunk), ShouldBeNil)
+
+			tx, err := storage.writes.begin(chunk, storage.vectorStore, storage.graphStore, storage.searchIndex)
+			So(err, ShouldBeNil)
+			So(tx.Search().Index(ctx, IndexDocument{ID: chunk.ID, Content: "half written"}), ShouldBeNil)
+			So(tx.Graph().DeleteNode(ctx, "entity_bob"), ShouldBeNil)
+
+			pending, err := newWriteCoordinator(filepath.Join(config.DataDir, writeIntentLogName)).interrupted()
+			So(err, ShouldBeNil)
+			So(len(pending), ShouldEqual, 1)
+
+			Convey("Then rolling it back should restore the earlier version, edges included", func() {
+				So(tx.rollback(ctx), ShouldBeNil)
+
+				doc, err := storage.searchIndex.GetDocument(ctx, chunk.ID)
+				So(err, ShouldBeNil)
+				So(doc.Content, ShouldEqual, chunk.Content)
+				_, err = storage.graphStore.GetNode(ctx, "entity_bob")
+				So(err, ShouldBeNil)
+				_, err = storage.graphStore.GetEdge(ctx, graphEdgeID(PartOf, "entity_bob", chunk.ID))
+				So(err, ShouldBeNil)
+				_, err = storage.graphStore.GetEdge(ctx, graphEdgeID(RelatedTo, "entity_alice", "entity_bob"))
+				So(err, ShouldBeNil)
+
+				pending, err := newWriteCoordinator(filepath.Join(config.DataDir, writeIntentLogName)).interrupted()
+				So(err, ShouldBeNil)
+				So(pending, ShouldBeEmpty)
+			})
+		})
+	})
+
+	Convey("Given storage over in-memory backends", t, func() {
+		config := DefaultServerConfig().Storage
+		config.DataDir = t.TempDir()
+		storage, err := NewStorageFromConfig(&config)
+		So(err, ShouldBeNil)
+
+		Convey("Then write intents should stay in memory", func() {
+			So(storage.writes.log, ShouldBeNil)
+			So(storage.StoreChunk(context.Background(), NewChunk("memory_chunk", "Kept in memory", "test")), ShouldBeNil)
+			entries, err := os.ReadDir(config.DataDir)
+			So(err, ShouldBeNil)
+			So(entries, ShouldBeEmpty)
+		})
+	})
+}
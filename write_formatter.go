@@ -86,11 +86,15 @@ func (f *WriteResponseFormatter) Format(response *WriteResponse, args WriteArgs)
 	formattedProvenanceID := f.formatProvenanceID(response.ProvenanceID)
 
 	result := WriteResult{
-		MemoryID:       formattedMemoryID,
-		CandidateCount: response.CandidateCount,
-		ConflictsFound: formattedConflicts,
-		EntitiesLinked: formattedEntities,
-		ProvenanceID:   formattedProvenanceID,
+		MemoryID:        formattedMemoryID,
+		CandidateCount:  response.CandidateCount,
+		ConflictsFound:  formattedConflicts,
+		EntitiesLinked:  formattedEntities,
+		ProvenanceID:    formattedProvenanceID,
+		ChunksCreated:   response.ChunksCreated,
+		ChunksUnchanged: response.ChunksUnchanged,
+		ChunksRemoved:   response.ChunksRemoved,
+		GraphUpdates:    response.GraphUpdates,
 	}
 
 	return result, nil
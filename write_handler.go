@@ -43,7 +43,7 @@ func NewWriteHandler(memoryWriter *MemoryWriter, contentProcessor *ContentProces
 		memoryWriter:     memoryWriter,
 		contentProcessor: contentProcessor,
 		validator:        NewWriteArgsValidator(),
-		formatter:        NewWriteResponseFormatter(),
+		formatter:        newWriteHandlerFormatter(),
 		config:           config,
 	}
 }
@@ -58,11 +58,18 @@ func NewWriteHandlerWithConfig(memoryWriter *MemoryWriter, contentProcessor *Con
 		memoryWriter:     memoryWriter,
 		contentProcessor: contentProcessor,
 		validator:        NewWriteArgsValidator(),
-		formatter:        NewWriteResponseFormatter(),
+		formatter:        newWriteHandlerFormatter(),
 		config:           config,
 	}
 }
 
+// newWriteHandlerFormatter returns a formatter that keeps memory IDs intact so clients can pass them to memory_manage
+func newWriteHandlerFormatter() *WriteResponseFormatter {
+	formatter := NewWriteResponseFormatter()
+	formatter.GetConfig().TruncateIDs = false
+	return formatter
+}
+
 // HandleWrite processes a memory write request
 func (wh *WriteHandler) HandleWrite(ctx context.Context, req *mcp.CallToolRequest, args WriteArgs) (*mcp.CallToolResult, WriteResult, error) {
 	startTime := time.Now()
@@ -84,12 +91,6 @@ func (wh *WriteHandler) HandleWrite(ctx context.Context, req *mcp.CallToolReques
 	writeCtx, cancel := context.WithTimeout(ctx, wh.config.ProcessingTimeout)
 	defer cancel()
 
-	// Process the content
-	processedContent, err := wh.processContent(writeCtx, sanitizedArgs.Content, sanitizedArgs.Source)
-	if err != nil {
-		return nil, WriteResult{}, fmt.Errorf("content processing failed: %w", err)
-	}
-
 	// Convert args to write metadata
 	metadata := wh.convertArgsToMetadata(sanitizedArgs)
 
@@ -99,29 +100,21 @@ func (wh *WriteHandler) HandleWrite(ctx context.Context, req *mcp.CallToolReques
 		return nil, WriteResult{}, fmt.Errorf("memory write failed: %w", err)
 	}
 
-	// Detect conflicts if enabled
-	var conflicts []ConflictInfo
-	if wh.config.EnableConflictDetection {
-		conflicts, err = wh.detectConflicts(writeCtx, processedContent, writeResponse)
-		if err != nil {
-			log.Printf("Conflict detection failed: %v", err)
-			// Don't fail the write operation, just log the error
-		}
-	}
+	// Report the conflicts the writer found
+	conflicts := wh.reportedConflicts(writeResponse)
 
 	// Create enhanced write response
 	enhancedResponse := &WriteResponse{
-		MemoryID:       writeResponse.MemoryID,
-		CandidateCount: writeResponse.CandidateCount,
-		ConflictsFound: conflicts,
-		EntitiesLinked: writeResponse.EntitiesLinked,
-		ProvenanceID:   writeResponse.ProvenanceID,
-		ChunksCreated:  processedContent.Stats.ChunkCount,
-		GraphUpdates: GraphUpdates{
-			NodesCreated: processedContent.Stats.EntityCount + processedContent.Stats.ClaimCount,
-			EdgesCreated: len(processedContent.Claims), // Simplified edge count
-		},
-		ProcessingTime: time.Since(startTime),
+		MemoryID:        writeResponse.MemoryID,
+		CandidateCount:  writeResponse.CandidateCount,
+		ConflictsFound:  conflicts,
+		EntitiesLinked:  writeResponse.EntitiesLinked,
+		ProvenanceID:    writeResponse.ProvenanceID,
+		ChunksCreated:   writeResponse.ChunksCreated,
+		ChunksUnchanged: writeResponse.ChunksUnchanged,
+		ChunksRemoved:   writeResponse.ChunksRemoved,
+		GraphUpdates:    writeResponse.GraphUpdates,
+		ProcessingTime:  time.Since(startTime),
 	}
 
 	// Format the response
@@ -131,12 +124,17 @@ func (wh *WriteHandler) HandleWrite(ctx context.Context, req *mcp.CallToolReques
 	}
 
 	// Create MCP result
+	text := fmt.Sprintf("Stored memory with ID: %s, created %d chunks, linked %d entities",
+		result.MemoryID, result.ChunksCreated, len(result.EntitiesLinked))
+	if sanitizedArgs.Reingest {
+		text += fmt.Sprintf(", kept %d unchanged chunks, removed %d", result.ChunksUnchanged, result.ChunksRemoved)
+	}
+	if result.MemoryID == "" {
+		text = fmt.Sprintf("Stored nothing: content duplicates existing memory (%d conflicts)", len(result.ConflictsFound))
+	}
 	mcpResult := &mcp.CallToolResult{
 		Content: []mcp.Content{
-			&mcp.TextContent{
-				Text: fmt.Sprintf("Stored memory with ID: %s, created %d chunks, linked %d entities",
-					result.MemoryID, enhancedResponse.ChunksCreated, len(result.EntitiesLinked)),
-			},
+			&mcp.TextContent{Text: text},
 		},
 	}
 
@@ -146,123 +144,17 @@ func (wh *WriteHandler) HandleWrite(ctx context.Context, req *mcp.CallToolReques
 	return mcpResult, result, nil
 }
 
-// processContent handles the core content processing logic
-func (wh *WriteHandler) processContent(_ context.Context, content, source string) (*ProcessingResult, error) {
-	// Process content through the content processor
-	processedContent, err := wh.contentProcessor.Process(content, source)
-	if err != nil {
-		return nil, fmt.Errorf("content processing failed: %w", err)
-	}
-
-	// Validate processing results
-	if len(processedContent.Chunks) == 0 {
-		return nil, fmt.Errorf("no valid chunks created from content")
-	}
-
-	// Apply additional processing filters
-	processedContent = wh.applyProcessingFilters(processedContent)
-
-	return processedContent, nil
-}
-
-// detectConflicts detects potential conflicts with existing memories
-func (wh *WriteHandler) detectConflicts(_ context.Context, processedContent *ProcessingResult, writeResponse *WriteResult) ([]ConflictInfo, error) {
-	conflicts := make([]ConflictInfo, 0)
-
-	// Simple conflict detection based on entity overlap
-	// This would be enhanced with actual similarity checking in a full implementation
-	for _, chunk := range processedContent.Chunks {
-		for _, entity := range chunk.Entities {
-			// Check if entity already exists with different claims
-			// This is a placeholder - real implementation would query the graph store
-			if wh.hasConflictingClaims(entity) {
-				conflicts = append(conflicts, ConflictInfo{
-					ID:             fmt.Sprintf("entity_conflict_%s", entity.ID),
-					Type:           "entity_claim_conflict",
-					Description:    fmt.Sprintf("Entity '%s' has conflicting claims in existing memory", entity.Name),
-					ConflictingIDs: []string{entity.ID},
-					Severity:       "medium",
-				})
-			}
-		}
-	}
-
-	// Check for duplicate content
-	if wh.hasDuplicateContent(processedContent) {
-		conflicts = append(conflicts, ConflictInfo{
-			ID:             fmt.Sprintf("duplicate_content_%s", writeResponse.MemoryID),
-			Type:           "duplicate_content",
-			Description:    "Similar content already exists in memory",
-			ConflictingIDs: []string{writeResponse.MemoryID},
-			Severity:       "low",
-		})
-	}
-
-	return conflicts, nil
-}
-
-// hasConflictingClaims checks if an entity has conflicting claims (placeholder)
-func (wh *WriteHandler) hasConflictingClaims(_ Entity) bool {
-	// Placeholder implementation
-	// Real implementation would query the graph store for existing claims about this entity
-	return false
-}
-
-// hasDuplicateContent checks for duplicate content (placeholder)
-func (wh *WriteHandler) hasDuplicateContent(_ *ProcessingResult) bool {
-	// Placeholder implementation
-	// Real implementation would use similarity search to find duplicate content
-	return false
-}
-
-// applyProcessingFilters applies additional filters to processed content
-func (wh *WriteHandler) applyProcessingFilters(processedContent *ProcessingResult) *ProcessingResult {
-	// Filter out low-confidence entities and claims
-	filtered := &ProcessingResult{
-		Chunks:   make([]*Chunk, 0),
-		Entities: make([]*Entity, 0),
-		Claims:   make([]*Claim, 0),
-		Stats:    processedContent.Stats,
-	}
-
-	for _, chunk := range processedContent.Chunks {
-		// Filter entities by confidence
-		var filteredEntities []Entity
-		for _, entity := range chunk.Entities {
-			if entity.Confidence >= 0.5 { // Minimum confidence threshold
-				filteredEntities = append(filteredEntities, entity)
-			}
-		}
-		chunk.Entities = filteredEntities
-
-		// Filter claims by confidence
-		var filteredClaims []Claim
-		for _, claim := range chunk.Claims {
-			if claim.Confidence >= 0.6 { // Minimum confidence threshold for claims
-				filteredClaims = append(filteredClaims, claim)
-			}
+// reportedConflicts returns the conflicts the memory writer found: the near-duplicates it
+// handled and, when conflict detection is enabled, the stored claims the new ones contradict
+func (wh *WriteHandler) reportedConflicts(writeResponse *WriteResult) []ConflictInfo {
+	conflicts := make([]ConflictInfo, 0, len(writeResponse.ConflictsFound))
+	for _, conflict := range writeResponse.ConflictsFound {
+		if conflict.Type == "claim_contradiction" && !wh.config.EnableConflictDetection {
+			continue
 		}
-		chunk.Claims = filteredClaims
-
-		// Only keep chunks that have entities or claims
-		if len(chunk.Entities) > 0 || len(chunk.Claims) > 0 || len(chunk.Content) > 0 {
-			filtered.Chunks = append(filtered.Chunks, chunk)
-		}
-	}
-
-	// Update stats
-	filtered.Stats.ChunkCount = len(filtered.Chunks)
-
-	entityCount := 0
-	claimCount := 0
-	for _, chunk := range filtered.Chunks {
-		entityCount += len(chunk.Entities)
-		claimCount += len(chunk.Claims)
+		conflicts = append(conflicts, conflict)
 	}
-	filtered.Stats.EntityCount = entityCount
-	filtered.Stats.ClaimCount = claimCount
-
-	return filtered
+	return conflicts
 }
 
 // convertArgsToMetadata converts WriteArgs to WriteMetadata
@@ -276,6 +168,9 @@ func (wh *WriteHandler) convertArgsToMetadata(args WriteArgs) WriteMetadata {
 		Language:        "en",
 		ContentType:     "text/plain",
 		Version:         "1.0",
+		ExternalID:      args.ExternalID,
+		IdempotencyKey:  args.IdempotencyKey,
+		Reingest:        args.Reingest,
 		Metadata:        args.Metadata,
 	}
 
@@ -301,6 +196,11 @@ func (wh *WriteHandler) convertArgsToMetadata(args WriteArgs) WriteMetadata {
 		metadata.UserID = userID
 	}
 
+	// Set external ID from metadata if not given as an argument
+	if externalID, ok := args.Metadata["external_id"].(string); ok && metadata.ExternalID == "" {
+		metadata.ExternalID = externalID
+	}
+
 	return metadata
 }
 
@@ -97,6 +97,7 @@ func TestWriteHandler(t *testing.T) {
 				So(result.CandidateCount, ShouldBeGreaterThan, 0)
 				So(result.EntitiesLinked, ShouldNotBeNil)
 				So(result.ProvenanceID, ShouldNotBeEmpty)
+				So(result.ChunksCreated, ShouldEqual, result.CandidateCount)
 				So(len(mcpResult.Content), ShouldBeGreaterThan, 0)
 			})
 
@@ -160,81 +161,29 @@ func TestWriteHandler(t *testing.T) {
 			})
 		})
 
-		Convey("processContent", func() {
-			ctx := context.Background()
-
-			Convey("Should process valid content", func() {
-				content := "This is a test document about machine learning algorithms."
-				source := "test_source"
-
-				result, err := handler.processContent(ctx, content, source)
-
-				So(err, ShouldBeNil)
-				So(result, ShouldNotBeNil)
-				So(len(result.Chunks), ShouldBeGreaterThan, 0)
-				So(result.Stats.OriginalLength, ShouldEqual, len(content))
-				So(result.Stats.ChunkCount, ShouldBeGreaterThan, 0)
-			})
-
-			Convey("Should reject empty content", func() {
-				content := ""
-				source := "test_source"
-
-				result, err := handler.processContent(ctx, content, source)
+		Convey("reportedConflicts", func() {
+			writeResponse := &WriteResult{
+				MemoryID: "test_memory_1",
+				ConflictsFound: []ConflictInfo{
+					{ID: "duplicate_content_1", Type: "duplicate_content", Severity: "low"},
+					{ID: "claim_conflict_1", Type: "claim_contradiction", ConflictingIDs: []string{"claim_new", "claim_old"}, Severity: "high"},
+				},
+			}
 
-				So(err, ShouldNotBeNil)
-				So(result, ShouldBeNil)
+			Convey("Should report every conflict the writer found", func() {
+				conflicts := handler.reportedConflicts(writeResponse)
+				So(conflicts, ShouldResemble, writeResponse.ConflictsFound)
 			})
 
-			Convey("Should process content with multiple sentences", func() {
-				content := "First sentence about AI. Second sentence about ML. Third sentence about data science."
-				source := "test_source"
-
-				result, err := handler.processContent(ctx, content, source)
-
-				So(err, ShouldBeNil)
-				So(result, ShouldNotBeNil)
-				So(len(result.Chunks), ShouldBeGreaterThan, 0)
-				So(result.Stats.ChunkCount, ShouldBeGreaterThan, 0)
+			Convey("Should leave out claim contradictions when conflict detection is disabled", func() {
+				handler.config.EnableConflictDetection = false
+				conflicts := handler.reportedConflicts(writeResponse)
+				So(len(conflicts), ShouldEqual, 1)
+				So(conflicts[0].Type, ShouldEqual, "duplicate_content")
 			})
-		})
-
-		Convey("detectConflicts", func() {
-			ctx := context.Background()
 
-			Convey("Should detect no conflicts for new content", func() {
-				processedContent := &ProcessingResult{
-					Chunks: []*Chunk{
-						{
-							ID:      "test_chunk_1",
-							Content: "Test content",
-							Entities: []Entity{
-								{ID: "entity_1", Name: "Test Entity", Type: "PERSON"},
-							},
-						},
-					},
-					Stats: ProcessingStats{ChunkCount: 1, EntityCount: 1},
-				}
-				writeResponse := &WriteResult{MemoryID: "test_memory_1"}
-
-				conflicts, err := handler.detectConflicts(ctx, processedContent, writeResponse)
-
-				So(err, ShouldBeNil)
-				So(conflicts, ShouldNotBeNil)
-				// With mock implementation, no conflicts should be detected
-				So(len(conflicts), ShouldEqual, 0)
-			})
-
-			Convey("Should handle empty processed content", func() {
-				processedContent := &ProcessingResult{
-					Chunks: []*Chunk{},
-					Stats:  ProcessingStats{ChunkCount: 0},
-				}
-				writeResponse := &WriteResult{MemoryID: "test_memory_1"}
-
-				conflicts, err := handler.detectConflicts(ctx, processedContent, writeResponse)
-
-				So(err, ShouldBeNil)
+			Convey("Should handle a write without conflicts", func() {
+				conflicts := handler.reportedConflicts(&WriteResult{MemoryID: "test_memory_1"})
 				So(conflicts, ShouldNotBeNil)
 				So(len(conflicts), ShouldEqual, 0)
 			})
@@ -295,6 +244,24 @@ func TestWriteHandler(t *testing.T) {
 				So(metadata.Language, ShouldEqual, "es")
 				So(metadata.UserID, ShouldEqual, "user123")
 			})
+
+			Convey("Should carry the external ID and idempotency key", func() {
+				args := WriteArgs{
+					Content:        "Test content",
+					Source:         "test_source",
+					ExternalID:     "doc-1",
+					IdempotencyKey: "retry-1",
+				}
+
+				metadata := handler.convertArgsToMetadata(args)
+
+				So(metadata.ExternalID, ShouldEqual, "doc-1")
+				So(metadata.IdempotencyKey, ShouldEqual, "retry-1")
+
+				args.ExternalID = ""
+				args.Metadata = map[string]interface{}{"external_id": "doc-2"}
+				So(handler.convertArgsToMetadata(args).ExternalID, ShouldEqual, "doc-2")
+			})
 		})
 
 		Convey("Configuration", func() {
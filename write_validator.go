@@ -27,6 +27,7 @@ type WriteValidatorConfig struct {
 	ValidateUTF8           bool     `json:"validate_utf8"`
 	MaxMetadataKeys        int      `json:"max_metadata_keys"`
 	MaxMetadataValueLength int      `json:"max_metadata_value_length"`
+	MaxIdentifierLength    int      `json:"max_identifier_length"` // Limit on external IDs and idempotency keys
 }
 
 // WriteValidationError represents a validation error with details
@@ -68,6 +69,7 @@ func NewWriteArgsValidator() *WriteArgsValidator {
 		ValidateUTF8:           true,
 		MaxMetadataKeys:        20,
 		MaxMetadataValueLength: 500,
+		MaxIdentifierLength:    200,
 	}
 
 	return &WriteArgsValidator{
@@ -119,6 +121,10 @@ func (v *WriteArgsValidator) ValidateDetailed(args WriteArgs) *WriteValidationRe
 	// Validate metadata
 	v.validateMetadata(args.Metadata, result)
 
+	// Validate the identifiers that make writes replace or repeat earlier ones
+	result.Sanitized.ExternalID = v.validateIdentifier("externalId", args.ExternalID, result)
+	result.Sanitized.IdempotencyKey = v.validateIdentifier("idempotencyKey", args.IdempotencyKey, result)
+
 	// Check for blocked patterns
 	v.checkBlockedPatterns(args.Content, result)
 
@@ -285,6 +291,32 @@ func (v *WriteArgsValidator) validateSource(source string, result *WriteValidati
 	result.Sanitized.Source = v.sanitizeSource(source)
 }
 
+// validateIdentifier validates an optional identifier field and returns it trimmed
+func (v *WriteArgsValidator) validateIdentifier(field, value string, result *WriteValidationResult) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+
+	if v.config.MaxIdentifierLength > 0 && len(value) > v.config.MaxIdentifierLength {
+		result.Errors = append(result.Errors, WriteValidationError{
+			Field:   field,
+			Message: fmt.Sprintf("%s exceeds maximum length of %d characters", field, v.config.MaxIdentifierLength),
+			Value:   len(value),
+		})
+	}
+
+	if !utf8.ValidString(value) || v.removeControlCharacters(value) != value {
+		result.Errors = append(result.Errors, WriteValidationError{
+			Field:   field,
+			Message: fmt.Sprintf("%s contains invalid characters", field),
+			Value:   value,
+		})
+	}
+
+	return value
+}
+
 // validateTags validates the tags field
 func (v *WriteArgsValidator) validateTags(tags []string, result *WriteValidationResult) {
 	if tags == nil {
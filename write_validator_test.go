@@ -143,6 +143,24 @@ func TestWriteArgsValidator(t *testing.T) {
 				So(err.Error(), ShouldContainSubstring, "exceeds maximum length")
 			})
 
+			Convey("Should reject identifiers that are too long or hold control characters", func() {
+				args := WriteArgs{
+					Content:    "Valid content",
+					Source:     "test_source",
+					ExternalID: strings.Repeat("a", 201),
+				}
+
+				err := validator.Validate(args)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "externalId exceeds maximum length")
+
+				args.ExternalID = "doc-1"
+				args.IdempotencyKey = "retry\x00-1"
+				err = validator.Validate(args)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "idempotencyKey contains invalid characters")
+			})
+
 			Convey("Should reject blocked patterns", func() {
 				args := WriteArgs{
 					Content: "This content has <script>alert('xss')</script> in it",